@@ -0,0 +1,112 @@
+package goresilience
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"time"
+)
+
+var ErrConcurrencyLimited = errors.New("concurrency limited")
+
+// adaptiveBackoff is the factor the limit shrinks by after a failure or a
+// call slower than the latency target.
+const adaptiveBackoff = 0.9
+
+// adaptiveLimiter caps concurrent calls at a limit that grows by one per
+// limit's worth of fast successes and shrinks multiplicatively on failures
+// or slow calls (AIMD).
+type adaptiveLimiter struct {
+	minLimit      float64
+	maxLimit      float64
+	latencyTarget time.Duration
+	now           func() time.Time
+
+	mu       sync.Mutex
+	limit    float64
+	inFlight int
+}
+
+func newAdaptiveLimiter(a AdaptiveLimit, unit time.Duration) (*adaptiveLimiter, error) {
+	var errs configErrors
+
+	latencyTarget := errs.requiredDuration("latencyTarget", a.LatencyTarget, unit)
+
+	minLimit := a.MinLimit
+	if minLimit == 0 {
+		minLimit = 1
+	}
+
+	maxLimit := float64(a.MaxLimit)
+	if a.MaxLimit == 0 {
+		maxLimit = math.Inf(1)
+	}
+
+	if minLimit < 1 || a.InitialLimit < minLimit || float64(a.InitialLimit) > maxLimit {
+		errs.add("initialLimit", "need 1 <= minLimit (%d) <= initialLimit (%d) <= maxLimit (%d)", minLimit, a.InitialLimit, a.MaxLimit)
+	}
+
+	if err := errs.err(); err != nil {
+		return nil, err
+	}
+
+	return &adaptiveLimiter{
+		minLimit:      float64(minLimit),
+		maxLimit:      maxLimit,
+		latencyTarget: latencyTarget,
+		now:           time.Now,
+		limit:         float64(a.InitialLimit),
+	}, nil
+}
+
+func (l *adaptiveLimiter) acquire() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.inFlight >= int(l.limit) {
+		return false
+	}
+
+	l.inFlight++
+	return true
+}
+
+func (l *adaptiveLimiter) release(latency time.Duration, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.inFlight--
+
+	switch {
+	case errors.Is(err, ErrOpenState) || errors.Is(err, ErrTooManyRequests) || errors.Is(err, context.Canceled):
+		// Rejections and abandoned calls say nothing about the dependency.
+	case err != nil || latency > l.latencyTarget:
+		l.limit = max(l.minLimit, l.limit*adaptiveBackoff)
+	default:
+		l.limit = min(l.maxLimit, l.limit+1/l.limit)
+	}
+}
+
+// current returns the limit, rounded down to whole calls.
+func (l *adaptiveLimiter) current() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return int(l.limit)
+}
+
+func (p *Policy) withAdaptiveLimit(oper Operation) Operation {
+	return func(ctx context.Context) (res any, err error) {
+		if !p.limiter.acquire() {
+			return nil, p.rejected(ctx, "adaptiveLimit", ErrConcurrencyLimited)
+		}
+
+		start := p.limiter.now()
+		defer func() {
+			p.limiter.release(p.limiter.now().Sub(start), err)
+		}()
+
+		return oper(ctx)
+	}
+}
@@ -0,0 +1,147 @@
+package goresilience_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	goresilience "github.com/rickKoch/go-resilience"
+)
+
+func adaptiveProvider(t *testing.T, limit goresilience.AdaptiveLimit) (*goresilience.Provider, *fakeClock) {
+	t.Helper()
+
+	provider, err := goresilience.FromConfig(goresilience.Config{
+		AdaptiveLimits: map[string]goresilience.AdaptiveLimit{
+			"test_limit": limit,
+		},
+		Targets: map[string]goresilience.PolicyNames{
+			"test_target": {AdaptiveLimit: "test_limit"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	clock := &fakeClock{}
+	goresilience.SetAdaptiveLimitClock(provider, "test_limit", clock.Now)
+
+	return provider, clock
+}
+
+func TestAdaptiveLimitFollowsLatency(t *testing.T) {
+	provider, clock := adaptiveProvider(t, goresilience.AdaptiveLimit{
+		InitialLimit:  10,
+		MinLimit:      2,
+		MaxLimit:      20,
+		LatencyTarget: "100ms",
+	})
+
+	call := func(latency time.Duration) {
+		exec := goresilience.NewExecWithPolicy(context.Background(), provider.Policy("test_target"))
+		if _, err := exec(func(ctx context.Context) (any, error) {
+			clock.Advance(latency)
+			return successResult, nil
+		}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	limit := func() int {
+		return provider.Metrics().ConcurrencyLimits["test_limit"]
+	}
+
+	for i := 0; i < 20; i++ {
+		call(300 * time.Millisecond)
+	}
+	if limit() != 2 {
+		t.Fatalf("expected the limit to shrink to the minimum, got %d", limit())
+	}
+
+	for i := 0; i < 200; i++ {
+		call(10 * time.Millisecond)
+	}
+	if got := limit(); got <= 10 || got > 20 {
+		t.Fatalf("expected the limit to regrow within the maximum, got %d", got)
+	}
+
+	for i := 0; i < 500; i++ {
+		call(10 * time.Millisecond)
+	}
+	if limit() != 20 {
+		t.Fatalf("expected the limit to stop at the maximum, got %d", limit())
+	}
+}
+
+func TestAdaptiveLimitShrinksOnFailure(t *testing.T) {
+	provider, _ := adaptiveProvider(t, goresilience.AdaptiveLimit{InitialLimit: 10, LatencyTarget: "100ms"})
+
+	exec := goresilience.NewExecWithPolicy(context.Background(), provider.Policy("test_target"))
+	_, _ = exec(func(ctx context.Context) (any, error) {
+		return nil, errors.New("failed")
+	})
+
+	if got := provider.Metrics().ConcurrencyLimits["test_limit"]; got != 9 {
+		t.Fatalf("expected the limit to shrink to 9, got %d", got)
+	}
+}
+
+func TestAdaptiveLimitRejects(t *testing.T) {
+	provider, _ := adaptiveProvider(t, goresilience.AdaptiveLimit{InitialLimit: 2, MaxLimit: 2, LatencyTarget: "100ms"})
+
+	release := make(chan struct{})
+	started := make(chan struct{}, 2)
+	done := make(chan struct{}, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			exec := goresilience.NewExecWithPolicy(context.Background(), provider.Policy("test_target"))
+			_, _ = exec(func(ctx context.Context) (any, error) {
+				started <- struct{}{}
+				<-release
+				return successResult, nil
+			})
+			done <- struct{}{}
+		}()
+	}
+	<-started
+	<-started
+
+	exec := goresilience.NewExecWithPolicy(context.Background(), provider.Policy("test_target"))
+	if _, err := exec(func(ctx context.Context) (any, error) {
+		return successResult, nil
+	}); !errors.Is(err, goresilience.ErrConcurrencyLimited) {
+		t.Fatalf("expected ErrConcurrencyLimited, got: %v", err)
+	}
+
+	close(release)
+	<-done
+	<-done
+
+	if _, err := exec(func(ctx context.Context) (any, error) {
+		return successResult, nil
+	}); err != nil {
+		t.Fatalf("expected the call to be admitted once slots free up, got: %v", err)
+	}
+}
+
+func TestAdaptiveLimitValidation(t *testing.T) {
+	tests := []struct {
+		name  string
+		limit goresilience.AdaptiveLimit
+	}{
+		{name: "missing latency target", limit: goresilience.AdaptiveLimit{InitialLimit: 10}},
+		{name: "initial below min", limit: goresilience.AdaptiveLimit{InitialLimit: 1, MinLimit: 2, LatencyTarget: "1s"}},
+		{name: "initial above max", limit: goresilience.AdaptiveLimit{InitialLimit: 10, MaxLimit: 5, LatencyTarget: "1s"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := goresilience.FromConfig(goresilience.Config{
+				AdaptiveLimits: map[string]goresilience.AdaptiveLimit{"test_limit": tt.limit},
+			})
+			if err == nil {
+				t.Fatal("expected a validation error")
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package goresilience
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// buildAliases maps every runtime name in cfg.Aliases to the target it is
+// an alias of. A name may only stand for one target, and may not shadow a
+// target of its own.
+func buildAliases(cfg Config) (map[string]string, error) {
+	var errs configErrors
+
+	aliases := make(map[string]string)
+	for _, target := range slices.Sorted(maps.Keys(cfg.Aliases)) {
+		path := entryPath("aliases", target)
+
+		if _, ok := cfg.Targets[target]; !ok {
+			errs.add(path, "%q is not a target", target)
+			continue
+		}
+
+		for i, alias := range cfg.Aliases[target] {
+			aliasPath := fmt.Sprintf("%s[%d]", path, i)
+
+			switch prev, dup := aliases[alias]; {
+			case alias == "":
+				errs.add(aliasPath, "must not be empty")
+			case dup:
+				errs.add(aliasPath, "%q is already an alias of %q", alias, prev)
+			default:
+				if _, ok := cfg.Targets[alias]; ok {
+					errs.add(aliasPath, "%q is already a target", alias)
+					continue
+				}
+				aliases[alias] = target
+			}
+		}
+	}
+
+	return aliases, errs.err()
+}
@@ -0,0 +1,117 @@
+package goresilience_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	goresilience "github.com/rickKoch/go-resilience"
+)
+
+func TestAliasResolvesToTarget(t *testing.T) {
+	provider, err := goresilience.FromConfig(goresilience.Config{
+		Retries: map[string]goresilience.Retry{
+			"test_retry": {Duration: "1ms", MaxRetries: 2},
+		},
+		Targets: map[string]goresilience.PolicyNames{
+			"users": {Retry: "test_retry"},
+		},
+		Aliases: map[string][]string{
+			"users": {"pkg.Users/Get", "pkg.Users/List"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	for _, name := range []string{"users", "pkg.Users/Get", "pkg.Users/List"} {
+		exec := goresilience.NewExecWithPolicy(context.Background(), provider.Policy(name))
+		if calls := countAttempts(exec); calls != 3 {
+			t.Errorf("%s: expected 3 attempts, got %d", name, calls)
+		}
+	}
+
+	exec := goresilience.NewExecWithPolicy(context.Background(), provider.Policy("pkg.Users/Delete"))
+	if calls := countAttempts(exec); calls != 1 {
+		t.Errorf("expected an unaliased name to get no retries, got %d attempts", calls)
+	}
+}
+
+func TestAliasFollowsReload(t *testing.T) {
+	cfg := goresilience.Config{
+		Retries: map[string]goresilience.Retry{
+			"test_retry": {Duration: "1ms", MaxRetries: 2},
+		},
+		Targets: map[string]goresilience.PolicyNames{
+			"users":  {Retry: "test_retry"},
+			"orders": {},
+		},
+		Aliases: map[string][]string{
+			"users": {"pkg.Service/Method"},
+		},
+	}
+
+	provider, err := goresilience.FromConfig(cfg)
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+	policy := provider.Policy("pkg.Service/Method")
+
+	cfg.Aliases = map[string][]string{
+		"orders": {"pkg.Service/Method"},
+	}
+	if err := provider.UpdateConfig(cfg); err != nil {
+		t.Fatalf("failed to update config: %v", err)
+	}
+
+	exec := goresilience.NewExecWithPolicy(context.Background(), policy)
+	if calls := countAttempts(exec); calls != 1 {
+		t.Fatalf("expected the alias to move to orders, got %d attempts", calls)
+	}
+}
+
+func TestAliasValidation(t *testing.T) {
+	tests := []struct {
+		name       string
+		aliases    map[string][]string
+		expectPath string
+	}{
+		{
+			name:       "alias of two targets",
+			aliases:    map[string][]string{"users": {"pkg.Method"}, "orders": {"pkg.Method"}},
+			expectPath: `aliases["users"][0]`,
+		},
+		{
+			name:       "alias shadows a target",
+			aliases:    map[string][]string{"users": {"orders"}},
+			expectPath: `aliases["users"][0]`,
+		},
+		{
+			name:       "unknown target",
+			aliases:    map[string][]string{"payments": {"pkg.Method"}},
+			expectPath: `aliases["payments"]`,
+		},
+		{
+			name:       "empty alias",
+			aliases:    map[string][]string{"users": {""}},
+			expectPath: `aliases["users"][0]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := goresilience.FromConfig(goresilience.Config{
+				Targets: map[string]goresilience.PolicyNames{
+					"users":  {},
+					"orders": {},
+				},
+				Aliases: tt.aliases,
+			})
+
+			var cfgErr *goresilience.ConfigError
+			if !errors.As(err, &cfgErr) || cfgErr.Path != tt.expectPath {
+				t.Fatalf("expected a ConfigError at %s, got: %v", tt.expectPath, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package goresilience
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// AllOption configures ExecuteAll.
+type AllOption func(*allOptions)
+
+type allOptions struct {
+	failFast bool
+}
+
+// FailFast makes ExecuteAll stop at the first failed input: no more inputs
+// are started, those running have their context cancelled, and only that
+// input's error is returned.
+func FailFast() AllOption {
+	return func(o *allOptions) {
+		o.failFast = true
+	}
+}
+
+// ExecuteAll runs fn for every input under policy, at most limit at a time,
+// or all at once if limit is less than 1. Results are in the order of
+// inputs, with R's zero value for the ones that failed or never ran. The
+// errors of failed inputs are joined, each naming its input's index. Once
+// ctx is done no more inputs are started, and if any were left ctx's error
+// is joined too.
+func ExecuteAll[T, R any](ctx context.Context, policy *Policy, inputs []T, limit int, fn func(ctx context.Context, in T) (R, error), opts ...AllOption) ([]R, error) {
+	var o allOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if limit < 1 || limit > len(inputs) {
+		limit = len(inputs)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([]R, len(inputs))
+	errs := make([]error, len(inputs))
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+		started  int
+	)
+
+	sem := make(chan struct{}, limit)
+
+launch:
+	for i, in := range inputs {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			break launch
+		}
+
+		// select picks at random when a slot frees up as ctx is done.
+		if ctx.Err() != nil {
+			break
+		}
+
+		started++
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			res, err := Execute(ctx, policy, func(ctx context.Context) (R, error) {
+				return fn(ctx, in)
+			})
+			if err == nil {
+				results[i] = res
+				return
+			}
+
+			errs[i] = fmt.Errorf("input %d: %w", i, err)
+			if o.failFast {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = errs[i]
+					cancel()
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if firstErr != nil {
+		return results, firstErr
+	}
+
+	if started < len(inputs) {
+		errs = append(errs, ctx.Err())
+	}
+
+	return results, errors.Join(errs...)
+}
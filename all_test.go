@@ -0,0 +1,135 @@
+package goresilience_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	goresilience "github.com/rickKoch/go-resilience"
+)
+
+func TestExecuteAllOrder(t *testing.T) {
+	provider, err := goresilience.FromConfig(reloadConfig(0, reloadBreaker))
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	var running, peak atomic.Int32
+	inputs := []int{5, 4, 3, 2, 1, 0}
+
+	got, err := goresilience.ExecuteAll(context.Background(), provider.Policy("test_target"), inputs, 2, func(ctx context.Context, in int) (string, error) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+
+		// Later inputs finish first.
+		time.Sleep(time.Duration(in) * time.Millisecond)
+		return fmt.Sprint(in), nil
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if want := []string{"5", "4", "3", "2", "1", "0"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected results %q, got %q", want, got)
+	}
+	if n := peak.Load(); n > 2 {
+		t.Fatalf("expected at most 2 concurrent executions, got %d", n)
+	}
+}
+
+func TestExecuteAllPartialFailure(t *testing.T) {
+	provider, err := goresilience.FromConfig(reloadConfig(1, goresilience.CircuitBreaker{MaxRequests: 1, Interval: "10s", Timeout: "10s", Failures: 100}))
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	var calls atomic.Int32
+	got, err := goresilience.ExecuteAll(context.Background(), provider.Policy("test_target"), []int{1, 2, 3, 4}, 0, func(ctx context.Context, in int) (int, error) {
+		calls.Add(1)
+		if in%2 == 0 {
+			return in, testError
+		}
+		return in * 10, nil
+	})
+
+	if want := []int{10, 0, 30, 0}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected results %v, got %v", want, got)
+	}
+	if !errors.Is(err, testError) {
+		t.Fatalf("expected the failures to be joined, got %v", err)
+	}
+	for _, want := range []string{"input 1: ", "input 3: "} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("expected %q in the error, got %v", want, err)
+		}
+	}
+
+	// Each failed input was retried once under the policy.
+	if n := calls.Load(); n != 6 {
+		t.Fatalf("expected 6 calls, got %d", n)
+	}
+}
+
+func TestExecuteAllFailFast(t *testing.T) {
+	provider, err := goresilience.FromConfig(reloadConfig(0, reloadBreaker))
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	var started atomic.Int32
+	inputs := make([]int, 20)
+	for i := range inputs {
+		inputs[i] = i
+	}
+
+	_, err = goresilience.ExecuteAll(context.Background(), provider.Policy("test_target"), inputs, 2, func(ctx context.Context, in int) (int, error) {
+		started.Add(1)
+		if in == 1 {
+			return 0, testError
+		}
+
+		<-ctx.Done()
+		return 0, ctx.Err()
+	}, goresilience.FailFast())
+
+	if err == nil || err.Error() != "input 1: "+testError.Error() {
+		t.Fatalf("expected only the first failure, got %v", err)
+	}
+	if n := started.Load(); n != 2 {
+		t.Fatalf("expected no inputs to start after the failure, got %d started", n)
+	}
+}
+
+func TestExecuteAllCancelled(t *testing.T) {
+	provider, err := goresilience.FromConfig(reloadConfig(0, reloadBreaker))
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var started atomic.Int32
+	_, err = goresilience.ExecuteAll(ctx, provider.Policy("test_target"), []int{1, 2, 3, 4}, 1, func(ctx context.Context, in int) (int, error) {
+		started.Add(1)
+		cancel()
+		return in, nil
+	})
+
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the cancellation to be reported, got %v", err)
+	}
+	if n := started.Load(); n != 1 {
+		t.Fatalf("expected no inputs to start after the cancellation, got %d started", n)
+	}
+}
@@ -0,0 +1,113 @@
+package goresilience
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+type breakerKeys struct {
+	maxKeys int
+	idle    time.Duration
+	now     func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List
+}
+
+type keyedEntry struct {
+	key      string
+	entry    *breakerEntry
+	lastUsed time.Time
+}
+
+// newBreakerKeys keeps up to maxKeys keyed breakers, evicting those unused
+// for idle by the time now, the owning breaker's clock, reports.
+func newBreakerKeys(maxKeys int, idle time.Duration, now func() time.Time) *breakerKeys {
+	return &breakerKeys{
+		maxKeys: maxKeys,
+		idle:    idle,
+		now:     now,
+		entries: make(map[string]*list.Element),
+		lru:     list.New(),
+	}
+}
+
+func (k *breakerKeys) get(key string, create func() *breakerEntry) *breakerEntry {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	now := k.now()
+	k.evictIdle(now)
+
+	if elem, ok := k.entries[key]; ok {
+		ke := elem.Value.(*keyedEntry)
+		ke.lastUsed = now
+		k.lru.MoveToFront(elem)
+		return ke.entry
+	}
+
+	ke := &keyedEntry{key: key, entry: create(), lastUsed: now}
+	k.entries[key] = k.lru.PushFront(ke)
+
+	for k.lru.Len() > k.maxKeys {
+		k.remove(k.lru.Back())
+	}
+
+	return ke.entry
+}
+
+func (k *breakerKeys) peek(key string) *breakerEntry {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.evictIdle(k.now())
+
+	if elem, ok := k.entries[key]; ok {
+		return elem.Value.(*keyedEntry).entry
+	}
+
+	return nil
+}
+
+func (k *breakerKeys) clear() {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.entries = make(map[string]*list.Element)
+	k.lru.Init()
+}
+
+func (k *breakerKeys) each(fn func(key string, entry *breakerEntry)) {
+	k.mu.Lock()
+	k.evictIdle(k.now())
+
+	entries := make([]*keyedEntry, 0, k.lru.Len())
+	for elem := k.lru.Front(); elem != nil; elem = elem.Next() {
+		entries = append(entries, elem.Value.(*keyedEntry))
+	}
+	k.mu.Unlock()
+
+	for _, ke := range entries {
+		fn(ke.key, ke.entry)
+	}
+}
+
+func (k *breakerKeys) evictIdle(now time.Time) {
+	if k.idle <= 0 {
+		return
+	}
+
+	for elem := k.lru.Back(); elem != nil; elem = k.lru.Back() {
+		if now.Sub(elem.Value.(*keyedEntry).lastUsed) < k.idle {
+			return
+		}
+		k.remove(elem)
+	}
+}
+
+func (k *breakerKeys) remove(elem *list.Element) {
+	k.lru.Remove(elem)
+	delete(k.entries, elem.Value.(*keyedEntry).key)
+}
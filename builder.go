@@ -0,0 +1,189 @@
+package goresilience
+
+// RetryOpts and CBOpts configure the retries and circuit breakers added
+// with a ConfigBuilder.
+type (
+	RetryOpts = Retry
+	CBOpts    = CircuitBreaker
+)
+
+// ConfigBuilder assembles a Config in code:
+//
+//	cfg, err := goresilience.NewConfigBuilder().
+//		Retry("std", goresilience.RetryOpts{Duration: "100ms", MaxRetries: 3}).
+//		Timeout("fast", "2s").
+//		Target("orders", goresilience.UseRetry("std"), goresilience.UseTimeout("fast")).
+//		Build()
+//
+// Defining a name twice, and everything FromConfig rejects, is reported by
+// Build.
+type ConfigBuilder struct {
+	cfg  Config
+	errs configErrors
+}
+
+// TargetOption sets one of a target's policies in ConfigBuilder.Target.
+type TargetOption func(*PolicyNames)
+
+func NewConfigBuilder() *ConfigBuilder {
+	return &ConfigBuilder{}
+}
+
+// Timeout adds a timeout of the given duration in the detached mode.
+func (b *ConfigBuilder) Timeout(name, duration string) *ConfigBuilder {
+	return b.TimeoutSpec(name, TimeoutSpec{Duration: duration})
+}
+
+func (b *ConfigBuilder) TimeoutSpec(name string, spec TimeoutSpec) *ConfigBuilder {
+	b.cfg.Timeouts = addEntry(&b.errs, "timeouts", b.cfg.Timeouts, name, spec)
+	return b
+}
+
+func (b *ConfigBuilder) Retry(name string, opts RetryOpts) *ConfigBuilder {
+	b.cfg.Retries = addEntry(&b.errs, "retries", b.cfg.Retries, name, opts)
+	return b
+}
+
+func (b *ConfigBuilder) CircuitBreaker(name string, opts CBOpts) *ConfigBuilder {
+	b.cfg.CircuitBreakers = addEntry(&b.errs, "circuitBreakers", b.cfg.CircuitBreakers, name, opts)
+	return b
+}
+
+func (b *ConfigBuilder) Bulkhead(name string, opts Bulkhead) *ConfigBuilder {
+	b.cfg.Bulkheads = addEntry(&b.errs, "bulkheads", b.cfg.Bulkheads, name, opts)
+	return b
+}
+
+func (b *ConfigBuilder) Cache(name string, opts Cache) *ConfigBuilder {
+	b.cfg.Caches = addEntry(&b.errs, "caches", b.cfg.Caches, name, opts)
+	return b
+}
+
+func (b *ConfigBuilder) AdaptiveLimit(name string, opts AdaptiveLimit) *ConfigBuilder {
+	b.cfg.AdaptiveLimits = addEntry(&b.errs, "adaptiveLimits", b.cfg.AdaptiveLimits, name, opts)
+	return b
+}
+
+func (b *ConfigBuilder) Shed(name string, opts Shed) *ConfigBuilder {
+	b.cfg.Sheds = addEntry(&b.errs, "sheds", b.cfg.Sheds, name, opts)
+	return b
+}
+
+func (b *ConfigBuilder) Target(name string, opts ...TargetOption) *ConfigBuilder {
+	var names PolicyNames
+	for _, opt := range opts {
+		opt(&names)
+	}
+
+	b.cfg.Targets = addEntry(&b.errs, "targets", b.cfg.Targets, name, names)
+	return b
+}
+
+func (b *ConfigBuilder) Chain(name string, targets ...string) *ConfigBuilder {
+	b.cfg.Chains = addEntry(&b.errs, "chains", b.cfg.Chains, name, targets)
+	return b
+}
+
+// Group gives the policies opts set to every member without a target of
+// its own.
+func (b *ConfigBuilder) Group(name string, members []string, opts ...TargetOption) *ConfigBuilder {
+	var g TargetGroup
+	for _, opt := range opts {
+		opt(&g.PolicyNames)
+	}
+	g.Members = members
+
+	b.cfg.Groups = addEntry(&b.errs, "groups", b.cfg.Groups, name, g)
+	return b
+}
+
+// Alias lets Policy find target under each of names as well.
+func (b *ConfigBuilder) Alias(target string, names ...string) *ConfigBuilder {
+	b.cfg.Aliases = addEntry(&b.errs, "aliases", b.cfg.Aliases, target, names)
+	return b
+}
+
+// DefaultTimeout sets the timeout of targets that do not set their own.
+func (b *ConfigBuilder) DefaultTimeout(ref string) *ConfigBuilder {
+	b.cfg.Defaults.Timeout = ref
+	return b
+}
+
+// Build returns the assembled config, or every problem found in it.
+func (b *ConfigBuilder) Build() (Config, error) {
+	if err := b.errs.err(); err != nil {
+		return Config{}, err
+	}
+
+	if _, err := FromConfig(b.cfg); err != nil {
+		return Config{}, err
+	}
+
+	// Copied so that later calls on b do not change the returned config.
+	return cloneConfig(b.cfg), nil
+}
+
+func addEntry[V any](errs *configErrors, section string, entries map[string]V, name string, v V) map[string]V {
+	if _, ok := entries[name]; ok {
+		errs.add(entryPath(section, name), "is defined twice")
+		return entries
+	}
+
+	if entries == nil {
+		entries = make(map[string]V)
+	}
+	entries[name] = v
+	return entries
+}
+
+func UseTimeout(name string) TargetOption {
+	return func(n *PolicyNames) { n.Timeout = name }
+}
+
+func UseOverallTimeout(name string) TargetOption {
+	return func(n *PolicyNames) { n.OverallTimeout = name }
+}
+
+func UseMinDeadline(d string) TargetOption {
+	return func(n *PolicyNames) { n.MinDeadline = d }
+}
+
+func UseRetry(name string) TargetOption {
+	return func(n *PolicyNames) { n.Retry = name }
+}
+
+func UseCircuitBreaker(name string) TargetOption {
+	return func(n *PolicyNames) { n.CircuitBreaker = name }
+}
+
+func UseBulkhead(name string) TargetOption {
+	return func(n *PolicyNames) { n.Bulkhead = name }
+}
+
+func UseCache(name string) TargetOption {
+	return func(n *PolicyNames) { n.Cache = name }
+}
+
+func UseAdaptiveLimit(name string) TargetOption {
+	return func(n *PolicyNames) { n.AdaptiveLimit = name }
+}
+
+func UseShed(name string) TargetOption {
+	return func(n *PolicyNames) { n.Shed = name }
+}
+
+func UseSingleflight() TargetOption {
+	return func(n *PolicyNames) { n.Singleflight = true }
+}
+
+func UseCoalesce(window string) TargetOption {
+	return func(n *PolicyNames) { n.Coalesce = Coalesce{Window: window} }
+}
+
+func UseFallbackOn(on string) TargetOption {
+	return func(n *PolicyNames) { n.FallbackOn = on }
+}
+
+func UseOrder(stages ...string) TargetOption {
+	return func(n *PolicyNames) { n.Order = stages }
+}
@@ -0,0 +1,95 @@
+package goresilience_test
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	goresilience "github.com/rickKoch/go-resilience"
+)
+
+func TestConfigBuilder(t *testing.T) {
+	built, err := goresilience.NewConfigBuilder().
+		Retry("std", goresilience.RetryOpts{Duration: "1ms", MaxRetries: 2}).
+		Timeout("fast", "2s").
+		CircuitBreaker("db", goresilience.CBOpts{MaxRequests: 1, Interval: "10s", Timeout: "10s", Failures: 5}).
+		Target("orders", goresilience.UseRetry("std"), goresilience.UseTimeout("fast"), goresilience.UseCircuitBreaker("db")).
+		Target("payments", goresilience.UseRetry("std"), goresilience.UseFallbackOn(goresilience.FallbackOnAny)).
+		Chain("checkout", "orders", "payments").
+		Build()
+	if err != nil {
+		t.Fatalf("failed to build config: %v", err)
+	}
+
+	literal := goresilience.Config{
+		Timeouts: map[string]goresilience.TimeoutSpec{
+			"fast": {Duration: "2s"},
+		},
+		Retries: map[string]goresilience.Retry{
+			"std": {Duration: "1ms", MaxRetries: 2},
+		},
+		CircuitBreakers: map[string]goresilience.CircuitBreaker{
+			"db": {MaxRequests: 1, Interval: "10s", Timeout: "10s", Failures: 5},
+		},
+		Targets: map[string]goresilience.PolicyNames{
+			"orders":   {Retry: "std", Timeout: "fast", CircuitBreaker: "db"},
+			"payments": {Retry: "std", FallbackOn: goresilience.FallbackOnAny},
+		},
+		Chains: map[string][]string{
+			"checkout": {"orders", "payments"},
+		},
+	}
+	if !reflect.DeepEqual(built, literal) {
+		t.Fatalf("expected %+v, got %+v", literal, built)
+	}
+
+	builtProvider, err := goresilience.FromConfig(built)
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+	literalProvider, err := goresilience.FromConfig(literal)
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	got := countAttempts(goresilience.NewExecWithPolicy(context.Background(), builtProvider.Policy("orders")))
+	want := countAttempts(goresilience.NewExecWithPolicy(context.Background(), literalProvider.Policy("orders")))
+	if got != want {
+		t.Fatalf("expected %d attempts, got %d", want, got)
+	}
+}
+
+func TestConfigBuilderErrors(t *testing.T) {
+	_, err := goresilience.NewConfigBuilder().
+		Retry("std", goresilience.RetryOpts{Duration: "1ms"}).
+		Retry("std", goresilience.RetryOpts{Duration: "2ms"}).
+		Build()
+	if err == nil || !strings.Contains(err.Error(), `retries["std"]: is defined twice`) {
+		t.Fatalf("expected a duplicate name error, got: %v", err)
+	}
+
+	_, err = goresilience.NewConfigBuilder().
+		Target("orders", goresilience.UseRetry("missing")).
+		Build()
+	if err == nil || !strings.Contains(err.Error(), `targets["orders"].retry: "missing" is not defined`) {
+		t.Fatalf("expected a missing reference error, got: %v", err)
+	}
+}
+
+func TestConfigBuilderReuse(t *testing.T) {
+	b := goresilience.NewConfigBuilder().Timeout("fast", "2s")
+
+	first, err := b.Build()
+	if err != nil {
+		t.Fatalf("failed to build config: %v", err)
+	}
+
+	if _, err := b.Timeout("slow", "10s").Build(); err != nil {
+		t.Fatalf("failed to build config: %v", err)
+	}
+
+	if _, ok := first.Timeouts["slow"]; ok {
+		t.Fatal("expected an earlier config to be unaffected by later builder calls")
+	}
+}
@@ -0,0 +1,117 @@
+package goresilience
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sync/semaphore"
+)
+
+var (
+	ErrBulkheadFull      = errors.New("bulkhead full")
+	ErrBulkheadQueueFull = errors.New("bulkhead queue full")
+)
+
+type bulkhead struct {
+	name     string
+	sem      *semaphore.Weighted
+	maxWait  time.Duration
+	maxQueue int64
+
+	active atomic.Int64
+	queued atomic.Int64
+	wait   histogram
+}
+
+func newBulkhead(name string, b Bulkhead, unit time.Duration) (*bulkhead, error) {
+	var errs configErrors
+
+	if b.MaxConcurrent < 1 {
+		errs.add("maxConcurrent", "must be at least 1, got %d", b.MaxConcurrent)
+	}
+
+	if b.MaxQueue < 0 {
+		errs.add("maxQueue", "must not be negative, got %d", b.MaxQueue)
+	}
+
+	maxWait := errs.duration("maxWait", b.MaxWait, unit)
+	if err := errs.err(); err != nil {
+		return nil, err
+	}
+
+	return &bulkhead{
+		name:     name,
+		sem:      semaphore.NewWeighted(int64(b.MaxConcurrent)),
+		maxWait:  maxWait,
+		maxQueue: int64(b.MaxQueue),
+		wait:     newHistogram(bulkheadWaitBounds),
+	}, nil
+}
+
+// acquire takes a slot, waiting at most maxWait for one to free up. Without
+// maxWait it fails immediately when the bulkhead is full; with maxQueue it
+// also fails immediately when that many callers are already waiting.
+func (b *bulkhead) acquire(ctx context.Context) error {
+	if b.sem.TryAcquire(1) {
+		b.wait.observe(0)
+		b.active.Add(1)
+		return nil
+	}
+
+	if b.maxWait <= 0 {
+		return fmt.Errorf("%w: %q", ErrBulkheadFull, b.name)
+	}
+
+	if queued := b.queued.Add(1); b.maxQueue > 0 && queued > b.maxQueue {
+		b.queued.Add(-1)
+		return fmt.Errorf("%w: %q has %d waiting", ErrBulkheadQueueFull, b.name, b.maxQueue)
+	}
+	defer b.queued.Add(-1)
+
+	waitCtx, cancel := context.WithTimeout(ctx, b.maxWait)
+	defer cancel()
+
+	start := time.Now()
+	if err := b.sem.Acquire(waitCtx, 1); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		return fmt.Errorf("%w: %q after waiting %s", ErrBulkheadFull, b.name, b.maxWait)
+	}
+
+	b.wait.observe(time.Since(start))
+	b.active.Add(1)
+	return nil
+}
+
+func (b *bulkhead) release() {
+	b.active.Add(-1)
+	b.sem.Release(1)
+}
+
+func (b *bulkhead) metrics() BulkheadMetrics {
+	return BulkheadMetrics{
+		Active: int(b.active.Load()),
+		Queued: int(b.queued.Load()),
+		Wait:   b.wait.snapshot(),
+	}
+}
+
+func (p *Policy) withBulkhead(oper Operation) Operation {
+	return func(ctx context.Context) (any, error) {
+		if err := p.bulkhead.acquire(ctx); err != nil {
+			if ctx.Err() != nil {
+				return nil, err
+			}
+
+			return nil, p.rejected(ctx, "bulkhead", err)
+		}
+		defer p.bulkhead.release()
+
+		return oper(ctx)
+	}
+}
@@ -0,0 +1,292 @@
+package goresilience_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	goresilience "github.com/rickKoch/go-resilience"
+)
+
+func bulkheadProvider(t *testing.T, bh goresilience.Bulkhead, opts ...goresilience.Option) *goresilience.Provider {
+	t.Helper()
+
+	provider, err := goresilience.FromConfigWithOptions(goresilience.Config{
+		Timeouts: map[string]goresilience.TimeoutSpec{
+			"test_timeout": {Duration: "20ms"},
+		},
+		Bulkheads: map[string]goresilience.Bulkhead{
+			"test_bulkhead": bh,
+		},
+		Targets: map[string]goresilience.PolicyNames{
+			"test_target":  {Bulkhead: "test_bulkhead"},
+			"other_target": {Bulkhead: "test_bulkhead"},
+			"timed_target": {Bulkhead: "test_bulkhead", Timeout: "test_timeout"},
+		},
+	}, opts...)
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	return provider
+}
+
+func TestBulkheadRejectsWhenSaturated(t *testing.T) {
+	provider := bulkheadProvider(t, goresilience.Bulkhead{MaxConcurrent: 3})
+
+	release := make(chan struct{})
+	var started sync.WaitGroup
+	var holders sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		started.Add(1)
+		holders.Add(1)
+		go func() {
+			defer holders.Done()
+			exec := goresilience.NewExecWithPolicy(context.Background(), provider.Policy("test_target"))
+			_, _ = exec(func(ctx context.Context) (any, error) {
+				started.Done()
+				<-release
+				return successResult, nil
+			})
+		}()
+	}
+	started.Wait()
+
+	var rejected atomic.Int32
+	var callers sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		callers.Add(1)
+		go func() {
+			defer callers.Done()
+			// The bulkhead is shared, so other targets are rejected too.
+			exec := goresilience.NewExecWithPolicy(context.Background(), provider.Policy("other_target"))
+			_, err := exec(func(ctx context.Context) (any, error) {
+				return successResult, nil
+			})
+			if errors.Is(err, goresilience.ErrBulkheadFull) {
+				rejected.Add(1)
+			}
+		}()
+	}
+	callers.Wait()
+
+	if rejected.Load() != 10 {
+		t.Fatalf("expected 10 rejections, got %d", rejected.Load())
+	}
+
+	close(release)
+	holders.Wait()
+
+	exec := goresilience.NewExecWithPolicy(context.Background(), provider.Policy("test_target"))
+	if _, err := exec(func(ctx context.Context) (any, error) {
+		return successResult, nil
+	}); err != nil {
+		t.Fatalf("expected a free slot after release, got: %v", err)
+	}
+}
+
+func TestBulkheadMaxWait(t *testing.T) {
+	provider := bulkheadProvider(t, goresilience.Bulkhead{MaxConcurrent: 1, MaxWait: "200ms"})
+
+	started := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		exec := goresilience.NewExecWithPolicy(context.Background(), provider.Policy("test_target"))
+		_, _ = exec(func(ctx context.Context) (any, error) {
+			close(started)
+			time.Sleep(20 * time.Millisecond)
+			return successResult, nil
+		})
+	}()
+	<-started
+
+	exec := goresilience.NewExecWithPolicy(context.Background(), provider.Policy("test_target"))
+	if _, err := exec(func(ctx context.Context) (any, error) {
+		return successResult, nil
+	}); err != nil {
+		t.Fatalf("expected the waiting call to get a slot, got: %v", err)
+	}
+	<-done
+
+	provider = bulkheadProvider(t, goresilience.Bulkhead{MaxConcurrent: 1, MaxWait: "10ms"})
+	release := make(chan struct{})
+	started = make(chan struct{})
+	go func() {
+		exec := goresilience.NewExecWithPolicy(context.Background(), provider.Policy("test_target"))
+		_, _ = exec(func(ctx context.Context) (any, error) {
+			close(started)
+			<-release
+			return successResult, nil
+		})
+	}()
+	<-started
+	defer close(release)
+
+	exec = goresilience.NewExecWithPolicy(context.Background(), provider.Policy("test_target"))
+	if _, err := exec(func(ctx context.Context) (any, error) {
+		return successResult, nil
+	}); !errors.Is(err, goresilience.ErrBulkheadFull) {
+		t.Fatalf("expected ErrBulkheadFull after waiting, got: %v", err)
+	}
+}
+
+func TestBulkheadReleasesSlot(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		opts   []goresilience.Option
+		oper   goresilience.Operation
+	}{
+		{
+			name:   "recovered panic",
+			target: "test_target",
+			oper: func(ctx context.Context) (any, error) {
+				panic("boom")
+			},
+		},
+		{
+			name:   "unrecovered panic",
+			target: "test_target",
+			opts:   []goresilience.Option{goresilience.WithPanicRecovery(false)},
+			oper: func(ctx context.Context) (any, error) {
+				panic("boom")
+			},
+		},
+		{
+			name:   "timeout",
+			target: "timed_target",
+			oper: func(ctx context.Context) (any, error) {
+				time.Sleep(200 * time.Millisecond)
+				return successResult, nil
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := bulkheadProvider(t, goresilience.Bulkhead{MaxConcurrent: 1}, tt.opts...)
+
+			func() {
+				defer func() { _ = recover() }()
+				exec := goresilience.NewExecWithPolicy(context.Background(), provider.Policy(tt.target))
+				_, _ = exec(tt.oper)
+			}()
+
+			exec := goresilience.NewExecWithPolicy(context.Background(), provider.Policy(tt.target))
+			if _, err := exec(func(ctx context.Context) (any, error) {
+				return successResult, nil
+			}); err != nil {
+				t.Fatalf("expected the slot to be released, got: %v", err)
+			}
+		})
+	}
+}
+
+func TestBulkheadValidation(t *testing.T) {
+	_, err := goresilience.FromConfig(goresilience.Config{
+		Bulkheads: map[string]goresilience.Bulkhead{
+			"test_bulkhead": {MaxConcurrent: 0},
+		},
+	})
+	if err == nil {
+		t.Fatal("expected an error for a bulkhead without maxConcurrent")
+	}
+}
+
+func TestBulkheadQueueLimit(t *testing.T) {
+	provider := bulkheadProvider(t, goresilience.Bulkhead{MaxConcurrent: 1, MaxWait: "300ms", MaxQueue: 1})
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	go func() {
+		exec := goresilience.NewExecWithPolicy(context.Background(), provider.Policy("test_target"))
+		_, _ = exec(func(ctx context.Context) (any, error) {
+			close(started)
+			<-release
+			return successResult, nil
+		})
+	}()
+	<-started
+
+	waited := make(chan error, 1)
+	go func() {
+		exec := goresilience.NewExecWithPolicy(context.Background(), provider.Policy("test_target"))
+		_, err := exec(func(ctx context.Context) (any, error) {
+			return successResult, nil
+		})
+		waited <- err
+	}()
+
+	for provider.Metrics().Bulkheads["test_bulkhead"].Queued != 1 {
+		time.Sleep(time.Millisecond)
+	}
+
+	m := provider.Metrics().Bulkheads["test_bulkhead"]
+	if m.Active != 1 {
+		t.Fatalf("expected 1 active call, got %d", m.Active)
+	}
+
+	exec := goresilience.NewExecWithPolicy(context.Background(), provider.Policy("test_target"))
+	start := time.Now()
+	_, err := exec(func(ctx context.Context) (any, error) {
+		return successResult, nil
+	})
+	if !errors.Is(err, goresilience.ErrBulkheadQueueFull) {
+		t.Fatalf("expected ErrBulkheadQueueFull, got: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Fatalf("expected an immediate rejection, took %s", elapsed)
+	}
+
+	if err := <-waited; !errors.Is(err, goresilience.ErrBulkheadFull) || errors.Is(err, goresilience.ErrBulkheadQueueFull) {
+		t.Fatalf("expected the queued call to fail with ErrBulkheadFull after waiting, got: %v", err)
+	}
+
+	close(release)
+}
+
+func TestBulkheadMetrics(t *testing.T) {
+	provider := bulkheadProvider(t, goresilience.Bulkhead{MaxConcurrent: 1, MaxWait: "1s"})
+
+	started := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		exec := goresilience.NewExecWithPolicy(context.Background(), provider.Policy("test_target"))
+		_, _ = exec(func(ctx context.Context) (any, error) {
+			close(started)
+			time.Sleep(20 * time.Millisecond)
+			return successResult, nil
+		})
+	}()
+	<-started
+
+	exec := goresilience.NewExecWithPolicy(context.Background(), provider.Policy("test_target"))
+	if _, err := exec(func(ctx context.Context) (any, error) {
+		return successResult, nil
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	<-done
+
+	m := provider.Metrics().Bulkheads["test_bulkhead"]
+	if m.Active != 0 || m.Queued != 0 {
+		t.Fatalf("expected idle gauges, got active %d queued %d", m.Active, m.Queued)
+	}
+	if m.Wait.Count != 2 {
+		t.Fatalf("expected 2 wait observations, got %d", m.Wait.Count)
+	}
+	if m.Wait.Counts[0] < 1 {
+		t.Fatalf("expected the first call in the lowest bucket, got %v", m.Wait.Counts)
+	}
+	if m.Wait.Sum < 10*time.Millisecond {
+		t.Fatalf("expected the queued call's wait in the sum, got %s", m.Wait.Sum)
+	}
+	if len(m.Wait.Counts) != len(m.Wait.Bounds)+1 {
+		t.Fatalf("expected an overflow bucket, got %d counts for %d bounds", len(m.Wait.Counts), len(m.Wait.Bounds))
+	}
+}
@@ -0,0 +1,151 @@
+package goresilience
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const defaultCacheEntries = 1000
+
+// ServedFromCache is returned alongside a cached result served because the
+// call failed. Err is the error that made the executor fall back to the
+// cache. A cache that answers before calling the operation returns its
+// results without an error; ExecutionResult.Cached reports those.
+type ServedFromCache struct {
+	Err error
+	Age time.Duration
+}
+
+func (e *ServedFromCache) Error() string {
+	return fmt.Sprintf("served from cache, age %s: %v", e.Age, e.Err)
+}
+
+func (e *ServedFromCache) Unwrap() error {
+	return e.Err
+}
+
+// resultCache is an LRU of successful results with a per-entry expiry.
+type resultCache struct {
+	ttl        time.Duration
+	maxEntries int
+	always     bool
+	now        func() time.Time
+
+	mu      sync.Mutex
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type cacheEntry struct {
+	key      string
+	value    any
+	storedAt time.Time
+}
+
+func newResultCache(c Cache, unit time.Duration) (*resultCache, error) {
+	var errs configErrors
+
+	ttl := errs.requiredDuration("ttl", c.TTL, unit)
+
+	if c.MaxEntries < 0 {
+		errs.add("maxEntries", "must not be negative, got %d", c.MaxEntries)
+	}
+
+	if err := errs.err(); err != nil {
+		return nil, err
+	}
+
+	maxEntries := c.MaxEntries
+	if maxEntries == 0 {
+		maxEntries = defaultCacheEntries
+	}
+
+	return &resultCache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		always:     c.Always,
+		now:        time.Now,
+		order:      list.New(),
+		entries:    make(map[string]*list.Element),
+	}, nil
+}
+
+func (c *resultCache) get(key string) (any, time.Duration, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, 0, false
+	}
+
+	entry := el.Value.(*cacheEntry)
+	age := c.now().Sub(entry.storedAt)
+	if age >= c.ttl {
+		c.order.Remove(el)
+		delete(c.entries, key)
+		return nil, 0, false
+	}
+
+	c.order.MoveToFront(el)
+	return entry.value, age, true
+}
+
+func (c *resultCache) set(key string, value any) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[key]; ok {
+		entry := el.Value.(*cacheEntry)
+		entry.value = value
+		entry.storedAt = c.now()
+		c.order.MoveToFront(el)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&cacheEntry{key: key, value: value, storedAt: c.now()})
+
+	for c.order.Len() > c.maxEntries {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+func (p *Policy) withCache(oper Operation) Operation {
+	return func(ctx context.Context) (any, error) {
+		key, ok := CacheKeyFromContext(ctx)
+		if !ok {
+			return oper(ctx)
+		}
+
+		// Caches may be shared between targets, so keys are scoped to the
+		// target to keep their results apart.
+		key = p.target + "\x00" + key
+
+		if p.cache.always {
+			if res, age, ok := p.cache.get(key); ok {
+				traceStep(ctx, "cache", "served entry aged %s", age)
+				traceCached(ctx)
+				return res, nil
+			}
+		}
+
+		res, err := oper(ctx)
+		if err == nil {
+			p.cache.set(key, res)
+			return res, nil
+		}
+
+		if cached, age, ok := p.cache.get(key); ok {
+			traceStep(ctx, "cache", "served entry aged %s after: %v", age, err)
+			traceCached(ctx)
+			return cached, &ServedFromCache{Err: err, Age: age}
+		}
+
+		return res, err
+	}
+}
@@ -0,0 +1,211 @@
+package goresilience_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	goresilience "github.com/rickKoch/go-resilience"
+)
+
+var errCacheTest = errors.New("downstream failed")
+
+func cacheProvider(t *testing.T, cache goresilience.Cache) (*goresilience.Provider, *fakeClock) {
+	t.Helper()
+
+	provider, err := goresilience.FromConfig(goresilience.Config{
+		CircuitBreakers: map[string]goresilience.CircuitBreaker{
+			"test_cb": {MaxRequests: 1, Interval: "10s", Timeout: "10s", Failures: 1},
+		},
+		Caches: map[string]goresilience.Cache{
+			"test_cache": cache,
+		},
+		Targets: map[string]goresilience.PolicyNames{
+			"test_target": {CircuitBreaker: "test_cb", Cache: "test_cache"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	clock := &fakeClock{}
+	clock.Advance(time.Hour)
+	goresilience.SetCacheClock(provider, "test_cache", clock.Now)
+
+	return provider, clock
+}
+
+func cachedCall(provider *goresilience.Provider, key string, res any, err error) (any, error) {
+	ctx := goresilience.WithCacheKey(context.Background(), key)
+	exec := goresilience.NewExecWithPolicy(ctx, provider.Policy("test_target"))
+	return exec(func(ctx context.Context) (any, error) {
+		return res, err
+	})
+}
+
+func TestCacheServesOnFailure(t *testing.T) {
+	provider, clock := cacheProvider(t, goresilience.Cache{TTL: "1m"})
+	provider.SetFallback("test_target", func(ctx context.Context, err error) (any, error) {
+		return "fallback", nil
+	})
+
+	if _, err := cachedCall(provider, "user-1", "alice", nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	clock.Advance(10 * time.Second)
+
+	// The failure trips the breaker and the next call is rejected; both are
+	// answered from the cache rather than the fallback.
+	for _, want := range []error{errCacheTest, goresilience.ErrOpenState} {
+		res, err := cachedCall(provider, "user-1", nil, errCacheTest)
+
+		var served *goresilience.ServedFromCache
+		if !errors.As(err, &served) {
+			t.Fatalf("expected ServedFromCache, got: %v", err)
+		}
+		if !errors.Is(err, want) {
+			t.Fatalf("expected the cause %v, got: %v", want, served.Err)
+		}
+		if res != "alice" {
+			t.Fatalf("expected the cached result, got %v", res)
+		}
+		if served.Age != 10*time.Second {
+			t.Fatalf("expected age 10s, got %s", served.Age)
+		}
+	}
+
+	if res, err := cachedCall(provider, "user-2", nil, errCacheTest); res != "fallback" || err != nil {
+		t.Fatalf("expected a miss to use the fallback, got %v, %v", res, err)
+	}
+}
+
+func TestCacheExpiry(t *testing.T) {
+	provider, clock := cacheProvider(t, goresilience.Cache{TTL: "1m"})
+
+	if _, err := cachedCall(provider, "user-1", "alice", nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	clock.Advance(time.Minute)
+
+	if _, err := cachedCall(provider, "user-1", nil, errCacheTest); !errors.Is(err, errCacheTest) {
+		t.Fatalf("expected the original error, got: %v", err)
+	}
+
+	var served *goresilience.ServedFromCache
+	if _, err := cachedCall(provider, "user-1", nil, errCacheTest); errors.As(err, &served) {
+		t.Fatalf("expected an expired entry not to be served, got: %v", err)
+	}
+}
+
+func TestCacheEviction(t *testing.T) {
+	provider, _ := cacheProvider(t, goresilience.Cache{TTL: "1m", MaxEntries: 2})
+	if err := provider.SetBreakerEnabled("test_cb", false); err != nil {
+		t.Fatalf("failed to disable breaker: %v", err)
+	}
+
+	for _, key := range []string{"a", "b"} {
+		if _, err := cachedCall(provider, key, key, nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	// Touch "a" so that "b" is the least recently used entry.
+	if _, err := cachedCall(provider, "a", nil, errCacheTest); err == nil {
+		t.Fatal("expected ServedFromCache for a")
+	}
+	if _, err := cachedCall(provider, "c", "c", nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for key, cached := range map[string]bool{"a": true, "b": false, "c": true} {
+		var served *goresilience.ServedFromCache
+		_, err := cachedCall(provider, key, nil, errCacheTest)
+		if errors.As(err, &served) != cached {
+			t.Fatalf("key %s: expected cached %v, got: %v", key, cached, err)
+		}
+	}
+}
+
+func TestCacheAlways(t *testing.T) {
+	provider, clock := cacheProvider(t, goresilience.Cache{TTL: "1m", Always: true})
+
+	if _, err := cachedCall(provider, "user-1", "alice", nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	res, err := cachedCall(provider, "user-1", "bob", nil)
+	if err != nil || res != "alice" {
+		t.Fatalf("expected a cache hit without calling the operation, got %v, %v", res, err)
+	}
+
+	ctx := goresilience.WithCacheKey(context.Background(), "user-1")
+	r, err := goresilience.ExecuteDetailed(ctx, provider.Policy("test_target"), func(ctx context.Context) (any, error) {
+		return "bob", nil
+	})
+	if err != nil || !r.Cached || r.Value != "alice" {
+		t.Fatalf("expected the hit to be reported as cached, got %+v, %v", r, err)
+	}
+
+	got, err := goresilience.ExecuteAll(ctx, provider.Policy("test_target"), []string{"bob", "carol"}, 0, func(ctx context.Context, name string) (string, error) {
+		return name, nil
+	})
+	if err != nil || !slices.Equal(got, []string{"alice", "alice"}) {
+		t.Fatalf("expected ExecuteAll to take the hits as results, got %v, %v", got, err)
+	}
+
+	clock.Advance(time.Minute)
+
+	if res, err := cachedCall(provider, "user-1", "bob", nil); res != "bob" || err != nil {
+		t.Fatalf("expected an expired entry to be refreshed, got %v, %v", res, err)
+	}
+}
+
+func TestCacheWithoutKey(t *testing.T) {
+	provider, _ := cacheProvider(t, goresilience.Cache{TTL: "1m", Always: true})
+
+	exec := goresilience.NewExecWithPolicy(context.Background(), provider.Policy("test_target"))
+	for i := 0; i < 2; i++ {
+		res, err := exec(func(ctx context.Context) (any, error) {
+			return i, nil
+		})
+		if res != i || err != nil {
+			t.Fatalf("expected calls without a key to bypass the cache, got %v, %v", res, err)
+		}
+	}
+}
+
+func TestCacheConcurrent(t *testing.T) {
+	provider, _ := cacheProvider(t, goresilience.Cache{TTL: "1m", MaxEntries: 8})
+	if err := provider.SetBreakerEnabled("test_cb", false); err != nil {
+		t.Fatalf("failed to disable breaker: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			key := fmt.Sprint(i % 16)
+			_, _ = cachedCall(provider, key, key, nil)
+			_, _ = cachedCall(provider, key, nil, errCacheTest)
+		}()
+	}
+	wg.Wait()
+}
+
+func TestCacheValidation(t *testing.T) {
+	_, err := goresilience.FromConfig(goresilience.Config{
+		Caches: map[string]goresilience.Cache{
+			"test_cache": {MaxEntries: 10},
+		},
+	})
+	if err == nil {
+		t.Fatal("expected an error for a cache without ttl")
+	}
+}
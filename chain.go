@@ -0,0 +1,79 @@
+package goresilience
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// ChainExecutor runs a chain's targets in order, each with its own policy
+// and the operation registered for it in ops, and returns the first
+// success. A target's FallbackOn decides when the chain moves on: by
+// default only after an open circuit or a timeout, with FallbackOnAny after
+// any error. Chains have no setting of their own for this, so FallbackOn
+// also still decides when a fallback set with SetFallback answers for the
+// target; a target its fallback answered for has succeeded, and the chain
+// stops there. If every target fails, the errors are joined.
+func (p *Provider) ChainExecutor(ctx context.Context, chain string) func(map[string]Operation) (any, error) {
+	return func(ops map[string]Operation) (any, error) {
+		state := p.current()
+
+		targets, ok := state.chains[chain]
+		if !ok {
+			return nil, fmt.Errorf("unknown chain %q", chain)
+		}
+
+		var errs []error
+		for _, target := range targets {
+			oper, ok := ops[target]
+			if !ok {
+				errs = append(errs, fmt.Errorf("target %q: no operation", target))
+				continue
+			}
+
+			res, err := NewExecutor(ctx, p.Policy(target))(oper)
+			if err == nil {
+				return res, nil
+			}
+
+			errs = append(errs, fmt.Errorf("target %q: %w", target, err))
+
+			if ctx.Err() != nil || !state.targets[target].advances(err) {
+				break
+			}
+		}
+
+		return nil, errors.Join(errs...)
+	}
+}
+
+func (t target) advances(err error) bool {
+	if t.fallbackOnAny {
+		return true
+	}
+
+	return errors.Is(err, ErrOpenState) || errors.Is(err, ErrTooManyRequests) || errors.Is(err, ErrExecutionTimeout)
+}
+
+func validateChains(cfg Config) error {
+	var errs configErrors
+
+	for _, name := range slices.Sorted(maps.Keys(cfg.Chains)) {
+		targets := cfg.Chains[name]
+		path := entryPath("chains", name)
+
+		if len(targets) == 0 {
+			errs.add(path, "chain has no targets")
+		}
+
+		for i, target := range targets {
+			if _, ok := cfg.Targets[target]; !ok {
+				errs.add(fmt.Sprintf("%s[%d]", path, i), "references unknown target %q", target)
+			}
+		}
+	}
+
+	return errs.err()
+}
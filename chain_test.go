@@ -0,0 +1,157 @@
+package goresilience_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	goresilience "github.com/rickKoch/go-resilience"
+)
+
+var (
+	errPrimary   = errors.New("primary failed")
+	errSecondary = errors.New("secondary failed")
+)
+
+func chainProvider(t *testing.T, fallbackOn string) *goresilience.Provider {
+	t.Helper()
+
+	provider, err := goresilience.FromConfig(goresilience.Config{
+		Timeouts: map[string]goresilience.TimeoutSpec{
+			"test_timeout": {Duration: "10ms"},
+		},
+		Targets: map[string]goresilience.PolicyNames{
+			"primary":   {Timeout: "test_timeout", FallbackOn: fallbackOn},
+			"secondary": {FallbackOn: fallbackOn},
+		},
+		Chains: map[string][]string{
+			"test_chain": {"primary", "secondary"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	return provider
+}
+
+func TestChainSecondarySucceeds(t *testing.T) {
+	tests := []struct {
+		name       string
+		fallbackOn string
+		primary    goresilience.Operation
+	}{
+		{
+			name:       "any error",
+			fallbackOn: goresilience.FallbackOnAny,
+			primary: func(ctx context.Context) (any, error) {
+				return nil, errPrimary
+			},
+		},
+		{
+			name: "timeout",
+			primary: func(ctx context.Context) (any, error) {
+				<-ctx.Done()
+				return nil, ctx.Err()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := chainProvider(t, tt.fallbackOn)
+
+			res, err := provider.ChainExecutor(context.Background(), "test_chain")(map[string]goresilience.Operation{
+				"primary": tt.primary,
+				"secondary": func(ctx context.Context) (any, error) {
+					return successResult, nil
+				},
+			})
+			if err != nil || res != successResult {
+				t.Fatalf("expected the secondary result, got %v, %v", res, err)
+			}
+		})
+	}
+}
+
+func TestChainStopsOnOtherErrors(t *testing.T) {
+	provider := chainProvider(t, "")
+
+	called := false
+	_, err := provider.ChainExecutor(context.Background(), "test_chain")(map[string]goresilience.Operation{
+		"primary": func(ctx context.Context) (any, error) {
+			return nil, errPrimary
+		},
+		"secondary": func(ctx context.Context) (any, error) {
+			called = true
+			return successResult, nil
+		},
+	})
+	if !errors.Is(err, errPrimary) {
+		t.Fatalf("expected the primary error, got: %v", err)
+	}
+	if called {
+		t.Fatal("expected the chain not to move on after a plain error")
+	}
+}
+
+func TestChainAdvanceFollowsFallbackOn(t *testing.T) {
+	provider := chainProvider(t, goresilience.FallbackOnAny)
+
+	var secondaryCalls int
+	ops := map[string]goresilience.Operation{
+		"primary": func(ctx context.Context) (any, error) {
+			return nil, errPrimary
+		},
+		"secondary": func(ctx context.Context) (any, error) {
+			secondaryCalls++
+			return successResult, nil
+		},
+	}
+
+	if res, err := provider.ChainExecutor(context.Background(), "test_chain")(ops); err != nil || res != successResult || secondaryCalls != 1 {
+		t.Fatalf("expected the chain to move on to the secondary, got %v, %v", res, err)
+	}
+
+	// The primary's fallback applies on the same errors, and answers before
+	// the chain can move on.
+	provider.SetFallback("primary", func(ctx context.Context, err error) (any, error) {
+		return "fallback", nil
+	})
+
+	if res, err := provider.ChainExecutor(context.Background(), "test_chain")(ops); err != nil || res != "fallback" || secondaryCalls != 1 {
+		t.Fatalf("expected the primary's fallback to end the chain, got %v, %v", res, err)
+	}
+}
+
+func TestChainAllFail(t *testing.T) {
+	provider := chainProvider(t, goresilience.FallbackOnAny)
+
+	_, err := provider.ChainExecutor(context.Background(), "test_chain")(map[string]goresilience.Operation{
+		"primary": func(ctx context.Context) (any, error) {
+			return nil, errPrimary
+		},
+		"secondary": func(ctx context.Context) (any, error) {
+			return nil, errSecondary
+		},
+	})
+	if !errors.Is(err, errPrimary) || !errors.Is(err, errSecondary) {
+		t.Fatalf("expected both errors to be joined, got: %v", err)
+	}
+}
+
+func TestChainValidation(t *testing.T) {
+	_, err := goresilience.FromConfig(goresilience.Config{
+		Chains: map[string][]string{
+			"test_chain": {"missing"},
+		},
+	})
+	if err == nil {
+		t.Fatal("expected an error for a chain with an unknown target")
+	}
+
+	provider := chainProvider(t, "")
+	if _, err := provider.ChainExecutor(context.Background(), "missing")(nil); err == nil {
+		t.Fatal("expected an error for an unknown chain")
+	}
+}
@@ -1,58 +1,920 @@
 package goresilience
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"maps"
+	"math/rand/v2"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/cenkalti/backoff/v4"
 	"github.com/sony/gobreaker"
 )
 
+// The sentinels keep gobreaker's identities so errors.Is checks written
+// against either package keep matching.
 var (
 	ErrOpenState       = gobreaker.ErrOpenState
 	ErrTooManyRequests = gobreaker.ErrTooManyRequests
+
+	errPanicked     = errors.New("operation panicked")
+	errProbeTimeout = errors.New("half-open probe timed out")
 )
 
+const defaultBreakerTimeout = 60 * time.Second
+
+// IntervalNever as a breaker's Interval keeps counts for as long as the
+// breaker stays closed instead of clearing them periodically.
+const IntervalNever = "never"
+
+type State int
+
+const (
+	StateClosed State = iota
+	StateHalfOpen
+	StateOpen
+	StateForcedOpen
+	StateForcedClosed
+	StateDisabled
+)
+
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateHalfOpen:
+		return "half-open"
+	case StateOpen:
+		return "open"
+	case StateForcedOpen:
+		return "forced-open"
+	case StateForcedClosed:
+		return "forced-closed"
+	case StateDisabled:
+		return "disabled"
+	default:
+		return fmt.Sprintf("unknown state: %d", int(s))
+	}
+}
+
+// MarshalText renders s the way String does, so that states read as
+// "open" rather than a number in JSON.
+func (s State) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+type Counts struct {
+	Requests             uint32 `json:"requests"`
+	TotalSuccesses       uint32 `json:"totalSuccesses"`
+	TotalFailures        uint32 `json:"totalFailures"`
+	ConsecutiveSuccesses uint32 `json:"consecutiveSuccesses"`
+	ConsecutiveFailures  uint32 `json:"consecutiveFailures"`
+}
+
+type CircuitOpenError struct {
+	Target    string
+	Breaker   string
+	Until     time.Time
+	Counts    Counts
+	LastError error
+	Err       error
+}
+
+func (e *CircuitOpenError) Error() string {
+	msg := fmt.Sprintf("circuit breaker %q for target %q rejected the request", e.Breaker, e.Target)
+	if !e.Until.IsZero() {
+		msg += fmt.Sprintf(" until %s", e.Until.Format(time.RFC3339Nano))
+	}
+
+	return msg + ": " + e.Err.Error()
+}
+
+func (e *CircuitOpenError) Unwrap() error {
+	return e.Err
+}
+
 type circuitBreaker struct {
-	breaker *gobreaker.CircuitBreaker
+	name          string
+	timeout       time.Duration
+	failures      uint32
+	rampUp        time.Duration
+	countTimeouts bool
+	warmUp        time.Duration
+	probeTimeout  time.Duration
+	dryRun        bool
+	random        func() float64
+	now           func() time.Time
+	maxRequests   uint32
+	interval      time.Duration
+
+	shared   atomic.Pointer[breakerEntry]
+	keys     *breakerKeys
+	outliers *outlierDetector
+
+	forceMu     sync.Mutex
+	forced      State
+	forcedUntil time.Time
+
+	enableMu sync.Mutex
+	disabled atomic.Bool
+}
+
+// breakerCall identifies who is executing through a breaker, so events are
+// attributed to the calling target and delivered to its provider.
+type breakerCall struct {
+	target   string
+	events   *eventBus
+	counters *targetCounters
+	observer Observer
 }
 
-func newCircuitBreaker(name string, config CircuitBreaker) (*circuitBreaker, error) {
-	interval, err := parseDuration(config.Interval)
+type breakerEntry struct {
+	key       string
+	breaker   *breaker
+	createdAt time.Time
+
+	mu          sync.Mutex
+	openedAt    time.Time
+	closedAt    time.Time
+	lastErr     error
+	tripCounts  Counts
+	transitions []transition
+	outliers    outlierWindow
+}
+
+type transition struct {
+	from, to State
+	at       time.Time
+	counts   Counts
+	lastErr  error
+}
+
+// StateChange describes a breaker transition. Counts and LastError capture
+// the failure that pushed the breaker open. DryRun marks transitions of a
+// breaker that only observes and never rejects.
+type StateChange struct {
+	Target    string
+	Breaker   string
+	Key       string
+	From      State
+	To        State
+	Time      time.Time
+	Counts    Counts
+	LastError error
+	DryRun    bool
+}
+
+func (e *breakerEntry) recordFailure(err error) {
+	var permanent *backoff.PermanentError
+	if errors.As(err, &permanent) {
+		err = permanent.Err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.lastErr = err
+}
+
+func newCircuitBreaker(name string, config CircuitBreaker, unit time.Duration) (*circuitBreaker, error) {
+	var errs configErrors
+
+	interval, err := parseInterval(config.Interval, unit)
 	if err != nil {
+		errs.add("interval", "%v", err)
+	}
+
+	timeout := errs.duration("timeout", config.Timeout, unit)
+	keyIdleTimeout := errs.duration("keyIdleTimeout", config.KeyIdleTimeout, unit)
+	rampUp := errs.duration("rampUp", config.RampUp, unit)
+	warmUp := errs.duration("warmUp", config.WarmUp, unit)
+	probeTimeout := errs.duration("probeTimeout", config.ProbeTimeout, unit)
+
+	outliers, err := newOutlierDetector(config.OutlierDetection, config.MaxKeys, unit)
+	errs.nest("outlierDetection", err)
+
+	if err := errs.err(); err != nil {
 		return nil, err
 	}
 
-	timeout, err := parseDuration(config.Timeout)
+	failures := uint32(config.Failures)
+
+	cb := &circuitBreaker{
+		name:          name,
+		timeout:       timeout,
+		failures:      failures,
+		rampUp:        rampUp,
+		countTimeouts: config.CountTimeouts,
+		warmUp:        warmUp,
+		probeTimeout:  probeTimeout,
+		dryRun:        config.DryRun,
+		random:        rand.Float64,
+		now:           time.Now,
+		maxRequests:   uint32(config.MaxRequests),
+		interval:      interval,
+		outliers:      outliers,
+	}
+	if cb.timeout <= 0 {
+		cb.timeout = defaultBreakerTimeout
+	}
+	if cb.maxRequests == 0 {
+		cb.maxRequests = 1
+	}
+
+	cb.shared.Store(cb.newEntry(""))
+
+	if config.MaxKeys > 0 {
+		cb.keys = newBreakerKeys(config.MaxKeys, keyIdleTimeout, func() time.Time {
+			return cb.now()
+		})
+	}
+
+	return cb, nil
+}
+
+func parseInterval(val string, unit time.Duration) (time.Duration, error) {
+	if val == IntervalNever {
+		return 0, nil
+	}
+
+	return parseDuration(val, unit)
+}
+
+// inlineCircuitBreakers returns a copy of cfg in which inline breaker specs
+// are registered under their target's name and referenced from it.
+func inlineCircuitBreakers(cfg Config) (Config, error) {
+	breakers := maps.Clone(cfg.CircuitBreakers)
+	targets := maps.Clone(cfg.Targets)
+
+	var errs configErrors
+	for _, name := range slices.Sorted(maps.Keys(cfg.Targets)) {
+		t := cfg.Targets[name]
+		if t.CircuitBreakerSpec == nil {
+			continue
+		}
+
+		path := entryPath("targets", name) + ".circuitBreakerSpec"
+
+		if t.CircuitBreaker != "" {
+			errs.add(path, "circuitBreaker and circuitBreakerSpec are mutually exclusive")
+			continue
+		}
+
+		if _, ok := cfg.CircuitBreakers[name]; ok {
+			errs.add(path, "inline circuit breaker conflicts with the circuit breaker of the same name")
+			continue
+		}
+
+		if breakers == nil {
+			breakers = make(map[string]CircuitBreaker)
+		}
+		breakers[name] = *t.CircuitBreakerSpec
+
+		t.CircuitBreaker = name
+		t.CircuitBreakerSpec = nil
+		targets[name] = t
+	}
+
+	if err := errs.err(); err != nil {
+		return cfg, err
+	}
+
+	cfg.CircuitBreakers = breakers
+	cfg.Targets = targets
+	return cfg, nil
+}
+
+// validateCircuitBreaker checks the settings newCircuitBreaker would
+// otherwise default. MaxRequests and Failures may only be left out when the
+// provider's options allow it.
+func validateCircuitBreaker(c CircuitBreaker, opts options) error {
+	var errs configErrors
+
+	if c.Interval == "" {
+		errs.add("interval", "is required, use %q to never clear counts while closed", IntervalNever)
+	}
+
+	if c.MaxRequests < 0 {
+		errs.add("maxRequests", "must not be negative, got %d", c.MaxRequests)
+	} else if c.MaxRequests == 0 && !opts.allowZeroMaxRequests {
+		errs.add("maxRequests", "must be at least 1, see WithAllowZeroMaxRequests")
+	}
+
+	if c.Failures < 0 {
+		errs.add("failures", "must not be negative, got %d", c.Failures)
+	} else if c.Failures == 0 && !opts.allowZeroFailures {
+		errs.add("failures", "must be at least 1, see WithAllowZeroFailures")
+	}
+
+	return errs.err()
+}
+
+func (cb *circuitBreaker) newEntry(key string) *breakerEntry {
+	entry := &breakerEntry{key: key, createdAt: cb.now()}
+	failures := cb.failures
+
+	entry.breaker = newBreaker(breakerSettings{
+		maxRequests: cb.maxRequests,
+		interval:    cb.interval,
+		timeout:     cb.timeout,
+		readyToTrip: func(counts Counts) bool {
+			return counts.ConsecutiveFailures >= failures
+		},
+		onStateChange: func(from, to State, counts Counts, now time.Time) {
+			entry.mu.Lock()
+			defer entry.mu.Unlock()
+
+			t := transition{from: from, to: to, at: now}
+			if to == StateOpen {
+				entry.tripCounts = counts
+				t.counts = counts
+				t.lastErr = entry.lastErr
+			}
+			entry.transitions = append(entry.transitions, t)
+
+			switch to {
+			case StateOpen:
+				entry.openedAt = now
+				entry.closedAt = time.Time{}
+			case StateClosed:
+				entry.openedAt = time.Time{}
+				entry.closedAt = now
+			}
+		},
+		now: func() time.Time {
+			return cb.now()
+		},
+	})
+
+	return entry
+}
+
+func (cb *circuitBreaker) entry(ctx context.Context) *breakerEntry {
+	if cb.keys == nil {
+		return cb.shared.Load()
+	}
+
+	key, ok := BreakerKeyFromContext(ctx)
+	if !ok {
+		return cb.shared.Load()
+	}
+
+	return cb.keys.get(key, func() *breakerEntry {
+		return cb.newEntry(key)
+	})
+}
+
+func (cb *circuitBreaker) lookup(key string) *breakerEntry {
+	if key == "" || cb.keys == nil {
+		return cb.shared.Load()
+	}
+
+	return cb.keys.peek(key)
+}
+
+// reset discards all breaker state, including keyed entries, and restarts
+// the warm-up window.
+func (cb *circuitBreaker) reset() {
+	cb.shared.Store(cb.newEntry(""))
+
+	if cb.keys != nil {
+		cb.keys.clear()
+	}
+}
+
+// setEnabled turns the breaker on or off. A re-enabled breaker starts over
+// closed, since whatever it recorded before being disabled is stale.
+func (cb *circuitBreaker) setEnabled(enabled bool) {
+	cb.enableMu.Lock()
+	defer cb.enableMu.Unlock()
+
+	if !enabled {
+		cb.disabled.Store(true)
+		return
+	}
+
+	if cb.disabled.Load() {
+		cb.reset()
+		cb.disabled.Store(false)
+	}
+}
+
+func (cb *circuitBreaker) force(state State, d time.Duration) {
+	cb.forceMu.Lock()
+	defer cb.forceMu.Unlock()
+
+	cb.forced = state
+	cb.forcedUntil = cb.now().Add(d)
+}
+
+func (cb *circuitBreaker) forcedState() (State, time.Time, bool) {
+	cb.forceMu.Lock()
+	defer cb.forceMu.Unlock()
+
+	if cb.forcedUntil.IsZero() {
+		return StateClosed, time.Time{}, false
+	}
+
+	if !cb.now().Before(cb.forcedUntil) {
+		cb.forcedUntil = time.Time{}
+		return StateClosed, time.Time{}, false
+	}
+
+	return cb.forced, cb.forcedUntil, true
+}
+
+func (cb *circuitBreaker) execute(ctx context.Context, call breakerCall, oper Operation) (any, error) {
+	if cb.disabled.Load() {
+		return oper(ctx)
+	}
+
+	entry := cb.entry(ctx)
+	probe := cb.probeTimeout > 0 && !cb.dryRun && entry.breaker.State() == StateHalfOpen
+
+	report, err := cb.allow(call, entry)
 	if err != nil {
 		return nil, err
 	}
-	maxRequest := uint32(config.MaxRequests)
-	failures := uint32(config.Failures)
 
-	cb := new(circuitBreaker)
+	err = errPanicked
+	defer func() {
+		report(err)
+	}()
+
+	if !probe {
+		var res any
+		res, err = oper(ctx)
+
+		return res, err
+	}
+
+	// Probes get their own, usually tighter, deadline so a dependency that
+	// is still slow re-opens the breaker quickly.
+	res, fired, err := detached{timeout: cb.probeTimeout}.run(ctx, oper)
+	if fired && ctx.Err() == nil {
+		err = fmt.Errorf("%w after %s: %w", errProbeTimeout, cb.probeTimeout, err)
+	}
+
+	return res, err
+}
+
+// isSuccessful decides how an outcome is reported to the breaker. The
+// policy's own timeouts usually reflect the caller's budget rather than the
+// dependency, so they only count as failures when countTimeouts is set. Any
+// other deadline error, such as one the dependency returned, is a failure,
+// and so are probe timeouts, the breaker's own deadline.
+func (cb *circuitBreaker) isSuccessful(err error) bool {
+	if err == nil {
+		return true
+	}
 
-	tripFn := func(counts gobreaker.Counts) bool {
-		return counts.ConsecutiveFailures >= failures
+	if errors.Is(err, errProbeTimeout) {
+		return false
 	}
 
-	cb.breaker = gobreaker.NewCircuitBreaker(gobreaker.Settings{
-		Name:        name,
-		MaxRequests: maxRequest,
-		Interval:    interval,
-		Timeout:     timeout,
-		ReadyToTrip: tripFn,
+	return !cb.countTimeouts && errors.Is(err, ErrExecutionTimeout)
+}
+
+// allow is the first half of a two-step execution: it either rejects the
+// request or returns the callback that records its outcome.
+func (cb *circuitBreaker) allow(call breakerCall, entry *breakerEntry) (func(err error), error) {
+	if cb.disabled.Load() {
+		return func(error) {}, nil
+	}
+
+	if state, _, ok := cb.forcedState(); ok {
+		if state == StateForcedOpen {
+			return cb.reject(call, entry, ErrOpenState)
+		}
+
+		return func(error) {}, nil
+	}
+
+	if !cb.admit(entry) {
+		return cb.reject(call, entry, ErrTooManyRequests)
+	}
+
+	probing := call.events != nil && entry.breaker.State() == StateHalfOpen
+	warming := cb.warmingUp(entry)
+
+	done, err := entry.breaker.Allow()
+	if err != nil {
+		return cb.reject(call, entry, err)
+	}
+
+	return func(err error) {
+		// An attempt minDeadline skipped never reached the dependency, which
+		// happens inside the breaker when a custom order puts it outermost.
+		if errors.Is(err, ErrInsufficientDeadline) {
+			done(false, false)
+			cb.publish(call, entry)
+			return
+		}
+
+		success := cb.isSuccessful(err)
+		if !success {
+			entry.recordFailure(err)
+		}
+
+		// Failures during warm-up are left out of the counts altogether,
+		// so that they neither trip the breaker now nor count toward
+		// tripping it once warm-up is over.
+		if warming && !success {
+			done(false, false)
+			cb.publish(call, entry)
+			return
+		}
+		done(success, true)
+
+		if cb.outliers != nil && entry.key != "" {
+			entry.observe(success)
+			cb.detectOutliers(cb.now())
+		}
+
+		if probing && success {
+			cb.emit(ProbeSucceeded, call, entry, entry.breaker.Counts(), nil)
+		}
+		cb.publish(call, entry)
+	}, nil
+}
+
+// warmingUp reports whether entry is still inside its warm-up window, during
+// which failures are passed through without being counted.
+func (cb *circuitBreaker) warmingUp(entry *breakerEntry) bool {
+	return cb.warmUp > 0 && cb.now().Sub(entry.createdAt) < cb.warmUp
+}
+
+// admit implements the ramp-up gate: for rampUp after the breaker closes,
+// only a linearly growing fraction of requests is let through.
+func (cb *circuitBreaker) admit(entry *breakerEntry) bool {
+	if cb.rampUp <= 0 {
+		return true
+	}
+
+	entry.mu.Lock()
+	closedAt := entry.closedAt
+	entry.mu.Unlock()
+
+	if closedAt.IsZero() {
+		return true
+	}
+
+	elapsed := cb.now().Sub(closedAt)
+	if elapsed >= cb.rampUp {
+		return true
+	}
+
+	return cb.random() < float64(elapsed)/float64(cb.rampUp)
+}
+
+// reject turns a rejection into an error, except in dry-run where it is
+// only reported and the request goes ahead without feeding the breaker,
+// just as it would not have reached the dependency.
+func (cb *circuitBreaker) reject(call breakerCall, entry *breakerEntry, err error) (func(err error), error) {
+	rejected := cb.rejection(call, entry, err)
+	if cb.dryRun {
+		return func(error) {}, nil
+	}
+
+	call.counters.rejected()
+	if call.observer != nil {
+		call.observer.OnRejected(call.target, rejected)
+	}
+
+	return nil, rejected
+}
+
+func (cb *circuitBreaker) rejection(call breakerCall, entry *breakerEntry, err error) error {
+	rejected := &CircuitOpenError{Target: call.target, Breaker: cb.name, Err: err}
+
+	if state, until, ok := cb.forcedState(); ok && state == StateForcedOpen {
+		rejected.Until = until
+	} else if err == ErrOpenState {
+		rejected.Until = entry.breaker.openUntil()
+
+		entry.mu.Lock()
+		rejected.Counts = entry.tripCounts
+		rejected.LastError = entry.lastErr
+		entry.mu.Unlock()
+	}
+
+	if call.events != nil {
+		cb.emit(RequestRejected, call, entry, entry.breaker.Counts(), nil)
+		cb.publish(call, entry)
+	}
+
+	return rejected
+}
+
+func (cb *circuitBreaker) State(key string) State {
+	if cb.disabled.Load() {
+		return StateDisabled
+	}
+
+	if state, _, ok := cb.forcedState(); ok {
+		return state
+	}
+
+	entry := cb.lookup(key)
+	if entry == nil {
+		return StateClosed
+	}
+
+	return entry.state()
+}
+
+func (e *breakerEntry) state() State {
+	return e.breaker.State()
+}
+
+func (cb *circuitBreaker) Counts(key string) Counts {
+	entry := cb.lookup(key)
+	if entry == nil {
+		return Counts{}
+	}
+
+	return entry.breaker.Counts()
+}
+
+// publish drains the state transitions recorded on entry and emits them on
+// behalf of call, so every transition is reported exactly once.
+func (cb *circuitBreaker) publish(call breakerCall, entry *breakerEntry) {
+	entry.mu.Lock()
+	transitions := entry.transitions
+	entry.transitions = nil
+	entry.mu.Unlock()
+
+	if call.events == nil {
+		return
+	}
+
+	for _, t := range transitions {
+		call.events.stateChanged(StateChange{
+			Target:    call.target,
+			Breaker:   cb.name,
+			Key:       entry.key,
+			From:      t.from,
+			To:        t.to,
+			Time:      t.at,
+			Counts:    t.counts,
+			LastError: t.lastErr,
+			DryRun:    cb.dryRun,
+		})
+
+		switch t.to {
+		case StateOpen:
+			cb.emit(BreakerOpened, call, entry, t.counts, t.lastErr)
+		case StateClosed:
+			cb.emit(BreakerClosed, call, entry, entry.breaker.Counts(), nil)
+		}
+	}
+}
+
+func (cb *circuitBreaker) emit(typ EventType, call breakerCall, entry *breakerEntry, counts Counts, lastErr error) {
+	call.events.emit(Event{
+		Type:      typ,
+		Time:      time.Now(),
+		Target:    call.target,
+		Breaker:   cb.name,
+		Key:       entry.key,
+		Counts:    counts,
+		LastError: lastErr,
+		DryRun:    cb.dryRun,
 	})
+}
 
-	return cb, nil
+type breakerSettings struct {
+	maxRequests   uint32
+	interval      time.Duration
+	timeout       time.Duration
+	readyToTrip   func(counts Counts) bool
+	onStateChange func(from, to State, counts Counts, now time.Time)
+	now           func() time.Time
+}
+
+// breaker is the circuit breaker state machine. While closed it trips once
+// readyToTrip accepts the counts, clearing them every interval (if set).
+// While open it rejects everything until timeout elapses, then lets up to
+// maxRequests probes through half-open: that many consecutive successes
+// close it, any failure re-opens it. Outcomes reported for an older
+// generation are ignored.
+type breaker struct {
+	breakerSettings
+
+	mu         sync.Mutex
+	state      State
+	generation uint64
+	counts     Counts
+	expiry     time.Time
+}
+
+func newBreaker(settings breakerSettings) *breaker {
+	b := &breaker{breakerSettings: settings}
+	b.toNewGeneration(b.now())
+
+	return b
+}
+
+func (b *breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	state, _ := b.currentState(b.now())
+	return state
+}
+
+func (b *breaker) Counts() Counts {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.counts
+}
+
+// Allow admits a request, or rejects it while open or when half-open has
+// let enough through. The returned func records the request's outcome, or
+// with counted false takes the request back out of the counts.
+func (b *breaker) Allow() (func(success, counted bool), error) {
+	generation, err := b.beforeRequest()
+	if err != nil {
+		return nil, err
+	}
+
+	return func(success, counted bool) {
+		if !counted {
+			b.forget(generation)
+			return
+		}
+
+		b.afterRequest(generation, success)
+	}, nil
+}
+
+func (b *breaker) openUntil() time.Time {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state != StateOpen {
+		return time.Time{}
+	}
+
+	return b.expiry
+}
+
+// expiresAt is when the current generation ends: the end of the open
+// period, or the next count reset while closed.
+func (b *breaker) expiresAt() time.Time {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.currentState(b.now())
+	return b.expiry
+}
+
+// trip opens the breaker until the given time, regardless of its counts.
+func (b *breaker) trip(until time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.setState(StateOpen, b.now())
+	b.expiry = until
+}
+
+func (b *breaker) beforeRequest() (uint64, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	state, generation := b.currentState(b.now())
+
+	if state == StateOpen {
+		return generation, ErrOpenState
+	} else if state == StateHalfOpen && b.counts.Requests >= b.maxRequests {
+		return generation, ErrTooManyRequests
+	}
+
+	b.counts.onRequest()
+	return generation, nil
+}
+
+func (b *breaker) afterRequest(before uint64, success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	state, generation := b.currentState(now)
+	if generation != before {
+		return
+	}
+
+	if success {
+		b.onSuccess(state, now)
+	} else {
+		b.onFailure(state, now)
+	}
+}
+
+// forget takes back a request that beforeRequest counted.
+func (b *breaker) forget(before uint64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, generation := b.currentState(b.now()); generation == before {
+		b.counts.Requests--
+	}
+}
+
+func (b *breaker) onSuccess(state State, now time.Time) {
+	switch state {
+	case StateClosed:
+		b.counts.onSuccess()
+	case StateHalfOpen:
+		b.counts.onSuccess()
+		if b.counts.ConsecutiveSuccesses >= b.maxRequests {
+			b.setState(StateClosed, now)
+		}
+	}
+}
+
+func (b *breaker) onFailure(state State, now time.Time) {
+	switch state {
+	case StateClosed:
+		b.counts.onFailure()
+		if b.readyToTrip(b.counts) {
+			b.setState(StateOpen, now)
+		}
+	case StateHalfOpen:
+		b.counts.onFailure()
+		b.setState(StateOpen, now)
+	}
+}
+
+func (b *breaker) currentState(now time.Time) (State, uint64) {
+	switch b.state {
+	case StateClosed:
+		if !b.expiry.IsZero() && b.expiry.Before(now) {
+			b.toNewGeneration(now)
+		}
+	case StateOpen:
+		if b.expiry.Before(now) {
+			b.setState(StateHalfOpen, now)
+		}
+	}
+
+	return b.state, b.generation
+}
+
+func (b *breaker) setState(state State, now time.Time) {
+	if b.state == state {
+		return
+	}
+
+	prev, counts := b.state, b.counts
+	b.state = state
+
+	b.toNewGeneration(now)
+
+	if b.onStateChange != nil {
+		b.onStateChange(prev, state, counts, now)
+	}
+}
+
+func (b *breaker) toNewGeneration(now time.Time) {
+	b.generation++
+	b.counts.clear()
+
+	switch b.state {
+	case StateClosed:
+		if b.interval <= 0 {
+			b.expiry = time.Time{}
+		} else {
+			b.expiry = now.Add(b.interval)
+		}
+	case StateOpen:
+		b.expiry = now.Add(b.timeout)
+	default:
+		b.expiry = time.Time{}
+	}
+}
+
+func (c *Counts) onRequest() {
+	c.Requests++
 }
 
-func (cb *circuitBreaker) State() gobreaker.State {
-	return cb.breaker.State()
+func (c *Counts) onSuccess() {
+	c.TotalSuccesses++
+	c.ConsecutiveSuccesses++
+	c.ConsecutiveFailures = 0
 }
 
-func (cb *circuitBreaker) Counts() gobreaker.Counts {
-	return cb.breaker.Counts()
+func (c *Counts) onFailure() {
+	c.TotalFailures++
+	c.ConsecutiveFailures++
+	c.ConsecutiveSuccesses = 0
 }
 
-func IsErrorPermanent(err error) bool {
-	return errors.Is(err, ErrOpenState) || errors.Is(err, ErrTooManyRequests)
+func (c *Counts) clear() {
+	*c = Counts{}
 }
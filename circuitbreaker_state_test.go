@@ -0,0 +1,396 @@
+package goresilience_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	goresilience "github.com/rickKoch/go-resilience"
+)
+
+type fakeClock struct {
+	now atomic.Int64
+}
+
+func (c *fakeClock) Now() time.Time {
+	return time.Unix(0, c.now.Load())
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.now.Add(int64(d))
+}
+
+func stateMachineProvider(t *testing.T, cb goresilience.CircuitBreaker, opts ...goresilience.Option) (*goresilience.Provider, *fakeClock) {
+	t.Helper()
+
+	provider, err := goresilience.FromConfigWithOptions(goresilience.Config{
+		CircuitBreakers: map[string]goresilience.CircuitBreaker{
+			"test_cb": cb,
+		},
+		Targets: map[string]goresilience.PolicyNames{
+			"test_target": {
+				CircuitBreaker: "test_cb",
+			},
+		},
+	}, opts...)
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	clock := &fakeClock{}
+	clock.now.Store(time.Now().UnixNano())
+	goresilience.SetBreakerClock(provider, "test_cb", clock.Now)
+	if err := provider.ResetBreaker("test_cb"); err != nil {
+		t.Fatalf("failed to reset breaker: %v", err)
+	}
+
+	return provider, clock
+}
+
+func acquire(t *testing.T, provider *goresilience.Provider) goresilience.Permit {
+	t.Helper()
+
+	permit, err := provider.Acquire("test_target")
+	if err != nil {
+		t.Fatalf("expected permit, got: %v", err)
+	}
+
+	return permit
+}
+
+func expectState(t *testing.T, provider *goresilience.Provider, want goresilience.State) {
+	t.Helper()
+
+	if state, _ := provider.BreakerState("test_cb"); state != want {
+		t.Fatalf("expected state %s, got %s", want, state)
+	}
+}
+
+func TestBreakerTripsOnConsecutiveFailures(t *testing.T) {
+	provider, _ := stateMachineProvider(t, goresilience.CircuitBreaker{
+		MaxRequests: 1,
+		Interval:    "never",
+		Timeout:     "10s",
+		Failures:    3,
+	})
+
+	acquire(t, provider).Failure(testError)
+	acquire(t, provider).Failure(testError)
+	acquire(t, provider).Success()
+	acquire(t, provider).Failure(testError)
+	acquire(t, provider).Failure(testError)
+	expectState(t, provider, goresilience.StateClosed)
+
+	counts, _ := provider.BreakerCounts("test_cb")
+	want := goresilience.Counts{Requests: 5, TotalSuccesses: 1, TotalFailures: 4, ConsecutiveFailures: 2}
+	if counts != want {
+		t.Fatalf("expected counts %+v, got %+v", want, counts)
+	}
+
+	acquire(t, provider).Failure(testError)
+	expectState(t, provider, goresilience.StateOpen)
+
+	if _, err := provider.Acquire("test_target"); !errors.Is(err, goresilience.ErrOpenState) {
+		t.Fatalf("expected ErrOpenState, got: %v", err)
+	}
+}
+
+func TestBreakerIntervalClearsCounts(t *testing.T) {
+	provider, clock := stateMachineProvider(t, goresilience.CircuitBreaker{
+		MaxRequests: 1,
+		Interval:    "1s",
+		Timeout:     "10s",
+		Failures:    2,
+	})
+
+	acquire(t, provider).Failure(testError)
+	clock.Advance(1500 * time.Millisecond)
+
+	if counts, _ := provider.BreakerCounts("test_cb"); counts != (goresilience.Counts{TotalFailures: 1, ConsecutiveFailures: 1, Requests: 1}) {
+		t.Fatalf("expected counts to be kept until the next check, got %+v", counts)
+	}
+
+	acquire(t, provider).Failure(testError)
+	expectState(t, provider, goresilience.StateClosed)
+
+	counts, _ := provider.BreakerCounts("test_cb")
+	if counts.ConsecutiveFailures != 1 {
+		t.Fatalf("expected counts to be cleared by the interval, got %+v", counts)
+	}
+}
+
+func TestBreakerWithoutIntervalKeepsCounts(t *testing.T) {
+	provider, clock := stateMachineProvider(t, goresilience.CircuitBreaker{
+		MaxRequests: 1,
+		Interval:    "never",
+		Timeout:     "10s",
+		Failures:    2,
+	})
+
+	acquire(t, provider).Failure(testError)
+	clock.Advance(time.Hour)
+	acquire(t, provider).Failure(testError)
+
+	expectState(t, provider, goresilience.StateOpen)
+}
+
+func TestBreakerOpenToHalfOpen(t *testing.T) {
+	provider, clock := stateMachineProvider(t, goresilience.CircuitBreaker{
+		MaxRequests: 1,
+		Interval:    "never",
+		Timeout:     "10s",
+		Failures:    1,
+	})
+
+	acquire(t, provider).Failure(testError)
+	expectState(t, provider, goresilience.StateOpen)
+
+	clock.Advance(10 * time.Second)
+	expectState(t, provider, goresilience.StateOpen)
+
+	clock.Advance(time.Millisecond)
+	expectState(t, provider, goresilience.StateHalfOpen)
+}
+
+func TestBreakerHalfOpenLimitsRequests(t *testing.T) {
+	provider, clock := stateMachineProvider(t, goresilience.CircuitBreaker{
+		MaxRequests: 2,
+		Interval:    "never",
+		Timeout:     "10s",
+		Failures:    1,
+	})
+
+	acquire(t, provider).Failure(testError)
+	clock.Advance(11 * time.Second)
+
+	first := acquire(t, provider)
+	second := acquire(t, provider)
+
+	if _, err := provider.Acquire("test_target"); !errors.Is(err, goresilience.ErrTooManyRequests) {
+		t.Fatalf("expected ErrTooManyRequests, got: %v", err)
+	}
+
+	first.Success()
+	expectState(t, provider, goresilience.StateHalfOpen)
+
+	// The limit counts requests, not in-flight requests.
+	if _, err := provider.Acquire("test_target"); !errors.Is(err, goresilience.ErrTooManyRequests) {
+		t.Fatalf("expected ErrTooManyRequests, got: %v", err)
+	}
+
+	second.Success()
+	expectState(t, provider, goresilience.StateClosed)
+
+	counts, _ := provider.BreakerCounts("test_cb")
+	if counts != (goresilience.Counts{}) {
+		t.Fatalf("expected counts to be cleared on close, got %+v", counts)
+	}
+}
+
+func TestBreakerHalfOpenFailureReopens(t *testing.T) {
+	provider, clock := stateMachineProvider(t, goresilience.CircuitBreaker{
+		MaxRequests: 3,
+		Interval:    "never",
+		Timeout:     "10s",
+		Failures:    1,
+	})
+
+	acquire(t, provider).Failure(testError)
+	clock.Advance(11 * time.Second)
+
+	acquire(t, provider).Success()
+	acquire(t, provider).Failure(testError)
+	expectState(t, provider, goresilience.StateOpen)
+
+	// A new open period starts from the re-open.
+	clock.Advance(5 * time.Second)
+	expectState(t, provider, goresilience.StateOpen)
+	clock.Advance(6 * time.Second)
+	expectState(t, provider, goresilience.StateHalfOpen)
+}
+
+func TestBreakerIgnoresStaleReports(t *testing.T) {
+	provider, clock := stateMachineProvider(t, goresilience.CircuitBreaker{
+		MaxRequests: 1,
+		Interval:    "never",
+		Timeout:     "10s",
+		Failures:    1,
+	})
+
+	stale := acquire(t, provider)
+	acquire(t, provider).Failure(testError)
+	expectState(t, provider, goresilience.StateOpen)
+
+	clock.Advance(11 * time.Second)
+	probe := acquire(t, provider)
+
+	// The permit was granted while closed and must not decide the probe.
+	stale.Failure(testError)
+	expectState(t, provider, goresilience.StateHalfOpen)
+
+	probe.Success()
+	expectState(t, provider, goresilience.StateClosed)
+}
+
+func TestBreakerZeroMaxRequests(t *testing.T) {
+	provider, clock := stateMachineProvider(t, goresilience.CircuitBreaker{
+		Interval: "never",
+		Timeout:  "10s",
+		Failures: 1,
+	}, goresilience.WithAllowZeroMaxRequests())
+
+	acquire(t, provider).Failure(testError)
+	clock.Advance(11 * time.Second)
+
+	probe := acquire(t, provider)
+	if _, err := provider.Acquire("test_target"); !errors.Is(err, goresilience.ErrTooManyRequests) {
+		t.Fatalf("expected a single half-open request, got: %v", err)
+	}
+
+	probe.Success()
+	expectState(t, provider, goresilience.StateClosed)
+}
+
+func TestBreakerDefaultTimeout(t *testing.T) {
+	provider, clock := stateMachineProvider(t, goresilience.CircuitBreaker{
+		MaxRequests: 1,
+		Interval:    "never",
+		Failures:    1,
+	})
+
+	acquire(t, provider).Failure(testError)
+
+	clock.Advance(59 * time.Second)
+	expectState(t, provider, goresilience.StateOpen)
+
+	clock.Advance(2 * time.Second)
+	expectState(t, provider, goresilience.StateHalfOpen)
+}
+
+func TestBreakerConcurrentOutcomes(t *testing.T) {
+	provider, clock := stateMachineProvider(t, goresilience.CircuitBreaker{
+		MaxRequests: 1,
+		Interval:    "1s",
+		Timeout:     "500ms",
+		Failures:    1,
+	})
+
+	exec := goresilience.NewExecWithPolicy(context.Background(), provider.Policy("test_target"))
+
+	const numGoroutines = 10
+	var (
+		wg       sync.WaitGroup
+		start    = make(chan struct{})
+		executed atomic.Int32
+		errs     = make(chan error, numGoroutines)
+	)
+
+	for i := 0; i < numGoroutines; i++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			<-start
+
+			_, err := exec(func(ctx context.Context) (any, error) {
+				executed.Add(1)
+				time.Sleep(10 * time.Millisecond)
+				if id%2 == 0 {
+					return successResult, nil
+				}
+				return nil, testError
+			})
+			errs <- err
+		}(i)
+	}
+
+	close(start)
+	wg.Wait()
+	close(errs)
+
+	var successes, failures, rejected int
+	for err := range errs {
+		switch {
+		case err == nil:
+			successes++
+		case errors.Is(err, goresilience.ErrOpenState):
+			rejected++
+		case errors.Is(err, testError):
+			failures++
+		default:
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if successes+failures+rejected != numGoroutines {
+		t.Fatalf("expected %d results, got %d", numGoroutines, successes+failures+rejected)
+	}
+	if int(executed.Load()) != successes+failures {
+		t.Fatalf("expected only admitted calls to run, ran %d for %d outcomes", executed.Load(), successes+failures)
+	}
+	if failures == 0 {
+		t.Fatal("expected at least one failure to reach the breaker")
+	}
+	expectState(t, provider, goresilience.StateOpen)
+
+	clock.Advance(time.Second)
+	expectState(t, provider, goresilience.StateHalfOpen)
+
+	if _, err := exec(func(ctx context.Context) (any, error) {
+		return successResult, nil
+	}); err != nil {
+		t.Fatalf("expected probe to succeed, got: %v", err)
+	}
+	expectState(t, provider, goresilience.StateClosed)
+}
+
+func TestBreakerProbeTimeout(t *testing.T) {
+	provider, clock := stateMachineProvider(t, goresilience.CircuitBreaker{
+		MaxRequests:  1,
+		Interval:     "never",
+		Timeout:      "10s",
+		Failures:     1,
+		ProbeTimeout: "20ms",
+	})
+
+	acquire(t, provider).Failure(testError)
+	clock.Advance(11 * time.Second)
+	expectState(t, provider, goresilience.StateHalfOpen)
+
+	exec := goresilience.NewExecWithPolicy(context.Background(), provider.Policy("test_target"))
+
+	start := time.Now()
+	_, err := exec(func(ctx context.Context) (any, error) {
+		time.Sleep(time.Second)
+		return successResult, nil
+	})
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("expected probe to be cut at the probe timeout, took %s", elapsed)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got: %v", err)
+	}
+	expectState(t, provider, goresilience.StateOpen)
+}
+
+func TestBreakerProbeTimeoutOnlyWhenHalfOpen(t *testing.T) {
+	provider, _ := stateMachineProvider(t, goresilience.CircuitBreaker{
+		MaxRequests:  1,
+		Interval:     "never",
+		Timeout:      "10s",
+		Failures:     1,
+		ProbeTimeout: "1ms",
+	})
+
+	exec := goresilience.NewExecWithPolicy(context.Background(), provider.Policy("test_target"))
+	result, err := exec(func(ctx context.Context) (any, error) {
+		time.Sleep(20 * time.Millisecond)
+		return successResult, nil
+	})
+	if err != nil || result != successResult {
+		t.Fatalf("expected closed breaker to ignore the probe timeout, got %v, %v", result, err)
+	}
+	expectState(t, provider, goresilience.StateClosed)
+}
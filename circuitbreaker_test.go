@@ -3,6 +3,10 @@ package goresilience_test
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -83,7 +87,7 @@ func TestCircuitBreakerTripping(t *testing.T) {
 		_, err := exec(func(ctx context.Context) (any, error) {
 			return nil, testError
 		})
-		if err != testError {
+		if !errors.Is(err, testError) {
 			t.Logf("Attempt %d: got error %v (expected %v)", i+1, err, testError)
 		}
 	}
@@ -94,7 +98,7 @@ func TestCircuitBreakerTripping(t *testing.T) {
 		return successResult, nil
 	})
 
-	if err != goresilience.ErrOpenState {
+	if !errors.Is(err, goresilience.ErrOpenState) {
 		t.Fatalf("expected ErrOpenState, got: %v", err)
 	}
 }
@@ -129,7 +133,7 @@ func TestCircuitBreakerRecovery(t *testing.T) {
 	_, err = exec(func(ctx context.Context) (any, error) {
 		return nil, testError
 	})
-	if err != testError {
+	if !errors.Is(err, testError) {
 		t.Fatalf("expected test error, got: %v", err)
 	}
 
@@ -137,7 +141,7 @@ func TestCircuitBreakerRecovery(t *testing.T) {
 	_, err = exec(func(ctx context.Context) (any, error) {
 		return nil, testError
 	})
-	if err != testError {
+	if !errors.Is(err, testError) {
 		t.Fatalf("expected test error, got: %v", err)
 	}
 
@@ -146,7 +150,7 @@ func TestCircuitBreakerRecovery(t *testing.T) {
 		t.Error("operation should not be executed when circuit is open")
 		return nil, nil
 	})
-	if err != goresilience.ErrOpenState {
+	if !errors.Is(err, goresilience.ErrOpenState) {
 		t.Fatalf("expected ErrOpenState, got: %v", err)
 	}
 
@@ -165,6 +169,111 @@ func TestCircuitBreakerRecovery(t *testing.T) {
 	}
 }
 
+func TestCircuitOpenErrorFields(t *testing.T) {
+	target := "test_target"
+	cfg := goresilience.Config{
+		CircuitBreakers: map[string]goresilience.CircuitBreaker{
+			"test_cb": {
+				MaxRequests: 1,
+				Interval:    "10s",
+				Timeout:     "5s",
+				Failures:    1,
+			},
+		},
+		Targets: map[string]goresilience.PolicyNames{
+			target: {
+				CircuitBreaker: "test_cb",
+			},
+		},
+	}
+
+	provider, err := goresilience.FromConfig(cfg)
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	exec := goresilience.NewExecWithPolicy(context.Background(), provider.Policy(target))
+
+	trippedAt := time.Now()
+	_, err = exec(func(ctx context.Context) (any, error) {
+		return nil, testError
+	})
+	if !errors.Is(err, testError) {
+		t.Fatalf("expected test error, got: %v", err)
+	}
+
+	_, err = exec(func(ctx context.Context) (any, error) {
+		t.Error("operation should not be executed when circuit is open")
+		return nil, nil
+	})
+
+	var openErr *goresilience.CircuitOpenError
+	if !errors.As(err, &openErr) {
+		t.Fatalf("expected CircuitOpenError, got: %v", err)
+	}
+	if openErr.Target != target {
+		t.Fatalf("expected target %q, got %q", target, openErr.Target)
+	}
+	if openErr.Breaker != "test_cb" {
+		t.Fatalf("expected breaker %q, got %q", "test_cb", openErr.Breaker)
+	}
+	if openErr.Until.Before(trippedAt.Add(5*time.Second)) || openErr.Until.After(time.Now().Add(5*time.Second)) {
+		t.Fatalf("unexpected open-until time: %v", openErr.Until)
+	}
+	if !errors.Is(err, goresilience.ErrOpenState) {
+		t.Fatalf("expected error to match ErrOpenState, got: %v", err)
+	}
+
+	var stageErr *goresilience.StageError
+	if !errors.As(err, &stageErr) || stageErr.Stage != goresilience.StageCircuitBreaker || stageErr.Target != target {
+		t.Fatalf("expected the rejection to be attributed to the circuit breaker, got: %+v", stageErr)
+	}
+}
+
+func TestCircuitOpenErrorWithRetry(t *testing.T) {
+	target := "test_target"
+	cfg := goresilience.Config{
+		Retries: map[string]goresilience.Retry{
+			"test_retry": {
+				Duration:   "10ms",
+				MaxRetries: 5,
+			},
+		},
+		CircuitBreakers: map[string]goresilience.CircuitBreaker{
+			"test_cb": {
+				MaxRequests: 1,
+				Interval:    "10s",
+				Timeout:     "5s",
+				Failures:    1,
+			},
+		},
+		Targets: map[string]goresilience.PolicyNames{
+			target: {
+				Retry:          "test_retry",
+				CircuitBreaker: "test_cb",
+			},
+		},
+	}
+
+	provider, err := goresilience.FromConfig(cfg)
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	exec := goresilience.NewExecWithPolicy(context.Background(), provider.Policy(target))
+	_, err = exec(func(ctx context.Context) (any, error) {
+		return nil, testError
+	})
+
+	var openErr *goresilience.CircuitOpenError
+	if !errors.As(err, &openErr) {
+		t.Fatalf("expected CircuitOpenError after retries, got: %v", err)
+	}
+	if openErr.Target != target || openErr.Breaker != "test_cb" {
+		t.Fatalf("unexpected error fields: %+v", openErr)
+	}
+}
+
 func TestIsErrorPermanent(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -265,6 +374,155 @@ func TestCircuitBreakerConfiguration(t *testing.T) {
 	}
 }
 
+func TestCircuitBreakerNumericValidation(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      goresilience.CircuitBreaker
+		referenced  bool
+		opts        []goresilience.Option
+		expectError bool
+	}{
+		{
+			name:        "negative max requests",
+			config:      goresilience.CircuitBreaker{MaxRequests: -1, Interval: "never", Failures: 3},
+			referenced:  true,
+			expectError: true,
+		},
+		{
+			name:        "negative failures",
+			config:      goresilience.CircuitBreaker{MaxRequests: 1, Interval: "never", Failures: -3},
+			referenced:  true,
+			expectError: true,
+		},
+		{
+			name:        "negative failures on unreferenced breaker",
+			config:      goresilience.CircuitBreaker{MaxRequests: 1, Interval: "never", Failures: -3},
+			referenced:  false,
+			expectError: true,
+		},
+		{
+			name:        "zero failures on referenced breaker",
+			config:      goresilience.CircuitBreaker{MaxRequests: 1, Interval: "never", Failures: 0},
+			referenced:  true,
+			expectError: true,
+		},
+		{
+			name:        "zero failures on unreferenced breaker",
+			config:      goresilience.CircuitBreaker{MaxRequests: 1, Interval: "never", Failures: 0},
+			referenced:  false,
+			expectError: true,
+		},
+		{
+			name:        "zero failures allowed",
+			config:      goresilience.CircuitBreaker{MaxRequests: 1, Interval: "never", Failures: 0},
+			referenced:  true,
+			opts:        []goresilience.Option{goresilience.WithAllowZeroFailures()},
+			expectError: false,
+		},
+		{
+			name:        "zero max requests",
+			config:      goresilience.CircuitBreaker{MaxRequests: 0, Interval: "never", Failures: 1},
+			referenced:  true,
+			expectError: true,
+		},
+		{
+			name:        "zero max requests allowed",
+			config:      goresilience.CircuitBreaker{MaxRequests: 0, Interval: "never", Failures: 1},
+			referenced:  true,
+			opts:        []goresilience.Option{goresilience.WithAllowZeroMaxRequests()},
+			expectError: false,
+		},
+		{
+			name:        "negative max requests not relaxed",
+			config:      goresilience.CircuitBreaker{MaxRequests: -1, Interval: "never", Failures: 1},
+			referenced:  true,
+			opts:        []goresilience.Option{goresilience.WithAllowZeroMaxRequests()},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := goresilience.Config{
+				CircuitBreakers: map[string]goresilience.CircuitBreaker{
+					"test_cb": tt.config,
+				},
+			}
+			if tt.referenced {
+				cfg.Targets = map[string]goresilience.PolicyNames{
+					"test_target": {
+						CircuitBreaker: "test_cb",
+					},
+				}
+			}
+
+			_, err := goresilience.FromConfigWithOptions(cfg, tt.opts...)
+			if tt.expectError && err == nil {
+				t.Fatal("expected error but got none")
+			}
+			if !tt.expectError && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestCircuitBreakerIntervalValidation(t *testing.T) {
+	tests := []struct {
+		name        string
+		interval    string
+		expectError bool
+	}{
+		{name: "never", interval: goresilience.IntervalNever},
+		{name: "duration", interval: "30s"},
+		{name: "missing", interval: "", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := goresilience.FromConfig(goresilience.Config{
+				CircuitBreakers: map[string]goresilience.CircuitBreaker{
+					"test_cb": {MaxRequests: 1, Interval: tt.interval, Failures: 1},
+				},
+			})
+			if tt.expectError {
+				if err == nil || !strings.Contains(err.Error(), `circuitBreakers["test_cb"].interval: is required`) {
+					t.Fatalf("expected missing interval error naming the breaker, got: %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestCircuitBreakerValidationReportsAllBreakers(t *testing.T) {
+	cfg := goresilience.Config{
+		CircuitBreakers: map[string]goresilience.CircuitBreaker{
+			"cb_a": {MaxRequests: -1, Interval: "never", Failures: 1},
+			"cb_b": {MaxRequests: 1, Interval: "never", Failures: -1},
+			"cb_c": {MaxRequests: 1, Interval: "never", Failures: 1},
+		},
+	}
+
+	_, err := goresilience.FromConfig(cfg)
+	if err == nil {
+		t.Fatal("expected error but got none")
+	}
+
+	msg := err.Error()
+	for _, name := range []string{`"cb_a"`, `"cb_b"`} {
+		if !strings.Contains(msg, name) {
+			t.Fatalf("expected error to mention %s, got: %s", name, msg)
+		}
+	}
+	if strings.Contains(msg, `"cb_c"`) {
+		t.Fatalf("valid breaker should not be reported, got: %s", msg)
+	}
+}
+
 func TestCircuitBreakerConcurrency(t *testing.T) {
 	target := "test_target"
 	cfg := goresilience.Config{
@@ -327,3 +585,689 @@ func TestCircuitBreakerConcurrency(t *testing.T) {
 
 	t.Logf("Concurrent test results: %d successes, %d errors", successCount, errorCount)
 }
+
+func keyedBreakerProvider(t *testing.T, maxKeys int, idle string) *goresilience.Provider {
+	t.Helper()
+
+	cfg := goresilience.Config{
+		CircuitBreakers: map[string]goresilience.CircuitBreaker{
+			"test_cb": {
+				MaxRequests:    1,
+				Interval:       "10s",
+				Timeout:        "10s",
+				Failures:       2,
+				MaxKeys:        maxKeys,
+				KeyIdleTimeout: idle,
+			},
+		},
+		Targets: map[string]goresilience.PolicyNames{
+			"test_target": {
+				CircuitBreaker: "test_cb",
+			},
+		},
+	}
+
+	provider, err := goresilience.FromConfig(cfg)
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	return provider
+}
+
+func tripKey(exec func(goresilience.Operation, string) (any, error), key string) {
+	for i := 0; i < 2; i++ {
+		_, _ = exec(func(ctx context.Context) (any, error) {
+			return nil, testError
+		}, key)
+	}
+}
+
+func TestKeyedCircuitBreakerIsolation(t *testing.T) {
+	provider := keyedBreakerProvider(t, 10, "")
+	policy := provider.Policy("test_target")
+
+	exec := func(oper goresilience.Operation, key string) (any, error) {
+		ctx := goresilience.WithBreakerKey(context.Background(), key)
+		return goresilience.NewExecutor(ctx, policy)(oper)
+	}
+	tripKey(exec, "host-a")
+
+	_, err := exec(func(ctx context.Context) (any, error) {
+		t.Error("operation should not be executed for an open key")
+		return nil, nil
+	}, "host-a")
+	if !errors.Is(err, goresilience.ErrOpenState) {
+		t.Fatalf("expected ErrOpenState for host-a, got: %v", err)
+	}
+
+	for _, key := range []string{"host-b", "host-c"} {
+		result, err := exec(func(ctx context.Context) (any, error) {
+			return successResult, nil
+		}, key)
+		if err != nil {
+			t.Fatalf("expected %s to keep executing, got: %v", key, err)
+		}
+		if result != successResult {
+			t.Fatalf("expected %s, got %v", successResult, result)
+		}
+	}
+
+	// Calls without a key use the shared breaker, which is unaffected.
+	_, err = goresilience.NewExecutor(context.Background(), policy)(func(ctx context.Context) (any, error) {
+		return successResult, nil
+	})
+	if err != nil {
+		t.Fatalf("expected unkeyed call to succeed, got: %v", err)
+	}
+
+	if state, _ := provider.BreakerState("test_cb", "host-a"); state != goresilience.StateOpen {
+		t.Fatalf("expected host-a to be open, got %s", state)
+	}
+	if state, _ := provider.BreakerState("test_cb", "host-b"); state != goresilience.StateClosed {
+		t.Fatalf("expected host-b to be closed, got %s", state)
+	}
+	if state, _ := provider.BreakerState("test_cb"); state != goresilience.StateClosed {
+		t.Fatalf("expected shared breaker to be closed, got %s", state)
+	}
+	if counts, _ := provider.BreakerCounts("test_cb", "host-b"); counts.TotalSuccesses != 1 {
+		t.Fatalf("expected 1 success for host-b, got %+v", counts)
+	}
+}
+
+func TestKeyedCircuitBreakerEviction(t *testing.T) {
+	provider := keyedBreakerProvider(t, 2, "")
+	policy := provider.Policy("test_target")
+
+	exec := func(oper goresilience.Operation, key string) (any, error) {
+		ctx := goresilience.WithBreakerKey(context.Background(), key)
+		return goresilience.NewExecutor(ctx, policy)(oper)
+	}
+	tripKey(exec, "host-a")
+	tripKey(exec, "host-b")
+	tripKey(exec, "host-c")
+
+	if state, _ := provider.BreakerState("test_cb", "host-a"); state != goresilience.StateClosed {
+		t.Fatalf("expected least recently used key to be evicted, got %s", state)
+	}
+	if state, _ := provider.BreakerState("test_cb", "host-c"); state != goresilience.StateOpen {
+		t.Fatalf("expected host-c to be open, got %s", state)
+	}
+}
+
+func TestKeyedCircuitBreakerIdleEviction(t *testing.T) {
+	provider := keyedBreakerProvider(t, 10, "50ms")
+	policy := provider.Policy("test_target")
+
+	clock := &fakeClock{}
+	clock.now.Store(time.Now().UnixNano())
+	goresilience.SetBreakerClock(provider, "test_cb", clock.Now)
+
+	exec := func(oper goresilience.Operation, key string) (any, error) {
+		ctx := goresilience.WithBreakerKey(context.Background(), key)
+		return goresilience.NewExecutor(ctx, policy)(oper)
+	}
+	tripKey(exec, "host-a")
+
+	clock.Advance(40 * time.Millisecond)
+	if state, _ := provider.BreakerState("test_cb", "host-a"); state != goresilience.StateOpen {
+		t.Fatalf("expected host-a to be open, got %s", state)
+	}
+
+	// Eviction follows the breaker's clock, not the wall clock.
+	clock.Advance(80 * time.Millisecond)
+
+	if state, _ := provider.BreakerState("test_cb", "host-a"); state != goresilience.StateClosed {
+		t.Fatalf("expected idle key to be evicted, got %s", state)
+	}
+}
+
+func TestCircuitBreakerRampUp(t *testing.T) {
+	target := "test_target"
+	cfg := goresilience.Config{
+		CircuitBreakers: map[string]goresilience.CircuitBreaker{
+			"test_cb": {
+				MaxRequests: 1,
+				Interval:    "10s",
+				Timeout:     "100ms",
+				Failures:    1,
+				RampUp:      "300ms",
+			},
+		},
+		Targets: map[string]goresilience.PolicyNames{
+			target: {
+				CircuitBreaker: "test_cb",
+			},
+		},
+	}
+
+	provider, err := goresilience.FromConfig(cfg)
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	var roll atomic.Value
+	roll.Store(0.999)
+	goresilience.SetBreakerRandom(provider, "test_cb", func() float64 {
+		return roll.Load().(float64)
+	})
+
+	exec := goresilience.NewExecWithPolicy(context.Background(), provider.Policy(target))
+	succeed := func(ctx context.Context) (any, error) {
+		return successResult, nil
+	}
+
+	// Before the breaker has ever tripped there is no ramp-up.
+	if _, err := exec(succeed); err != nil {
+		t.Fatalf("expected success before tripping, got: %v", err)
+	}
+
+	_, _ = exec(func(ctx context.Context) (any, error) {
+		return nil, testError
+	})
+
+	time.Sleep(150 * time.Millisecond)
+
+	// Half-open probe closes the breaker and starts the ramp-up window.
+	if _, err := exec(succeed); err != nil {
+		t.Fatalf("expected probe to succeed, got: %v", err)
+	}
+	if state, _ := provider.BreakerState("test_cb"); state != goresilience.StateClosed {
+		t.Fatalf("expected breaker to be closed, got %s", state)
+	}
+
+	_, err = exec(succeed)
+	if !errors.Is(err, goresilience.ErrTooManyRequests) {
+		t.Fatalf("expected ErrTooManyRequests during ramp-up, got: %v", err)
+	}
+
+	roll.Store(0.0)
+	if _, err := exec(succeed); err != nil {
+		t.Fatalf("expected admitted request during ramp-up, got: %v", err)
+	}
+
+	roll.Store(0.999)
+	time.Sleep(300 * time.Millisecond)
+	if _, err := exec(succeed); err != nil {
+		t.Fatalf("expected full traffic after ramp-up, got: %v", err)
+	}
+}
+
+func TestCircuitBreakerForceOpen(t *testing.T) {
+	provider := eventsProvider(t)
+	exec := goresilience.NewExecWithPolicy(context.Background(), provider.Policy("test_target"))
+
+	if err := provider.ForceOpen("test_cb", 100*time.Millisecond); err != nil {
+		t.Fatalf("failed to force open: %v", err)
+	}
+
+	if state, _ := provider.BreakerState("test_cb"); state != goresilience.StateForcedOpen {
+		t.Fatalf("expected %s, got %s", goresilience.StateForcedOpen, state)
+	}
+
+	_, err := exec(func(ctx context.Context) (any, error) {
+		t.Error("operation should not be executed while forced open")
+		return nil, nil
+	})
+	if !errors.Is(err, goresilience.ErrOpenState) {
+		t.Fatalf("expected ErrOpenState, got: %v", err)
+	}
+
+	time.Sleep(150 * time.Millisecond)
+
+	if state, _ := provider.BreakerState("test_cb"); state != goresilience.StateClosed {
+		t.Fatalf("expected forced state to expire, got %s", state)
+	}
+
+	// Normal accounting resumes: two failures trip the breaker.
+	for i := 0; i < 2; i++ {
+		_, _ = exec(func(ctx context.Context) (any, error) {
+			return nil, testError
+		})
+	}
+	if state, _ := provider.BreakerState("test_cb"); state != goresilience.StateOpen {
+		t.Fatalf("expected breaker to trip after forced state expired, got %s", state)
+	}
+}
+
+func TestCircuitBreakerForceClose(t *testing.T) {
+	provider := eventsProvider(t)
+	exec := goresilience.NewExecWithPolicy(context.Background(), provider.Policy("test_target"))
+
+	for i := 0; i < 2; i++ {
+		_, _ = exec(func(ctx context.Context) (any, error) {
+			return nil, testError
+		})
+	}
+
+	if err := provider.ForceClose("test_cb", 100*time.Millisecond); err != nil {
+		t.Fatalf("failed to force close: %v", err)
+	}
+
+	if state, _ := provider.BreakerState("test_cb"); state != goresilience.StateForcedClosed {
+		t.Fatalf("expected %s, got %s", goresilience.StateForcedClosed, state)
+	}
+
+	for i := 0; i < 3; i++ {
+		_, err := exec(func(ctx context.Context) (any, error) {
+			return nil, testError
+		})
+		if !errors.Is(err, testError) {
+			t.Fatalf("expected operation to run while forced closed, got: %v", err)
+		}
+	}
+
+	if counts, _ := provider.BreakerCounts("test_cb"); counts.Requests != 0 {
+		t.Fatalf("forced executions should not be counted, got %+v", counts)
+	}
+
+	time.Sleep(150 * time.Millisecond)
+
+	// The underlying breaker was left untouched and is still open.
+	_, err := exec(func(ctx context.Context) (any, error) {
+		return successResult, nil
+	})
+	if err != nil {
+		t.Fatalf("expected half-open probe to run after forced state expired, got: %v", err)
+	}
+	if state, _ := provider.BreakerState("test_cb"); state != goresilience.StateClosed {
+		t.Fatalf("expected probe to close the breaker, got %s", state)
+	}
+}
+
+func TestCircuitBreakerForceUnknown(t *testing.T) {
+	provider := eventsProvider(t)
+
+	if err := provider.ForceOpen("missing_cb", time.Second); err == nil {
+		t.Fatal("expected error for unknown breaker")
+	}
+	if err := provider.ForceClose("test_cb", 0); err == nil {
+		t.Fatal("expected error for non-positive duration")
+	}
+}
+
+func TestCircuitBreakerCountTimeouts(t *testing.T) {
+	tests := []struct {
+		name          string
+		countTimeouts bool
+		expected      goresilience.State
+	}{
+		{"timeouts counted", true, goresilience.StateOpen},
+		{"timeouts ignored", false, goresilience.StateClosed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := goresilience.Config{
+				Timeouts: map[string]goresilience.TimeoutSpec{
+					"short": {Duration: "50ms"},
+				},
+				CircuitBreakers: map[string]goresilience.CircuitBreaker{
+					"test_cb": {
+						MaxRequests:   1,
+						Interval:      "10s",
+						Timeout:       "10s",
+						Failures:      1,
+						CountTimeouts: tt.countTimeouts,
+					},
+				},
+				Targets: map[string]goresilience.PolicyNames{
+					"test_target": {
+						Timeout:        "short",
+						CircuitBreaker: "test_cb",
+					},
+				},
+			}
+
+			provider, err := goresilience.FromConfig(cfg)
+			if err != nil {
+				t.Fatalf("failed to create provider: %v", err)
+			}
+
+			exec := goresilience.NewExecutor(context.Background(), provider.Policy("test_target"))
+			_, err = exec(func(ctx context.Context) (any, error) {
+				<-ctx.Done()
+				return nil, ctx.Err()
+			})
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Fatalf("expected deadline exceeded, got: %v", err)
+			}
+
+			if state, _ := provider.BreakerState("test_cb"); state != tt.expected {
+				t.Fatalf("expected breaker to be %s, got %s", tt.expected, state)
+			}
+		})
+	}
+}
+
+func TestCircuitBreakerCountsDependencyDeadlines(t *testing.T) {
+	cfg := reloadConfig(0, goresilience.CircuitBreaker{MaxRequests: 1, Interval: "10s", Timeout: "10s", Failures: 2})
+	provider, err := goresilience.FromConfig(cfg)
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	// A dependency that only ever times out on its own, without a timeout
+	// policy firing, trips the breaker even though CountTimeouts is unset.
+	exec := goresilience.NewExecutor(context.Background(), provider.Policy("test_target"))
+	for range 2 {
+		_, err = exec(func(ctx context.Context) (any, error) {
+			return nil, fmt.Errorf("calling dependency: %w", context.DeadlineExceeded)
+		})
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected deadline exceeded, got: %v", err)
+		}
+	}
+
+	if state, _ := provider.BreakerState("test_cb"); state != goresilience.StateOpen {
+		t.Fatalf("expected breaker to be open, got %s", state)
+	}
+}
+
+func TestCircuitBreakerCountsCallerDeadline(t *testing.T) {
+	cfg := reloadConfig(0, goresilience.CircuitBreaker{MaxRequests: 1, Interval: "10s", Timeout: "10s", Failures: 1})
+	provider, err := goresilience.FromConfig(cfg)
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err = goresilience.NewExecutor(ctx, provider.Policy("test_target"))(func(ctx context.Context) (any, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	if !errors.Is(err, context.DeadlineExceeded) || errors.Is(err, goresilience.ErrExecutionTimeout) {
+		t.Fatalf("expected the caller's deadline, got: %v", err)
+	}
+
+	if state, _ := provider.BreakerState("test_cb"); state != goresilience.StateOpen {
+		t.Fatalf("expected the caller's deadline to count as a failure, got %s", state)
+	}
+}
+
+func TestCircuitBreakerWarmUp(t *testing.T) {
+	target := "test_target"
+	cfg := goresilience.Config{
+		CircuitBreakers: map[string]goresilience.CircuitBreaker{
+			"test_cb": {
+				MaxRequests: 1,
+				Interval:    "10s",
+				Timeout:     "10s",
+				Failures:    2,
+				WarmUp:      "1m",
+			},
+		},
+		Targets: map[string]goresilience.PolicyNames{
+			target: {
+				CircuitBreaker: "test_cb",
+			},
+		},
+	}
+
+	provider, err := goresilience.FromConfig(cfg)
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	var clock atomic.Int64
+	clock.Store(time.Now().UnixNano())
+	goresilience.SetBreakerClock(provider, "test_cb", func() time.Time {
+		return time.Unix(0, clock.Load())
+	})
+	if err := provider.ResetBreaker("test_cb"); err != nil {
+		t.Fatalf("failed to reset breaker: %v", err)
+	}
+
+	exec := goresilience.NewExecWithPolicy(context.Background(), provider.Policy(target))
+	fail := func(ctx context.Context) (any, error) {
+		return nil, testError
+	}
+
+	for i := 0; i < 5; i++ {
+		if _, err := exec(fail); !errors.Is(err, testError) {
+			t.Fatalf("expected failure to pass through during warm-up, got: %v", err)
+		}
+	}
+	if state, _ := provider.BreakerState("test_cb"); state != goresilience.StateClosed {
+		t.Fatalf("expected breaker to stay closed during warm-up, got %s", state)
+	}
+	if _, err := exec(succeed); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if counts, _ := provider.BreakerCounts("test_cb"); counts != (goresilience.Counts{Requests: 1, TotalSuccesses: 1, ConsecutiveSuccesses: 1}) {
+		t.Fatalf("expected only the success to be counted during warm-up, got %+v", counts)
+	}
+
+	clock.Add(int64(2 * time.Minute))
+
+	for i := 0; i < 2; i++ {
+		_, _ = exec(fail)
+	}
+	if state, _ := provider.BreakerState("test_cb"); state != goresilience.StateOpen {
+		t.Fatalf("expected breaker to trip after warm-up, got %s", state)
+	}
+
+	// Reset restarts the warm-up window.
+	if err := provider.ResetBreaker("test_cb"); err != nil {
+		t.Fatalf("failed to reset breaker: %v", err)
+	}
+	for i := 0; i < 5; i++ {
+		_, _ = exec(fail)
+	}
+	if state, _ := provider.BreakerState("test_cb"); state != goresilience.StateClosed {
+		t.Fatalf("expected breaker to stay closed after reset, got %s", state)
+	}
+}
+
+func TestCircuitBreakerDisabled(t *testing.T) {
+	provider, _ := stateMachineProvider(t, goresilience.CircuitBreaker{
+		MaxRequests: 1,
+		Interval:    "never",
+		Timeout:     "10s",
+		Failures:    1,
+	})
+
+	if err := provider.SetBreakerEnabled("test_cb", false); err != nil {
+		t.Fatalf("failed to disable breaker: %v", err)
+	}
+	expectState(t, provider, goresilience.StateDisabled)
+
+	exec := goresilience.NewExecWithPolicy(context.Background(), provider.Policy("test_target"))
+	for i := 0; i < 5; i++ {
+		if _, err := exec(func(ctx context.Context) (any, error) {
+			return nil, testError
+		}); !errors.Is(err, testError) {
+			t.Fatalf("expected failure to pass through while disabled, got: %v", err)
+		}
+	}
+
+	if counts, _ := provider.BreakerCounts("test_cb"); counts != (goresilience.Counts{}) {
+		t.Fatalf("expected no counts while disabled, got %+v", counts)
+	}
+
+	if err := provider.SetBreakerEnabled("test_cb", true); err != nil {
+		t.Fatalf("failed to enable breaker: %v", err)
+	}
+	expectState(t, provider, goresilience.StateClosed)
+
+	_, _ = exec(func(ctx context.Context) (any, error) {
+		return nil, testError
+	})
+	expectState(t, provider, goresilience.StateOpen)
+
+	if err := provider.SetBreakerEnabled("unknown_cb", false); err == nil {
+		t.Fatal("expected error for unknown breaker")
+	}
+}
+
+func TestCircuitBreakerReenableStartsClosed(t *testing.T) {
+	provider, _ := stateMachineProvider(t, goresilience.CircuitBreaker{
+		MaxRequests: 1,
+		Interval:    "never",
+		Timeout:     "10s",
+		Failures:    1,
+	})
+
+	acquire(t, provider).Failure(testError)
+	expectState(t, provider, goresilience.StateOpen)
+
+	_ = provider.SetBreakerEnabled("test_cb", false)
+	_ = provider.SetBreakerEnabled("test_cb", true)
+
+	expectState(t, provider, goresilience.StateClosed)
+	acquire(t, provider).Success()
+}
+
+func TestCircuitBreakerToggleConcurrency(t *testing.T) {
+	provider, _ := stateMachineProvider(t, goresilience.CircuitBreaker{
+		MaxRequests: 1,
+		Interval:    "never",
+		Timeout:     "10s",
+		Failures:    1000,
+	})
+
+	exec := goresilience.NewExecWithPolicy(context.Background(), provider.Policy("test_target"))
+
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+
+				if _, err := exec(func(ctx context.Context) (any, error) {
+					return successResult, nil
+				}); err != nil {
+					t.Errorf("unexpected error: %v", err)
+					return
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < 100; i++ {
+		_ = provider.SetBreakerEnabled("test_cb", i%2 == 0)
+	}
+	close(done)
+	wg.Wait()
+
+	_ = provider.SetBreakerEnabled("test_cb", true)
+	expectState(t, provider, goresilience.StateClosed)
+}
+
+func TestCircuitBreakerDryRun(t *testing.T) {
+	provider, err := goresilience.FromConfig(goresilience.Config{
+		CircuitBreakers: map[string]goresilience.CircuitBreaker{
+			"test_cb": {
+				MaxRequests: 1,
+				Interval:    "10s",
+				Timeout:     "10s",
+				Failures:    2,
+				DryRun:      true,
+			},
+		},
+		Targets: map[string]goresilience.PolicyNames{
+			"test_target": {
+				CircuitBreaker: "test_cb",
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	var changes []goresilience.StateChange
+	provider.OnStateChange(func(sc goresilience.StateChange) {
+		changes = append(changes, sc)
+	})
+
+	exec := goresilience.NewExecWithPolicy(context.Background(), provider.Policy("test_target"))
+	var calls int
+	for i := 0; i < 5; i++ {
+		_, err := exec(func(ctx context.Context) (any, error) {
+			calls++
+			return nil, testError
+		})
+		if errors.Is(err, goresilience.ErrOpenState) {
+			t.Fatalf("dry-run breaker must not reject, got: %v", err)
+		}
+		if !errors.Is(err, testError) {
+			t.Fatalf("expected test error, got: %v", err)
+		}
+	}
+
+	if calls != 5 {
+		t.Fatalf("expected every call to run, got %d", calls)
+	}
+	if state, _ := provider.BreakerState("test_cb"); state != goresilience.StateOpen {
+		t.Fatalf("expected the state machine to be open, got %s", state)
+	}
+	if len(changes) != 1 || changes[0].To != goresilience.StateOpen || !changes[0].DryRun {
+		t.Fatalf("expected one dry-run transition to open, got %+v", changes)
+	}
+
+	events := provider.Events()
+	var rejected int
+	for len(events) > 0 {
+		e := <-events
+		if !e.DryRun {
+			t.Fatalf("expected dry-run event, got %+v", e)
+		}
+		if e.Type == goresilience.RequestRejected {
+			rejected++
+		}
+	}
+	if rejected != 3 {
+		t.Fatalf("expected 3 would-be rejections, got %d", rejected)
+	}
+
+	if _, err := provider.Acquire("test_target"); err != nil {
+		t.Fatalf("expected dry-run permit, got: %v", err)
+	}
+}
+
+func TestCircuitBreakerCountsDependencyDeadlinesUnderTimeout(t *testing.T) {
+	for _, mode := range []string{goresilience.TimeoutModeDetached, goresilience.TimeoutModeCooperative} {
+		t.Run(mode, func(t *testing.T) {
+			provider, err := goresilience.FromConfig(goresilience.Config{
+				Timeouts: map[string]goresilience.TimeoutSpec{
+					"long": {Duration: "5s", Mode: mode},
+				},
+				CircuitBreakers: map[string]goresilience.CircuitBreaker{
+					"test_cb": {MaxRequests: 1, Interval: "10s", Timeout: "10s", Failures: 2},
+				},
+				Targets: map[string]goresilience.PolicyNames{
+					"test_target": {Timeout: "long", CircuitBreaker: "test_cb"},
+				},
+			})
+			if err != nil {
+				t.Fatalf("failed to create provider: %v", err)
+			}
+
+			// The dependency's own deadline expires long before the policy's,
+			// so its error is passed through and counted as a failure.
+			exec := goresilience.NewExecutor(context.Background(), provider.Policy("test_target"))
+			for range 2 {
+				_, err = exec(func(ctx context.Context) (any, error) {
+					return nil, fmt.Errorf("calling dependency: %w", context.DeadlineExceeded)
+				})
+				if !errors.Is(err, context.DeadlineExceeded) || errors.Is(err, goresilience.ErrExecutionTimeout) {
+					t.Fatalf("expected the dependency's deadline error, got: %v", err)
+				}
+			}
+
+			if state, _ := provider.BreakerState("test_cb"); state != goresilience.StateOpen {
+				t.Fatalf("expected breaker to be open, got %s", state)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package goresilience
+
+import (
+	"math"
+	"strconv"
+	"time"
+)
+
+// CloneWith builds a new provider from the config this one is running
+// with, as Config returns it, after passing it to mutate. The clone has the
+// same options but shares no state: its breakers start closed, and
+// fallbacks, health checks and a breaker registry are not carried over.
+// A nil mutate clones the config unchanged.
+func (p *Provider) CloneWith(mutate func(cfg *Config)) (*Provider, error) {
+	cfg := p.Config()
+	if mutate != nil {
+		mutate(&cfg)
+	}
+
+	opts := p.opts
+	opts.registry = nil
+
+	return newProvider(cfg, opts)
+}
+
+// ScaleDurations returns a CloneWith mutator that multiplies every
+// duration in the config by factor, for example 0.01 to run a production
+// config in tests. Bare integers stay bare, so they keep the unit they are
+// read in. Named timeout references, warnAt fractions and values such as
+// "never" are left alone, and a duration is never scaled down to zero.
+func ScaleDurations(factor float64) func(cfg *Config) {
+	return func(cfg *Config) {
+		*cfg, _ = mapConfigStrings(*cfg, func(field, val string) (string, error) {
+			if !durationFields[field] || val == "" {
+				return val, nil
+			}
+
+			if _, named := cfg.Timeouts[val]; named {
+				return val, nil
+			}
+
+			if i, err := strconv.ParseInt(val, 10, 64); err == nil {
+				return strconv.FormatInt(int64(scaleDuration(time.Duration(i), factor)), 10), nil
+			}
+
+			if f, err := strconv.ParseFloat(val, 64); err == nil && field == "warnAt" && f > 0 && f < 1 {
+				return val, nil
+			}
+
+			d, err := parseDuration(val, 0)
+			if err != nil {
+				return val, nil
+			}
+
+			return scaleDuration(d, factor).String(), nil
+		})
+	}
+}
+
+func scaleDuration(d time.Duration, factor float64) time.Duration {
+	scaled := time.Duration(math.Round(float64(d) * factor))
+	if d > 0 && scaled <= 0 {
+		return 1
+	}
+
+	return scaled
+}
@@ -0,0 +1,135 @@
+package goresilience_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	goresilience "github.com/rickKoch/go-resilience"
+)
+
+func cloneConfig() goresilience.Config {
+	cfg := reloadConfig(2, reloadBreaker)
+	cfg.Retries["test_retry"] = goresilience.Retry{Duration: "100ms", MaxRetries: 2}
+	cfg.Timeouts = map[string]goresilience.TimeoutSpec{
+		"test_timeout": {Duration: "1s", WarnAt: "0.5"},
+	}
+	cfg.Targets["test_target"] = goresilience.PolicyNames{Timeout: "test_timeout", Retry: "test_retry", CircuitBreaker: "test_cb"}
+	return cfg
+}
+
+func TestCloneWithScaleDurations(t *testing.T) {
+	provider, err := goresilience.FromConfig(cloneConfig())
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	clone, err := provider.CloneWith(goresilience.ScaleDurations(0.01))
+	if err != nil {
+		t.Fatalf("failed to clone provider: %v", err)
+	}
+
+	cfg := clone.Config()
+	if got := cfg.Retries["test_retry"].Duration; got != "1ms" {
+		t.Errorf("expected the clone's retry to wait 1ms, got %s", got)
+	}
+	if got := cfg.Timeouts["test_timeout"].Duration; got != "10ms" {
+		t.Errorf("expected the clone's timeout to be 10ms, got %s", got)
+	}
+	if got := cfg.CircuitBreakers["test_cb"].Timeout; got != "100ms" {
+		t.Errorf("expected the clone's breaker timeout to be 100ms, got %s", got)
+	}
+	if got := provider.Config().Timeouts["test_timeout"].Duration; got != "1s" {
+		t.Errorf("expected the original timeout to stay 1s, got %s", got)
+	}
+
+	hang := func(ctx context.Context) (any, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	start := time.Now()
+	_, err = goresilience.NewExecWithPolicy(context.Background(), clone.Policy("test_target"))(hang)
+	if !errors.Is(err, goresilience.ErrExecutionTimeout) {
+		t.Fatalf("expected the clone to time out, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("expected the clone's three attempts to time out quickly, took %s", elapsed)
+	}
+
+	// The original still allows a second per attempt.
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err = goresilience.NewExecWithPolicy(ctx, provider.Policy("test_target"))(hang)
+	if !errors.Is(err, context.DeadlineExceeded) || errors.Is(err, goresilience.ErrExecutionTimeout) {
+		t.Fatalf("expected the caller's deadline to expire first on the original, got %v", err)
+	}
+}
+
+func TestCloneWithFreshBreakers(t *testing.T) {
+	provider, err := goresilience.FromConfig(reloadConfig(2, reloadBreaker))
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	countAttempts(goresilience.NewExecWithPolicy(context.Background(), provider.Policy("test_target")))
+	if state, _ := provider.BreakerState("test_cb"); state != goresilience.StateOpen {
+		t.Fatalf("expected the original breaker to be open, got %s", state)
+	}
+
+	clone, err := provider.CloneWith(func(cfg *goresilience.Config) {
+		cb := cfg.CircuitBreakers["test_cb"]
+		cb.Failures = 6
+		cfg.CircuitBreakers["test_cb"] = cb
+	})
+	if err != nil {
+		t.Fatalf("failed to clone provider: %v", err)
+	}
+
+	if state, _ := clone.BreakerState("test_cb"); state != goresilience.StateClosed {
+		t.Fatalf("expected the clone's breaker to start closed, got %s", state)
+	}
+	if got := clone.Config().CircuitBreakers["test_cb"].Failures; got != 6 {
+		t.Fatalf("expected the clone's breaker to trip after 6 failures, got %d", got)
+	}
+	if got := provider.Config().CircuitBreakers["test_cb"].Failures; got != 3 {
+		t.Fatalf("expected the original breaker to keep its threshold, got %d", got)
+	}
+}
+
+func TestScaleDurations(t *testing.T) {
+	cfg := goresilience.Config{
+		Timeouts: map[string]goresilience.TimeoutSpec{"slow": {Duration: "1500", WarnAt: "0.5"}},
+		CircuitBreakers: map[string]goresilience.CircuitBreaker{
+			"cb": {Interval: goresilience.IntervalNever, Timeout: "PT1M", RampUp: "1ns"},
+		},
+		Targets: map[string]goresilience.PolicyNames{"t": {Timeout: "slow", MinDeadline: "10ms"}},
+	}
+
+	goresilience.ScaleDurations(0.1)(&cfg)
+
+	got := map[string]string{
+		"timeout duration": cfg.Timeouts["slow"].Duration,
+		"warnAt":           cfg.Timeouts["slow"].WarnAt,
+		"interval":         cfg.CircuitBreakers["cb"].Interval,
+		"breaker timeout":  cfg.CircuitBreakers["cb"].Timeout,
+		"rampUp":           cfg.CircuitBreakers["cb"].RampUp,
+		"timeout ref":      cfg.Targets["t"].Timeout,
+		"minDeadline":      cfg.Targets["t"].MinDeadline,
+	}
+	want := map[string]string{
+		"timeout duration": "150",
+		"warnAt":           "0.5",
+		"interval":         "never",
+		"breaker timeout":  "6s",
+		"rampUp":           "1ns",
+		"timeout ref":      "slow",
+		"minDeadline":      "1ms",
+	}
+	for field, val := range got {
+		if val != want[field] {
+			t.Errorf("%s: expected %q, got %q", field, want[field], val)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package goresilience_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	goresilience "github.com/rickKoch/go-resilience"
+)
+
+func TestCloseStopsGoroutines(t *testing.T) {
+	before := runtime.NumGoroutine()
+
+	path := filepath.Join(t.TempDir(), "resilience.yaml")
+	writeRetryConfig(t, path, 1)
+
+	provider, err := goresilience.FromConfigWithOptions(goresilience.Config{}, goresilience.WithConfigPollInterval(time.Millisecond))
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	if err := provider.WatchConfigFile(context.Background(), path); err != nil {
+		t.Fatalf("failed to watch config: %v", err)
+	}
+	provider.SetHealthCheck("test_target", func(ctx context.Context) error { return nil }, time.Millisecond)
+
+	if err := provider.Close(); err != nil {
+		t.Fatalf("failed to close provider: %v", err)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %d goroutines after Close, got %d", before, runtime.NumGoroutine())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestCloseFailsLaterExecutions(t *testing.T) {
+	provider, err := goresilience.FromConfig(reloadConfig(1, reloadBreaker))
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+	exec := goresilience.NewExecutor(context.Background(), provider.Policy("test_target"))
+
+	if err := provider.Close(); err != nil {
+		t.Fatalf("failed to close provider: %v", err)
+	}
+
+	var calls int
+	_, err = exec(func(ctx context.Context) (any, error) {
+		calls++
+		return successResult, nil
+	})
+	if !errors.Is(err, goresilience.ErrProviderClosed) || calls != 0 {
+		t.Fatalf("expected ErrProviderClosed without running the operation, got %v after %d calls", err, calls)
+	}
+
+	if _, err := provider.Acquire("test_target"); !errors.Is(err, goresilience.ErrProviderClosed) {
+		t.Fatalf("expected Acquire to fail with ErrProviderClosed, got: %v", err)
+	}
+
+	if _, ok := <-provider.Events(); ok {
+		t.Fatal("expected the events channel to be closed")
+	}
+}
+
+func TestCloseConcurrentWithExecutions(t *testing.T) {
+	provider, err := goresilience.FromConfig(reloadConfig(3, reloadBreaker))
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+	exec := goresilience.NewExecutor(context.Background(), provider.Policy("test_target"))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				// Failures trip the breaker, which emits events while
+				// the provider closes.
+				_, _ = exec(func(ctx context.Context) (any, error) {
+					return nil, testError
+				})
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			_ = provider.Close()
+		}()
+	}
+	wg.Wait()
+
+	if err := provider.Close(); err != nil {
+		t.Fatalf("expected Close to be idempotent, got: %v", err)
+	}
+}
@@ -0,0 +1,96 @@
+package goresilience
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// coalescer merges calls that share a call key and arrive within a window
+// of the first one. At the end of the window only the most recent call's
+// operation runs, and every waiter gets its result.
+type coalescer struct {
+	window time.Duration
+	after  func(time.Duration) <-chan time.Time
+
+	mu      sync.Mutex
+	pending map[string]*coalescedCall
+}
+
+type coalescedCall struct {
+	ctx  context.Context
+	oper Operation
+	done chan struct{}
+	res  any
+	err  error
+}
+
+func newCoalescer(c Coalesce, unit time.Duration) (*coalescer, error) {
+	if c == (Coalesce{}) {
+		return nil, nil
+	}
+
+	var errs configErrors
+
+	window := errs.requiredDuration("window", c.Window, unit)
+	if err := errs.err(); err != nil {
+		return nil, err
+	}
+
+	return &coalescer{
+		window:  window,
+		after:   time.After,
+		pending: make(map[string]*coalescedCall),
+	}, nil
+}
+
+func (c *coalescer) join(ctx context.Context, key string, oper Operation) *coalescedCall {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// The shared call outlives any one caller, so it keeps their values but
+	// not their cancellation.
+	ctx = context.WithoutCancel(ctx)
+
+	if call, ok := c.pending[key]; ok {
+		call.ctx, call.oper = ctx, oper
+		return call
+	}
+
+	call := &coalescedCall{ctx: ctx, oper: oper, done: make(chan struct{})}
+	c.pending[key] = call
+
+	timer := c.after(c.window)
+	go func() {
+		<-timer
+
+		c.mu.Lock()
+		delete(c.pending, key)
+		ctx, oper := call.ctx, call.oper
+		c.mu.Unlock()
+
+		call.res, call.err = oper(ctx)
+		close(call.done)
+	}()
+
+	return call
+}
+
+func (p *Policy) withCoalesce(oper Operation) Operation {
+	return func(ctx context.Context) (any, error) {
+		key, ok := CallKeyFromContext(ctx)
+		if !ok {
+			return oper(ctx)
+		}
+
+		// Members of a group share its coalescer.
+		call := p.coalescer.join(ctx, p.target+"\x00"+key, oper)
+
+		select {
+		case <-call.done:
+			return call.res, call.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
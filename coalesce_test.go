@@ -0,0 +1,213 @@
+package goresilience_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	goresilience "github.com/rickKoch/go-resilience"
+)
+
+// fakeTimer hands out windows that only end when the test fires them.
+type fakeTimer struct {
+	mu      sync.Mutex
+	windows []chan time.Time
+}
+
+func (f *fakeTimer) After(time.Duration) <-chan time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	ch := make(chan time.Time, 1)
+	f.windows = append(f.windows, ch)
+	return ch
+}
+
+func (f *fakeTimer) Started() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return len(f.windows)
+}
+
+func (f *fakeTimer) Fire(i int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.windows[i] <- time.Time{}
+}
+
+func coalesceProvider(t *testing.T) (*goresilience.Provider, *fakeTimer) {
+	t.Helper()
+
+	provider, err := goresilience.FromConfig(goresilience.Config{
+		Targets: map[string]goresilience.PolicyNames{
+			"test_target": {Coalesce: goresilience.Coalesce{Window: "100ms"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	timer := &fakeTimer{}
+	goresilience.SetCoalesceTimer(provider, "test_target", timer.After)
+
+	return provider, timer
+}
+
+func coalescedCall(ctx context.Context, provider *goresilience.Provider, oper goresilience.Operation) (any, error) {
+	ctx = goresilience.WithCallKey(ctx, "config")
+	return goresilience.NewExecWithPolicy(ctx, provider.Policy("test_target"))(oper)
+}
+
+func TestCoalesceBurst(t *testing.T) {
+	provider, timer := coalesceProvider(t)
+
+	var calls atomic.Int32
+	results := make(chan any, 100)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res, err := coalescedCall(context.Background(), provider, func(ctx context.Context) (any, error) {
+				calls.Add(1)
+				return successResult, nil
+			})
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			results <- res
+		}()
+	}
+
+	// Nothing runs until the window ends, however long that takes.
+	time.Sleep(50 * time.Millisecond)
+	if calls.Load() != 0 {
+		t.Fatalf("expected no execution before the window ends, got %d", calls.Load())
+	}
+	if timer.Started() != 1 {
+		t.Fatalf("expected a single window for the burst, got %d", timer.Started())
+	}
+
+	timer.Fire(0)
+	wg.Wait()
+	close(results)
+
+	if calls.Load() != 1 {
+		t.Fatalf("expected exactly one execution, got %d", calls.Load())
+	}
+	for res := range results {
+		if res != successResult {
+			t.Fatalf("expected every caller to get the shared result, got %v", res)
+		}
+	}
+}
+
+func TestCoalesceRunsLastOperation(t *testing.T) {
+	provider, timer := coalesceProvider(t)
+
+	first := make(chan any, 1)
+	go func() {
+		res, _ := coalescedCall(context.Background(), provider, func(ctx context.Context) (any, error) {
+			return "first", nil
+		})
+		first <- res
+	}()
+
+	for timer.Started() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	second := make(chan any, 1)
+	go func() {
+		res, _ := coalescedCall(context.Background(), provider, func(ctx context.Context) (any, error) {
+			return "second", nil
+		})
+		second <- res
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+	timer.Fire(0)
+
+	if a, b := <-first, <-second; a != "second" || b != "second" {
+		t.Fatalf("expected both callers to get the last operation's result, got %v and %v", a, b)
+	}
+
+	// A call after the window has closed starts a new one.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = coalescedCall(context.Background(), provider, func(ctx context.Context) (any, error) {
+			return successResult, nil
+		})
+	}()
+
+	for timer.Started() != 2 {
+		time.Sleep(time.Millisecond)
+	}
+	timer.Fire(1)
+	<-done
+}
+
+func TestCoalesceCancelledWaiter(t *testing.T) {
+	provider, timer := coalesceProvider(t)
+
+	var sharedErr atomic.Value
+	oper := func(ctx context.Context) (any, error) {
+		if err := ctx.Err(); err != nil {
+			sharedErr.Store(err)
+			return nil, err
+		}
+		return successResult, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancelled := make(chan error, 1)
+	go func() {
+		_, err := coalescedCall(ctx, provider, oper)
+		cancelled <- err
+	}()
+
+	for timer.Started() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	waiting := make(chan any, 1)
+	go func() {
+		res, _ := coalescedCall(context.Background(), provider, oper)
+		waiting <- res
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	cancel()
+	if err := <-cancelled; !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the cancelled caller to detach, got: %v", err)
+	}
+
+	timer.Fire(0)
+	if res := <-waiting; res != successResult {
+		t.Fatalf("expected the shared call to run for the remaining waiter, got %v", res)
+	}
+	if err := sharedErr.Load(); err != nil {
+		t.Fatalf("expected the shared call not to be cancelled, got: %v", err)
+	}
+}
+
+func TestCoalesceWithoutKey(t *testing.T) {
+	provider, timer := coalesceProvider(t)
+
+	exec := goresilience.NewExecWithPolicy(context.Background(), provider.Policy("test_target"))
+	if res, err := exec(func(ctx context.Context) (any, error) {
+		return successResult, nil
+	}); res != successResult || err != nil {
+		t.Fatalf("expected calls without a key to run immediately, got %v, %v", res, err)
+	}
+	if timer.Started() != 0 {
+		t.Fatal("expected no window for calls without a key")
+	}
+}
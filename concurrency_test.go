@@ -0,0 +1,90 @@
+package goresilience_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	goresilience "github.com/rickKoch/go-resilience"
+)
+
+// TestExecutorSharedAcrossGoroutines calls one executor from many
+// goroutines with every stateful stage enabled. Run it with -race.
+func TestExecutorSharedAcrossGoroutines(t *testing.T) {
+	provider, err := goresilience.FromConfigWithOptions(goresilience.Config{
+		Timeouts: map[string]goresilience.TimeoutSpec{
+			"test_timeout": {Duration: "10ms", Mode: goresilience.TimeoutModeCooperative},
+		},
+		Retries: map[string]goresilience.Retry{
+			"test_retry": {Duration: "1ms", MaxRetries: 1},
+		},
+		CircuitBreakers: map[string]goresilience.CircuitBreaker{
+			"test_cb": {MaxRequests: 5, Interval: "50ms", Timeout: "5ms", Failures: 20},
+		},
+		Bulkheads: map[string]goresilience.Bulkhead{
+			"test_bulkhead": {MaxConcurrent: 20, MaxWait: "100ms"},
+		},
+		Targets: map[string]goresilience.PolicyNames{
+			"test_target": {
+				Timeout:        "test_timeout",
+				Retry:          "test_retry",
+				CircuitBreaker: "test_cb",
+				Bulkhead:       "test_bulkhead",
+			},
+		},
+	}, goresilience.WithExecutionTraces(10), goresilience.WithObserver(&fakeExecutionObserver{}))
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	provider.Use("test_target", func(next goresilience.Operation) goresilience.Operation { return next })
+	provider.Intercept("", goresilience.Interceptor{
+		After: func(context.Context, string, any, error, time.Duration) {},
+	})
+
+	exec := goresilience.NewExecutor(context.Background(), provider.Policy("test_target"))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 20; j++ {
+				var op goresilience.Operation
+				switch (i + j) % 3 {
+				case 0:
+					op = succeed
+				case 1:
+					op = func(ctx context.Context) (any, error) { return nil, testError }
+				default:
+					op = func(ctx context.Context) (any, error) {
+						<-ctx.Done()
+						return nil, ctx.Err()
+					}
+				}
+
+				res, err := exec(op)
+
+				var stageErr *goresilience.StageError
+				switch {
+				case err == nil && res != successResult:
+					t.Errorf("expected %q on success, got %v", successResult, res)
+				case err != nil && !errors.As(err, &stageErr):
+					t.Errorf("expected the error to be attributed to a stage, got %v", err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	m := provider.Metrics().Targets["test_target"]
+	if m.Executions != 2000 || m.Successes+m.Failures != 2000 {
+		t.Fatalf("expected every call to be counted once, got %+v", m)
+	}
+	if n := provider.InFlight("test_target"); n != 0 {
+		t.Fatalf("expected no calls in flight, got %d", n)
+	}
+}
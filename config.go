@@ -1,26 +1,227 @@
 package goresilience
 
+import "encoding/json"
+
+// Config describes the policies of a provider. Version selects the schema
+// it is written against; see ConfigV1 and ConfigV2.
 type Config struct {
-	Timeouts        map[string]string         `json:"timeouts,omitempty" yaml:"timeouts,omitempty"`
-	Retries         map[string]Retry          `json:"retries,omitempty" yaml:"retries,omitempty"`
-	CircuitBreakers map[string]CircuitBreaker `json:"circuitBreakers,omitempty" yaml:"circuitBreakers,omitempty"`
-	Targets         map[string]PolicyNames    `json:"targets,omitempty" yaml:"targets,omitempty"`
+	Version         string                     `json:"version,omitempty" yaml:"version,omitempty"`
+	Timeouts        map[string]TimeoutSpec     `json:"timeouts,omitempty" yaml:"timeouts,omitempty"`
+	Retries         map[string]Retry           `json:"retries,omitempty" yaml:"retries,omitempty"`
+	CircuitBreakers map[string]CircuitBreaker  `json:"circuitBreakers,omitempty" yaml:"circuitBreakers,omitempty"`
+	Bulkheads       map[string]Bulkhead        `json:"bulkheads,omitempty" yaml:"bulkheads,omitempty"`
+	Caches          map[string]Cache           `json:"caches,omitempty" yaml:"caches,omitempty"`
+	AdaptiveLimits  map[string]AdaptiveLimit   `json:"adaptiveLimits,omitempty" yaml:"adaptiveLimits,omitempty"`
+	Sheds           map[string]Shed            `json:"sheds,omitempty" yaml:"sheds,omitempty"`
+	Custom          map[string]json.RawMessage `json:"custom,omitempty" yaml:"-"`
+	Targets         map[string]PolicyNames     `json:"targets,omitempty" yaml:"targets,omitempty"`
+	Chains          map[string][]string        `json:"chains,omitempty" yaml:"chains,omitempty"`
+	Aliases         map[string][]string        `json:"aliases,omitempty" yaml:"aliases,omitempty"`
+	Groups          map[string]TargetGroup     `json:"groups,omitempty" yaml:"groups,omitempty"`
+	Defaults        Defaults                   `json:"defaults,omitzero" yaml:"defaults,omitempty"`
+}
+
+// Defaults applies to every target, including ones not listed in Targets,
+// unless the target sets its own policy. Timeout may be a named timeout or
+// a duration literal; a target opts out with the TimeoutNone reference.
+type Defaults struct {
+	Timeout string `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+}
+
+// TimeoutSpec configures a timeout policy. In config files it may also be
+// written as a bare duration string, which selects the detached mode.
+// GracePeriod and DrainTimeout only apply to the detached mode. WarnAt is
+// either a fraction of Duration ("0.8") or a duration ("800ms").
+type TimeoutSpec struct {
+	Duration     string `json:"duration,omitempty" yaml:"duration,omitempty"`
+	Mode         string `json:"mode,omitempty" yaml:"mode,omitempty"`
+	GracePeriod  string `json:"gracePeriod,omitempty" yaml:"gracePeriod,omitempty"`
+	DrainTimeout string `json:"drainTimeout,omitempty" yaml:"drainTimeout,omitempty"`
+	WarnAt       string `json:"warnAt,omitempty" yaml:"warnAt,omitempty"`
+}
+
+func (t *TimeoutSpec) UnmarshalJSON(data []byte) error {
+	var duration string
+	if err := json.Unmarshal(data, &duration); err == nil {
+		*t = TimeoutSpec{Duration: duration}
+		return nil
+	}
+
+	type plain TimeoutSpec
+	return json.Unmarshal(data, (*plain)(t))
+}
+
+func (t *TimeoutSpec) UnmarshalYAML(unmarshal func(any) error) error {
+	var duration string
+	if err := unmarshal(&duration); err == nil {
+		*t = TimeoutSpec{Duration: duration}
+		return nil
+	}
+
+	type plain TimeoutSpec
+	return unmarshal((*plain)(t))
 }
 
+// Retry retries a failed call every Duration. MaxRetries counts the retries
+// after the first attempt and MaxAttempts counts all attempts; set one of
+// them. Either is unbounded when negative. With Backoff set to
+// RetryBackoffExponential, Duration is only the first wait, and each one
+// after it doubles up to MaxInterval (60s by default) and is randomized by
+// up to half. Extends names a retry to take Duration, Backoff and
+// MaxInterval from when they are empty, and the retry count when neither
+// field is set.
 type Retry struct {
-	Duration   string `json:"duration,omitempty" yaml:"duration,omitempty"`
-	MaxRetries int    `json:"maxRetries,omitempty" yaml:"maxRetries,omitempty"`
+	Extends     string `json:"extends,omitempty" yaml:"extends,omitempty"`
+	Duration    string `json:"duration,omitempty" yaml:"duration,omitempty"`
+	MaxRetries  int    `json:"maxRetries,omitempty" yaml:"maxRetries,omitempty"`
+	MaxAttempts int    `json:"maxAttempts,omitempty" yaml:"maxAttempts,omitempty"`
+	Backoff     string `json:"backoff,omitempty" yaml:"backoff,omitempty"`
+	MaxInterval string `json:"maxInterval,omitempty" yaml:"maxInterval,omitempty"`
 }
 
+// CircuitBreaker configures a circuit breaker. Extends names a breaker to
+// take every setting from that is left at its zero value here, so a child
+// can override but not clear its parent's settings. CountTimeouts makes the
+// target's own timeouts count as failures; other deadline errors, such as
+// the caller's or one the dependency returned, always do.
 type CircuitBreaker struct {
+	Extends     string `json:"extends,omitempty" yaml:"extends,omitempty"`
 	MaxRequests int    `json:"maxRequests,omitempty" yaml:"maxRequests,omitempty"`
 	Interval    string `json:"interval,omitempty" yaml:"interval,omitempty"`
 	Timeout     string `json:"timeout,omitempty" yaml:"timeout,omitempty"`
 	Failures    int    `json:"failures,omitempty" yaml:"failures,omitempty"`
+
+	MaxKeys        int    `json:"maxKeys,omitempty" yaml:"maxKeys,omitempty"`
+	KeyIdleTimeout string `json:"keyIdleTimeout,omitempty" yaml:"keyIdleTimeout,omitempty"`
+	RampUp         string `json:"rampUp,omitempty" yaml:"rampUp,omitempty"`
+	CountTimeouts  bool   `json:"countTimeouts,omitempty" yaml:"countTimeouts,omitempty"`
+	WarmUp         string `json:"warmUp,omitempty" yaml:"warmUp,omitempty"`
+	ProbeTimeout   string `json:"probeTimeout,omitempty" yaml:"probeTimeout,omitempty"`
+	DryRun         bool   `json:"dryRun,omitempty" yaml:"dryRun,omitempty"`
+
+	OutlierDetection OutlierDetection `json:"outlierDetection,omitzero" yaml:"outlierDetection,omitempty"`
+}
+
+// OutlierDetection ejects a key of a keyed breaker for Cooldown when its
+// failure rate over a Window is more than Sensitivity (default 2) standard
+// deviations above the mean of its peers, even if it has not tripped on its
+// own. Only keys with at least MinRequests (default 5) calls in the window
+// are compared.
+type OutlierDetection struct {
+	Window      string  `json:"window,omitempty" yaml:"window,omitempty"`
+	Sensitivity float64 `json:"sensitivity,omitempty" yaml:"sensitivity,omitempty"`
+	Cooldown    string  `json:"cooldown,omitempty" yaml:"cooldown,omitempty"`
+	MinRequests int     `json:"minRequests,omitempty" yaml:"minRequests,omitempty"`
 }
 
+// Bulkhead caps concurrent calls. MaxWait is how long a call may wait for a
+// free slot; without it, calls are rejected as soon as the bulkhead is full.
+// MaxQueue bounds how many calls may wait at once; zero leaves it unbounded.
+type Bulkhead struct {
+	MaxConcurrent int    `json:"maxConcurrent,omitempty" yaml:"maxConcurrent,omitempty"`
+	MaxWait       string `json:"maxWait,omitempty" yaml:"maxWait,omitempty"`
+	MaxQueue      int    `json:"maxQueue,omitempty" yaml:"maxQueue,omitempty"`
+}
+
+// Cache keeps the last successful result per cache key for TTL and serves
+// it when a call fails. With Always, a fresh entry is served without
+// calling the operation at all, and without an error; ExecutionResult.Cached
+// tells such a hit apart. MaxEntries defaults to 1000.
+type Cache struct {
+	TTL        string `json:"ttl,omitempty" yaml:"ttl,omitempty"`
+	MaxEntries int    `json:"maxEntries,omitempty" yaml:"maxEntries,omitempty"`
+	Always     bool   `json:"always,omitempty" yaml:"always,omitempty"`
+}
+
+// AdaptiveLimit caps concurrent calls at a limit that starts at
+// InitialLimit, grows while calls succeed within LatencyTarget and shrinks
+// on failures or slower calls, staying within MinLimit (default 1) and
+// MaxLimit (unbounded when zero).
+type AdaptiveLimit struct {
+	InitialLimit  int    `json:"initialLimit,omitempty" yaml:"initialLimit,omitempty"`
+	MinLimit      int    `json:"minLimit,omitempty" yaml:"minLimit,omitempty"`
+	MaxLimit      int    `json:"maxLimit,omitempty" yaml:"maxLimit,omitempty"`
+	LatencyTarget string `json:"latencyTarget,omitempty" yaml:"latencyTarget,omitempty"`
+}
+
+// Shed rejects calls once the load reaches the threshold for their
+// priority. The load is the number of calls in flight through the shed
+// policy unless a LoadFunc is set. Zero thresholds never shed; a higher
+// priority may not shed at a lower threshold than a lower one.
+type Shed struct {
+	Low    float64 `json:"low,omitempty" yaml:"low,omitempty"`
+	Normal float64 `json:"normal,omitempty" yaml:"normal,omitempty"`
+	High   float64 `json:"high,omitempty" yaml:"high,omitempty"`
+}
+
+// Coalesce merges calls with the same call key that arrive within Window of
+// the first one: the operation of the last of them runs once the window
+// ends, and all of them get its result.
+type Coalesce struct {
+	Window string `json:"window,omitempty" yaml:"window,omitempty"`
+}
+
+// PolicyNames references the policies a target uses, each by its name in
+// the matching section of Config. Fields left empty add no stage.
 type PolicyNames struct {
-	Timeout        string `json:"timeout,omitempty" yaml:"timeout,omitempty"`
-	Retry          string `json:"retry,omitempty" yaml:"retry,omitempty"`
-	CircuitBreaker string `json:"circuitBreaker,omitempty" yaml:"circuitBreaker,omitempty"`
+	// Timeout bounds each attempt. It may also be a duration literal such
+	// as "2s", or TimeoutNone to opt out of Defaults.Timeout.
+	Timeout string `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+
+	// OverallTimeout bounds the whole call, retries and their backoff
+	// included. Like Timeout, it may be a duration literal.
+	OverallTimeout string `json:"overallTimeout,omitempty" yaml:"overallTimeout,omitempty"`
+
+	// MinDeadline skips attempts when the caller's deadline leaves less
+	// than this much time.
+	MinDeadline string `json:"minDeadline,omitempty" yaml:"minDeadline,omitempty"`
+
+	// Retry is always a name, since a duration alone cannot say how often
+	// to retry. RetrySpec instead defines a retry inline, registered under
+	// the target's name; the two are mutually exclusive.
+	Retry     string `json:"retry,omitempty" yaml:"retry,omitempty"`
+	RetrySpec *Retry `json:"retrySpec,omitempty" yaml:"retrySpec,omitempty"`
+
+	// CircuitBreakerSpec defines a breaker inline, registered under the
+	// target's name, instead of referencing one with CircuitBreaker.
+	CircuitBreaker     string          `json:"circuitBreaker,omitempty" yaml:"circuitBreaker,omitempty"`
+	CircuitBreakerSpec *CircuitBreaker `json:"circuitBreakerSpec,omitempty" yaml:"circuitBreakerSpec,omitempty"`
+
+	// Bulkhead is shared by every target that references it.
+	Bulkhead string `json:"bulkhead,omitempty" yaml:"bulkhead,omitempty"`
+
+	// Cache keeps the target's results under the key set by WithCacheKey.
+	Cache string `json:"cache,omitempty" yaml:"cache,omitempty"`
+
+	// Singleflight runs concurrent calls with the same call key only once.
+	Singleflight bool `json:"singleflight,omitempty" yaml:"singleflight,omitempty"`
+
+	// Coalesce delays calls with the same call key to merge a burst into
+	// one call.
+	Coalesce Coalesce `json:"coalesce,omitzero" yaml:"coalesce,omitempty"`
+
+	AdaptiveLimit string `json:"adaptiveLimit,omitempty" yaml:"adaptiveLimit,omitempty"`
+	Shed          string `json:"shed,omitempty" yaml:"shed,omitempty"`
+
+	// FallbackOn is FallbackOnOpen, the default, or FallbackOnAny. It
+	// decides both when the target's fallback answers and when a chain
+	// moves past the target.
+	FallbackOn string `json:"fallbackOn,omitempty" yaml:"fallbackOn,omitempty"`
+
+	// Order lists the timeout, circuitBreaker and retry stages from
+	// innermost to outermost. By default the timeout is innermost and the
+	// retry outermost.
+	Order []string `json:"order,omitempty" yaml:"order,omitempty"`
+
+	// Custom names entries of Config.Custom to run on every attempt.
+	Custom []string `json:"custom,omitempty" yaml:"custom,omitempty"`
+}
+
+// TargetGroup gives its policies to every member that has no entry in
+// Targets of its own. Members are target names or path.Match patterns such
+// as "db.*". A target covered by several groups gets the first one's
+// policies in name order.
+type TargetGroup struct {
+	PolicyNames `yaml:",inline"`
+	Members     []string `json:"members,omitempty" yaml:"members,omitempty"`
 }
@@ -0,0 +1,397 @@
+package goresilience_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	goresilience "github.com/rickKoch/go-resilience"
+	"gopkg.in/yaml.v3"
+)
+
+const inlineBreakerYAML = `
+circuitBreakers:
+  shared_cb:
+    maxRequests: 1
+    interval: 1m
+    timeout: 10s
+    failures: 1
+targets:
+  by_name:
+    circuitBreaker: shared_cb
+  inline:
+    circuitBreakerSpec:
+      maxRequests: 1
+      interval: never
+      timeout: 10s
+      failures: 2
+`
+
+const inlineBreakerJSON = `{
+  "circuitBreakers": {
+    "shared_cb": {"maxRequests": 1, "interval": "1m", "timeout": "10s", "failures": 1}
+  },
+  "targets": {
+    "by_name": {"circuitBreaker": "shared_cb"},
+    "inline": {"circuitBreakerSpec": {"maxRequests": 1, "interval": "never", "timeout": "10s", "failures": 2}}
+  }
+}`
+
+func TestInlineCircuitBreakerDecoding(t *testing.T) {
+	decoders := map[string]func(*goresilience.Config) error{
+		"yaml": func(cfg *goresilience.Config) error {
+			return yaml.Unmarshal([]byte(inlineBreakerYAML), cfg)
+		},
+		"json": func(cfg *goresilience.Config) error {
+			return json.Unmarshal([]byte(inlineBreakerJSON), cfg)
+		},
+	}
+
+	for name, decode := range decoders {
+		t.Run(name, func(t *testing.T) {
+			var cfg goresilience.Config
+			if err := decode(&cfg); err != nil {
+				t.Fatalf("failed to decode config: %v", err)
+			}
+
+			if got := cfg.Targets["by_name"].CircuitBreaker; got != "shared_cb" {
+				t.Fatalf("expected reference to shared_cb, got %q", got)
+			}
+			spec := cfg.Targets["inline"].CircuitBreakerSpec
+			if spec == nil || spec.Failures != 2 || spec.Timeout != "10s" {
+				t.Fatalf("unexpected inline spec: %+v", spec)
+			}
+
+			provider, err := goresilience.FromConfig(cfg)
+			if err != nil {
+				t.Fatalf("failed to create provider: %v", err)
+			}
+
+			if _, ok := provider.BreakerState("shared_cb"); !ok {
+				t.Fatal("expected named breaker to exist")
+			}
+			if _, ok := provider.BreakerState("inline"); !ok {
+				t.Fatal("expected inline breaker to be named after its target")
+			}
+
+			permit, err := provider.Acquire("inline")
+			if err != nil {
+				t.Fatalf("expected inline target to use its breaker: %v", err)
+			}
+			permit.Failure(testError)
+
+			if counts, _ := provider.BreakerCounts("inline"); counts.TotalFailures != 1 {
+				t.Fatalf("expected failure on the inline breaker, got %+v", counts)
+			}
+			if counts, _ := provider.BreakerCounts("shared_cb"); counts.TotalFailures != 0 {
+				t.Fatalf("expected named breaker to be untouched, got %+v", counts)
+			}
+		})
+	}
+}
+
+func TestInlineCircuitBreakerValidation(t *testing.T) {
+	spec := &goresilience.CircuitBreaker{MaxRequests: 1, Interval: "never", Timeout: "10s", Failures: 1}
+
+	_, err := goresilience.FromConfig(goresilience.Config{
+		CircuitBreakers: map[string]goresilience.CircuitBreaker{
+			"test_cb": *spec,
+		},
+		Targets: map[string]goresilience.PolicyNames{
+			"test_target": {
+				CircuitBreaker:     "test_cb",
+				CircuitBreakerSpec: spec,
+			},
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), "mutually exclusive") {
+		t.Fatalf("expected error for reference and inline spec, got: %v", err)
+	}
+
+	_, err = goresilience.FromConfig(goresilience.Config{
+		CircuitBreakers: map[string]goresilience.CircuitBreaker{
+			"test_target": *spec,
+		},
+		Targets: map[string]goresilience.PolicyNames{
+			"test_target": {
+				CircuitBreakerSpec: spec,
+			},
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), "conflicts") {
+		t.Fatalf("expected error for name conflict, got: %v", err)
+	}
+
+	_, err = goresilience.FromConfig(goresilience.Config{
+		Targets: map[string]goresilience.PolicyNames{
+			"test_target": {
+				CircuitBreakerSpec: &goresilience.CircuitBreaker{Interval: "never", Timeout: "10s"},
+			},
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), `targets["test_target"].circuitBreakerSpec.failures`) {
+		t.Fatalf("expected inline spec to be validated, got: %v", err)
+	}
+}
+
+const inlineRetryYAML = `
+retries:
+  shared_retry:
+    duration: 1ms
+    maxRetries: 2
+targets:
+  by_name:
+    retry: shared_retry
+  inline:
+    retrySpec:
+      duration: 1ms
+      maxRetries: 2
+`
+
+func TestInlineRetry(t *testing.T) {
+	cfg, err := goresilience.ParseConfigYAML([]byte(inlineRetryYAML))
+	if err != nil {
+		t.Fatalf("failed to decode config: %v", err)
+	}
+
+	spec := cfg.Targets["inline"].RetrySpec
+	if spec == nil || spec.MaxRetries != 2 || spec.Duration != "1ms" {
+		t.Fatalf("unexpected inline spec: %+v", spec)
+	}
+
+	provider, err := goresilience.FromConfig(cfg)
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	inline := countAttempts(goresilience.NewExecWithPolicy(context.Background(), provider.Policy("inline")))
+	named := countAttempts(goresilience.NewExecWithPolicy(context.Background(), provider.Policy("by_name")))
+	if inline != 3 || inline != named {
+		t.Fatalf("expected the inline retry to behave like the named one, got %d and %d attempts", inline, named)
+	}
+
+	if got := provider.Config().Targets["inline"].Retry; got != "inline" {
+		t.Fatalf("expected the inline retry to be named after its target, got %q", got)
+	}
+}
+
+func TestInlineRetryValidation(t *testing.T) {
+	spec := &goresilience.Retry{Duration: "1ms", MaxRetries: 1}
+
+	_, err := goresilience.FromConfig(goresilience.Config{
+		Retries: map[string]goresilience.Retry{
+			"test_retry": *spec,
+		},
+		Targets: map[string]goresilience.PolicyNames{
+			"test_target": {Retry: "test_retry", RetrySpec: spec},
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), `targets["test_target"].retrySpec: retry and retrySpec are mutually exclusive`) {
+		t.Fatalf("expected error for reference and inline spec, got: %v", err)
+	}
+
+	_, err = goresilience.FromConfig(goresilience.Config{
+		Retries: map[string]goresilience.Retry{
+			"test_target": *spec,
+		},
+		Targets: map[string]goresilience.PolicyNames{
+			"test_target": {RetrySpec: spec},
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), "conflicts") {
+		t.Fatalf("expected error for name conflict, got: %v", err)
+	}
+
+	_, err = goresilience.FromConfig(goresilience.Config{
+		Targets: map[string]goresilience.PolicyNames{
+			"test_target": {RetrySpec: &goresilience.Retry{Duration: "soon"}},
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), `targets["test_target"].retrySpec.duration`) {
+		t.Fatalf("expected inline spec to be validated, got: %v", err)
+	}
+}
+
+func TestTimeoutConfigDecodingInvalid(t *testing.T) {
+	var cfg goresilience.Config
+	if err := json.Unmarshal([]byte(`{"timeouts": {"bad": 5}}`), &cfg); err == nil {
+		t.Fatal("expected error for a timeout that is neither a string nor an object")
+	}
+}
+
+func TestTimeoutConfigDecoding(t *testing.T) {
+	decoders := map[string]func(*goresilience.Config) error{
+		"yaml": func(cfg *goresilience.Config) error {
+			return yaml.Unmarshal([]byte("timeouts:\n  plain: 1s\n  structured:\n    duration: 2s\n    mode: cooperative\n    warnAt: \"0.8\"\n"), cfg)
+		},
+		"json": func(cfg *goresilience.Config) error {
+			return json.Unmarshal([]byte(`{"timeouts": {"plain": "1s", "structured": {"duration": "2s", "mode": "cooperative", "warnAt": "0.8"}}}`), cfg)
+		},
+	}
+
+	for name, decode := range decoders {
+		t.Run(name, func(t *testing.T) {
+			var cfg goresilience.Config
+			if err := decode(&cfg); err != nil {
+				t.Fatalf("failed to decode config: %v", err)
+			}
+
+			if got := cfg.Timeouts["plain"]; got != (goresilience.TimeoutSpec{Duration: "1s"}) {
+				t.Fatalf("unexpected plain timeout: %+v", got)
+			}
+			if got := cfg.Timeouts["structured"]; got != (goresilience.TimeoutSpec{Duration: "2s", Mode: goresilience.TimeoutModeCooperative, WarnAt: "0.8"}) {
+				t.Fatalf("unexpected structured timeout: %+v", got)
+			}
+
+			if _, err := goresilience.FromConfig(cfg); err != nil {
+				t.Fatalf("failed to create provider: %v", err)
+			}
+		})
+	}
+}
+
+func TestBareIntegerDurationRejected(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  goresilience.Config
+	}{
+		{
+			name: "timeout",
+			cfg: goresilience.Config{Timeouts: map[string]goresilience.TimeoutSpec{
+				"test_timeout": {Duration: "500"},
+			}},
+		},
+		{
+			name: "retry duration",
+			cfg: goresilience.Config{Retries: map[string]goresilience.Retry{
+				"test_retry": {Duration: "500", MaxRetries: 1},
+			}},
+		},
+		{
+			name: "breaker interval",
+			cfg: goresilience.Config{CircuitBreakers: map[string]goresilience.CircuitBreaker{
+				"test_cb": {MaxRequests: 1, Interval: "500", Timeout: "1s", Failures: 1},
+			}},
+		},
+		{
+			name: "breaker timeout",
+			cfg: goresilience.Config{CircuitBreakers: map[string]goresilience.CircuitBreaker{
+				"test_cb": {MaxRequests: 1, Interval: "1s", Timeout: "500", Failures: 1},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := goresilience.FromConfig(tt.cfg)
+			if err == nil || !strings.Contains(err.Error(), "has no unit") {
+				t.Fatalf("expected a missing unit error, got: %v", err)
+			}
+		})
+	}
+}
+
+func TestBareIntegerDurationUnit(t *testing.T) {
+	tests := []struct {
+		name    string
+		opt     goresilience.Option
+		atLeast time.Duration
+		atMost  time.Duration
+	}{
+		{
+			name:    "milliseconds",
+			opt:     goresilience.WithDefaultDurationUnit(time.Millisecond),
+			atLeast: 50 * time.Millisecond,
+			atMost:  time.Second,
+		},
+		{
+			name:   "legacy microseconds",
+			opt:    goresilience.WithLegacyMicrosecondDurations(),
+			atMost: 40 * time.Millisecond,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, err := goresilience.FromConfigWithOptions(goresilience.Config{
+				Timeouts: map[string]goresilience.TimeoutSpec{
+					"test_timeout": {Duration: "50", Mode: goresilience.TimeoutModeCooperative},
+				},
+				Targets: map[string]goresilience.PolicyNames{
+					"test_target": {Timeout: "test_timeout"},
+				},
+			}, tt.opt)
+			if err != nil {
+				t.Fatalf("failed to create provider: %v", err)
+			}
+
+			start := time.Now()
+			exec := goresilience.NewExecWithPolicy(context.Background(), provider.Policy("test_target"))
+			_, _ = exec(func(ctx context.Context) (any, error) {
+				<-ctx.Done()
+				return nil, ctx.Err()
+			})
+
+			if elapsed := time.Since(start); elapsed < tt.atLeast || elapsed > tt.atMost {
+				t.Fatalf("expected the timeout to fire within [%v, %v], took %v", tt.atLeast, tt.atMost, elapsed)
+			}
+		})
+	}
+}
+
+func TestConfigErrorsAggregated(t *testing.T) {
+	_, err := goresilience.FromConfig(goresilience.Config{
+		Timeouts: map[string]goresilience.TimeoutSpec{
+			"fast": {Duration: "soon"},
+		},
+		Retries: map[string]goresilience.Retry{
+			"slow": {Duration: "1 minute", MaxRetries: 3},
+		},
+		CircuitBreakers: map[string]goresilience.CircuitBreaker{
+			"db": {MaxRequests: -1, Interval: "never", Timeout: "forever", Failures: 1},
+		},
+		Bulkheads: map[string]goresilience.Bulkhead{
+			"pool": {MaxConcurrent: 0},
+		},
+		Targets: map[string]goresilience.PolicyNames{
+			"orders": {Retry: "slow", CircuitBreaker: "db", Cache: "missing"},
+		},
+		Chains: map[string][]string{
+			"checkout": {"orders", "payments"},
+		},
+	})
+	if err == nil {
+		t.Fatal("expected config errors")
+	}
+
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		t.Fatalf("expected a joined error, got: %v", err)
+	}
+
+	var paths []string
+	for _, err := range joined.Unwrap() {
+		var cfgErr *goresilience.ConfigError
+		if !errors.As(err, &cfgErr) {
+			t.Fatalf("expected every error to be a ConfigError, got: %v", err)
+		}
+		paths = append(paths, cfgErr.Path)
+	}
+
+	want := []string{
+		`timeouts["fast"].duration`,
+		`retries["slow"].duration`,
+		`targets["orders"].cache`,
+		`circuitBreakers["db"].maxRequests`,
+		`circuitBreakers["db"].timeout`,
+		`bulkheads["pool"].maxConcurrent`,
+		`chains["checkout"][1]`,
+	}
+	if !slices.Equal(paths, want) {
+		t.Fatalf("expected paths %q, got %q", want, paths)
+	}
+}
@@ -0,0 +1,102 @@
+package goresilience
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ConfigError is a problem with one value of a Config. Path locates the
+// value the way a config file spells it, such as retries["slow"].duration.
+// Config errors are joined, so errors.As finds the first of them; range
+// over the Unwrap() []error of the returned error to see them all.
+type ConfigError struct {
+	Path   string
+	Reason string
+}
+
+func (e *ConfigError) Error() string {
+	return e.Path + ": " + e.Reason
+}
+
+// configErrors collects the ConfigErrors found while reading a config, so
+// that all of them are reported at once.
+type configErrors struct {
+	errs []error
+}
+
+func (c *configErrors) add(path, format string, args ...any) {
+	c.errs = append(c.errs, &ConfigError{Path: path, Reason: fmt.Sprintf(format, args...)})
+}
+
+// duration parses val, recording an error for path if it is invalid.
+func (c *configErrors) duration(path, val string, unit time.Duration) time.Duration {
+	d, err := parseDuration(val, unit)
+	if err != nil {
+		c.add(path, "%v", err)
+	}
+
+	return d
+}
+
+// requiredDuration is like duration, but also rejects a missing or
+// non-positive val.
+func (c *configErrors) requiredDuration(path, val string, unit time.Duration) time.Duration {
+	if val == "" {
+		c.add(path, "is required")
+		return 0
+	}
+
+	d, err := parseDuration(val, unit)
+	switch {
+	case err != nil:
+		c.add(path, "%v", err)
+	case d <= 0:
+		c.add(path, "must be positive, got %s", val)
+	}
+
+	return d
+}
+
+// nest records err, whose ConfigErrors have paths relative to path.
+func (c *configErrors) nest(path string, err error) {
+	if err == nil {
+		return
+	}
+
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, err := range joined.Unwrap() {
+			c.nest(path, err)
+		}
+		return
+	}
+
+	var cfgErr *ConfigError
+	if errors.As(err, &cfgErr) {
+		c.errs = append(c.errs, &ConfigError{Path: joinPath(path, cfgErr.Path), Reason: cfgErr.Reason})
+		return
+	}
+
+	c.errs = append(c.errs, &ConfigError{Path: path, Reason: err.Error()})
+}
+
+func (c *configErrors) err() error {
+	return errors.Join(c.errs...)
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+
+	if name == "" || strings.HasPrefix(name, "[") {
+		return path + name
+	}
+
+	return path + "." + name
+}
+
+func entryPath(section, name string) string {
+	return fmt.Sprintf("%s[%q]", section, name)
+}
@@ -0,0 +1,69 @@
+package goresilience
+
+import "context"
+
+type breakerKeyCtxKey struct{}
+
+func WithBreakerKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, breakerKeyCtxKey{}, key)
+}
+
+func BreakerKeyFromContext(ctx context.Context) (string, bool) {
+	key, ok := ctx.Value(breakerKeyCtxKey{}).(string)
+	return key, ok && key != ""
+}
+
+type cacheKeyCtxKey struct{}
+
+// WithCacheKey sets the key under which a target's cache stores the result
+// of calls made with ctx. Calls without a key bypass the cache.
+func WithCacheKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, cacheKeyCtxKey{}, key)
+}
+
+func CacheKeyFromContext(ctx context.Context) (string, bool) {
+	key, ok := ctx.Value(cacheKeyCtxKey{}).(string)
+	return key, ok && key != ""
+}
+
+type callKeyCtxKey struct{}
+
+// WithCallKey identifies calls that are interchangeable, so that a target
+// with Singleflight runs concurrent calls sharing the key only once. The
+// cache key is used when no call key is set.
+func WithCallKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, callKeyCtxKey{}, key)
+}
+
+func CallKeyFromContext(ctx context.Context) (string, bool) {
+	if key, ok := ctx.Value(callKeyCtxKey{}).(string); ok && key != "" {
+		return key, true
+	}
+
+	return CacheKeyFromContext(ctx)
+}
+
+type priorityCtxKey struct{}
+
+// WithPriority sets the priority that shed policies use for calls made
+// with ctx.
+func WithPriority(ctx context.Context, p Priority) context.Context {
+	return context.WithValue(ctx, priorityCtxKey{}, p)
+}
+
+func PriorityFromContext(ctx context.Context) Priority {
+	p, _ := ctx.Value(priorityCtxKey{}).(Priority)
+	return p
+}
+
+// TargetFromContext returns the target whose policy is running the
+// operation that ctx was passed to. It reports false outside an operation
+// and for policies that were not resolved by a Provider.
+func TargetFromContext(ctx context.Context) (string, bool) {
+	c := callFrom(ctx)
+	if c == nil {
+		return "", false
+	}
+
+	return c.target, c.target != ""
+}
@@ -0,0 +1,100 @@
+package goresilience_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	goresilience "github.com/rickKoch/go-resilience"
+)
+
+func TestTargetFromContextThroughRetries(t *testing.T) {
+	provider, err := goresilience.FromConfig(reloadConfig(2, reloadBreaker))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var targets []string
+	exec := goresilience.NewExecutor(context.Background(), provider.Policy("test_target"))
+	_, _ = exec(func(ctx context.Context) (any, error) {
+		target, _ := goresilience.TargetFromContext(ctx)
+		targets = append(targets, target)
+		return nil, errors.New("failed")
+	})
+
+	if want := []string{"test_target", "test_target", "test_target"}; !slices.Equal(targets, want) {
+		t.Fatalf("expected every attempt to see its target, got: %q", targets)
+	}
+}
+
+func TestTargetFromContextThroughTimeout(t *testing.T) {
+	for _, mode := range []string{goresilience.TimeoutModeDetached, goresilience.TimeoutModeCooperative} {
+		t.Run(mode, func(t *testing.T) {
+			provider := timeoutProvider(t, goresilience.TimeoutSpec{Duration: "1s", Mode: mode})
+
+			res, err := provider.Policy("test_target").Execute(context.Background(), func(ctx context.Context) (any, error) {
+				target, ok := goresilience.TargetFromContext(ctx)
+				if !ok {
+					return nil, errors.New("no target in context")
+				}
+				return target, nil
+			})
+
+			if err != nil || res != "test_target" {
+				t.Fatalf("expected test_target, got: %v, %v", res, err)
+			}
+		})
+	}
+}
+
+func TestTargetFromContextNestedPolicies(t *testing.T) {
+	provider, err := goresilience.FromConfig(goresilience.Config{
+		Targets: map[string]goresilience.PolicyNames{
+			"outer": {},
+			"inner": {},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var seen []string
+	record := func(ctx context.Context) {
+		target, _ := goresilience.TargetFromContext(ctx)
+		seen = append(seen, target)
+	}
+
+	_, err = provider.Policy("outer").Execute(context.Background(), func(ctx context.Context) (any, error) {
+		record(ctx)
+		_, err := provider.Policy("inner").Execute(ctx, func(ctx context.Context) (any, error) {
+			record(ctx)
+			return nil, nil
+		})
+		record(ctx)
+		return nil, err
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if want := []string{"outer", "inner", "outer"}; !slices.Equal(seen, want) {
+		t.Fatalf("expected %q, got: %q", want, seen)
+	}
+}
+
+func TestTargetFromContextWithoutProvider(t *testing.T) {
+	if target, ok := goresilience.TargetFromContext(context.Background()); ok || target != "" {
+		t.Fatalf("expected no target outside an operation, got: %q", target)
+	}
+
+	_, err := new(goresilience.Policy).Execute(context.Background(), func(ctx context.Context) (any, error) {
+		if target, ok := goresilience.TargetFromContext(ctx); ok || target != "" {
+			t.Errorf("expected no target for a policy without a provider, got: %q", target)
+		}
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
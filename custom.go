@@ -0,0 +1,107 @@
+package goresilience
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+)
+
+// PolicyFactory builds a custom policy from its entry in Config.Custom,
+// which holds the entry as JSON, kind included.
+type PolicyFactory func(raw json.RawMessage) (Middleware, error)
+
+var (
+	policyKindsMu sync.RWMutex
+	policyKinds   = make(map[string]PolicyFactory)
+)
+
+// RegisterPolicyKind makes a kind of policy available to Config.Custom
+// entries, which name it in their "kind" field:
+//
+//	custom:
+//	  flaky-payments:
+//	    kind: flaky
+//	    failEvery: 3
+//	targets:
+//	  payments:
+//	    custom: [flaky-payments]
+//
+// It is meant to be called from an init function, and panics if name is
+// empty, factory is nil or the kind is already registered.
+func RegisterPolicyKind(name string, factory PolicyFactory) {
+	if name == "" {
+		panic("goresilience: RegisterPolicyKind: empty kind")
+	}
+	if factory == nil {
+		panic(fmt.Sprintf("goresilience: RegisterPolicyKind: nil factory for kind %q", name))
+	}
+
+	policyKindsMu.Lock()
+	defer policyKindsMu.Unlock()
+
+	if _, ok := policyKinds[name]; ok {
+		panic(fmt.Sprintf("goresilience: RegisterPolicyKind: kind %q is already registered", name))
+	}
+	policyKinds[name] = factory
+}
+
+func policyKind(name string) (PolicyFactory, bool) {
+	policyKindsMu.RLock()
+	defer policyKindsMu.RUnlock()
+
+	factory, ok := policyKinds[name]
+	return factory, ok
+}
+
+func registeredPolicyKinds() []string {
+	policyKindsMu.RLock()
+	defer policyKindsMu.RUnlock()
+
+	return slices.Sorted(maps.Keys(policyKinds))
+}
+
+// newCustomPolicy builds a Config.Custom entry with the factory of its kind.
+func newCustomPolicy(raw json.RawMessage) (Middleware, error) {
+	var spec struct {
+		Kind string `json:"kind"`
+	}
+	if err := json.Unmarshal(raw, &spec); err != nil {
+		return nil, fmt.Errorf("invalid custom policy: %w", err)
+	}
+
+	if spec.Kind == "" {
+		return nil, errors.New("kind is required")
+	}
+
+	factory, ok := policyKind(spec.Kind)
+	if !ok {
+		return nil, fmt.Errorf("unknown kind %q, must be one of %q", spec.Kind, registeredPolicyKinds())
+	}
+
+	mw, err := factory(raw)
+	if err != nil {
+		return nil, err
+	}
+	if mw == nil {
+		return nil, fmt.Errorf("kind %q built no middleware", spec.Kind)
+	}
+
+	return mw, nil
+}
+
+// customMiddleware returns the custom policies names refers to, in order.
+// Names missing from state, which only happens when dangling references are
+// allowed, are skipped.
+func (s *providerState) customMiddleware(names []string) []Middleware {
+	var mw []Middleware
+	for _, name := range names {
+		if m, ok := s.custom[name]; ok {
+			mw = append(mw, m)
+		}
+	}
+
+	return mw
+}
@@ -0,0 +1,160 @@
+package goresilience_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	goresilience "github.com/rickKoch/go-resilience"
+)
+
+var errFlaky = errors.New("flaky")
+
+// flaky fails every failEvery-th attempt, to exercise custom policies.
+func init() {
+	goresilience.RegisterPolicyKind("flaky", func(raw json.RawMessage) (goresilience.Middleware, error) {
+		var spec struct {
+			FailEvery int64 `json:"failEvery"`
+		}
+		if err := json.Unmarshal(raw, &spec); err != nil {
+			return nil, err
+		}
+		if spec.FailEvery <= 0 {
+			return nil, errors.New("failEvery must be positive")
+		}
+
+		var calls atomic.Int64
+		return func(next goresilience.Operation) goresilience.Operation {
+			return func(ctx context.Context) (any, error) {
+				if calls.Add(1)%spec.FailEvery == 0 {
+					return nil, errFlaky
+				}
+				return next(ctx)
+			}
+		}, nil
+	})
+}
+
+const flakyYAML = `
+retries:
+  std:
+    duration: 1ms
+    maxRetries: 1
+custom:
+  every-other:
+    kind: flaky
+    failEvery: 2
+targets:
+  payments:
+    retry: std
+    custom: [every-other]
+`
+
+func TestCustomPolicyFromYAML(t *testing.T) {
+	cfg, err := goresilience.ParseConfigYAML([]byte(flakyYAML))
+	if err != nil {
+		t.Fatalf("failed to parse config: %v", err)
+	}
+
+	provider, err := goresilience.FromConfig(cfg)
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	exec := goresilience.NewContextExecutor(provider.Policy("payments"))
+
+	var calls int
+	for i := 0; i < 3; i++ {
+		res, err := exec(context.Background(), func(ctx context.Context) (any, error) {
+			calls++
+			return "ok", nil
+		})
+		if err != nil || res != "ok" {
+			t.Fatalf("call %d: expected the retry to recover from the flaky policy, got %v, %v", i, res, err)
+		}
+	}
+
+	// Attempts 2 and 4 fail in the flaky policy before reaching the
+	// operation, and are retried.
+	if calls != 3 {
+		t.Fatalf("expected the operation to run 3 times, got %d", calls)
+	}
+
+	if d, _ := provider.Describe("payments"); !strings.Contains(d.String(), "custom: every-other") {
+		t.Fatalf("expected the description to list the custom policy, got:\n%s", d)
+	}
+}
+
+func TestCustomPolicyKeptOnUpdate(t *testing.T) {
+	cfg, err := goresilience.ParseConfigYAML([]byte(flakyYAML))
+	if err != nil {
+		t.Fatalf("failed to parse config: %v", err)
+	}
+
+	provider, err := goresilience.FromConfig(cfg)
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	call := func() error {
+		_, err := goresilience.NewContextExecutor(provider.Policy("payments"))(context.Background(), succeed)
+		return err
+	}
+
+	if err := call(); err != nil {
+		t.Fatalf("expected the first call to pass, got %v", err)
+	}
+
+	cfg.Retries["std"] = goresilience.Retry{Duration: "1ms", MaxRetries: 0}
+	if err := provider.UpdateConfig(cfg); err != nil {
+		t.Fatalf("failed to update config: %v", err)
+	}
+
+	// The unchanged policy keeps counting across the update, so its second
+	// attempt fails and is no longer retried.
+	if err := call(); !errors.Is(err, errFlaky) {
+		t.Fatalf("expected the second call to fail in the flaky policy, got %v", err)
+	}
+}
+
+func TestCustomPolicyErrors(t *testing.T) {
+	_, err := goresilience.FromConfig(goresilience.Config{
+		Custom: map[string]json.RawMessage{
+			"a": json.RawMessage(`{"kind": "flakey"}`),
+			"b": json.RawMessage(`{"failEvery": 2}`),
+			"c": json.RawMessage(`{"kind": "flaky", "failEvery": 0}`),
+		},
+		Targets: map[string]goresilience.PolicyNames{
+			"test_target": {Custom: []string{"a", "d"}},
+		},
+	})
+	if err == nil {
+		t.Fatal("expected custom policy errors")
+	}
+
+	for _, want := range []string{
+		`custom["a"]: unknown kind "flakey", must be one of`,
+		`custom["b"]: kind is required`,
+		`custom["c"]: failEvery must be positive`,
+		`targets["test_target"].custom[1]: "d" is not defined`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("expected %q in the error, got: %v", want, err)
+		}
+	}
+}
+
+func TestRegisterPolicyKindTwice(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil || !strings.Contains(r.(string), `kind "flaky" is already registered`) {
+			t.Fatalf("expected a duplicate registration to panic, got %v", r)
+		}
+	}()
+
+	goresilience.RegisterPolicyKind("flaky", func(json.RawMessage) (goresilience.Middleware, error) {
+		return nil, nil
+	})
+}
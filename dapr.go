@@ -0,0 +1,230 @@
+package goresilience
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// daprSpec is the subset of a Dapr Resiliency resource that FromDaprSpec
+// reads. Fields that Dapr defines but Config cannot express are decoded
+// anyway, so that they are reported instead of ignored.
+type daprSpec struct {
+	APIVersion string    `yaml:"apiVersion"`
+	Kind       string    `yaml:"kind"`
+	Metadata   yaml.Node `yaml:"metadata"`
+	Scopes     []string  `yaml:"scopes"`
+	Spec       struct {
+		Policies struct {
+			Timeouts        map[string]string             `yaml:"timeouts"`
+			Retries         map[string]daprRetry          `yaml:"retries"`
+			CircuitBreakers map[string]daprCircuitBreaker `yaml:"circuitBreakers"`
+		} `yaml:"policies"`
+		Targets struct {
+			Apps       map[string]daprTarget    `yaml:"apps"`
+			Actors     map[string]yaml.Node     `yaml:"actors"`
+			Components map[string]daprComponent `yaml:"components"`
+		} `yaml:"targets"`
+	} `yaml:"spec"`
+}
+
+type daprRetry struct {
+	Policy      string    `yaml:"policy"`
+	Duration    string    `yaml:"duration"`
+	MaxInterval string    `yaml:"maxInterval"`
+	MaxRetries  *int      `yaml:"maxRetries"`
+	Matching    yaml.Node `yaml:"matching"`
+}
+
+type daprCircuitBreaker struct {
+	MaxRequests int    `yaml:"maxRequests"`
+	Interval    string `yaml:"interval"`
+	Timeout     string `yaml:"timeout"`
+	Trip        string `yaml:"trip"`
+}
+
+type daprTarget struct {
+	Timeout                 string `yaml:"timeout"`
+	Retry                   string `yaml:"retry"`
+	CircuitBreaker          string `yaml:"circuitBreaker"`
+	CircuitBreakerCacheSize int    `yaml:"circuitBreakerCacheSize"`
+}
+
+type daprComponent struct {
+	Outbound daprTarget `yaml:"outbound"`
+	Inbound  daprTarget `yaml:"inbound"`
+}
+
+const (
+	daprDefaultRetryDuration = "5s"
+	daprDefaultTrip          = "consecutiveFailures > 5"
+)
+
+var daprTrip = regexp.MustCompile(`^\s*consecutiveFailures\s*(>=|>)\s*(\d+)\s*$`)
+
+// FromDaprSpec converts a Dapr Resiliency resource into a Config. Apps
+// become targets named "apps/<name>" and components "components/<name>/
+// outbound" and "components/<name>/inbound". Only constant retries and
+// trips on consecutive failures can be expressed; anything else the spec
+// sets, including actor targets, is reported as a ConfigError rather than
+// dropped. Metadata and scopes only decide where Dapr loads the spec and
+// are ignored.
+func FromDaprSpec(data []byte) (Config, error) {
+	var spec daprSpec
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&spec); err != nil && !errors.Is(err, io.EOF) {
+		return Config{}, fmt.Errorf("invalid dapr spec: %w", err)
+	}
+
+	if spec.Kind != "Resiliency" {
+		return Config{}, fmt.Errorf("invalid dapr spec: kind must be %q, got %q", "Resiliency", spec.Kind)
+	}
+
+	var errs configErrors
+	policies := spec.Spec.Policies
+	var cfg Config
+
+	if len(policies.Timeouts) > 0 {
+		cfg.Timeouts = make(map[string]TimeoutSpec, len(policies.Timeouts))
+		for name, d := range policies.Timeouts {
+			cfg.Timeouts[name] = TimeoutSpec{Duration: d}
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(policies.Retries)) {
+		if cfg.Retries == nil {
+			cfg.Retries = make(map[string]Retry, len(policies.Retries))
+		}
+
+		if retry, ok := daprRetryConfig(entryPath("spec.policies.retries", name), policies.Retries[name], &errs); ok {
+			cfg.Retries[name] = retry
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(policies.CircuitBreakers)) {
+		if cfg.CircuitBreakers == nil {
+			cfg.CircuitBreakers = make(map[string]CircuitBreaker, len(policies.CircuitBreakers))
+		}
+
+		if cb, ok := daprBreakerConfig(entryPath("spec.policies.circuitBreakers", name), policies.CircuitBreakers[name], &errs); ok {
+			cfg.CircuitBreakers[name] = cb
+		}
+	}
+
+	targets := spec.Spec.Targets
+	addTarget := func(path, name string, t daprTarget) {
+		if t.CircuitBreakerCacheSize != 0 {
+			errs.add(joinPath(path, "circuitBreakerCacheSize"), "is not supported")
+		}
+
+		if t == (daprTarget{}) {
+			return
+		}
+
+		if cfg.Targets == nil {
+			cfg.Targets = make(map[string]PolicyNames)
+		}
+		cfg.Targets[name] = PolicyNames{Timeout: t.Timeout, Retry: t.Retry, CircuitBreaker: t.CircuitBreaker}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(targets.Apps)) {
+		addTarget(entryPath("spec.targets.apps", name), "apps/"+name, targets.Apps[name])
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(targets.Actors)) {
+		errs.add(entryPath("spec.targets.actors", name), "actor targets are not supported")
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(targets.Components)) {
+		path := entryPath("spec.targets.components", name)
+		addTarget(path+".outbound", "components/"+name+"/outbound", targets.Components[name].Outbound)
+		addTarget(path+".inbound", "components/"+name+"/inbound", targets.Components[name].Inbound)
+	}
+
+	if err := errs.err(); err != nil {
+		return Config{}, err
+	}
+
+	return cfg, nil
+}
+
+func daprRetryConfig(path string, r daprRetry, errs *configErrors) (Retry, bool) {
+	ok := true
+
+	if r.Policy != "" && r.Policy != "constant" {
+		errs.add(joinPath(path, "policy"), "only constant retries are supported, got %q", r.Policy)
+		ok = false
+	}
+
+	if r.MaxInterval != "" {
+		errs.add(joinPath(path, "maxInterval"), "is not supported")
+		ok = false
+	}
+
+	if !r.Matching.IsZero() {
+		errs.add(joinPath(path, "matching"), "is not supported")
+		ok = false
+	}
+
+	// Dapr retries forever every 5s unless told otherwise.
+	retry := Retry{Duration: r.Duration, MaxRetries: -1}
+	if retry.Duration == "" {
+		retry.Duration = daprDefaultRetryDuration
+	}
+	if r.MaxRetries != nil {
+		retry.MaxRetries = *r.MaxRetries
+	}
+
+	return retry, ok
+}
+
+func daprBreakerConfig(path string, c daprCircuitBreaker, errs *configErrors) (CircuitBreaker, bool) {
+	trip := c.Trip
+	if trip == "" {
+		trip = daprDefaultTrip
+	}
+
+	m := daprTrip.FindStringSubmatch(trip)
+	if m == nil {
+		errs.add(joinPath(path, "trip"), "only %q or %q expressions are supported, got %q", "consecutiveFailures > N", "consecutiveFailures >= N", trip)
+		return CircuitBreaker{}, false
+	}
+
+	failures, err := strconv.Atoi(m[2])
+	if err != nil {
+		errs.add(joinPath(path, "trip"), "%v", err)
+		return CircuitBreaker{}, false
+	}
+	if m[1] == ">" {
+		failures++
+	}
+
+	// Dapr never clears the counts of a closed breaker unless given an
+	// interval.
+	interval := c.Interval
+	if interval == "" {
+		interval = IntervalNever
+	}
+
+	// Dapr lets a single request through while half-open by default.
+	maxRequests := c.MaxRequests
+	if maxRequests == 0 {
+		maxRequests = 1
+	}
+
+	return CircuitBreaker{
+		MaxRequests: maxRequests,
+		Interval:    interval,
+		Timeout:     c.Timeout,
+		Failures:    failures,
+	}, true
+}
@@ -0,0 +1,97 @@
+package goresilience_test
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"slices"
+	"testing"
+
+	goresilience "github.com/rickKoch/go-resilience"
+)
+
+func TestFromDaprSpec(t *testing.T) {
+	data, err := os.ReadFile("testdata/dapr.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := goresilience.FromDaprSpec(data)
+	if err != nil {
+		t.Fatalf("failed to convert dapr spec: %v", err)
+	}
+
+	want := goresilience.Config{
+		Timeouts: map[string]goresilience.TimeoutSpec{
+			"general":   {Duration: "5s"},
+			"important": {Duration: "60s"},
+		},
+		Retries: map[string]goresilience.Retry{
+			"pubsubRetry":  {Duration: "5s", MaxRetries: 10},
+			"retryForever": {Duration: "5s", MaxRetries: -1},
+		},
+		CircuitBreakers: map[string]goresilience.CircuitBreaker{
+			"simpleCB": {MaxRequests: 1, Interval: goresilience.IntervalNever, Timeout: "30s", Failures: 5},
+			"pubsubCB": {MaxRequests: 1, Interval: "8s", Timeout: "45s", Failures: 9},
+		},
+		Targets: map[string]goresilience.PolicyNames{
+			"apps/appB":                       {Timeout: "general", Retry: "pubsubRetry", CircuitBreaker: "simpleCB"},
+			"components/statestore1/outbound": {Timeout: "general", Retry: "retryForever", CircuitBreaker: "simpleCB"},
+			"components/pubsub1/inbound":      {Timeout: "important", Retry: "pubsubRetry", CircuitBreaker: "pubsubCB"},
+		},
+	}
+	if !reflect.DeepEqual(cfg, want) {
+		t.Fatalf("expected %+v, got %+v", want, cfg)
+	}
+
+	if _, err := goresilience.FromConfig(cfg); err != nil {
+		t.Fatalf("expected the converted config to be valid, got: %v", err)
+	}
+}
+
+func TestFromDaprSpecUnsupported(t *testing.T) {
+	data, err := os.ReadFile("testdata/dapr_unsupported.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = goresilience.FromDaprSpec(data)
+	if err == nil {
+		t.Fatal("expected unsupported field errors")
+	}
+
+	var paths []string
+	for _, err := range err.(interface{ Unwrap() []error }).Unwrap() {
+		var cfgErr *goresilience.ConfigError
+		if !errors.As(err, &cfgErr) {
+			t.Fatalf("expected a ConfigError, got: %v", err)
+		}
+		paths = append(paths, cfgErr.Path)
+	}
+
+	want := []string{
+		`spec.policies.retries["important"].policy`,
+		`spec.policies.retries["important"].maxInterval`,
+		`spec.policies.circuitBreakers["requestsCB"].trip`,
+		`spec.targets.apps["appB"].circuitBreakerCacheSize`,
+		`spec.targets.actors["myActorType"]`,
+	}
+	if !slices.Equal(paths, want) {
+		t.Fatalf("expected unsupported fields %q, got %q", want, paths)
+	}
+}
+
+func TestFromDaprSpecInvalid(t *testing.T) {
+	tests := map[string]string{
+		"wrong kind":    "kind: Configuration\n",
+		"unknown field": "kind: Resiliency\nspec:\n  polices: {}\n",
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := goresilience.FromDaprSpec([]byte(data)); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package goresilience
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrInsufficientDeadline = errors.New("insufficient deadline")
+
+// withMinDeadline refuses to start an attempt that the caller's deadline
+// would certainly cut short. It wraps the outermost stage of the order, so
+// it runs inside the breaker when that comes last; the breaker leaves a
+// skipped attempt out of its counts either way.
+func (p *Policy) withMinDeadline(oper Operation) Operation {
+	return func(ctx context.Context) (any, error) {
+		if deadline, ok := ctx.Deadline(); ok {
+			if remaining := time.Until(deadline); remaining < p.minDeadline {
+				return nil, p.rejected(ctx, "minDeadline", fmt.Errorf("%w: %s remaining for target %q, need at least %s", ErrInsufficientDeadline, remaining, p.target, p.minDeadline))
+			}
+		}
+
+		return oper(ctx)
+	}
+}
@@ -0,0 +1,166 @@
+package goresilience_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	goresilience "github.com/rickKoch/go-resilience"
+)
+
+func minDeadlineProvider(t *testing.T) *goresilience.Provider {
+	t.Helper()
+
+	provider, err := goresilience.FromConfig(goresilience.Config{
+		Retries: map[string]goresilience.Retry{
+			"test_retry": {Duration: "30ms", MaxRetries: 5},
+		},
+		CircuitBreakers: map[string]goresilience.CircuitBreaker{
+			"test_cb": {MaxRequests: 1, Interval: "10s", Timeout: "10s", Failures: 1},
+		},
+		Targets: map[string]goresilience.PolicyNames{
+			"test_target": {
+				Retry:          "test_retry",
+				CircuitBreaker: "test_cb",
+				MinDeadline:    "50ms",
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	return provider
+}
+
+func TestMinDeadlineSkipsAttempt(t *testing.T) {
+	provider := minDeadlineProvider(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	var calls atomic.Int32
+	exec := goresilience.NewExecWithPolicy(ctx, provider.Policy("test_target"))
+	_, err := exec(func(ctx context.Context) (any, error) {
+		calls.Add(1)
+		return successResult, nil
+	})
+
+	if !errors.Is(err, goresilience.ErrInsufficientDeadline) {
+		t.Fatalf("expected ErrInsufficientDeadline, got: %v", err)
+	}
+	if calls.Load() != 0 {
+		t.Fatalf("expected the operation not to run, ran %d times", calls.Load())
+	}
+	if counts, _ := provider.BreakerCounts("test_cb"); counts != (goresilience.Counts{}) {
+		t.Fatalf("expected the breaker not to see the skipped attempt, got %+v", counts)
+	}
+}
+
+func TestMinDeadlineInsideBreaker(t *testing.T) {
+	provider, err := goresilience.FromConfig(goresilience.Config{
+		CircuitBreakers: map[string]goresilience.CircuitBreaker{
+			"test_cb": {MaxRequests: 1, Interval: "10s", Timeout: "10s", Failures: 1},
+		},
+		Targets: map[string]goresilience.PolicyNames{
+			"test_target": {
+				CircuitBreaker: "test_cb",
+				MinDeadline:    "50ms",
+				Order:          []string{goresilience.StageTimeout, goresilience.StageRetry, goresilience.StageCircuitBreaker},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	exec := goresilience.NewExecWithPolicy(ctx, provider.Policy("test_target"))
+	for range 3 {
+		if _, err := exec(succeed); !errors.Is(err, goresilience.ErrInsufficientDeadline) {
+			t.Fatalf("expected ErrInsufficientDeadline, got: %v", err)
+		}
+	}
+
+	if counts, _ := provider.BreakerCounts("test_cb"); counts != (goresilience.Counts{}) {
+		t.Fatalf("expected the breaker not to see the skipped attempts, got %+v", counts)
+	}
+	if state, _ := provider.BreakerState("test_cb"); state != goresilience.StateClosed {
+		t.Fatalf("expected the breaker to stay closed, got %s", state)
+	}
+}
+
+func TestMinDeadlineStopsRetries(t *testing.T) {
+	provider, err := goresilience.FromConfig(goresilience.Config{
+		Retries: map[string]goresilience.Retry{
+			"test_retry": {Duration: "30ms", MaxRetries: 10},
+		},
+		Targets: map[string]goresilience.PolicyNames{
+			"test_target": {
+				Retry:       "test_retry",
+				MinDeadline: "50ms",
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 150*time.Millisecond)
+	defer cancel()
+
+	var calls atomic.Int32
+	exec := goresilience.NewExecWithPolicy(ctx, provider.Policy("test_target"))
+	_, err = exec(func(ctx context.Context) (any, error) {
+		calls.Add(1)
+		return nil, testError
+	})
+
+	if !errors.Is(err, goresilience.ErrInsufficientDeadline) {
+		t.Fatalf("expected ErrInsufficientDeadline, got: %v", err)
+	}
+	if n := calls.Load(); n < 1 || n > 4 {
+		t.Fatalf("expected attempts to stop once the budget ran low, got %d", n)
+	}
+}
+
+func TestMinDeadlinePassThrough(t *testing.T) {
+	provider := minDeadlineProvider(t)
+
+	tests := []struct {
+		name string
+		ctx  func() (context.Context, context.CancelFunc)
+	}{
+		{
+			name: "no deadline",
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithCancel(context.Background())
+			},
+		},
+		{
+			name: "enough time",
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithTimeout(context.Background(), time.Second)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := tt.ctx()
+			defer cancel()
+
+			exec := goresilience.NewExecWithPolicy(ctx, provider.Policy("test_target"))
+			result, err := exec(func(ctx context.Context) (any, error) {
+				return successResult, nil
+			})
+			if err != nil || result != successResult {
+				t.Fatalf("expected the operation to run, got %v, %v", result, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package goresilience
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// PolicyDescription tells what a target resolves to. Alias is the name
+// Describe was given when it is an alias of Target, and Group the group the
+// policies come from when the target has no entry of its own.
+type PolicyDescription struct {
+	Target         string                     `json:"target"`
+	Alias          string                     `json:"alias,omitempty"`
+	Group          string                     `json:"group,omitempty"`
+	Timeout        *TimeoutDescription        `json:"timeout,omitempty"`
+	OverallTimeout *TimeoutDescription        `json:"overallTimeout,omitempty"`
+	Retry          *RetryDescription          `json:"retry,omitempty"`
+	CircuitBreaker *CircuitBreakerDescription `json:"circuitBreaker,omitempty"`
+	Bulkhead       string                     `json:"bulkhead,omitempty"`
+	Cache          string                     `json:"cache,omitempty"`
+	AdaptiveLimit  string                     `json:"adaptiveLimit,omitempty"`
+	Shed           string                     `json:"shed,omitempty"`
+	Custom         []string                   `json:"custom,omitempty"`
+	Order          []string                   `json:"order,omitempty"`
+}
+
+// TimeoutDescription is a resolved timeout. Name is the timeout's name, or
+// the literal duration it was referenced by; Default is set when it is the
+// config's default timeout.
+type TimeoutDescription struct {
+	Name     string `json:"name"`
+	Duration string `json:"duration"`
+	Default  bool   `json:"default,omitempty"`
+}
+
+type RetryDescription struct {
+	Name        string `json:"name"`
+	Duration    string `json:"duration"`
+	MaxRetries  int    `json:"maxRetries"`
+	Backoff     string `json:"backoff,omitempty"`
+	MaxInterval string `json:"maxInterval,omitempty"`
+}
+
+type CircuitBreakerDescription struct {
+	Name     string         `json:"name"`
+	Settings CircuitBreaker `json:"settings"`
+}
+
+// String renders the description on one line per policy, in a fixed order.
+func (d PolicyDescription) String() string {
+	var b strings.Builder
+
+	b.WriteString("target " + d.Target)
+	if d.Alias != "" {
+		fmt.Fprintf(&b, " (alias %s)", d.Alias)
+	}
+	if d.Group != "" {
+		fmt.Fprintf(&b, " (group %s)", d.Group)
+	}
+
+	line := func(format string, args ...any) {
+		fmt.Fprintf(&b, "\n  "+format, args...)
+	}
+
+	timeout := func(field string, t *TimeoutDescription) {
+		if t == nil {
+			return
+		}
+		if t.Default {
+			line("%s: %s (%s, default)", field, t.Name, t.Duration)
+			return
+		}
+		line("%s: %s (%s)", field, t.Name, t.Duration)
+	}
+
+	timeout("timeout", d.Timeout)
+	timeout("overallTimeout", d.OverallTimeout)
+
+	if d.Retry != nil {
+		line("retry: %s (duration %s, maxRetries %d)", d.Retry.Name, d.Retry.Duration, d.Retry.MaxRetries)
+	}
+	if d.CircuitBreaker != nil {
+		line("circuitBreaker: %s %s", d.CircuitBreaker.Name, formatDiffValue(d.CircuitBreaker.Settings))
+	}
+
+	for _, ref := range []struct{ field, name string }{
+		{"bulkhead", d.Bulkhead},
+		{"cache", d.Cache},
+		{"adaptiveLimit", d.AdaptiveLimit},
+		{"shed", d.Shed},
+	} {
+		if ref.name != "" {
+			line("%s: %s", ref.field, ref.name)
+		}
+	}
+
+	if len(d.Custom) > 0 {
+		line("custom: %s", strings.Join(d.Custom, ", "))
+	}
+
+	if len(d.Order) > 0 {
+		line("order: %s", strings.Join(d.Order, ", "))
+	}
+
+	return b.String()
+}
+
+// Targets returns the names of the configured targets, sorted. Aliases and
+// group members are not included.
+func (p *Provider) Targets() []string {
+	return slices.Sorted(maps.Keys(p.current().targets))
+}
+
+// Describe reports the policies Policy resolves for target. It returns
+// false for a name that is neither a target, an alias nor a group member.
+func (p *Provider) Describe(name string) (PolicyDescription, bool) {
+	state := p.current()
+
+	d := PolicyDescription{Target: name}
+	if canonical, ok := state.aliases[name]; ok {
+		d.Target, d.Alias = canonical, name
+	}
+
+	t, ok := state.targets[d.Target]
+	if !ok {
+		g, ok := state.groupOf(d.Target)
+		if !ok {
+			return PolicyDescription{}, false
+		}
+		t, d.Group = g.target, g.name
+	}
+
+	ref, literal := t.timeout, t.literals.timeout
+	if ref == "" {
+		ref, literal = state.defaultTimeout, state.defaultLiteral
+	}
+	d.Timeout = state.describeTimeout(ref, literal)
+	if d.Timeout != nil && t.timeout == "" {
+		d.Timeout.Default = true
+	}
+	d.OverallTimeout = state.describeTimeout(t.overallTimeout, t.literals.overallTimeout)
+
+	if r, ok := state.retries[t.retry]; ok {
+		spec := r.spec()
+		d.Retry = &RetryDescription{Name: t.retry, Duration: spec.Duration, MaxRetries: spec.MaxRetries, Backoff: spec.Backoff, MaxInterval: spec.MaxInterval}
+	}
+
+	if cb, ok := state.circuitBreakers[t.circuitBreaker]; ok {
+		d.CircuitBreaker = &CircuitBreakerDescription{Name: t.circuitBreaker, Settings: cb.config()}
+	}
+
+	d.Bulkhead = t.bulkhead
+	d.Cache = t.cache
+	d.AdaptiveLimit = t.adaptiveLimit
+	d.Shed = t.shed
+	d.Custom = slices.Clone(t.custom)
+	d.Order = slices.Clone(t.order)
+
+	return d, true
+}
+
+func (s *providerState) describeTimeout(ref string, literal *timeout) *TimeoutDescription {
+	t, ok := s.timeout(ref, literal)
+	if !ok {
+		return nil
+	}
+
+	return &TimeoutDescription{Name: ref, Duration: formatDuration(t.duration)}
+}
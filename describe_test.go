@@ -0,0 +1,109 @@
+package goresilience_test
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+
+	goresilience "github.com/rickKoch/go-resilience"
+)
+
+func describeProvider(t *testing.T) *goresilience.Provider {
+	t.Helper()
+
+	provider, err := goresilience.FromConfig(goresilience.Config{
+		Timeouts: map[string]goresilience.TimeoutSpec{
+			"fast": {Duration: "1s"},
+		},
+		Retries: map[string]goresilience.Retry{
+			"std": {Duration: "100ms", MaxRetries: 3},
+		},
+		CircuitBreakers: map[string]goresilience.CircuitBreaker{
+			"db": {MaxRequests: 1, Interval: "1m", Timeout: "30s", Failures: 5},
+		},
+		Bulkheads: map[string]goresilience.Bulkhead{
+			"pool": {MaxConcurrent: 10},
+		},
+		Targets: map[string]goresilience.PolicyNames{
+			"users": {
+				Timeout:        "fast",
+				OverallTimeout: "5s",
+				Retry:          "std",
+				CircuitBreaker: "db",
+				Bulkhead:       "pool",
+			},
+			"orders": {Retry: "std"},
+		},
+		Groups: map[string]goresilience.TargetGroup{
+			"databases": {PolicyNames: goresilience.PolicyNames{Retry: "std"}, Members: []string{"db.*"}},
+		},
+		Aliases: map[string][]string{
+			"users": {"pkg.Users/Get"},
+		},
+		Defaults: goresilience.Defaults{Timeout: "2s"},
+	})
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	return provider
+}
+
+func TestProviderTargets(t *testing.T) {
+	provider := describeProvider(t)
+
+	if got, want := provider.Targets(), []string{"orders", "users"}; !slices.Equal(got, want) {
+		t.Fatalf("expected targets %v, got %v", want, got)
+	}
+}
+
+func TestDescribeFullTarget(t *testing.T) {
+	provider := describeProvider(t)
+
+	d, ok := provider.Describe("pkg.Users/Get")
+	if !ok {
+		t.Fatal("expected the alias to be described")
+	}
+
+	want := `target users (alias pkg.Users/Get)
+  timeout: fast (1s)
+  overallTimeout: 5s (5s)
+  retry: std (duration 100ms, maxRetries 3)
+  circuitBreaker: db {"maxRequests":1,"interval":"1m0s","timeout":"30s","failures":5}
+  bulkhead: pool`
+	if got := d.String(); got != want {
+		t.Fatalf("unexpected description\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestDescribePartialTarget(t *testing.T) {
+	provider := describeProvider(t)
+
+	d, ok := provider.Describe("orders")
+	if !ok {
+		t.Fatal("expected the target to be described")
+	}
+
+	data, err := json.Marshal(d)
+	if err != nil {
+		t.Fatalf("failed to marshal description: %v", err)
+	}
+
+	want := `{"target":"orders","timeout":{"name":"2s","duration":"2s","default":true},"retry":{"name":"std","duration":"100ms","maxRetries":3}}`
+	if string(data) != want {
+		t.Fatalf("unexpected description\ngot:  %s\nwant: %s", data, want)
+	}
+}
+
+func TestDescribeGroupMember(t *testing.T) {
+	provider := describeProvider(t)
+
+	d, ok := provider.Describe("db.audit")
+	if !ok || d.Group != "databases" || d.Retry == nil || d.Retry.Name != "std" {
+		t.Fatalf("expected the group's policies, got %+v", d)
+	}
+
+	if _, ok := provider.Describe("cache"); ok {
+		t.Fatal("expected an unknown target not to be described")
+	}
+}
@@ -0,0 +1,229 @@
+package goresilience
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+)
+
+type ChangeKind int
+
+const (
+	ChangeAdded ChangeKind = iota + 1
+	ChangeRemoved
+	ChangeModified
+)
+
+func (k ChangeKind) String() string {
+	switch k {
+	case ChangeAdded:
+		return "added"
+	case ChangeRemoved:
+		return "removed"
+	case ChangeModified:
+		return "modified"
+	default:
+		return fmt.Sprintf("unknown change: %d", int(k))
+	}
+}
+
+// ConfigChange is one entry of a config section, such as retries["slow"],
+// that differs between two configs. Name is empty for the version and
+// defaults sections. Old is nil for an added entry and New for a removed
+// one; for a modified struct entry, Fields names the fields that differ.
+type ConfigChange struct {
+	Kind    ChangeKind
+	Section string
+	Name    string
+	Old     any
+	New     any
+	Fields  []string
+}
+
+func (c ConfigChange) String() string {
+	path := c.Section
+	if c.Name != "" {
+		path = entryPath(c.Section, c.Name)
+	}
+
+	switch c.Kind {
+	case ChangeAdded:
+		return fmt.Sprintf("+ %s: %s", path, formatDiffValue(c.New))
+	case ChangeRemoved:
+		return fmt.Sprintf("- %s: %s", path, formatDiffValue(c.Old))
+	}
+
+	if len(c.Fields) == 0 {
+		return fmt.Sprintf("~ %s: %s -> %s", path, formatDiffValue(c.Old), formatDiffValue(c.New))
+	}
+
+	oldVal, newVal := reflect.ValueOf(c.Old), reflect.ValueOf(c.New)
+	fields := make([]string, len(c.Fields))
+	for i, name := range c.Fields {
+		fields[i] = fmt.Sprintf("%s %s -> %s", name,
+			formatDiffValue(fieldByName(oldVal, name).Interface()),
+			formatDiffValue(fieldByName(newVal, name).Interface()))
+	}
+
+	return fmt.Sprintf("~ %s: %s", path, strings.Join(fields, "; "))
+}
+
+// ConfigDiff lists the entries that differ between two configs, section by
+// section in the order Config declares them and by name within a section.
+type ConfigDiff struct {
+	Changes []ConfigChange
+}
+
+// Empty reports whether the configs are equivalent.
+func (d ConfigDiff) Empty() bool {
+	return len(d.Changes) == 0
+}
+
+// String renders one change per line: "+" for added entries, "-" for
+// removed ones and "~" for modified ones, followed by the fields that
+// changed.
+func (d ConfigDiff) String() string {
+	if d.Empty() {
+		return "no changes"
+	}
+
+	lines := make([]string, len(d.Changes))
+	for i, c := range d.Changes {
+		lines[i] = c.String()
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func (d ConfigDiff) changed(section, name string) bool {
+	return slices.ContainsFunc(d.Changes, func(c ConfigChange) bool {
+		return c.Section == section && c.Name == name
+	})
+}
+
+// DiffConfigs returns the entries that were added, removed or modified
+// going from old to new. Durations are compared by value, so "1000ms" and
+// "1s" are the same; bare integers are read in the unit of each config's
+// version.
+func DiffConfigs(old, new Config) ConfigDiff {
+	oldUnit, _ := versionDurationUnit(old.Version, 0)
+	newUnit, _ := versionDurationUnit(new.Version, 0)
+
+	return diffConfigs(old, oldUnit, new, newUnit)
+}
+
+func diffConfigs(old Config, oldUnit time.Duration, new Config, newUnit time.Duration) ConfigDiff {
+	oldVal := reflect.ValueOf(normalizeDurations(old, oldUnit))
+	newVal := reflect.ValueOf(normalizeDurations(new, newUnit))
+
+	var diff ConfigDiff
+	for i := 0; i < oldVal.NumField(); i++ {
+		section := fieldName(oldVal.Type().Field(i))
+		a, b := oldVal.Field(i), newVal.Field(i)
+
+		if a.Kind() != reflect.Map {
+			if change, ok := diffValues(a, b); ok {
+				change.Section = section
+				diff.Changes = append(diff.Changes, change)
+			}
+			continue
+		}
+
+		for _, name := range mapKeys(a, b) {
+			change, ok := diffValues(a.MapIndex(name), b.MapIndex(name))
+			if ok {
+				change.Section = section
+				change.Name = name.String()
+				diff.Changes = append(diff.Changes, change)
+			}
+		}
+	}
+
+	return diff
+}
+
+// diffValues compares one entry of both configs, either of which may be
+// missing.
+func diffValues(a, b reflect.Value) (ConfigChange, bool) {
+	switch {
+	case !a.IsValid():
+		return ConfigChange{Kind: ChangeAdded, New: b.Interface()}, true
+	case !b.IsValid():
+		return ConfigChange{Kind: ChangeRemoved, Old: a.Interface()}, true
+	case reflect.DeepEqual(a.Interface(), b.Interface()):
+		return ConfigChange{}, false
+	}
+
+	change := ConfigChange{Kind: ChangeModified, Old: a.Interface(), New: b.Interface()}
+	if a.Kind() == reflect.Struct {
+		for i := 0; i < a.NumField(); i++ {
+			if !reflect.DeepEqual(a.Field(i).Interface(), b.Field(i).Interface()) {
+				change.Fields = append(change.Fields, fieldName(a.Type().Field(i)))
+			}
+		}
+	}
+
+	return change, true
+}
+
+// mapKeys returns the keys of both maps, sorted.
+func mapKeys(a, b reflect.Value) []reflect.Value {
+	seen := make(map[string]reflect.Value)
+	for _, key := range append(a.MapKeys(), b.MapKeys()...) {
+		seen[key.String()] = key
+	}
+
+	keys := make([]reflect.Value, 0, len(seen))
+	for _, name := range slices.Sorted(maps.Keys(seen)) {
+		keys = append(keys, seen[name])
+	}
+
+	return keys
+}
+
+// normalizeDurations rewrites every duration in cfg the way time.Duration
+// prints it. Values that are not durations, such as named timeout
+// references or "never", are kept as written.
+func normalizeDurations(cfg Config, unit time.Duration) Config {
+	normalized, _ := mapConfigStrings(cfg, func(field, val string) (string, error) {
+		if !durationFields[field] {
+			return val, nil
+		}
+
+		if _, named := cfg.Timeouts[val]; named {
+			return val, nil
+		}
+
+		d, err := parseDuration(val, unit)
+		if err != nil || val == "" {
+			return val, nil
+		}
+
+		return d.String(), nil
+	})
+
+	return normalized
+}
+
+func fieldByName(v reflect.Value, name string) reflect.Value {
+	for i := 0; i < v.NumField(); i++ {
+		if fieldName(v.Type().Field(i)) == name {
+			return v.Field(i)
+		}
+	}
+
+	return reflect.Value{}
+}
+
+func formatDiffValue(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+
+	return string(data)
+}
@@ -0,0 +1,137 @@
+package goresilience_test
+
+import (
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	goresilience "github.com/rickKoch/go-resilience"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+func diffBase() goresilience.Config {
+	return goresilience.Config{
+		Timeouts: map[string]goresilience.TimeoutSpec{
+			"fast": {Duration: "1s"},
+			"slow": {Duration: "10s"},
+		},
+		Retries: map[string]goresilience.Retry{
+			"std": {Duration: "100ms", MaxRetries: 3},
+		},
+		CircuitBreakers: map[string]goresilience.CircuitBreaker{
+			"db": {MaxRequests: 1, Interval: "1m", Timeout: "30s", Failures: 5},
+		},
+		Targets: map[string]goresilience.PolicyNames{
+			"users":  {Timeout: "fast", Retry: "std"},
+			"orders": {Timeout: "slow", CircuitBreaker: "db"},
+		},
+	}
+}
+
+func TestDiffConfigsGolden(t *testing.T) {
+	tests := []struct {
+		name   string
+		update func(cfg *goresilience.Config)
+	}{
+		{
+			name:   "unchanged",
+			update: func(*goresilience.Config) {},
+		},
+		{
+			name: "entries",
+			update: func(cfg *goresilience.Config) {
+				delete(cfg.Timeouts, "slow")
+				cfg.Retries["aggressive"] = goresilience.Retry{Duration: "10ms", MaxRetries: 10}
+				cfg.CircuitBreakers["db"] = goresilience.CircuitBreaker{MaxRequests: 1, Interval: "1m", Timeout: "1m", Failures: 3}
+			},
+		},
+		{
+			name: "normalized",
+			update: func(cfg *goresilience.Config) {
+				cfg.Version = goresilience.ConfigV1
+				cfg.Timeouts["fast"] = goresilience.TimeoutSpec{Duration: "1000ms"}
+				cfg.Retries["std"] = goresilience.Retry{Duration: "100000", MaxRetries: 3}
+				cfg.CircuitBreakers["db"] = goresilience.CircuitBreaker{MaxRequests: 1, Interval: "60s", Timeout: "PT30S", Failures: 5}
+			},
+		},
+		{
+			name: "targets",
+			update: func(cfg *goresilience.Config) {
+				cfg.Targets["users"] = goresilience.PolicyNames{Timeout: "2s", Retry: "std"}
+				cfg.Chains = map[string][]string{"all": {"users", "orders"}}
+				cfg.Aliases = map[string][]string{"users": {"pkg.Users/Get"}}
+				cfg.Defaults.Timeout = "fast"
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updated := diffBase()
+			tt.update(&updated)
+
+			got := goresilience.DiffConfigs(diffBase(), updated).String() + "\n"
+
+			golden := filepath.Join("testdata", "diff", tt.name+".golden")
+			if *updateGolden {
+				if err := os.WriteFile(golden, []byte(got), 0o644); err != nil {
+					t.Fatalf("failed to write golden file: %v", err)
+				}
+			}
+
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("failed to read golden file: %v", err)
+			}
+			if got != string(want) {
+				t.Errorf("diff mismatch\ngot:\n%s\nwant:\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestUpdateConfigEmitsDiff(t *testing.T) {
+	provider, err := goresilience.FromConfig(reloadConfig(3, reloadBreaker))
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	if err := provider.UpdateConfig(reloadConfig(4, reloadBreaker)); err != nil {
+		t.Fatalf("failed to update config: %v", err)
+	}
+
+	e := nextEvent(t, provider.Events())
+	if e.Type != goresilience.ConfigReloaded || e.Diff == nil {
+		t.Fatalf("expected a config_reloaded event with a diff, got %v", e.Type)
+	}
+
+	want := `~ retries["test_retry"]: maxRetries 3 -> 4`
+	if got := e.Diff.String(); got != want {
+		t.Fatalf("expected diff %q, got %q", want, got)
+	}
+}
+
+func TestUpdateConfigKeepsRespelledBreaker(t *testing.T) {
+	provider, err := goresilience.FromConfig(reloadConfig(0, reloadBreaker))
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	exec := goresilience.NewExecutor(context.Background(), provider.Policy("test_target"))
+	for i := 0; i < 3; i++ {
+		countAttempts(exec)
+	}
+
+	respelled := reloadBreaker
+	respelled.Interval = "10000ms"
+	if err := provider.UpdateConfig(reloadConfig(0, respelled)); err != nil {
+		t.Fatalf("failed to update config: %v", err)
+	}
+
+	if calls := countAttempts(exec); calls != 0 {
+		t.Fatalf("expected the breaker to stay open, got %d attempts", calls)
+	}
+}
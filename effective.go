@@ -0,0 +1,232 @@
+package goresilience
+
+import (
+	"encoding/json"
+	"maps"
+	"math"
+	"slices"
+	"time"
+)
+
+// Config returns the configuration the provider is running with. Inline
+// circuit breakers appear under CircuitBreakers, defaults the provider
+// applied are filled in, and durations, literal timeouts included, are
+// written out with their units, so the result builds an equivalent provider
+// on its own. It is a copy; changing
+// it does not affect the provider.
+func (p *Provider) Config() Config {
+	s := p.current()
+	cfg := s.config
+
+	out := Config{
+		Version:  cfg.Version,
+		Sheds:    maps.Clone(cfg.Sheds),
+		Defaults: cfg.Defaults,
+	}
+
+	if s.defaultLiteral != nil {
+		out.Defaults.Timeout = formatDuration(s.defaultLiteral.duration)
+	}
+
+	if len(cfg.Custom) > 0 {
+		out.Custom = make(map[string]json.RawMessage, len(cfg.Custom))
+		for name, raw := range cfg.Custom {
+			out.Custom[name] = slices.Clone(raw)
+		}
+	}
+
+	if len(cfg.Timeouts) > 0 {
+		out.Timeouts = make(map[string]TimeoutSpec, len(cfg.Timeouts))
+		for name := range cfg.Timeouts {
+			out.Timeouts[name] = s.timeouts[name].spec()
+		}
+	}
+
+	if len(cfg.Retries) > 0 {
+		out.Retries = make(map[string]Retry, len(cfg.Retries))
+		for name := range cfg.Retries {
+			spec := s.retries[name].spec()
+			if cfg.Version == ConfigV2 {
+				spec.MaxAttempts, spec.MaxRetries = retriesToAttempts(spec.MaxRetries), 0
+			}
+			out.Retries[name] = spec
+		}
+	}
+
+	if len(cfg.CircuitBreakers) > 0 {
+		out.CircuitBreakers = make(map[string]CircuitBreaker, len(cfg.CircuitBreakers))
+		for name := range cfg.CircuitBreakers {
+			out.CircuitBreakers[name] = s.circuitBreakers[name].config()
+		}
+	}
+
+	if len(cfg.Bulkheads) > 0 {
+		out.Bulkheads = make(map[string]Bulkhead, len(cfg.Bulkheads))
+		for name, b := range cfg.Bulkheads {
+			out.Bulkheads[name] = Bulkhead{
+				MaxConcurrent: b.MaxConcurrent,
+				MaxWait:       formatDuration(s.bulkheads[name].maxWait),
+				MaxQueue:      b.MaxQueue,
+			}
+		}
+	}
+
+	if len(cfg.Caches) > 0 {
+		out.Caches = make(map[string]Cache, len(cfg.Caches))
+		for name := range cfg.Caches {
+			c := s.caches[name]
+			out.Caches[name] = Cache{TTL: formatDuration(c.ttl), MaxEntries: c.maxEntries, Always: c.always}
+		}
+	}
+
+	if len(cfg.AdaptiveLimits) > 0 {
+		out.AdaptiveLimits = make(map[string]AdaptiveLimit, len(cfg.AdaptiveLimits))
+		for name, a := range cfg.AdaptiveLimits {
+			l := s.limiters[name]
+
+			maxLimit := 0
+			if !math.IsInf(l.maxLimit, 1) {
+				maxLimit = int(l.maxLimit)
+			}
+
+			out.AdaptiveLimits[name] = AdaptiveLimit{
+				InitialLimit:  a.InitialLimit,
+				MinLimit:      int(l.minLimit),
+				MaxLimit:      maxLimit,
+				LatencyTarget: formatDuration(l.latencyTarget),
+			}
+		}
+	}
+
+	if len(cfg.Targets) > 0 {
+		out.Targets = make(map[string]PolicyNames, len(cfg.Targets))
+		for name, n := range cfg.Targets {
+			out.Targets[name] = s.targets[name].policyNames(n)
+		}
+	}
+
+	if len(cfg.Groups) > 0 {
+		out.Groups = make(map[string]TargetGroup, len(cfg.Groups))
+		for _, g := range s.groups {
+			out.Groups[g.name] = TargetGroup{
+				PolicyNames: g.target.policyNames(cfg.Groups[g.name].PolicyNames),
+				Members:     slices.Clone(g.members),
+			}
+		}
+	}
+
+	if len(cfg.Chains) > 0 {
+		out.Chains = make(map[string][]string, len(cfg.Chains))
+		for name, targets := range cfg.Chains {
+			out.Chains[name] = slices.Clone(targets)
+		}
+	}
+
+	if len(cfg.Aliases) > 0 {
+		out.Aliases = make(map[string][]string, len(cfg.Aliases))
+		for name, aliases := range cfg.Aliases {
+			out.Aliases[name] = slices.Clone(aliases)
+		}
+	}
+
+	return out
+}
+
+// policyNames fills in the settings of n that t was built with, on a copy
+// that shares nothing with n.
+func (t target) policyNames(n PolicyNames) PolicyNames {
+	n.Custom = slices.Clone(n.Custom)
+	if n.RetrySpec != nil {
+		spec := *n.RetrySpec
+		n.RetrySpec = &spec
+	}
+	if n.CircuitBreakerSpec != nil {
+		spec := *n.CircuitBreakerSpec
+		n.CircuitBreakerSpec = &spec
+	}
+	if t.literals.timeout != nil {
+		n.Timeout = formatDuration(t.literals.timeout.duration)
+	}
+	if t.literals.overallTimeout != nil {
+		n.OverallTimeout = formatDuration(t.literals.overallTimeout.duration)
+	}
+	n.MinDeadline = formatDuration(t.minDeadline)
+	n.Order = slices.Clone(t.order)
+	n.FallbackOn = FallbackOnOpen
+	if t.fallbackOnAny {
+		n.FallbackOn = FallbackOnAny
+	}
+	if t.coalescer != nil {
+		n.Coalesce = Coalesce{Window: formatDuration(t.coalescer.window)}
+	}
+
+	return n
+}
+
+func (r *retry) spec() Retry {
+	spec := Retry{Duration: formatDuration(r.duration), MaxRetries: r.maxRetries}
+	if r.exponential {
+		spec.Backoff = RetryBackoffExponential
+		spec.MaxInterval = formatDuration(r.maxInterval)
+	}
+
+	return spec
+}
+
+func (t *timeout) spec() TimeoutSpec {
+	spec := TimeoutSpec{
+		Duration:     formatDuration(t.duration),
+		Mode:         TimeoutModeDetached,
+		GracePeriod:  formatDuration(t.grace),
+		DrainTimeout: formatDuration(t.drain),
+		WarnAt:       formatDuration(t.warnAt),
+	}
+	if t.cooperative {
+		spec.Mode = TimeoutModeCooperative
+	}
+
+	return spec
+}
+
+func (cb *circuitBreaker) config() CircuitBreaker {
+	c := CircuitBreaker{
+		MaxRequests:   int(cb.maxRequests),
+		Interval:      IntervalNever,
+		Timeout:       formatDuration(cb.timeout),
+		Failures:      int(cb.failures),
+		RampUp:        formatDuration(cb.rampUp),
+		CountTimeouts: cb.countTimeouts,
+		WarmUp:        formatDuration(cb.warmUp),
+		ProbeTimeout:  formatDuration(cb.probeTimeout),
+		DryRun:        cb.dryRun,
+	}
+	if cb.interval > 0 {
+		c.Interval = formatDuration(cb.interval)
+	}
+
+	if cb.keys != nil {
+		c.MaxKeys = cb.keys.maxKeys
+		c.KeyIdleTimeout = formatDuration(cb.keys.idle)
+	}
+
+	if d := cb.outliers; d != nil {
+		c.OutlierDetection = OutlierDetection{
+			Window:      formatDuration(d.window),
+			Sensitivity: d.sensitivity,
+			Cooldown:    formatDuration(d.cooldown),
+			MinRequests: int(d.minRequests),
+		}
+	}
+
+	return c
+}
+
+// formatDuration renders d the way config values are written, leaving unset
+// durations empty.
+func formatDuration(d time.Duration) string {
+	if d == 0 {
+		return ""
+	}
+
+	return d.String()
+}
@@ -0,0 +1,216 @@
+package goresilience_test
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	goresilience "github.com/rickKoch/go-resilience"
+)
+
+func effectiveConfig() goresilience.Config {
+	return goresilience.Config{
+		Timeouts: map[string]goresilience.TimeoutSpec{
+			"test_timeout": {Duration: "500", WarnAt: "0.5"},
+		},
+		Retries: map[string]goresilience.Retry{
+			"test_retry": {Duration: "1", MaxRetries: 2},
+		},
+		CircuitBreakers: map[string]goresilience.CircuitBreaker{
+			"test_cb": {Interval: goresilience.IntervalNever, MaxRequests: 1, Failures: 5},
+		},
+		Caches: map[string]goresilience.Cache{
+			"test_cache": {TTL: "60000"},
+		},
+		Targets: map[string]goresilience.PolicyNames{
+			"test_target": {
+				Timeout:        "test_timeout",
+				Retry:          "test_retry",
+				CircuitBreaker: "test_cb",
+				Cache:          "test_cache",
+			},
+			"inline_target": {
+				CircuitBreakerSpec: &goresilience.CircuitBreaker{Interval: "1m", MaxRequests: 1, Failures: 1},
+				Coalesce:           goresilience.Coalesce{Window: "10"},
+			},
+		},
+		Chains: map[string][]string{
+			"test_chain": {"test_target", "inline_target"},
+		},
+	}
+}
+
+func TestProviderConfig(t *testing.T) {
+	provider, err := goresilience.FromConfigWithOptions(effectiveConfig(), goresilience.WithDefaultDurationUnit(time.Millisecond))
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	cfg := provider.Config()
+
+	if got := cfg.Timeouts["test_timeout"]; got != (goresilience.TimeoutSpec{Duration: "500ms", Mode: goresilience.TimeoutModeDetached, WarnAt: "250ms"}) {
+		t.Errorf("unexpected timeout %+v", got)
+	}
+	if got := cfg.CircuitBreakers["test_cb"]; got.MaxRequests != 1 || got.Timeout != "1m0s" {
+		t.Errorf("expected breaker defaults to be filled in, got %+v", got)
+	}
+	if got := cfg.Caches["test_cache"]; got.TTL != "1m0s" || got.MaxEntries != 1000 {
+		t.Errorf("expected cache defaults to be filled in, got %+v", got)
+	}
+	if got := cfg.Targets["inline_target"]; got.CircuitBreaker != "inline_target" || got.CircuitBreakerSpec != nil || got.Coalesce.Window != "10ms" {
+		t.Errorf("expected the inline breaker to be referenced by name, got %+v", got)
+	}
+}
+
+func TestProviderConfigRoundTrip(t *testing.T) {
+	provider, err := goresilience.FromConfigWithOptions(effectiveConfig(), goresilience.WithDefaultDurationUnit(time.Millisecond))
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	rebuilt, err := goresilience.FromConfig(provider.Config())
+	if err != nil {
+		t.Fatalf("expected the exported config to be valid on its own, got: %v", err)
+	}
+
+	if !reflect.DeepEqual(rebuilt.Config(), provider.Config()) {
+		t.Fatalf("expected an equivalent provider, got %+v, want %+v", rebuilt.Config(), provider.Config())
+	}
+
+	got := countAttempts(goresilience.NewExecWithPolicy(context.Background(), rebuilt.Policy("test_target")))
+	want := countAttempts(goresilience.NewExecWithPolicy(context.Background(), provider.Policy("test_target")))
+	if got != want {
+		t.Fatalf("expected %d attempts, got %d", want, got)
+	}
+}
+
+func TestProviderConfigRoundTripVersions(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  goresilience.Config
+	}{
+		{
+			name: "v1 literals",
+			cfg: goresilience.Config{
+				Version:  goresilience.ConfigV1,
+				Defaults: goresilience.Defaults{Timeout: "2000000"},
+				Retries: map[string]goresilience.Retry{
+					"test_retry": {Duration: "1000", MaxRetries: 2, Backoff: goresilience.RetryBackoffExponential, MaxInterval: "4000"},
+				},
+				Targets: map[string]goresilience.PolicyNames{
+					"test_target":  {Retry: "test_retry", Timeout: "3000000", OverallTimeout: "5000000"},
+					"default_only": {},
+				},
+			},
+		},
+		{
+			name: "v2 attempts",
+			cfg: goresilience.Config{
+				Version: goresilience.ConfigV2,
+				Retries: map[string]goresilience.Retry{
+					"test_retry": {Duration: "1ms", MaxAttempts: 3, Backoff: goresilience.RetryBackoffExponential},
+				},
+				Targets: map[string]goresilience.PolicyNames{
+					"test_target": {Retry: "test_retry", Timeout: "3s"},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, err := goresilience.FromConfig(tt.cfg)
+			if err != nil {
+				t.Fatalf("failed to create provider: %v", err)
+			}
+
+			rebuilt, err := goresilience.FromConfig(provider.Config())
+			if err != nil {
+				t.Fatalf("expected the exported config to be valid on its own, got: %v", err)
+			}
+			if _, err := provider.CloneWith(nil); err != nil {
+				t.Fatalf("expected the provider to clone, got: %v", err)
+			}
+
+			if !reflect.DeepEqual(rebuilt.Config(), provider.Config()) {
+				t.Fatalf("expected an equivalent provider, got %+v, want %+v", rebuilt.Config(), provider.Config())
+			}
+
+			// Literal timeouts are referenced by their duration with a unit.
+			for target := range tt.cfg.Targets {
+				got, _ := rebuilt.Describe(target)
+				want, _ := provider.Describe(target)
+				if got.Timeout.Duration != want.Timeout.Duration || !reflect.DeepEqual(got.Retry, want.Retry) {
+					t.Fatalf("expected %s to run with the same policies, got %+v, want %+v", target, got, want)
+				}
+				if (got.OverallTimeout == nil) != (want.OverallTimeout == nil) ||
+					got.OverallTimeout != nil && got.OverallTimeout.Duration != want.OverallTimeout.Duration {
+					t.Fatalf("expected %s to have the same overall timeout, got %+v, want %+v", target, got, want)
+				}
+			}
+
+			got := countAttempts(goresilience.NewExecWithPolicy(context.Background(), rebuilt.Policy("test_target")))
+			if got != 3 {
+				t.Fatalf("expected 3 attempts, got %d", got)
+			}
+		})
+	}
+}
+
+func TestProviderConfigIsCopy(t *testing.T) {
+	provider, err := goresilience.FromConfigWithOptions(effectiveConfig(), goresilience.WithDefaultDurationUnit(time.Millisecond))
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	cfg := provider.Config()
+	cfg.Retries["test_retry"] = goresilience.Retry{Duration: "1h"}
+	cfg.Chains["test_chain"][0] = "changed"
+	delete(cfg.Targets, "test_target")
+
+	cfg = provider.Config()
+	if cfg.Retries["test_retry"].Duration != "1ms" || cfg.Chains["test_chain"][0] != "test_target" {
+		t.Fatalf("expected the provider's config to be unaffected, got %+v", cfg)
+	}
+	if _, ok := cfg.Targets["test_target"]; !ok {
+		t.Fatal("expected the provider's targets to be unaffected")
+	}
+}
+
+func TestProviderConfigCustomIsCopy(t *testing.T) {
+	provider, err := goresilience.FromConfig(goresilience.Config{
+		Custom: map[string]json.RawMessage{
+			"every-other": json.RawMessage(`{"kind": "flaky", "failEvery": 2}`),
+		},
+		Targets: map[string]goresilience.PolicyNames{
+			"test_target": {
+				Custom:             []string{"every-other"},
+				CircuitBreakerSpec: &goresilience.CircuitBreaker{Interval: "1m", MaxRequests: 1, Failures: 1},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	cfg := provider.Config()
+	copy(cfg.Custom["every-other"], "XXXXXXXX")
+	cfg.Targets["test_target"].Custom[0] = "changed"
+	if spec := cfg.Targets["test_target"].CircuitBreakerSpec; spec != nil {
+		spec.Failures = 100
+	}
+
+	cfg = provider.Config()
+	if got := string(cfg.Custom["every-other"]); got != `{"kind": "flaky", "failEvery": 2}` {
+		t.Fatalf("expected the provider's custom policy to be unaffected, got %s", got)
+	}
+	target := cfg.Targets["test_target"]
+	if target.Custom[0] != "every-other" {
+		t.Fatalf("expected the provider's target to be unaffected, got %q", target.Custom)
+	}
+	if spec := target.CircuitBreakerSpec; spec != nil && spec.Failures != 1 {
+		t.Fatalf("expected the provider's breaker to be unaffected, got %+v", spec)
+	}
+}
@@ -0,0 +1,171 @@
+package goresilience
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// expandEnv replaces ${VAR} and ${VAR:-default} in s with the value of the
+// environment variable, or the default when it is unset or empty. A "$" not
+// followed by "{" is kept as is.
+func expandEnv(s string) (string, error) {
+	if !strings.Contains(s, "${") {
+		return s, nil
+	}
+
+	var b strings.Builder
+	for {
+		start := strings.Index(s, "${")
+		if start < 0 {
+			b.WriteString(s)
+			return b.String(), nil
+		}
+
+		end := strings.IndexByte(s[start:], '}')
+		if end < 0 {
+			return "", fmt.Errorf("unterminated ${ in %q", s)
+		}
+		end += start
+
+		name, def, hasDefault := strings.Cut(s[start+2:end], ":-")
+		if name == "" {
+			return "", fmt.Errorf("empty variable name in %q", s)
+		}
+
+		val, ok := os.LookupEnv(name)
+		if !ok || val == "" {
+			if !hasDefault {
+				return "", fmt.Errorf("environment variable %q is not set", name)
+			}
+			val = def
+		}
+
+		b.WriteString(s[:start])
+		b.WriteString(val)
+		s = s[end+1:]
+	}
+}
+
+// ExpandEnv applies ${VAR} and ${VAR:-default} expansion to a whole config
+// file. WithEnvExpansion only expands string fields once decoded, and
+// numeric fields such as maxRetries cannot hold a ${VAR} reference, so
+// expand the file before parsing it when they come from the environment.
+// Build the provider without WithEnvExpansion then, or values that contain
+// ${ are expanded a second time.
+func ExpandEnv(data []byte) ([]byte, error) {
+	expanded, err := expandEnv(string(data))
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(expanded), nil
+}
+
+// expandConfigEnv returns a copy of cfg with environment variables expanded
+// in every string field. Each error names the field it was found in.
+func expandConfigEnv(cfg Config) (Config, error) {
+	return mapConfigStrings(cfg, func(_, val string) (string, error) {
+		return expandEnv(val)
+	})
+}
+
+// mapConfigStrings returns a deep copy of cfg in which every string value
+// is replaced by fn's result. fn is given the name of the field the value
+// belongs to, such as "duration" or "chains".
+func mapConfigStrings(cfg Config, fn func(field, val string) (string, error)) (Config, error) {
+	var errs configErrors
+
+	mapped := mapStrings(reflect.ValueOf(cfg), "", "", fn, &errs)
+	if err := errs.err(); err != nil {
+		return cfg, err
+	}
+
+	return mapped.Interface().(Config), nil
+}
+
+// cloneConfig returns a deep copy of cfg.
+func cloneConfig(cfg Config) Config {
+	clone, _ := mapConfigStrings(cfg, func(_, val string) (string, error) {
+		return val, nil
+	})
+	return clone
+}
+
+func mapStrings(v reflect.Value, path, field string, fn func(field, val string) (string, error), errs *configErrors) reflect.Value {
+	switch v.Kind() {
+	case reflect.String:
+		s, err := fn(field, v.String())
+		if err != nil {
+			errs.add(path, "%v", err)
+			return v
+		}
+
+		out := reflect.New(v.Type()).Elem()
+		out.SetString(s)
+		return out
+
+	case reflect.Struct:
+		out := reflect.New(v.Type()).Elem()
+		out.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			f := v.Type().Field(i)
+			if !f.IsExported() {
+				continue
+			}
+
+			// Embedded structs are inlined in config files.
+			name := fieldName(f)
+			fieldPath := joinPath(path, name)
+			if f.Anonymous {
+				fieldPath = path
+			}
+			out.Field(i).Set(mapStrings(v.Field(i), fieldPath, name, fn, errs))
+		}
+		return out
+
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+
+		out := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			out.SetMapIndex(iter.Key(), mapStrings(iter.Value(), entryPath(path, iter.Key().String()), field, fn, errs))
+		}
+		return out
+
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+
+		out := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			out.Index(i).Set(mapStrings(v.Index(i), fmt.Sprintf("%s[%d]", path, i), field, fn, errs))
+		}
+		return out
+
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+
+		out := reflect.New(v.Type().Elem())
+		out.Elem().Set(mapStrings(v.Elem(), path, field, fn, errs))
+		return out
+
+	default:
+		return v
+	}
+}
+
+func fieldName(field reflect.StructField) string {
+	if name, _, _ := strings.Cut(field.Tag.Get("json"), ","); name != "" {
+		return name
+	}
+
+	return field.Name
+}
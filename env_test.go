@@ -0,0 +1,142 @@
+package goresilience_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	goresilience "github.com/rickKoch/go-resilience"
+)
+
+func TestEnvExpansion(t *testing.T) {
+	t.Setenv("RESILIENCE_BACKOFF", "1ms")
+	t.Setenv("RESILIENCE_EMPTY", "")
+
+	cfg := goresilience.Config{
+		Retries: map[string]goresilience.Retry{
+			"set":      {Duration: "${RESILIENCE_BACKOFF}", MaxRetries: 1},
+			"default":  {Duration: "${RESILIENCE_UNSET:-1ms}", MaxRetries: 2},
+			"empty":    {Duration: "${RESILIENCE_EMPTY:-1ms}", MaxRetries: 3},
+			"embedded": {Duration: "${RESILIENCE_UNSET:-1}ms", MaxRetries: 4},
+		},
+		Targets: map[string]goresilience.PolicyNames{
+			"set":      {Retry: "set"},
+			"default":  {Retry: "default"},
+			"empty":    {Retry: "empty"},
+			"embedded": {Retry: "embedded"},
+		},
+	}
+
+	provider, err := goresilience.FromConfigWithOptions(cfg, goresilience.WithEnvExpansion())
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	for target, retry := range cfg.Retries {
+		exec := goresilience.NewExecWithPolicy(context.Background(), provider.Policy(target))
+		if got, want := countAttempts(exec), int32(retry.MaxRetries+1); got != want {
+			t.Errorf("target %q: expected %d attempts, got %d", target, want, got)
+		}
+	}
+
+	if cfg.Retries["set"].Duration != "${RESILIENCE_BACKOFF}" {
+		t.Fatalf("expected the caller's config to be left alone, got %q", cfg.Retries["set"].Duration)
+	}
+}
+
+func TestEnvExpansionMissingVariable(t *testing.T) {
+	_, err := goresilience.FromConfigWithOptions(goresilience.Config{
+		Retries: map[string]goresilience.Retry{
+			"test_retry": {Duration: "${RESILIENCE_UNSET}", MaxRetries: 3},
+		},
+	}, goresilience.WithEnvExpansion())
+	if err == nil {
+		t.Fatal("expected an error for an unset variable without a default")
+	}
+	if !strings.Contains(err.Error(), `retries["test_retry"].duration`) || !strings.Contains(err.Error(), "RESILIENCE_UNSET") {
+		t.Fatalf("expected the error to name the field and variable, got: %v", err)
+	}
+}
+
+func TestEnvExpansionDisabled(t *testing.T) {
+	_, err := goresilience.FromConfig(goresilience.Config{
+		Timeouts: map[string]goresilience.TimeoutSpec{
+			"test_timeout": {Duration: "${RESILIENCE_UNSET:-1s}"},
+		},
+	})
+	if err == nil {
+		t.Fatal("expected the reference to be left unexpanded without WithEnvExpansion")
+	}
+}
+
+func TestEnvExpansionConfigFile(t *testing.T) {
+	t.Setenv("RESILIENCE_BACKOFF", "1ms")
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	data := "retries:\n  test_retry:\n    duration: ${RESILIENCE_BACKOFF}\n    maxRetries: 7\ntargets:\n  test_target:\n    retry: test_retry\n"
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	provider, err := goresilience.FromConfigFile(path, goresilience.WithEnvExpansion())
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	exec := goresilience.NewExecWithPolicy(context.Background(), provider.Policy("test_target"))
+	if got := countAttempts(exec); got != 8 {
+		t.Fatalf("expected 8 attempts, got %d", got)
+	}
+}
+
+func TestEnvExpansionOnce(t *testing.T) {
+	t.Setenv("RESILIENCE_BACKOFF", "${RESILIENCE_UNSET}")
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	data := "retries:\n  test_retry:\n    duration: ${RESILIENCE_BACKOFF}\n    maxRetries: 1\n"
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// The variable's value is used as is rather than expanded in turn, and
+	// then fails as a duration.
+	_, err := goresilience.FromConfigFile(path, goresilience.WithEnvExpansion())
+	var cfgErr *goresilience.ConfigError
+	if !errors.As(err, &cfgErr) || cfgErr.Path != `retries["test_retry"].duration` || !strings.Contains(err.Error(), `"${RESILIENCE_UNSET}"`) {
+		t.Fatalf("expected the duration to be rejected as written in the environment, got: %v", err)
+	}
+}
+
+func TestEnvExpansionMissingVariableInFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	data := "timeouts:\n  test_timeout: ${RESILIENCE_UNSET}\n"
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := goresilience.FromConfigFile(path, goresilience.WithEnvExpansion())
+	var cfgErr *goresilience.ConfigError
+	if !errors.As(err, &cfgErr) || cfgErr.Path != `timeouts["test_timeout"].duration` {
+		t.Fatalf("expected a ConfigError for the timeout duration, got: %v", err)
+	}
+}
+
+func TestExpandEnvNumericFields(t *testing.T) {
+	t.Setenv("RESILIENCE_MAX_RETRIES", "7")
+
+	data, err := goresilience.ExpandEnv([]byte("retries:\n  test_retry:\n    duration: 1ms\n    maxRetries: ${RESILIENCE_MAX_RETRIES}\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cfg, err := goresilience.ParseConfigYAML(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Retries["test_retry"].MaxRetries != 7 {
+		t.Fatalf("expected maxRetries to come from the environment, got %d", cfg.Retries["test_retry"].MaxRetries)
+	}
+}
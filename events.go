@@ -0,0 +1,203 @@
+package goresilience
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const defaultEventBuffer = 256
+
+type EventType int
+
+const (
+	BreakerOpened EventType = iota + 1
+	BreakerClosed
+	RequestRejected
+	ProbeSucceeded
+	OperationLeaked
+	ConfigReloaded
+)
+
+func (t EventType) String() string {
+	switch t {
+	case BreakerOpened:
+		return "breaker_opened"
+	case BreakerClosed:
+		return "breaker_closed"
+	case RequestRejected:
+		return "request_rejected"
+	case ProbeSucceeded:
+		return "probe_succeeded"
+	case OperationLeaked:
+		return "operation_leaked"
+	case ConfigReloaded:
+		return "config_reloaded"
+	default:
+		return fmt.Sprintf("unknown event: %d", int(t))
+	}
+}
+
+type Event struct {
+	Type      EventType
+	Time      time.Time
+	Target    string
+	Breaker   string
+	Key       string
+	Counts    Counts
+	LastError error
+	DryRun    bool
+
+	// Diff is set on ConfigReloaded events to what the update changed.
+	Diff *ConfigDiff
+}
+
+type eventBus struct {
+	ch      chan Event
+	dropped atomic.Uint64
+	leaks   atomic.Uint64
+
+	// chMu keeps emit from sending on ch once close has closed it.
+	chMu   sync.RWMutex
+	closed bool
+
+	mu          sync.RWMutex
+	stateHooks  []func(StateChange)
+	slowHooks   []func(target string, elapsed, limit time.Duration)
+	reloadHooks []func(path string)
+	errorHooks  []func(path string, err error)
+}
+
+func newEventBus(size int) *eventBus {
+	return &eventBus{ch: make(chan Event, size)}
+}
+
+func (b *eventBus) emit(e Event) {
+	if b == nil {
+		return
+	}
+
+	b.chMu.RLock()
+	defer b.chMu.RUnlock()
+
+	if b.closed {
+		return
+	}
+
+	select {
+	case b.ch <- e:
+	default:
+		b.dropped.Add(1)
+	}
+}
+
+func (b *eventBus) close() {
+	b.chMu.Lock()
+	defer b.chMu.Unlock()
+
+	if !b.closed {
+		b.closed = true
+		close(b.ch)
+	}
+}
+
+// leaked records a timed-out operation that outlived its drain window.
+func (b *eventBus) leaked(target string) {
+	if b == nil {
+		return
+	}
+
+	b.leaks.Add(1)
+	b.emit(Event{Type: OperationLeaked, Time: time.Now(), Target: target})
+}
+
+func (b *eventBus) stateChanged(sc StateChange) {
+	b.mu.RLock()
+	hooks := b.stateHooks
+	b.mu.RUnlock()
+
+	for _, hook := range hooks {
+		hook(sc)
+	}
+}
+
+func (b *eventBus) slow(target string, elapsed, limit time.Duration) {
+	if b == nil {
+		return
+	}
+
+	b.mu.RLock()
+	hooks := b.slowHooks
+	b.mu.RUnlock()
+
+	for _, hook := range hooks {
+		hook(target, elapsed, limit)
+	}
+}
+
+func (b *eventBus) reloaded(path string) {
+	b.mu.RLock()
+	hooks := b.reloadHooks
+	b.mu.RUnlock()
+
+	for _, hook := range hooks {
+		hook(path)
+	}
+}
+
+func (b *eventBus) reloadFailed(path string, err error) {
+	b.mu.RLock()
+	hooks := b.errorHooks
+	b.mu.RUnlock()
+
+	for _, hook := range hooks {
+		hook(path, err)
+	}
+}
+
+func (p *Provider) OnStateChange(fn func(StateChange)) {
+	p.events.mu.Lock()
+	defer p.events.mu.Unlock()
+
+	p.events.stateHooks = append(p.events.stateHooks, fn)
+}
+
+// OnSlowOperation registers fn to be called once for each call that is
+// still running at its timeout's warnAt threshold.
+func (p *Provider) OnSlowOperation(fn func(target string, elapsed, limit time.Duration)) {
+	p.events.mu.Lock()
+	defer p.events.mu.Unlock()
+
+	p.events.slowHooks = append(p.events.slowHooks, fn)
+}
+
+// OnReload registers fn to be called after a watched config file has been
+// reloaded.
+func (p *Provider) OnReload(fn func(path string)) {
+	p.events.mu.Lock()
+	defer p.events.mu.Unlock()
+
+	p.events.reloadHooks = append(p.events.reloadHooks, fn)
+}
+
+// OnReloadError registers fn to be called when a change to a watched config
+// file could not be applied.
+func (p *Provider) OnReloadError(fn func(path string, err error)) {
+	p.events.mu.Lock()
+	defer p.events.mu.Unlock()
+
+	p.events.errorHooks = append(p.events.errorHooks, fn)
+}
+
+func (p *Provider) Events() <-chan Event {
+	return p.events.ch
+}
+
+func (p *Provider) DroppedEvents() uint64 {
+	return p.events.dropped.Load()
+}
+
+func (p *Provider) LeakedOperations() uint64 {
+	return p.events.leaks.Load()
+}
@@ -0,0 +1,223 @@
+package goresilience_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	goresilience "github.com/rickKoch/go-resilience"
+)
+
+func eventsProvider(t *testing.T) *goresilience.Provider {
+	t.Helper()
+
+	cfg := goresilience.Config{
+		CircuitBreakers: map[string]goresilience.CircuitBreaker{
+			"test_cb": {
+				MaxRequests: 1,
+				Interval:    "10s",
+				Timeout:     "100ms",
+				Failures:    2,
+			},
+		},
+		Targets: map[string]goresilience.PolicyNames{
+			"test_target": {
+				CircuitBreaker: "test_cb",
+			},
+		},
+	}
+
+	provider, err := goresilience.FromConfig(cfg)
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	return provider
+}
+
+func nextEvent(t *testing.T, events <-chan goresilience.Event) goresilience.Event {
+	t.Helper()
+
+	select {
+	case e := <-events:
+		return e
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for event")
+		return goresilience.Event{}
+	}
+}
+
+func TestEventStreamBreakerLifecycle(t *testing.T) {
+	provider := eventsProvider(t)
+	events := provider.Events()
+	exec := goresilience.NewExecutor(context.Background(), provider.Policy("test_target"))
+
+	for i := 0; i < 2; i++ {
+		_, _ = exec(func(ctx context.Context) (any, error) {
+			return nil, testError
+		})
+	}
+
+	_, _ = exec(func(ctx context.Context) (any, error) {
+		return successResult, nil
+	})
+
+	time.Sleep(150 * time.Millisecond)
+
+	_, err := exec(func(ctx context.Context) (any, error) {
+		return successResult, nil
+	})
+	if err != nil {
+		t.Fatalf("expected probe to succeed, got: %v", err)
+	}
+
+	expected := []goresilience.EventType{
+		goresilience.BreakerOpened,
+		goresilience.RequestRejected,
+		goresilience.ProbeSucceeded,
+		goresilience.BreakerClosed,
+	}
+
+	for i, typ := range expected {
+		e := nextEvent(t, events)
+		if e.Type != typ {
+			t.Fatalf("event %d: expected %s, got %s", i, typ, e.Type)
+		}
+		if e.Target != "test_target" || e.Breaker != "test_cb" {
+			t.Fatalf("event %d: unexpected target/breaker: %+v", i, e)
+		}
+		if e.Time.IsZero() {
+			t.Fatalf("event %d: missing timestamp", i)
+		}
+	}
+
+	select {
+	case e := <-events:
+		t.Fatalf("unexpected extra event: %+v", e)
+	default:
+	}
+}
+
+func TestEventStreamOpenedCounts(t *testing.T) {
+	provider := eventsProvider(t)
+	exec := goresilience.NewExecutor(context.Background(), provider.Policy("test_target"))
+
+	for i := 0; i < 2; i++ {
+		_, _ = exec(func(ctx context.Context) (any, error) {
+			return nil, testError
+		})
+	}
+
+	e := nextEvent(t, provider.Events())
+	if e.Type != goresilience.BreakerOpened {
+		t.Fatalf("expected %s, got %s", goresilience.BreakerOpened, e.Type)
+	}
+	if e.Counts.ConsecutiveFailures != 2 || e.Counts.TotalFailures != 2 {
+		t.Fatalf("unexpected counts snapshot: %+v", e.Counts)
+	}
+}
+
+func TestEventStreamDropsWhenFull(t *testing.T) {
+	provider := eventsProvider(t)
+	exec := goresilience.NewExecutor(context.Background(), provider.Policy("test_target"))
+
+	// Nobody reads the channel; executions must not block once it fills up.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 1000; i++ {
+			_, _ = exec(func(ctx context.Context) (any, error) {
+				return nil, testError
+			})
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("executions blocked on a slow event consumer")
+	}
+
+	if provider.DroppedEvents() == 0 {
+		t.Fatal("expected dropped events to be counted")
+	}
+}
+
+func TestStateChangeCapturesLastError(t *testing.T) {
+	provider := eventsProvider(t)
+
+	var changes []goresilience.StateChange
+	provider.OnStateChange(func(sc goresilience.StateChange) {
+		changes = append(changes, sc)
+	})
+
+	exec := goresilience.NewExecutor(context.Background(), provider.Policy("test_target"))
+	lastErr := errors.New("connection refused")
+
+	_, _ = exec(func(ctx context.Context) (any, error) {
+		return nil, testError
+	})
+	_, _ = exec(func(ctx context.Context) (any, error) {
+		return nil, lastErr
+	})
+
+	if len(changes) != 1 {
+		t.Fatalf("expected 1 state change, got %d", len(changes))
+	}
+
+	sc := changes[0]
+	if sc.From != goresilience.StateClosed || sc.To != goresilience.StateOpen {
+		t.Fatalf("unexpected transition: %s -> %s", sc.From, sc.To)
+	}
+	if sc.Target != "test_target" || sc.Breaker != "test_cb" {
+		t.Fatalf("unexpected target/breaker: %+v", sc)
+	}
+	if sc.LastError != lastErr {
+		t.Fatalf("expected last error %v, got %v", lastErr, sc.LastError)
+	}
+	if sc.Counts.ConsecutiveFailures != 2 {
+		t.Fatalf("expected counts snapshot at trip time, got %+v", sc.Counts)
+	}
+
+	_, err := exec(func(ctx context.Context) (any, error) {
+		return successResult, nil
+	})
+
+	var openErr *goresilience.CircuitOpenError
+	if !errors.As(err, &openErr) {
+		t.Fatalf("expected CircuitOpenError, got: %v", err)
+	}
+	if openErr.LastError != lastErr {
+		t.Fatalf("expected last error %v, got %v", lastErr, openErr.LastError)
+	}
+	if openErr.Counts.ConsecutiveFailures != 2 {
+		t.Fatalf("expected counts snapshot in error, got %+v", openErr.Counts)
+	}
+
+	if e := nextEvent(t, provider.Events()); e.Type != goresilience.BreakerOpened || e.LastError != lastErr {
+		t.Fatalf("expected opened event with last error, got %+v", e)
+	}
+}
+
+func TestStateChangeUnwrapsPermanentError(t *testing.T) {
+	provider := eventsProvider(t)
+
+	var lastErr error
+	provider.OnStateChange(func(sc goresilience.StateChange) {
+		lastErr = sc.LastError
+	})
+
+	for i := 0; i < 2; i++ {
+		permit, err := provider.Acquire("test_target")
+		if err != nil {
+			t.Fatalf("expected permit, got: %v", err)
+		}
+		permit.Failure(backoff.Permanent(testError))
+	}
+
+	if lastErr != testError {
+		t.Fatalf("expected unwrapped operation error, got %v", lastErr)
+	}
+}
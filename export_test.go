@@ -0,0 +1,23 @@
+package goresilience
+
+import "time"
+
+func SetBreakerRandom(p *Provider, name string, random func() float64) {
+	p.current().circuitBreakers[name].random = random
+}
+
+func SetBreakerClock(p *Provider, name string, now func() time.Time) {
+	p.current().circuitBreakers[name].now = now
+}
+
+func SetCacheClock(p *Provider, name string, now func() time.Time) {
+	p.current().caches[name].now = now
+}
+
+func SetAdaptiveLimitClock(p *Provider, name string, now func() time.Time) {
+	p.current().limiters[name].now = now
+}
+
+func SetCoalesceTimer(p *Provider, target string, after func(time.Duration) <-chan time.Time) {
+	p.current().targets[target].coalescer.after = after
+}
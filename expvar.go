@@ -0,0 +1,37 @@
+package goresilience
+
+import (
+	"errors"
+	"expvar"
+	"fmt"
+	"sync"
+)
+
+// ErrExpvarPublished is returned by PublishExpvar for a prefix that is taken.
+var ErrExpvarPublished = errors.New("expvar already published")
+
+// expvarMu makes checking for a prefix and publishing it one step, since
+// expvar.Publish panics on a name that is taken.
+var expvarMu sync.Mutex
+
+// PublishExpvar publishes p's Metrics in expvar under prefix, so that
+// they are served as JSON at /debug/vars alongside the runtime's, with
+// every target's counters and breaker state. A prefix can only be
+// published once per process, by this or any other package.
+func PublishExpvar(p *Provider, prefix string) error {
+	if p == nil {
+		return fmt.Errorf("cannot publish a nil provider as %q", prefix)
+	}
+
+	expvarMu.Lock()
+	defer expvarMu.Unlock()
+
+	if expvar.Get(prefix) != nil {
+		return fmt.Errorf("%w: %q", ErrExpvarPublished, prefix)
+	}
+
+	expvar.Publish(prefix, expvar.Func(func() any {
+		return p.Metrics()
+	}))
+	return nil
+}
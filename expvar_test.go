@@ -0,0 +1,86 @@
+package goresilience_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"testing"
+
+	goresilience "github.com/rickKoch/go-resilience"
+)
+
+func TestPublishExpvar(t *testing.T) {
+	provider, err := goresilience.FromConfig(reloadConfig(0, reloadBreaker))
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	if err := goresilience.PublishExpvar(provider, "test_resilience"); err != nil {
+		t.Fatalf("failed to publish: %v", err)
+	}
+
+	type targetVars struct {
+		Executions   uint64 `json:"executions"`
+		Successes    uint64 `json:"successes"`
+		Failures     uint64 `json:"failures"`
+		Breaker      string `json:"breaker"`
+		BreakerState string `json:"breakerState"`
+	}
+
+	read := func() map[string]targetVars {
+		t.Helper()
+
+		v := expvar.Get("test_resilience")
+		if v == nil {
+			t.Fatal("expected the metrics to be published")
+		}
+
+		var m struct {
+			Targets map[string]targetVars `json:"targets"`
+		}
+		if err := json.Unmarshal([]byte(v.String()), &m); err != nil {
+			t.Fatalf("expected the metrics as JSON, got %q: %v", v.String(), err)
+		}
+		return m.Targets
+	}
+
+	if got := read()["test_target"].Executions; got != 0 {
+		t.Fatalf("expected no executions yet, got %d", got)
+	}
+
+	exec := goresilience.NewExecutor(context.Background(), provider.Policy("test_target"))
+	_, _ = exec(succeed)
+	for range reloadBreaker.Failures {
+		countAttempts(exec)
+	}
+
+	got := read()["test_target"]
+	if got.Executions != 4 || got.Successes != 1 || got.Failures != 3 {
+		t.Fatalf("expected 4 executions, 1 success and 3 failures, got %+v", got)
+	}
+	if got.Breaker != "test_cb" || got.BreakerState != "open" {
+		t.Fatalf("expected breaker test_cb to be open, got %q %q", got.Breaker, got.BreakerState)
+	}
+}
+
+func TestPublishExpvarTaken(t *testing.T) {
+	provider, err := goresilience.FromConfig(reloadConfig(0, reloadBreaker))
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	if err := goresilience.PublishExpvar(provider, "test_resilience_taken"); err != nil {
+		t.Fatalf("failed to publish: %v", err)
+	}
+
+	if err := goresilience.PublishExpvar(provider, "test_resilience_taken"); !errors.Is(err, goresilience.ErrExpvarPublished) {
+		t.Fatalf("expected ErrExpvarPublished for a duplicate prefix, got %v", err)
+	}
+	if err := goresilience.PublishExpvar(provider, "memstats"); !errors.Is(err, goresilience.ErrExpvarPublished) {
+		t.Fatalf("expected ErrExpvarPublished for a name expvar itself publishes, got %v", err)
+	}
+	if err := goresilience.PublishExpvar(nil, "test_resilience_nil"); err == nil {
+		t.Fatal("expected publishing a nil provider to fail")
+	}
+}
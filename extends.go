@@ -0,0 +1,114 @@
+package goresilience
+
+import (
+	"maps"
+	"slices"
+	"strings"
+)
+
+// resolveExtends returns a copy of entries in which every entry that
+// extends another has inherited its parent's settings, parents first. An
+// entry naming an unknown parent or taking part in a cycle is reported and
+// kept as written, so that references to it are not reported as well.
+func resolveExtends[V any](entries map[string]V, path func(string) string, parent func(V) string, inherit func(child, parent V) V, errs *configErrors) map[string]V {
+	if entries == nil {
+		return nil
+	}
+
+	resolved := make(map[string]V, len(entries))
+	failed := make(map[string]bool)
+
+	var resolve func(name string, chain []string) bool
+	resolve = func(name string, chain []string) bool {
+		if failed[name] {
+			return false
+		}
+		if _, ok := resolved[name]; ok {
+			return true
+		}
+
+		v := entries[name]
+		p := parent(v)
+		if p == "" {
+			resolved[name] = v
+			return true
+		}
+
+		extendsPath := joinPath(path(name), "extends")
+
+		if _, ok := entries[p]; !ok {
+			errs.add(extendsPath, "%q is not defined", p)
+			failed[name] = true
+			return false
+		}
+
+		if i := slices.Index(chain, p); i >= 0 {
+			errs.add(extendsPath, "cycle: %s", strings.Join(slices.Concat(chain[i:], []string{p}), " -> "))
+			failed[name] = true
+			return false
+		}
+
+		if !resolve(p, append(chain, p)) {
+			failed[name] = true
+			return false
+		}
+
+		resolved[name] = inherit(v, resolved[p])
+		return true
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(entries)) {
+		if !resolve(name, []string{name}) {
+			resolved[name] = entries[name]
+		}
+	}
+
+	return resolved
+}
+
+// inheritRetry fills the settings child leaves unset from parent. The
+// retry count is inherited as a whole: only when child sets neither
+// MaxRetries nor MaxAttempts.
+func inheritRetry(child, parent Retry) Retry {
+	child.Extends = ""
+
+	inherit(&child.Duration, parent.Duration)
+	inherit(&child.Backoff, parent.Backoff)
+	inherit(&child.MaxInterval, parent.MaxInterval)
+
+	if child.MaxRetries == 0 && child.MaxAttempts == 0 {
+		child.MaxRetries = parent.MaxRetries
+		child.MaxAttempts = parent.MaxAttempts
+	}
+
+	return child
+}
+
+// inheritCircuitBreaker fills every setting child leaves at its zero value
+// from parent. A child cannot switch off CountTimeouts or DryRun its parent
+// sets, and OutlierDetection is inherited as a whole.
+func inheritCircuitBreaker(child, parent CircuitBreaker) CircuitBreaker {
+	child.Extends = ""
+
+	inherit(&child.MaxRequests, parent.MaxRequests)
+	inherit(&child.Interval, parent.Interval)
+	inherit(&child.Timeout, parent.Timeout)
+	inherit(&child.Failures, parent.Failures)
+	inherit(&child.MaxKeys, parent.MaxKeys)
+	inherit(&child.KeyIdleTimeout, parent.KeyIdleTimeout)
+	inherit(&child.RampUp, parent.RampUp)
+	inherit(&child.CountTimeouts, parent.CountTimeouts)
+	inherit(&child.WarmUp, parent.WarmUp)
+	inherit(&child.ProbeTimeout, parent.ProbeTimeout)
+	inherit(&child.DryRun, parent.DryRun)
+	inherit(&child.OutlierDetection, parent.OutlierDetection)
+
+	return child
+}
+
+func inherit[T comparable](field *T, parent T) {
+	var zero T
+	if *field == zero {
+		*field = parent
+	}
+}
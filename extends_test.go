@@ -0,0 +1,107 @@
+package goresilience_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	goresilience "github.com/rickKoch/go-resilience"
+)
+
+func TestRetryExtends(t *testing.T) {
+	provider, err := goresilience.FromConfig(goresilience.Config{
+		Retries: map[string]goresilience.Retry{
+			"base_retry": {Duration: "1ms", MaxRetries: 2},
+			"slow_retry": {Extends: "base_retry", MaxRetries: 4},
+			"slower":     {Extends: "slow_retry", Duration: "2ms"},
+		},
+		Targets: map[string]goresilience.PolicyNames{
+			"base":   {Retry: "base_retry"},
+			"slow":   {Retry: "slow_retry"},
+			"slower": {Retry: "slower"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	want := map[string]goresilience.Retry{
+		"base_retry": {Duration: "1ms", MaxRetries: 2},
+		"slow_retry": {Duration: "1ms", MaxRetries: 4},
+		"slower":     {Duration: "2ms", MaxRetries: 4},
+	}
+	for name, retry := range want {
+		if got := provider.Config().Retries[name]; got != retry {
+			t.Errorf("retry %q: expected %+v, got %+v", name, retry, got)
+		}
+	}
+
+	for target, calls := range map[string]int32{"base": 3, "slow": 5, "slower": 5} {
+		exec := goresilience.NewExecWithPolicy(context.Background(), provider.Policy(target))
+		if got := countAttempts(exec); got != calls {
+			t.Errorf("target %q: expected %d attempts, got %d", target, calls, got)
+		}
+	}
+}
+
+func TestCircuitBreakerExtends(t *testing.T) {
+	provider, err := goresilience.FromConfig(goresilience.Config{
+		CircuitBreakers: map[string]goresilience.CircuitBreaker{
+			"base_cb":   {MaxRequests: 2, Interval: "10s", Timeout: "30s", Failures: 3, CountTimeouts: true},
+			"strict_cb": {Extends: "base_cb", Failures: 1},
+		},
+		Targets: map[string]goresilience.PolicyNames{
+			"test_target": {CircuitBreaker: "strict_cb"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	want := goresilience.CircuitBreaker{MaxRequests: 2, Interval: "10s", Timeout: "30s", Failures: 1, CountTimeouts: true}
+	if got := provider.Config().CircuitBreakers["strict_cb"]; got != want {
+		t.Fatalf("expected %+v, got %+v", want, got)
+	}
+}
+
+func TestExtendsErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		retries map[string]goresilience.Retry
+		path    string
+	}{
+		{
+			name: "cycle",
+			retries: map[string]goresilience.Retry{
+				"a": {Extends: "b"},
+				"b": {Extends: "a"},
+			},
+			path: `retries["b"].extends`,
+		},
+		{
+			name: "self",
+			retries: map[string]goresilience.Retry{
+				"a": {Extends: "a"},
+			},
+			path: `retries["a"].extends`,
+		},
+		{
+			name: "unknown parent",
+			retries: map[string]goresilience.Retry{
+				"a": {Extends: "missing", Duration: "1ms"},
+			},
+			path: `retries["a"].extends`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := goresilience.FromConfig(goresilience.Config{Retries: tt.retries})
+
+			var cfgErr *goresilience.ConfigError
+			if !errors.As(err, &cfgErr) || cfgErr.Path != tt.path {
+				t.Fatalf("expected a ConfigError for %s, got: %v", tt.path, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package goresilience
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+const (
+	FallbackOnOpen = "open"
+	FallbackOnAny  = "any"
+)
+
+type FallbackFunc func(ctx context.Context, err error) (any, error)
+
+type fallback struct {
+	fn    FallbackFunc
+	onAny bool
+}
+
+func parseFallbackOn(val string) (bool, error) {
+	switch val {
+	case "", FallbackOnOpen:
+		return false, nil
+	case FallbackOnAny:
+		return true, nil
+	default:
+		return false, fmt.Errorf("invalid fallbackOn %q: must be %q or %q", val, FallbackOnOpen, FallbackOnAny)
+	}
+}
+
+func (f *fallback) applies(err error) bool {
+	var served *ServedFromCache
+	if err == nil || errors.As(err, &served) {
+		return false
+	}
+
+	if f.onAny {
+		return true
+	}
+
+	return errors.Is(err, ErrOpenState) || errors.Is(err, ErrTooManyRequests)
+}
+
+func (p *Policy) withFallback(oper Operation) Operation {
+	return func(ctx context.Context) (any, error) {
+		res, err := oper(ctx)
+		if !p.fallback.applies(err) {
+			return res, err
+		}
+
+		fbRes, fbErr := p.fallback.fn(ctx, err)
+		if fbErr != nil {
+			traceFailure(ctx, "fallback", "failed: %v", fbErr)
+			return nil, fmt.Errorf("fallback failed: %w: %w", fbErr, err)
+		}
+		traceStep(ctx, "fallback", "served after: %v", err)
+
+		return fbRes, nil
+	}
+}
@@ -0,0 +1,153 @@
+package goresilience_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	goresilience "github.com/rickKoch/go-resilience"
+)
+
+func fallbackConfig(fallbackOn string) goresilience.Config {
+	return goresilience.Config{
+		CircuitBreakers: map[string]goresilience.CircuitBreaker{
+			"test_cb": {
+				MaxRequests: 1,
+				Interval:    "10s",
+				Timeout:     "10s",
+				Failures:    1,
+			},
+		},
+		Targets: map[string]goresilience.PolicyNames{
+			"test_target": {
+				CircuitBreaker: "test_cb",
+				FallbackOn:     fallbackOn,
+			},
+		},
+	}
+}
+
+func TestFallbackOnOpenCircuit(t *testing.T) {
+	provider, err := goresilience.FromConfig(fallbackConfig(""))
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	var fallbackErr error
+	provider.SetFallback("test_target", func(ctx context.Context, err error) (any, error) {
+		fallbackErr = err
+		return "cached", nil
+	})
+
+	exec := goresilience.NewExecutor(context.Background(), provider.Policy("test_target"))
+
+	// The first failure trips the breaker, but is not a rejection, so no fallback.
+	_, err = exec(func(ctx context.Context) (any, error) {
+		return nil, testError
+	})
+	if !errors.Is(err, testError) {
+		t.Fatalf("expected test error, got: %v", err)
+	}
+	if fallbackErr != nil {
+		t.Fatalf("fallback should not run for regular errors, got: %v", fallbackErr)
+	}
+
+	result, err := exec(func(ctx context.Context) (any, error) {
+		t.Error("operation should not be executed when circuit is open")
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatalf("expected fallback result, got error: %v", err)
+	}
+	if result != "cached" {
+		t.Fatalf("expected cached, got %v", result)
+	}
+	if !errors.Is(fallbackErr, goresilience.ErrOpenState) {
+		t.Fatalf("expected fallback to receive ErrOpenState, got: %v", fallbackErr)
+	}
+}
+
+func TestFallbackFailure(t *testing.T) {
+	provider, err := goresilience.FromConfig(fallbackConfig(goresilience.FallbackOnAny))
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	fallbackFailure := errors.New("fallback failure")
+	provider.SetFallback("test_target", func(ctx context.Context, err error) (any, error) {
+		return nil, fallbackFailure
+	})
+
+	exec := goresilience.NewExecutor(context.Background(), provider.Policy("test_target"))
+	result, err := exec(func(ctx context.Context) (any, error) {
+		return nil, testError
+	})
+	if result != nil {
+		t.Fatalf("expected nil result, got %v", result)
+	}
+	if !errors.Is(err, fallbackFailure) {
+		t.Fatalf("expected fallback error, got: %v", err)
+	}
+	if !errors.Is(err, testError) {
+		t.Fatalf("expected original error to be wrapped, got: %v", err)
+	}
+}
+
+func TestFallbackOnAnyError(t *testing.T) {
+	provider, err := goresilience.FromConfig(fallbackConfig(goresilience.FallbackOnAny))
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	provider.SetFallback("test_target", func(ctx context.Context, err error) (any, error) {
+		return "default", nil
+	})
+
+	exec := goresilience.NewExecutor(context.Background(), provider.Policy("test_target"))
+	result, err := exec(func(ctx context.Context) (any, error) {
+		return nil, testError
+	})
+	if err != nil {
+		t.Fatalf("expected fallback result, got error: %v", err)
+	}
+	if result != "default" {
+		t.Fatalf("expected default, got %v", result)
+	}
+}
+
+func TestFallbackOnInvalid(t *testing.T) {
+	_, err := goresilience.FromConfig(fallbackConfig("sometimes"))
+	if err == nil {
+		t.Fatal("expected error for invalid fallbackOn but got none")
+	}
+}
+
+func TestSetFallbackAfterFirstUse(t *testing.T) {
+	provider, err := goresilience.FromConfig(fallbackConfig(goresilience.FallbackOnAny))
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	exec := goresilience.NewExecutor(context.Background(), provider.Policy("test_target"))
+	fail := func(ctx context.Context) (any, error) {
+		return nil, testError
+	}
+
+	if _, err := exec(fail); !errors.Is(err, testError) {
+		t.Fatalf("expected test error before a fallback is set, got: %v", err)
+	}
+
+	provider.SetFallback("test_target", func(ctx context.Context, err error) (any, error) {
+		return "fallback", nil
+	})
+
+	if res, err := exec(fail); err != nil || res != "fallback" {
+		t.Fatalf("expected the new fallback for an executor made before it, got: %v, %v", res, err)
+	}
+
+	provider.SetFallback("test_target", nil)
+
+	if _, err := exec(fail); err == nil {
+		t.Fatal("expected no fallback once it is removed")
+	}
+}
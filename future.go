@@ -0,0 +1,48 @@
+package goresilience
+
+import "context"
+
+// Future is a call started by Go. Its result is delivered exactly once,
+// whether the call finishes on its own or after Cancel.
+type Future struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+	res    any
+	err    error
+}
+
+// Go starts running op under policy in the background and returns at once,
+// so the caller can do other work before waiting for the result or
+// abandoning the call.
+func Go(ctx context.Context, policy *Policy, op Operation) *Future {
+	ctx, cancel := context.WithCancel(ctx)
+
+	f := &Future{cancel: cancel, done: make(chan struct{})}
+	go func() {
+		defer cancel()
+
+		f.res, f.err = policy.Execute(ctx, op)
+		close(f.done)
+	}()
+
+	return f
+}
+
+// Done is closed once the result is available.
+func (f *Future) Done() <-chan struct{} {
+	return f.done
+}
+
+// Result waits for the call to finish and returns its result. It may be
+// called any number of times, and returns the same result each time.
+func (f *Future) Result() (any, error) {
+	<-f.done
+	return f.res, f.err
+}
+
+// Cancel cancels the call's context. The call still delivers a result,
+// usually the context's error, once its operation returns; a call that has
+// already finished keeps its result.
+func (f *Future) Cancel() {
+	f.cancel()
+}
@@ -0,0 +1,94 @@
+package goresilience_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	goresilience "github.com/rickKoch/go-resilience"
+)
+
+func TestGoResult(t *testing.T) {
+	provider, err := goresilience.FromConfig(reloadConfig(2, reloadBreaker))
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	attempts := 0
+	f := goresilience.Go(context.Background(), provider.Policy("test_target"), func(ctx context.Context) (any, error) {
+		attempts++
+		if attempts < 2 {
+			return nil, errors.New("failed")
+		}
+		return "ok", nil
+	})
+
+	select {
+	case <-f.Done():
+	case <-time.After(time.Second):
+		t.Fatal("expected the future to finish")
+	}
+
+	// Every call to Result returns the same result.
+	for i := 0; i < 2; i++ {
+		if res, err := f.Result(); err != nil || res != "ok" {
+			t.Fatalf("call %d: expected the retried result, got %v, %v", i, res, err)
+		}
+	}
+}
+
+func TestGoCancel(t *testing.T) {
+	provider, err := goresilience.FromConfig(reloadConfig(2, reloadBreaker))
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	started := make(chan struct{})
+	f := goresilience.Go(context.Background(), provider.Policy("test_target"), func(ctx context.Context) (any, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+
+	<-started
+	f.Cancel()
+
+	if _, err := f.Result(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the cancelled call's error, got %v", err)
+	}
+
+	// Cancelling again, or after the result, changes nothing.
+	f.Cancel()
+	if _, err := f.Result(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the same result again, got %v", err)
+	}
+}
+
+func TestGoCancelRacesCompletion(t *testing.T) {
+	provider, err := goresilience.FromConfig(reloadConfig(0, reloadBreaker))
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	for i := 0; i < 100; i++ {
+		f := goresilience.Go(context.Background(), provider.Policy("test_target"), succeed)
+
+		var wg sync.WaitGroup
+		results := make([]any, 2)
+		for j := range results {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				results[j], _ = f.Result()
+			}()
+		}
+		f.Cancel()
+		wg.Wait()
+
+		if results[0] != results[1] {
+			t.Fatalf("expected one result for every waiter, got %v", results)
+		}
+	}
+}
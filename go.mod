@@ -5,4 +5,7 @@ go 1.24.4
 require (
 	github.com/cenkalti/backoff/v4 v4.3.0
 	github.com/sony/gobreaker v1.0.0
+	gopkg.in/yaml.v3 v3.0.1
 )
+
+require golang.org/x/sync v0.16.0
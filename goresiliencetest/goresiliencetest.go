@@ -0,0 +1,100 @@
+// Package goresiliencetest helps test code that takes a goresilience
+// Provider, Policy or Executor, without building configs with tiny
+// durations.
+package goresiliencetest
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	goresilience "github.com/rickKoch/go-resilience"
+)
+
+// ErrScriptExhausted is returned by a ScriptedExecutor called more times
+// than it has steps.
+var ErrScriptExhausted = errors.New("goresiliencetest: script exhausted")
+
+// NoopProvider returns a provider whose policies have no stages, so every
+// operation runs once, as is, and panics propagate.
+func NoopProvider() *goresilience.Provider {
+	p, err := goresilience.FromConfigWithOptions(goresilience.Config{}, goresilience.WithPanicRecovery(false))
+	if err != nil {
+		panic(err)
+	}
+
+	return p
+}
+
+// Step is one canned outcome of a ScriptedExecutor.
+type Step struct {
+	Value any
+	Err   error
+}
+
+// Return is a step that succeeds with v.
+func Return(v any) Step {
+	return Step{Value: v}
+}
+
+// Fail is a step that fails with err.
+func Fail(err error) Step {
+	return Step{Err: err}
+}
+
+// ScriptedExecutor returns an executor that ignores its operations and
+// returns steps in order, one per call, then ErrScriptExhausted. It is safe
+// for concurrent use.
+func ScriptedExecutor(steps ...Step) goresilience.Executor {
+	var (
+		mu   sync.Mutex
+		next int
+	)
+
+	return func(goresilience.Operation) (any, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if next == len(steps) {
+			return nil, ErrScriptExhausted
+		}
+
+		step := steps[next]
+		next++
+		return step.Value, step.Err
+	}
+}
+
+// AssertBreakerOpen reports an error through t unless target's circuit
+// breaker is open, and returns whether it is.
+func AssertBreakerOpen(t testing.TB, p *goresilience.Provider, target string) bool {
+	t.Helper()
+	return AssertBreakerState(t, p, target, goresilience.StateOpen)
+}
+
+// AssertBreakerClosed is AssertBreakerOpen for a closed breaker.
+func AssertBreakerClosed(t testing.TB, p *goresilience.Provider, target string) bool {
+	t.Helper()
+	return AssertBreakerState(t, p, target, goresilience.StateClosed)
+}
+
+// AssertBreakerState reports an error through t unless target's circuit
+// breaker is in state want, and returns whether it is. target may be an
+// alias or a group member, and must have a breaker.
+func AssertBreakerState(t testing.TB, p *goresilience.Provider, target string, want goresilience.State) bool {
+	t.Helper()
+
+	d, ok := p.Describe(target)
+	if !ok || d.CircuitBreaker == nil {
+		t.Errorf("target %q has no circuit breaker", target)
+		return false
+	}
+
+	got, _ := p.BreakerState(d.CircuitBreaker.Name)
+	if got != want {
+		t.Errorf("expected the circuit breaker %q of target %q to be %s, got %s", d.CircuitBreaker.Name, target, want, got)
+		return false
+	}
+
+	return true
+}
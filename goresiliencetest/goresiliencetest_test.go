@@ -0,0 +1,120 @@
+package goresiliencetest_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	goresilience "github.com/rickKoch/go-resilience"
+	"github.com/rickKoch/go-resilience/goresiliencetest"
+)
+
+var errUnavailable = errors.New("unavailable")
+
+// userService is the kind of code under test: it takes an executor.
+type userService struct {
+	exec goresilience.Executor
+}
+
+func (s userService) Name(id string) (string, error) {
+	res, err := s.exec(func(ctx context.Context) (any, error) {
+		return nil, errors.New("the real backend is not called in tests")
+	})
+	if err != nil {
+		return "", fmt.Errorf("user %s: %w", id, err)
+	}
+
+	return res.(string), nil
+}
+
+func TestScriptedExecutor(t *testing.T) {
+	svc := userService{exec: goresiliencetest.ScriptedExecutor(
+		goresiliencetest.Return("ada"),
+		goresiliencetest.Fail(errUnavailable),
+	)}
+
+	if name, err := svc.Name("1"); err != nil || name != "ada" {
+		t.Fatalf("expected the first step, got %q, %v", name, err)
+	}
+	if _, err := svc.Name("2"); !errors.Is(err, errUnavailable) {
+		t.Fatalf("expected the second step's error, got %v", err)
+	}
+	if _, err := svc.Name("3"); !errors.Is(err, goresiliencetest.ErrScriptExhausted) {
+		t.Fatalf("expected the script to be exhausted, got %v", err)
+	}
+}
+
+func TestNoopProvider(t *testing.T) {
+	p := goresiliencetest.NoopProvider()
+
+	calls := 0
+	_, err := goresilience.NewExecutor(context.Background(), p.Policy("users"))(func(ctx context.Context) (any, error) {
+		calls++
+		return nil, errUnavailable
+	})
+	if !errors.Is(err, errUnavailable) || calls != 1 {
+		t.Fatalf("expected one call passed straight through, got %d calls and %v", calls, err)
+	}
+
+	defer func() {
+		if r := recover(); r != "boom" {
+			t.Fatalf("expected the panic to propagate, got %v", r)
+		}
+	}()
+	_, _ = goresilience.NewExecutor(context.Background(), p.Policy("users"))(func(ctx context.Context) (any, error) {
+		panic("boom")
+	})
+}
+
+func TestAssertBreaker(t *testing.T) {
+	p, err := goresilience.FromConfig(goresilience.Config{
+		CircuitBreakers: map[string]goresilience.CircuitBreaker{
+			"users_cb": {MaxRequests: 1, Interval: "10s", Timeout: "10s", Failures: 1},
+		},
+		Targets: map[string]goresilience.PolicyNames{
+			"users": {CircuitBreaker: "users_cb"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	goresiliencetest.AssertBreakerClosed(t, p, "users")
+
+	_, _ = goresilience.NewExecutor(context.Background(), p.Policy("users"))(func(ctx context.Context) (any, error) {
+		return nil, errUnavailable
+	})
+
+	goresiliencetest.AssertBreakerOpen(t, p, "users")
+
+	rec := &recorder{TB: t}
+	if goresiliencetest.AssertBreakerClosed(rec, p, "users") {
+		t.Fatal("expected the assertion to fail for an open breaker")
+	}
+	if goresiliencetest.AssertBreakerOpen(rec, p, "unknown") {
+		t.Fatal("expected the assertion to fail for a target without a breaker")
+	}
+
+	want := []string{
+		`expected the circuit breaker "users_cb" of target "users" to be closed, got open`,
+		`target "unknown" has no circuit breaker`,
+	}
+	if got := strings.Join(rec.errors, "\n"); got != strings.Join(want, "\n") {
+		t.Fatalf("expected errors\n%s\ngot\n%s", strings.Join(want, "\n"), got)
+	}
+}
+
+// recorder collects the errors an assertion reports instead of failing
+// the test.
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,115 @@
+package goresilience
+
+import (
+	"fmt"
+	"maps"
+	"path"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+)
+
+// group is a built TargetGroup.
+type group struct {
+	name    string
+	members []string
+	target  target
+}
+
+// buildGroups builds cfg.Groups in name order, which is the order
+// groupTarget tries them in.
+func (s *providerState) buildGroups(cfg Config, unit time.Duration, errs *configErrors) []group {
+	var groups []group
+
+	for _, name := range slices.Sorted(maps.Keys(cfg.Groups)) {
+		g := cfg.Groups[name]
+		groupPath := entryPath("groups", name)
+
+		if g.RetrySpec != nil {
+			errs.add(joinPath(groupPath, "retrySpec"), "is not supported in groups, reference a retry by name")
+		}
+		if g.CircuitBreakerSpec != nil {
+			errs.add(joinPath(groupPath, "circuitBreakerSpec"), "is not supported in groups, reference a circuit breaker by name")
+		}
+
+		if len(g.Members) == 0 {
+			errs.add(joinPath(groupPath, "members"), "group has no members")
+		}
+
+		for i, member := range g.Members {
+			if _, err := path.Match(member, ""); err != nil {
+				errs.add(fmt.Sprintf("%s.members[%d]", groupPath, i), "invalid pattern %q: %v", member, err)
+			}
+		}
+
+		groups = append(groups, group{
+			name:    name,
+			members: slices.Clone(g.Members),
+			target:  s.buildTarget(cfg, fmt.Sprintf("group %q", name), groupPath, g.PolicyNames, unit, errs),
+		})
+	}
+
+	errs.nest("", validateGroupConflicts(cfg))
+
+	return groups
+}
+
+// groupOf returns the first group name is a member of.
+func (s *providerState) groupOf(name string) (group, bool) {
+	for _, g := range s.groups {
+		if memberOf(g.members, name) {
+			return g, true
+		}
+	}
+
+	return group{}, false
+}
+
+func memberOf(members []string, name string) bool {
+	return slices.ContainsFunc(members, func(member string) bool {
+		ok, _ := path.Match(member, name)
+		return ok
+	})
+}
+
+// validateGroupConflicts reports every target listed by name in one group
+// that another group also covers with different policies. A target with its
+// own entry is exempt, since the entry takes precedence over both groups.
+func validateGroupConflicts(cfg Config) error {
+	var errs configErrors
+
+	names := slices.Sorted(maps.Keys(cfg.Groups))
+	for _, name := range names {
+		for i, member := range cfg.Groups[name].Members {
+			if isPattern(member) {
+				continue
+			}
+			if _, ok := cfg.Targets[member]; ok {
+				continue
+			}
+
+			for _, other := range names {
+				g := cfg.Groups[other]
+				if other == name || !memberOf(g.Members, member) {
+					continue
+				}
+
+				// Both list the target by name; report it once, at the latter.
+				if other > name && slices.Contains(g.Members, member) {
+					continue
+				}
+
+				if !reflect.DeepEqual(g.PolicyNames, cfg.Groups[name].PolicyNames) {
+					errs.add(fmt.Sprintf("%s.members[%d]", entryPath("groups", name), i), "%q is also a member of group %q, which has different policies", member, other)
+				}
+			}
+		}
+	}
+
+	return errs.err()
+}
+
+func isPattern(member string) bool {
+	return strings.ContainsAny(member, `*?[\`)
+}
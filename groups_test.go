@@ -0,0 +1,150 @@
+package goresilience_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	goresilience "github.com/rickKoch/go-resilience"
+)
+
+func TestGroupMembership(t *testing.T) {
+	provider, err := goresilience.FromConfig(goresilience.Config{
+		Retries: map[string]goresilience.Retry{
+			"std":  {Duration: "1ms", MaxRetries: 2},
+			"once": {Duration: "1ms", MaxRetries: 1},
+		},
+		Targets: map[string]goresilience.PolicyNames{
+			"db.audit": {Retry: "once"},
+		},
+		Groups: map[string]goresilience.TargetGroup{
+			"databases": {
+				PolicyNames: goresilience.PolicyNames{Retry: "std"},
+				Members:     []string{"users-db", "db.*"},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	for target, want := range map[string]int32{
+		"users-db":  3, // listed by name
+		"db.orders": 3, // matched by pattern
+		"db.audit":  2, // own entry takes precedence
+		"cache":     1, // not a member
+	} {
+		exec := goresilience.NewExecWithPolicy(context.Background(), provider.Policy(target))
+		if calls := countAttempts(exec); calls != want {
+			t.Errorf("%s: expected %d attempts, got %d", target, want, calls)
+		}
+	}
+}
+
+func TestGroupValidation(t *testing.T) {
+	std := goresilience.PolicyNames{Retry: "std"}
+	fast := goresilience.PolicyNames{Retry: "fast"}
+
+	tests := []struct {
+		name       string
+		targets    map[string]goresilience.PolicyNames
+		groups     map[string]goresilience.TargetGroup
+		expectPath string
+	}{
+		{
+			name: "conflicting members",
+			groups: map[string]goresilience.TargetGroup{
+				"a": {PolicyNames: std, Members: []string{"users"}},
+				"b": {PolicyNames: fast, Members: []string{"users"}},
+			},
+			expectPath: `groups["b"].members[0]`,
+		},
+		{
+			name: "conflicting pattern",
+			groups: map[string]goresilience.TargetGroup{
+				"a": {PolicyNames: std, Members: []string{"db.users"}},
+				"b": {PolicyNames: fast, Members: []string{"db.*"}},
+			},
+			expectPath: `groups["a"].members[0]`,
+		},
+		{
+			name: "undefined retry",
+			groups: map[string]goresilience.TargetGroup{
+				"a": {PolicyNames: goresilience.PolicyNames{Retry: "slow"}, Members: []string{"users"}},
+			},
+			expectPath: `groups["a"].retry`,
+		},
+		{
+			name: "invalid pattern",
+			groups: map[string]goresilience.TargetGroup{
+				"a": {PolicyNames: std, Members: []string{"db.["}},
+			},
+			expectPath: `groups["a"].members[0]`,
+		},
+		{
+			name: "no members",
+			groups: map[string]goresilience.TargetGroup{
+				"a": {PolicyNames: std},
+			},
+			expectPath: `groups["a"].members`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := goresilience.FromConfig(goresilience.Config{
+				Retries: map[string]goresilience.Retry{
+					"std":  {Duration: "1ms", MaxRetries: 2},
+					"fast": {Duration: "1ms", MaxRetries: 1},
+				},
+				Targets: tt.targets,
+				Groups:  tt.groups,
+			})
+
+			var cfgErr *goresilience.ConfigError
+			if !errors.As(err, &cfgErr) || cfgErr.Path != tt.expectPath {
+				t.Fatalf("expected a ConfigError at %s, got: %v", tt.expectPath, err)
+			}
+		})
+	}
+}
+
+func TestGroupOverlapAllowed(t *testing.T) {
+	std := goresilience.PolicyNames{Retry: "std"}
+
+	_, err := goresilience.FromConfig(goresilience.Config{
+		Retries: map[string]goresilience.Retry{
+			"std":  {Duration: "1ms", MaxRetries: 2},
+			"fast": {Duration: "1ms", MaxRetries: 1},
+		},
+		Targets: map[string]goresilience.PolicyNames{
+			"orders": {Retry: "fast"},
+		},
+		Groups: map[string]goresilience.TargetGroup{
+			"a": {PolicyNames: std, Members: []string{"users", "orders"}},
+			"b": {PolicyNames: std, Members: []string{"users"}},
+			"c": {PolicyNames: goresilience.PolicyNames{Retry: "fast"}, Members: []string{"orders"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("expected groups with the same policies or an own entry to be allowed, got: %v", err)
+	}
+}
+
+func TestGroupYAML(t *testing.T) {
+	cfg, err := goresilience.ParseConfigYAML([]byte(`
+groups:
+  databases:
+    retry: std
+    timeout: 2s
+    members: [users-db, "db.*"]
+`))
+	if err != nil {
+		t.Fatalf("failed to parse config: %v", err)
+	}
+
+	g := cfg.Groups["databases"]
+	if g.Retry != "std" || g.Timeout != "2s" || len(g.Members) != 2 {
+		t.Fatalf("expected the group's policies inline with its members, got %+v", g)
+	}
+}
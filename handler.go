@@ -0,0 +1,100 @@
+package goresilience
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+)
+
+// ProviderStatus is the document StatusHandler serves. Targets holds every
+// configured target, and any other name that has executed, by canonical
+// name.
+type ProviderStatus struct {
+	Targets  map[string]TargetStatus `json:"targets"`
+	Breakers []BreakerStatus         `json:"breakers"`
+}
+
+// TargetStatus is what Describe, Health and Metrics report for a target.
+// Policy is nil for a name that only executed, and Metrics for a target
+// that has not.
+type TargetStatus struct {
+	Policy  *PolicyDescription `json:"policy,omitempty"`
+	Health  TargetHealth       `json:"health"`
+	Metrics *TargetMetrics     `json:"metrics,omitempty"`
+}
+
+// StatusHandler serves the provider's ProviderStatus as JSON, with every
+// breaker:
+//
+//	mux.Handle("/resilience", provider.StatusHandler())
+//
+// Each target query parameter, such as ?target=payments, limits the
+// document to that target, which may be an alias, and its breaker. A
+// target the provider does not know is answered with 404.
+func (p *Provider) StatusHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		status, err := p.status(r.URL.Query()["target"])
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(status)
+	})
+}
+
+// status gathers the ProviderStatus of targets, or of every target if
+// there are none.
+func (p *Provider) status(targets []string) (ProviderStatus, error) {
+	health := p.Health()
+	metrics := p.Metrics()
+
+	s := ProviderStatus{Targets: make(map[string]TargetStatus), Breakers: []BreakerStatus{}}
+
+	filtered := len(targets) > 0
+	if !filtered {
+		for name := range health.Targets {
+			targets = append(targets, name)
+		}
+	}
+
+	var breakers []string
+	for _, name := range targets {
+		var t TargetStatus
+		if d, ok := p.Describe(name); ok {
+			name = d.Target
+			t.Policy = &d
+		}
+
+		h, ok := health.Targets[name]
+		if !ok && t.Policy == nil {
+			return ProviderStatus{}, fmt.Errorf("unknown target %q", name)
+		}
+		t.Health = h
+
+		if m, ok := metrics.Targets[name]; ok {
+			t.Metrics = &m
+		}
+
+		if t.Policy != nil && t.Policy.CircuitBreaker != nil {
+			breakers = append(breakers, t.Policy.CircuitBreaker.Name)
+		}
+		s.Targets[name] = t
+	}
+
+	for _, b := range p.BreakerStatuses() {
+		if !filtered || slices.Contains(breakers, b.Name) {
+			s.Breakers = append(s.Breakers, b)
+		}
+	}
+
+	return s, nil
+}
@@ -0,0 +1,133 @@
+package goresilience_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	goresilience "github.com/rickKoch/go-resilience"
+)
+
+func TestStatusHandler(t *testing.T) {
+	cfg := reloadConfig(1, reloadBreaker)
+	cfg.CircuitBreakers["other_cb"] = reloadBreaker
+	cfg.Targets["other_target"] = goresilience.PolicyNames{CircuitBreaker: "other_cb"}
+	cfg.Aliases = map[string][]string{"test_target": {"test_alias"}}
+
+	provider, err := goresilience.FromConfig(cfg)
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	exec := goresilience.NewExecutor(context.Background(), provider.Policy("test_target"))
+	_, _ = exec(succeed)
+	countAttempts(exec)
+
+	server := httptest.NewServer(provider.StatusHandler())
+	defer server.Close()
+
+	type status struct {
+		Targets map[string]struct {
+			Policy *struct {
+				Target string `json:"target"`
+				Retry  *struct {
+					Name       string `json:"name"`
+					MaxRetries int    `json:"maxRetries"`
+				} `json:"retry"`
+			} `json:"policy"`
+			Health struct {
+				Breaker          string  `json:"breaker"`
+				FailureRatio     float64 `json:"failureRatio"`
+				RecentExecutions int     `json:"recentExecutions"`
+			} `json:"health"`
+			Metrics *struct {
+				Executions uint64 `json:"executions"`
+				Failures   uint64 `json:"failures"`
+				Retries    uint64 `json:"retries"`
+			} `json:"metrics"`
+		} `json:"targets"`
+		Breakers []struct {
+			Name   string `json:"name"`
+			State  string `json:"state"`
+			Counts struct {
+				Requests uint32 `json:"requests"`
+			} `json:"counts"`
+		} `json:"breakers"`
+	}
+
+	get := func(query string) (status, *http.Response) {
+		t.Helper()
+
+		resp, err := http.Get(server.URL + query)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		defer resp.Body.Close()
+
+		var s status
+		if resp.StatusCode == http.StatusOK {
+			if got := resp.Header.Get("Content-Type"); got != "application/json" {
+				t.Fatalf("expected JSON, got %q", got)
+			}
+			if err := json.NewDecoder(resp.Body).Decode(&s); err != nil {
+				t.Fatalf("failed to decode status: %v", err)
+			}
+		}
+		return s, resp
+	}
+
+	s, resp := get("")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+
+	if len(s.Targets) != 2 {
+		t.Fatalf("expected both targets, got %+v", s.Targets)
+	}
+
+	target := s.Targets["test_target"]
+	if target.Policy == nil || target.Policy.Retry == nil || target.Policy.Retry.Name != "test_retry" || target.Policy.Retry.MaxRetries != 1 {
+		t.Fatalf("expected test_target's resolved retry, got %+v", target.Policy)
+	}
+	if target.Health.Breaker != "test_cb" || target.Health.FailureRatio != 0.5 || target.Health.RecentExecutions != 2 {
+		t.Fatalf("expected test_target's health, got %+v", target.Health)
+	}
+	if target.Metrics == nil || target.Metrics.Executions != 2 || target.Metrics.Failures != 1 || target.Metrics.Retries != 1 {
+		t.Fatalf("expected test_target's metrics, got %+v", target.Metrics)
+	}
+	if other := s.Targets["other_target"]; other.Policy == nil || other.Metrics != nil {
+		t.Fatalf("expected other_target's policy and no metrics before it executes, got %+v", other)
+	}
+
+	var breakers []string
+	for _, b := range s.Breakers {
+		breakers = append(breakers, b.Name)
+	}
+	if !slices.Equal(breakers, []string{"other_cb", "test_cb"}) {
+		t.Fatalf("expected every breaker, got %q", breakers)
+	}
+
+	s, _ = get("?target=test_alias")
+	if _, ok := s.Targets["test_target"]; !ok || len(s.Targets) != 1 {
+		t.Fatalf("expected only test_target, got %+v", s.Targets)
+	}
+	if len(s.Breakers) != 1 || s.Breakers[0].Name != "test_cb" || s.Breakers[0].State != "closed" || s.Breakers[0].Counts.Requests != 3 {
+		t.Fatalf("expected only test_cb, closed after 3 requests, got %+v", s.Breakers)
+	}
+
+	if _, resp := get("?target=unknown_target"); resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected 404 for an unknown target, got %d", resp.StatusCode)
+	}
+
+	resp, err = http.Post(server.URL, "application/json", nil)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Fatalf("expected 405 for a POST, got %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,83 @@
+package goresilience
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+var ErrUnhealthy = errors.New("target unhealthy")
+
+const defaultHealthInterval = 10 * time.Second
+
+type HealthCheck func(ctx context.Context) error
+
+// healthGate holds the latest result of a target's health check, polled in
+// the background so that executions never wait on a check.
+type healthGate struct {
+	interval time.Duration
+	failure  atomic.Pointer[error]
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+func startHealthGate(fn HealthCheck, interval time.Duration) *healthGate {
+	ctx, cancel := context.WithCancel(context.Background())
+	g := &healthGate{interval: interval, cancel: cancel, done: make(chan struct{})}
+
+	go g.poll(ctx, fn)
+
+	return g
+}
+
+func (g *healthGate) poll(ctx context.Context, fn HealthCheck) {
+	defer close(g.done)
+
+	ticker := time.NewTicker(g.interval)
+	defer ticker.Stop()
+
+	for {
+		g.check(ctx, fn)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (g *healthGate) check(ctx context.Context, fn HealthCheck) {
+	checkCtx, cancel := context.WithTimeout(ctx, g.interval)
+	defer cancel()
+
+	err := fn(checkCtx)
+	if ctx.Err() != nil {
+		return
+	}
+
+	if err != nil {
+		g.failure.Store(&err)
+		return
+	}
+
+	g.failure.Store(nil)
+}
+
+// stop cancels the polling and waits for the poller to return.
+func (g *healthGate) stop() {
+	g.cancel()
+	<-g.done
+}
+
+func (p *Policy) withHealthGate(oper Operation) Operation {
+	return func(ctx context.Context) (any, error) {
+		if failure := p.health.failure.Load(); failure != nil {
+			return nil, p.rejected(ctx, "health", fmt.Errorf("%w: %q: %w", ErrUnhealthy, p.target, *failure))
+		}
+
+		return oper(ctx)
+	}
+}
@@ -0,0 +1,169 @@
+package goresilience_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	goresilience "github.com/rickKoch/go-resilience"
+)
+
+var errHealth = errors.New("health endpoint red")
+
+func healthProvider(t *testing.T) *goresilience.Provider {
+	t.Helper()
+
+	provider, err := goresilience.FromConfig(goresilience.Config{
+		Targets: map[string]goresilience.PolicyNames{
+			"test_target": {},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+	t.Cleanup(func() { _ = provider.Close() })
+
+	return provider
+}
+
+func healthCall(provider *goresilience.Provider) error {
+	exec := goresilience.NewExecWithPolicy(context.Background(), provider.Policy("test_target"))
+	_, err := exec(func(ctx context.Context) (any, error) {
+		return successResult, nil
+	})
+	return err
+}
+
+func waitForHealth(t *testing.T, provider *goresilience.Provider, healthy bool) error {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		err := healthCall(provider)
+		if (err == nil) == healthy {
+			return err
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for healthy %v, last error: %v", healthy, err)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestHealthGate(t *testing.T) {
+	provider := healthProvider(t)
+
+	var failing atomic.Bool
+	provider.SetHealthCheck("test_target", func(ctx context.Context) error {
+		if failing.Load() {
+			return errHealth
+		}
+		return nil
+	}, 5*time.Millisecond)
+
+	waitForHealth(t, provider, true)
+
+	failing.Store(true)
+	err := waitForHealth(t, provider, false)
+	if !errors.Is(err, goresilience.ErrUnhealthy) || !errors.Is(err, errHealth) {
+		t.Fatalf("expected ErrUnhealthy wrapping the check error, got: %v", err)
+	}
+
+	failing.Store(false)
+	waitForHealth(t, provider, true)
+}
+
+func TestHealthGateDoesNotBlockOnSlowCheck(t *testing.T) {
+	provider := healthProvider(t)
+
+	provider.SetHealthCheck("test_target", func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	}, time.Second)
+
+	start := time.Now()
+	if err := healthCall(provider); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Fatalf("expected the call not to wait for the check, took %s", elapsed)
+	}
+}
+
+func TestHealthGateRemoved(t *testing.T) {
+	provider := healthProvider(t)
+
+	provider.SetHealthCheck("test_target", func(ctx context.Context) error {
+		return errHealth
+	}, 5*time.Millisecond)
+	waitForHealth(t, provider, false)
+
+	provider.SetHealthCheck("test_target", nil, 0)
+	if err := healthCall(provider); err != nil {
+		t.Fatalf("expected the gate to be removed, got: %v", err)
+	}
+}
+
+func TestHealthGateReplaced(t *testing.T) {
+	provider := healthProvider(t)
+	policy := provider.Policy("test_target")
+
+	call := func() error {
+		_, err := policy.Execute(context.Background(), succeed)
+		return err
+	}
+
+	var checks atomic.Int32
+	provider.SetHealthCheck("test_target", func(ctx context.Context) error {
+		checks.Add(1)
+		return errHealth
+	}, time.Millisecond)
+
+	deadline := time.Now().Add(time.Second)
+	for call() == nil {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the held policy to see the failing check")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	provider.SetHealthCheck("test_target", func(ctx context.Context) error {
+		return nil
+	}, time.Millisecond)
+	stopped := checks.Load()
+
+	if err := call(); err != nil {
+		t.Fatalf("expected the held policy to use the new check, got: %v", err)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	if checks.Load() != stopped {
+		t.Fatalf("expected the replaced check to have stopped, got %d more", checks.Load()-stopped)
+	}
+}
+
+func TestCloseStopsHealthChecks(t *testing.T) {
+	provider := healthProvider(t)
+
+	var checks atomic.Int32
+	provider.SetHealthCheck("test_target", func(ctx context.Context) error {
+		checks.Add(1)
+		return nil
+	}, time.Millisecond)
+
+	for checks.Load() < 3 {
+		time.Sleep(time.Millisecond)
+	}
+
+	if err := provider.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	stopped := checks.Load()
+	time.Sleep(20 * time.Millisecond)
+	if checks.Load() != stopped {
+		t.Fatalf("expected no checks after Close, got %d more", checks.Load()-stopped)
+	}
+}
@@ -0,0 +1,66 @@
+package goresilience
+
+import (
+	"context"
+	"slices"
+	"time"
+)
+
+// Interceptor is a pair of callbacks around each attempt of an operation,
+// for request-scoped instrumentation such as recording a downstream's
+// latency without the backoff sleeps in between. Before may return a
+// context for the attempt, or nil to keep ctx; After sees the attempt's
+// result and how long it took. Neither can change the result. Either may be
+// nil.
+type Interceptor struct {
+	Before func(ctx context.Context, target string) context.Context
+	After  func(ctx context.Context, target string, value any, err error, d time.Duration)
+}
+
+// Intercept adds interceptors to target, or to every target if target is
+// empty. They run once per attempt, right around the operation. Global
+// interceptors run outside a target's own, and earlier ones outside later
+// ones: Before in that order, After in reverse.
+func (p *Provider) Intercept(target string, interceptors ...Interceptor) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	defer p.settingsChanged()
+
+	p.interceptors[target] = append(slices.Clip(p.interceptors[target]), interceptors...)
+}
+
+// interceptorsFor returns the interceptors target gets, outermost first.
+// The caller holds mu.
+func (p *Provider) interceptorsFor(target string) []Interceptor {
+	if target == "" {
+		return slices.Clone(p.interceptors[""])
+	}
+
+	return slices.Concat(p.interceptors[""], p.interceptors[target])
+}
+
+func (p *Policy) withInterceptors(oper Operation) Operation {
+	return func(ctx context.Context) (any, error) {
+		for _, i := range p.interceptors {
+			if i.Before == nil {
+				continue
+			}
+			if c := i.Before(ctx, p.target); c != nil {
+				ctx = c
+			}
+		}
+
+		start := time.Now()
+		res, err := oper(ctx)
+		d := time.Since(start)
+
+		for _, i := range slices.Backward(p.interceptors) {
+			if i.After != nil {
+				i.After(ctx, p.target, res, err, d)
+			}
+		}
+
+		return res, err
+	}
+}
@@ -0,0 +1,109 @@
+package goresilience_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	goresilience "github.com/rickKoch/go-resilience"
+)
+
+type attemptKey struct{}
+
+func TestInterceptorsRunPerAttempt(t *testing.T) {
+	cfg := reloadConfig(2, reloadBreaker)
+	cfg.Retries["test_retry"] = goresilience.Retry{Duration: "50ms", MaxRetries: 2}
+
+	provider, err := goresilience.FromConfig(cfg)
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	var (
+		calls     []string
+		durations []time.Duration
+	)
+	provider.Intercept("", goresilience.Interceptor{
+		Before: func(ctx context.Context, target string) context.Context {
+			calls = append(calls, "global before "+target)
+			return nil
+		},
+		After: func(ctx context.Context, target string, value any, err error, d time.Duration) {
+			calls = append(calls, "global after")
+		},
+	})
+	provider.Intercept("test_target", goresilience.Interceptor{
+		Before: func(ctx context.Context, target string) context.Context {
+			calls = append(calls, "target before")
+			return context.WithValue(ctx, attemptKey{}, len(calls))
+		},
+		After: func(ctx context.Context, target string, value any, err error, d time.Duration) {
+			calls = append(calls, "target after")
+			durations = append(durations, d)
+		},
+	})
+
+	attempt := 0
+	res, err := goresilience.NewExecutor(context.Background(), provider.Policy("test_target"))(func(ctx context.Context) (any, error) {
+		attempt++
+		if ctx.Value(attemptKey{}) == nil {
+			t.Error("expected the context returned by Before to reach the operation")
+		}
+		if attempt < 3 {
+			return nil, errors.New("failed")
+		}
+		return "ok", nil
+	})
+	if err != nil || res != "ok" {
+		t.Fatalf("expected the third attempt to succeed unchanged, got %v, %v", res, err)
+	}
+
+	var want []string
+	for i := 0; i < 3; i++ {
+		want = append(want, "global before test_target", "target before", "target after", "global after")
+	}
+	if !reflect.DeepEqual(calls, want) {
+		t.Fatalf("expected calls\n%q\ngot\n%q", want, calls)
+	}
+
+	// The backoffs between attempts are not part of any attempt.
+	for _, d := range durations {
+		if d >= 50*time.Millisecond {
+			t.Fatalf("expected attempt durations without the backoff, got %v", durations)
+		}
+	}
+}
+
+func TestInterceptorSeesResult(t *testing.T) {
+	provider, err := goresilience.FromConfig(reloadConfig(0, reloadBreaker))
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	var (
+		gotValue any
+		gotErr   error
+	)
+	provider.Intercept("test_target", goresilience.Interceptor{
+		After: func(ctx context.Context, target string, value any, err error, d time.Duration) {
+			gotValue, gotErr = value, err
+		},
+	})
+
+	_, _ = goresilience.NewExecutor(context.Background(), provider.Policy("other_target"))(succeed)
+	if gotValue != nil {
+		t.Fatalf("expected another target's call not to be intercepted, got %v", gotValue)
+	}
+
+	_, err = goresilience.NewExecutor(context.Background(), provider.Policy("test_target"))(func(ctx context.Context) (any, error) {
+		return "partial", errQuotaExceeded
+	})
+	if gotValue != "partial" || gotErr != errQuotaExceeded {
+		t.Fatalf("expected After to see the attempt's result, got %v, %v", gotValue, gotErr)
+	}
+	if !errors.Is(err, errQuotaExceeded) {
+		t.Fatalf("expected the error to be returned, got %v", err)
+	}
+}
@@ -0,0 +1,93 @@
+package goresilience
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// isoUnits lists the ISO-8601 duration designators in the order they must
+// appear. Years, months and weeks are recognised only to reject them, as
+// they have no fixed length. MS is a common extension for milliseconds.
+var isoUnits = []struct {
+	designator string
+	timePart   bool
+	unit       time.Duration
+}{
+	{"Y", false, 0},
+	{"M", false, 0},
+	{"W", false, 0},
+	{"D", false, 24 * time.Hour},
+	{"H", true, time.Hour},
+	{"M", true, time.Minute},
+	{"S", true, time.Second},
+	{"MS", true, time.Millisecond},
+}
+
+// parseISODuration parses an ISO-8601 duration such as "PT1M30S",
+// "PT0.5S" or "PT500MS". Days are taken as 24 hours.
+func parseISODuration(val string) (time.Duration, error) {
+	rest, ok := strings.CutPrefix(val, "P")
+	if !ok || rest == "" || rest == "T" {
+		return 0, fmt.Errorf("invalid ISO-8601 duration %q", val)
+	}
+
+	var total float64
+	timePart := false
+	next := 0
+
+	for rest != "" {
+		if rest[0] == 'T' {
+			if timePart {
+				return 0, fmt.Errorf("invalid ISO-8601 duration %q: repeated T", val)
+			}
+			timePart = true
+			rest = rest[1:]
+			if rest == "" {
+				return 0, fmt.Errorf("invalid ISO-8601 duration %q: nothing after T", val)
+			}
+			continue
+		}
+
+		end := strings.IndexFunc(rest, func(r rune) bool {
+			return (r < '0' || r > '9') && r != '.'
+		})
+		if end <= 0 {
+			return 0, fmt.Errorf("invalid ISO-8601 duration %q: expected a number", val)
+		}
+
+		n, err := strconv.ParseFloat(rest[:end], 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid ISO-8601 duration %q: %w", val, err)
+		}
+		rest = rest[end:]
+
+		i := next
+		for ; i < len(isoUnits); i++ {
+			u := isoUnits[i]
+			if u.timePart == timePart && strings.HasPrefix(rest, u.designator) && !(u.designator == "M" && strings.HasPrefix(rest, "MS")) {
+				break
+			}
+		}
+		if i == len(isoUnits) {
+			return 0, fmt.Errorf("invalid ISO-8601 duration %q: unexpected %q", val, rest)
+		}
+
+		u := isoUnits[i]
+		if u.unit == 0 {
+			return 0, fmt.Errorf("unsupported ISO-8601 duration %q: years, months and weeks have no fixed length", val)
+		}
+
+		total += n * float64(u.unit)
+		rest = rest[len(u.designator):]
+		next = i + 1
+	}
+
+	if total > math.MaxInt64 {
+		return 0, fmt.Errorf("invalid ISO-8601 duration %q: out of range", val)
+	}
+
+	return time.Duration(math.Round(total)), nil
+}
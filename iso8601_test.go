@@ -0,0 +1,70 @@
+package goresilience_test
+
+import (
+	"testing"
+	"time"
+
+	goresilience "github.com/rickKoch/go-resilience"
+)
+
+func isoRetryProvider(duration string) (*goresilience.Provider, error) {
+	return goresilience.FromConfig(goresilience.Config{
+		Retries: map[string]goresilience.Retry{
+			"test_retry": {Duration: duration, MaxRetries: 1},
+		},
+	})
+}
+
+func TestISO8601Durations(t *testing.T) {
+	tests := []struct {
+		iso  string
+		want time.Duration
+	}{
+		{"PT1M30S", 90 * time.Second},
+		{"PT500MS", 500 * time.Millisecond},
+		{"PT0.5S", 500 * time.Millisecond},
+		{"PT1.25S", 1250 * time.Millisecond},
+		{"PT2H", 2 * time.Hour},
+		{"PT1H2M3S", time.Hour + 2*time.Minute + 3*time.Second},
+		{"PT1S250MS", 1250 * time.Millisecond},
+		{"P1D", 24 * time.Hour},
+		{"P1DT12H", 36 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.iso, func(t *testing.T) {
+			provider, err := isoRetryProvider(tt.iso)
+			if err != nil {
+				t.Fatalf("failed to create provider: %v", err)
+			}
+
+			if got := provider.Config().Retries["test_retry"].Duration; got != tt.want.String() {
+				t.Fatalf("expected %v, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestISO8601DurationsInvalid(t *testing.T) {
+	for _, iso := range []string{
+		"P",
+		"PT",
+		"P1Y",
+		"P2M",
+		"P1W",
+		"PT1D",
+		"P1H",
+		"PTS",
+		"PT1S1M",
+		"PT1M1M",
+		"PT1X",
+		"P1DT",
+		"PT1.2.3S",
+	} {
+		t.Run(iso, func(t *testing.T) {
+			if _, err := isoRetryProvider(iso); err == nil {
+				t.Fatalf("expected %q to be rejected", iso)
+			}
+		})
+	}
+}
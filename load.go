@@ -0,0 +1,127 @@
+package goresilience
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LoadConfigFile reads a config from a .yaml, .yml or .json file. Unknown
+// keys are errors, so that a misspelled section is not silently ignored.
+func LoadConfigFile(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, err
+	}
+
+	return parseConfigFile(path, data)
+}
+
+func parseConfigFile(path string, data []byte) (Config, error) {
+	var cfg Config
+	var err error
+
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		cfg, err = ParseConfigYAML(data)
+	case ".json":
+		cfg, err = ParseConfigJSON(data)
+	default:
+		return Config{}, fmt.Errorf("unsupported config file extension %q for %s: must be .yaml, .yml or .json", ext, path)
+	}
+	if err != nil {
+		return Config{}, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return cfg, nil
+}
+
+// ParseConfigYAML decodes a YAML config, rejecting unknown keys. Each
+// unknown key is reported as a ConfigError naming where it was found, such
+// as retries["std"].maxRetires.
+func ParseConfigYAML(data []byte) (Config, error) {
+	var raw any
+	if err := yaml.Unmarshal(data, &raw); err == nil {
+		if err := unknownFields(raw); err != nil {
+			return Config{}, err
+		}
+	}
+
+	// Custom policies are kept as JSON for their factories, so they are
+	// decoded generically and converted.
+	var doc struct {
+		Config `yaml:",inline"`
+		Custom map[string]any `yaml:"custom"`
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&doc); err != nil && !errors.Is(err, io.EOF) {
+		return Config{}, fmt.Errorf("invalid yaml config: %w", err)
+	}
+
+	cfg := doc.Config
+	for _, name := range slices.Sorted(maps.Keys(doc.Custom)) {
+		raw, err := json.Marshal(doc.Custom[name])
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid yaml config: %s: %w", entryPath("custom", name), err)
+		}
+
+		if cfg.Custom == nil {
+			cfg.Custom = make(map[string]json.RawMessage, len(doc.Custom))
+		}
+		cfg.Custom[name] = raw
+	}
+
+	return cfg, nil
+}
+
+// ParseConfigJSON decodes a JSON config, rejecting unknown keys like
+// ParseConfigYAML.
+func ParseConfigJSON(data []byte) (Config, error) {
+	var raw any
+	if err := json.Unmarshal(data, &raw); err == nil {
+		if err := unknownFields(raw); err != nil {
+			return Config{}, err
+		}
+	}
+
+	var cfg Config
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&cfg); err != nil {
+		return Config{}, fmt.Errorf("invalid json config: %w", err)
+	}
+
+	if dec.More() {
+		return Config{}, errors.New("invalid json config: unexpected data after the config object")
+	}
+
+	return cfg, nil
+}
+
+// FromConfigFile loads a config like LoadConfigFile and builds a provider
+// from it.
+func FromConfigFile(path string, opts ...Option) (*Provider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := parseConfigFile(path, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return FromConfigWithOptions(cfg, opts...)
+}
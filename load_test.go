@@ -0,0 +1,151 @@
+package goresilience_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	goresilience "github.com/rickKoch/go-resilience"
+)
+
+func TestLoadConfigFile(t *testing.T) {
+	want := goresilience.Config{
+		Timeouts: map[string]goresilience.TimeoutSpec{
+			"fast": {Duration: "100ms"},
+			"slow": {Duration: "2s", Mode: goresilience.TimeoutModeCooperative},
+		},
+		Retries: map[string]goresilience.Retry{
+			"standard": {Duration: "50ms", MaxRetries: 3},
+		},
+		CircuitBreakers: map[string]goresilience.CircuitBreaker{
+			"users": {MaxRequests: 1, Interval: "60s", Timeout: "30s", Failures: 5},
+		},
+		Targets: map[string]goresilience.PolicyNames{
+			"users": {Timeout: "fast", Retry: "standard", CircuitBreaker: "users"},
+		},
+	}
+
+	for _, path := range []string{"testdata/config.yaml", "testdata/config.json"} {
+		t.Run(path, func(t *testing.T) {
+			cfg, err := goresilience.LoadConfigFile(path)
+			if err != nil {
+				t.Fatalf("failed to load config: %v", err)
+			}
+
+			if !reflect.DeepEqual(cfg, want) {
+				t.Fatalf("unexpected config:\n got %+v\nwant %+v", cfg, want)
+			}
+		})
+	}
+}
+
+func TestLoadConfigFileStrict(t *testing.T) {
+	for _, path := range []string{"testdata/typo.yaml", "testdata/typo.json"} {
+		t.Run(path, func(t *testing.T) {
+			_, err := goresilience.LoadConfigFile(path)
+			if err == nil || !strings.Contains(err.Error(), "circutBreakers") {
+				t.Fatalf("expected an error naming the unknown key, got: %v", err)
+			}
+		})
+	}
+}
+
+func TestLoadConfigFileUnknownFieldPaths(t *testing.T) {
+	for _, path := range []string{"testdata/typo_nested.yaml", "testdata/typo_nested.json"} {
+		t.Run(path, func(t *testing.T) {
+			_, err := goresilience.LoadConfigFile(path)
+			if err == nil {
+				t.Fatal("expected an error for the misspelled keys")
+			}
+
+			for _, want := range []string{`retries["std"].maxRetires`, `targets["users"].coalesce.windw`} {
+				if !strings.Contains(err.Error(), want+": is not a known field") {
+					t.Errorf("expected an error at %s, got: %v", want, err)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateRaw(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		data   string
+		want   string
+	}{
+		{
+			name:   "valid yaml",
+			format: goresilience.FormatYAML,
+			data:   "retries:\n  std: {duration: 100ms, maxRetries: 3}\n",
+		},
+		{
+			name:   "valid json",
+			format: goresilience.FormatJSON,
+			data:   `{"retries": {"std": {"duration": "100ms", "maxRetries": 3}}}`,
+		},
+		{
+			name:   "unknown yaml key",
+			format: goresilience.FormatYAML,
+			data:   "retries:\n  std: {duration: 100ms, maxRetires: 3}\n",
+			want:   `retries["std"].maxRetires: is not a known field`,
+		},
+		{
+			name:   "unknown json key",
+			format: goresilience.FormatJSON,
+			data:   `{"retries": {"std": {"duration": "100ms", "maxRetires": 3}}}`,
+			want:   `retries["std"].maxRetires: is not a known field`,
+		},
+		{
+			name:   "invalid value",
+			format: goresilience.FormatJSON,
+			data:   `{"retries": {"std": {"duration": "soon"}}}`,
+			want:   `retries["std"].duration`,
+		},
+		{
+			name:   "unsupported format",
+			format: "toml",
+			want:   "unsupported config format",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := goresilience.ValidateRaw([]byte(tt.data), tt.format)
+			if tt.want == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected an error containing %q, got: %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestLoadConfigFileUnsupported(t *testing.T) {
+	if _, err := goresilience.LoadConfigFile("testdata/config.toml"); err == nil || !strings.Contains(err.Error(), "unsupported") {
+		t.Fatalf("expected an error for an unsupported extension, got: %v", err)
+	}
+}
+
+func TestFromConfigFile(t *testing.T) {
+	provider, err := goresilience.FromConfigFile("testdata/config.yaml")
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	exec := goresilience.NewExecWithPolicy(context.Background(), provider.Policy("users"))
+	_, err = exec(func(ctx context.Context) (any, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	if !errors.Is(err, goresilience.ErrExecutionTimeout) {
+		t.Fatalf("expected the file's timeout to apply, got: %v", err)
+	}
+}
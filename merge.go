@@ -0,0 +1,126 @@
+package goresilience
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Override records an entry of a config section, such as retries["slow"],
+// that an overlay passed to MergeConfigs replaced. Overlay is the index of
+// the overlay that replaced it.
+type Override struct {
+	Section string
+	Name    string
+	Overlay int
+}
+
+func (o Override) String() string {
+	if o.Name == "" {
+		return fmt.Sprintf("%s (overlay %d)", o.Section, o.Overlay)
+	}
+
+	return fmt.Sprintf("%s[%q] (overlay %d)", o.Section, o.Name, o.Overlay)
+}
+
+// OverrideError is returned by MergeConfigsStrict, listing the entries an
+// overlay would have replaced.
+type OverrideError struct {
+	Overrides []Override
+}
+
+func (e *OverrideError) Error() string {
+	overrides := make([]string, len(e.Overrides))
+	for i, o := range e.Overrides {
+		overrides[i] = o.String()
+	}
+
+	return "config entries overridden: " + strings.Join(overrides, ", ")
+}
+
+// MergeConfigs unions base with each overlay in turn. Entries of the same
+// name are replaced by the later overlay, as are defaults an overlay sets.
+// The replacements are returned alongside the merged config, for callers to
+// log; MergeConfigsStrict fails on them instead.
+func MergeConfigs(base Config, overlays ...Config) (Config, []Override) {
+	merged := Config{
+		Version:         base.Version,
+		Timeouts:        maps.Clone(base.Timeouts),
+		Retries:         maps.Clone(base.Retries),
+		CircuitBreakers: maps.Clone(base.CircuitBreakers),
+		Bulkheads:       maps.Clone(base.Bulkheads),
+		Caches:          maps.Clone(base.Caches),
+		AdaptiveLimits:  maps.Clone(base.AdaptiveLimits),
+		Sheds:           maps.Clone(base.Sheds),
+		Custom:          maps.Clone(base.Custom),
+		Targets:         maps.Clone(base.Targets),
+		Chains:          maps.Clone(base.Chains),
+		Aliases:         maps.Clone(base.Aliases),
+		Groups:          maps.Clone(base.Groups),
+		Defaults:        base.Defaults,
+	}
+
+	var overrides []Override
+	for i, overlay := range overlays {
+		merged.Timeouts = overrideEntries("timeouts", i, merged.Timeouts, overlay.Timeouts, &overrides)
+		merged.Retries = overrideEntries("retries", i, merged.Retries, overlay.Retries, &overrides)
+		merged.CircuitBreakers = overrideEntries("circuitBreakers", i, merged.CircuitBreakers, overlay.CircuitBreakers, &overrides)
+		merged.Bulkheads = overrideEntries("bulkheads", i, merged.Bulkheads, overlay.Bulkheads, &overrides)
+		merged.Caches = overrideEntries("caches", i, merged.Caches, overlay.Caches, &overrides)
+		merged.AdaptiveLimits = overrideEntries("adaptiveLimits", i, merged.AdaptiveLimits, overlay.AdaptiveLimits, &overrides)
+		merged.Sheds = overrideEntries("sheds", i, merged.Sheds, overlay.Sheds, &overrides)
+		merged.Custom = overrideEntries("custom", i, merged.Custom, overlay.Custom, &overrides)
+		merged.Targets = overrideEntries("targets", i, merged.Targets, overlay.Targets, &overrides)
+		merged.Chains = overrideEntries("chains", i, merged.Chains, overlay.Chains, &overrides)
+		merged.Aliases = overrideEntries("aliases", i, merged.Aliases, overlay.Aliases, &overrides)
+		merged.Groups = overrideEntries("groups", i, merged.Groups, overlay.Groups, &overrides)
+
+		if overlay.Version != "" {
+			if merged.Version != "" && merged.Version != overlay.Version {
+				overrides = append(overrides, Override{Section: "version", Overlay: i})
+			}
+			merged.Version = overlay.Version
+		}
+
+		if overlay.Defaults != (Defaults{}) {
+			if merged.Defaults != (Defaults{}) {
+				overrides = append(overrides, Override{Section: "defaults", Overlay: i})
+			}
+			merged.Defaults = overlay.Defaults
+		}
+	}
+
+	return merged, overrides
+}
+
+// MergeConfigsStrict merges like MergeConfigs, but returns an
+// *OverrideError instead of the merged config if an overlay replaced
+// anything.
+func MergeConfigsStrict(base Config, overlays ...Config) (Config, error) {
+	merged, overrides := MergeConfigs(base, overlays...)
+	if len(overrides) > 0 {
+		return Config{}, &OverrideError{Overrides: overrides}
+	}
+
+	return merged, nil
+}
+
+func overrideEntries[V any](section string, overlay int, dst, src map[string]V, overrides *[]Override) map[string]V {
+	if len(src) == 0 {
+		return dst
+	}
+
+	if dst == nil {
+		dst = make(map[string]V, len(src))
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(src)) {
+		if _, ok := dst[name]; ok {
+			*overrides = append(*overrides, Override{Section: section, Name: name, Overlay: overlay})
+		}
+		dst[name] = src[name]
+	}
+
+	return dst
+}
@@ -0,0 +1,148 @@
+package goresilience_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	goresilience "github.com/rickKoch/go-resilience"
+)
+
+func TestMergeConfigsRoundTrip(t *testing.T) {
+	base := goresilience.Config{
+		Retries: map[string]goresilience.Retry{
+			"shared": {Duration: "1ms", MaxRetries: 1},
+		},
+		Targets: map[string]goresilience.PolicyNames{
+			"orders": {Retry: "shared"},
+		},
+	}
+	overlay := goresilience.Config{
+		Retries: map[string]goresilience.Retry{
+			"payments": {Duration: "1ms", MaxRetries: 3},
+		},
+		Targets: map[string]goresilience.PolicyNames{
+			"payments": {Retry: "payments"},
+		},
+	}
+	combined := goresilience.Config{
+		Retries: map[string]goresilience.Retry{
+			"shared":   {Duration: "1ms", MaxRetries: 1},
+			"payments": {Duration: "1ms", MaxRetries: 3},
+		},
+		Targets: map[string]goresilience.PolicyNames{
+			"orders":   {Retry: "shared"},
+			"payments": {Retry: "payments"},
+		},
+	}
+
+	merged, overrides := goresilience.MergeConfigs(base, overlay)
+	if len(overrides) != 0 {
+		t.Fatalf("expected no overrides, got: %v", overrides)
+	}
+	if !reflect.DeepEqual(merged, combined) {
+		t.Fatalf("expected %+v, got %+v", combined, merged)
+	}
+
+	mergedProvider, err := goresilience.FromConfig(merged)
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+	combinedProvider, err := goresilience.FromConfig(combined)
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	for _, target := range []string{"orders", "payments"} {
+		got := countAttempts(goresilience.NewExecWithPolicy(context.Background(), mergedProvider.Policy(target)))
+		want := countAttempts(goresilience.NewExecWithPolicy(context.Background(), combinedProvider.Policy(target)))
+		if got != want {
+			t.Errorf("target %q: expected %d attempts, got %d", target, want, got)
+		}
+	}
+
+	if _, ok := base.Retries["payments"]; ok {
+		t.Fatal("expected the base config to be left alone")
+	}
+}
+
+func TestMergeConfigsOverrides(t *testing.T) {
+	base := goresilience.Config{
+		Retries: map[string]goresilience.Retry{
+			"shared": {Duration: "1ms", MaxRetries: 1},
+		},
+		Defaults: goresilience.Defaults{Timeout: "1s"},
+	}
+	first := goresilience.Config{
+		Retries: map[string]goresilience.Retry{
+			"shared": {Duration: "1ms", MaxRetries: 2},
+		},
+	}
+	second := goresilience.Config{
+		Retries: map[string]goresilience.Retry{
+			"shared": {Duration: "1ms", MaxRetries: 3},
+		},
+		Defaults: goresilience.Defaults{Timeout: "2s"},
+	}
+
+	merged, overrides := goresilience.MergeConfigs(base, first, second)
+
+	want := []goresilience.Override{
+		{Section: "retries", Name: "shared", Overlay: 0},
+		{Section: "retries", Name: "shared", Overlay: 1},
+		{Section: "defaults", Overlay: 1},
+	}
+	if !reflect.DeepEqual(overrides, want) {
+		t.Fatalf("expected overrides %+v, got %+v", want, overrides)
+	}
+
+	if got := merged.Retries["shared"].MaxRetries; got != 3 {
+		t.Fatalf("expected the last overlay to win, got maxRetries %d", got)
+	}
+	if merged.Defaults.Timeout != "2s" {
+		t.Fatalf("expected the overlay's defaults, got %+v", merged.Defaults)
+	}
+}
+
+func TestMergeConfigsStrict(t *testing.T) {
+	base := goresilience.Config{
+		Retries: map[string]goresilience.Retry{
+			"shared": {Duration: "1ms", MaxRetries: 1},
+		},
+	}
+
+	merged, err := goresilience.MergeConfigsStrict(base, goresilience.Config{
+		Retries: map[string]goresilience.Retry{
+			"other": {Duration: "1ms", MaxRetries: 2},
+		},
+	})
+	if err != nil || len(merged.Retries) != 2 {
+		t.Fatalf("expected a merge without overrides to succeed, got %+v, %v", merged, err)
+	}
+
+	_, err = goresilience.MergeConfigsStrict(base, goresilience.Config{
+		Retries: map[string]goresilience.Retry{
+			"shared": {Duration: "1ms", MaxRetries: 2},
+		},
+	})
+	var overrideErr *goresilience.OverrideError
+	if !errors.As(err, &overrideErr) {
+		t.Fatalf("expected an OverrideError, got: %v", err)
+	}
+	if want := []goresilience.Override{{Section: "retries", Name: "shared"}}; !reflect.DeepEqual(overrideErr.Overrides, want) {
+		t.Fatalf("expected overrides %+v, got %+v", want, overrideErr.Overrides)
+	}
+}
+
+func TestMergeConfigsEmpty(t *testing.T) {
+	merged, overrides := goresilience.MergeConfigs(goresilience.Config{}, goresilience.Config{}, goresilience.Config{
+		Retries: map[string]goresilience.Retry{},
+	})
+	if len(overrides) != 0 {
+		t.Fatalf("expected no overrides, got: %v", overrides)
+	}
+	if len(merged.Retries) != 0 || merged.Targets != nil {
+		t.Fatalf("expected an empty config, got %+v", merged)
+	}
+}
@@ -0,0 +1,341 @@
+package goresilience
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sync/atomic"
+	"time"
+)
+
+// MetricsSnapshot is a point-in-time copy of the provider's counters and
+// gauges. It shares no memory with the provider.
+type MetricsSnapshot struct {
+	// InFlight counts timed operations per target that are still running,
+	// including ones abandoned after their timeout fired.
+	InFlight               map[string]int `json:"inFlight,omitempty"`
+	LeakedOperations       uint64         `json:"leakedOperations"`
+	DroppedEvents          uint64         `json:"droppedEvents"`
+	DuplicatePermitReports uint64         `json:"duplicatePermitReports"`
+
+	Bulkheads map[string]BulkheadMetrics `json:"bulkheads,omitempty"`
+	// ConcurrencyLimits holds the current limit of each adaptive limit.
+	ConcurrencyLimits map[string]int `json:"concurrencyLimits,omitempty"`
+
+	// Targets holds the totals of every target that has executed, keyed by
+	// canonical name so that aliases add up to their target.
+	Targets map[string]TargetMetrics `json:"targets,omitempty"`
+}
+
+// Metrics is the former name of MetricsSnapshot.
+//
+// Deprecated: Use MetricsSnapshot.
+type Metrics = MetricsSnapshot
+
+// TargetMetrics holds a target's totals since the provider was created.
+// Executions counts calls, and Successes and Failures their outcomes after
+// fallbacks, so calls still running are the difference. Retries counts
+// attempts after the first, Timeouts every timeout that fired, per attempt
+// or overall, and BreakerRejections the attempts the circuit breaker
+// turned away. Breaker names the target's breaker, if any, and
+// BreakerState is its current state. AttemptLatency holds how long each
+// attempt of the operation took, and ExecutionLatency how long finished
+// calls took, backoff included, in the buckets set by WithLatencyBuckets.
+type TargetMetrics struct {
+	Executions        uint64    `json:"executions"`
+	Successes         uint64    `json:"successes"`
+	Failures          uint64    `json:"failures"`
+	Retries           uint64    `json:"retries"`
+	Timeouts          uint64    `json:"timeouts"`
+	BreakerRejections uint64    `json:"breakerRejections"`
+	Breaker           string    `json:"breaker,omitempty"`
+	BreakerState      State     `json:"breakerState"`
+	AttemptLatency    Histogram `json:"attemptLatency"`
+	ExecutionLatency  Histogram `json:"executionLatency"`
+}
+
+// recentOutcomes is how many of a target's latest executions its failure
+// ratio covers.
+const recentOutcomes = 100
+
+// targetCounters are updated by the executor stages of every policy
+// resolved for one target. A nil *targetCounters counts nothing.
+type targetCounters struct {
+	executions atomic.Uint64
+	successes  atomic.Uint64
+	failures   atomic.Uint64
+	retries    atomic.Uint64
+	timeouts   atomic.Uint64
+	rejections atomic.Uint64
+
+	// recent is a ring of the latest outcomes, true for a failure, and
+	// recentNext the number of outcomes ever written to it.
+	recent     [recentOutcomes]atomic.Bool
+	recentNext atomic.Uint64
+
+	attemptLatency   histogram
+	executionLatency histogram
+}
+
+func newTargetCounters(latencyBounds []time.Duration) *targetCounters {
+	return &targetCounters{
+		attemptLatency:   newHistogram(latencyBounds),
+		executionLatency: newHistogram(latencyBounds),
+	}
+}
+
+func (c *targetCounters) executed() {
+	if c != nil {
+		c.executions.Add(1)
+	}
+}
+
+func (c *targetCounters) done(d time.Duration, err error) {
+	if c == nil {
+		return
+	}
+
+	c.executionLatency.observe(d)
+	if err == nil {
+		c.successes.Add(1)
+	} else {
+		c.failures.Add(1)
+	}
+
+	i := c.recentNext.Add(1) - 1
+	c.recent[i%recentOutcomes].Store(err != nil)
+}
+
+// failureRatio returns the share of failures among the latest outcomes and
+// how many outcomes that is.
+func (c *targetCounters) failureRatio() (float64, int) {
+	n := int(min(c.recentNext.Load(), recentOutcomes))
+	if n == 0 {
+		return 0, 0
+	}
+
+	failures := 0
+	for i := range n {
+		if c.recent[i].Load() {
+			failures++
+		}
+	}
+
+	return float64(failures) / float64(n), n
+}
+
+// timeAttempts records how long each call of oper takes.
+func (c *targetCounters) timeAttempts(oper Operation) Operation {
+	return func(ctx context.Context) (any, error) {
+		start := time.Now()
+		res, err := oper(ctx)
+		c.attemptLatency.observe(time.Since(start))
+
+		return res, err
+	}
+}
+
+func (c *targetCounters) retried() {
+	if c != nil {
+		c.retries.Add(1)
+	}
+}
+
+func (c *targetCounters) timedOut() {
+	if c != nil {
+		c.timeouts.Add(1)
+	}
+}
+
+func (c *targetCounters) rejected() {
+	if c != nil {
+		c.rejections.Add(1)
+	}
+}
+
+// snapshot reads the outcomes before the executions, so a call finishing
+// concurrently can never make Successes+Failures exceed Executions.
+func (c *targetCounters) snapshot() TargetMetrics {
+	m := TargetMetrics{
+		Successes:         c.successes.Load(),
+		Failures:          c.failures.Load(),
+		Retries:           c.retries.Load(),
+		Timeouts:          c.timeouts.Load(),
+		BreakerRejections: c.rejections.Load(),
+		AttemptLatency:    c.attemptLatency.snapshot(),
+		ExecutionLatency:  c.executionLatency.snapshot(),
+	}
+	m.Executions = c.executions.Load()
+
+	return m
+}
+
+// BulkheadMetrics reports a bulkhead's occupied slots, the callers waiting
+// for one, and how long admitted calls waited.
+type BulkheadMetrics struct {
+	Active int       `json:"active"`
+	Queued int       `json:"queued"`
+	Wait   Histogram `json:"wait"`
+}
+
+// Histogram counts observations per bucket. Counts[i] holds observations no
+// greater than Bounds[i] and above the previous bound; the last count holds
+// everything above the largest bound.
+type Histogram struct {
+	Bounds []time.Duration `json:"bounds"`
+	Counts []uint64        `json:"counts"`
+	Count  uint64          `json:"count"`
+	Sum    time.Duration   `json:"sum"`
+}
+
+// defaultLatencyBounds are the latency buckets of targets unless
+// WithLatencyBuckets sets others.
+var defaultLatencyBounds = []time.Duration{
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+}
+
+var bulkheadWaitBounds = []time.Duration{
+	time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+}
+
+type histogram struct {
+	bounds []time.Duration
+	counts []atomic.Uint64
+	sum    atomic.Int64
+}
+
+func newHistogram(bounds []time.Duration) histogram {
+	return histogram{bounds: bounds, counts: make([]atomic.Uint64, len(bounds)+1)}
+}
+
+func (h *histogram) observe(d time.Duration) {
+	i, _ := slices.BinarySearch(h.bounds, d)
+	h.counts[i].Add(1)
+	h.sum.Add(int64(d))
+}
+
+func (h *histogram) snapshot() Histogram {
+	s := Histogram{
+		Bounds: slices.Clone(h.bounds),
+		Counts: make([]uint64, len(h.counts)),
+		Sum:    time.Duration(h.sum.Load()),
+	}
+
+	for i := range h.counts {
+		s.Counts[i] = h.counts[i].Load()
+		s.Count += s.Counts[i]
+	}
+
+	return s
+}
+
+// Metrics returns a snapshot of the provider's metrics. It only loads
+// counters and takes no lock that executions wait on, so it is cheap enough
+// to call on every scrape.
+func (p *Provider) Metrics() MetricsSnapshot {
+	m := MetricsSnapshot{
+		InFlight:               make(map[string]int),
+		LeakedOperations:       p.LeakedOperations(),
+		DroppedEvents:          p.DroppedEvents(),
+		DuplicatePermitReports: p.DuplicatePermitReports(),
+	}
+
+	p.gaugeMu.Lock()
+	gauges := maps.Clone(p.inFlight)
+	counters := maps.Clone(p.counters)
+	p.gaugeMu.Unlock()
+
+	for target, gauge := range gauges {
+		m.InFlight[target] = int(gauge.Load())
+	}
+
+	state := p.current()
+
+	if len(state.bulkheads) > 0 {
+		m.Bulkheads = make(map[string]BulkheadMetrics, len(state.bulkheads))
+		for name, bh := range state.bulkheads {
+			m.Bulkheads[name] = bh.metrics()
+		}
+	}
+
+	if len(state.limiters) > 0 {
+		m.ConcurrencyLimits = make(map[string]int, len(state.limiters))
+		for name, limiter := range state.limiters {
+			m.ConcurrencyLimits[name] = limiter.current()
+		}
+	}
+
+	for target, c := range counters {
+		totals := c.snapshot()
+		if totals.Executions == 0 {
+			continue
+		}
+
+		if cb := p.policy(target).circuitBreaker; cb != nil {
+			totals.Breaker = cb.name
+			totals.BreakerState = cb.State("")
+		}
+
+		if m.Targets == nil {
+			m.Targets = make(map[string]TargetMetrics)
+		}
+		m.Targets[target] = totals
+	}
+
+	return m
+}
+
+// InFlight returns how many timed operations for target are still running.
+func (p *Provider) InFlight(target string) int {
+	p.gaugeMu.Lock()
+	gauge := p.inFlight[target]
+	p.gaugeMu.Unlock()
+
+	if gauge == nil {
+		return 0
+	}
+
+	return int(gauge.Load())
+}
+
+func (p *Provider) inFlightGauge(target string) *atomic.Int64 {
+	p.gaugeMu.Lock()
+	defer p.gaugeMu.Unlock()
+
+	gauge, ok := p.inFlight[target]
+	if !ok {
+		gauge = new(atomic.Int64)
+		p.inFlight[target] = gauge
+	}
+
+	return gauge
+}
+
+func (p *Provider) targetCounters(target string) *targetCounters {
+	p.gaugeMu.Lock()
+	defer p.gaugeMu.Unlock()
+
+	c, ok := p.counters[target]
+	if !ok {
+		c = newTargetCounters(p.opts.latencyBounds)
+		p.counters[target] = c
+	}
+
+	return c
+}
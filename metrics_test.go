@@ -0,0 +1,237 @@
+package goresilience_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	goresilience "github.com/rickKoch/go-resilience"
+)
+
+func TestInFlightGauge(t *testing.T) {
+	expectInFlightGauge(t, timeoutProvider(t, goresilience.TimeoutSpec{Duration: "10ms"}))
+}
+
+func TestInFlightGaugeOverallTimeout(t *testing.T) {
+	provider, err := goresilience.FromConfig(goresilience.Config{
+		Timeouts: map[string]goresilience.TimeoutSpec{
+			"overall": {Duration: "10ms"},
+		},
+		Targets: map[string]goresilience.PolicyNames{
+			"test_target": {OverallTimeout: "overall"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	expectInFlightGauge(t, provider)
+}
+
+// expectInFlightGauge abandons operations on test_target to its timeout and
+// checks that the gauge counts them until they return.
+func expectInFlightGauge(t *testing.T, provider *goresilience.Provider) {
+	t.Helper()
+
+	exec := goresilience.NewExecWithPolicy(context.Background(), provider.Policy("test_target"))
+
+	release := make(chan struct{})
+	var wg sync.WaitGroup
+
+	const hanging = 5
+	for i := 0; i < hanging; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = exec(func(ctx context.Context) (any, error) {
+				<-release
+				return nil, nil
+			})
+		}()
+	}
+	wg.Wait()
+
+	if n := provider.InFlight("test_target"); n != hanging {
+		t.Fatalf("expected %d abandoned operations in flight, got %d", hanging, n)
+	}
+	if n := provider.Metrics().InFlight["test_target"]; n != hanging {
+		t.Fatalf("expected metrics to report %d in flight, got %d", hanging, n)
+	}
+
+	close(release)
+
+	deadline := time.Now().Add(time.Second)
+	for provider.InFlight("test_target") != 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected in-flight gauge to fall to zero, got %d", provider.InFlight("test_target"))
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if n := provider.InFlight("unknown_target"); n != 0 {
+		t.Fatalf("expected no in-flight operations for an unknown target, got %d", n)
+	}
+}
+
+func succeed(ctx context.Context) (any, error) {
+	return successResult, nil
+}
+
+func TestTargetMetrics(t *testing.T) {
+	cfg := reloadConfig(1, reloadBreaker)
+	cfg.Timeouts = map[string]goresilience.TimeoutSpec{"test_timeout": {Duration: "20ms"}}
+	cfg.Targets["test_target"] = goresilience.PolicyNames{Timeout: "test_timeout", Retry: "test_retry", CircuitBreaker: "test_cb"}
+	cfg.Aliases = map[string][]string{"test_target": {"test_alias"}}
+
+	provider, err := goresilience.FromConfig(cfg)
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	if m := provider.Metrics(); m.Targets != nil {
+		t.Fatalf("expected no target metrics before any execution, got %+v", m.Targets)
+	}
+
+	exec := goresilience.NewExecWithPolicy(context.Background(), provider.Policy("test_target"))
+
+	// One success through the alias.
+	_, _ = goresilience.NewExecWithPolicy(context.Background(), provider.Policy("test_alias"))(succeed)
+
+	// One timeout, then a successful retry.
+	var attempts int
+	_, _ = exec(func(ctx context.Context) (any, error) {
+		attempts++
+		if attempts == 1 {
+			<-ctx.Done()
+		}
+		return "ok", nil
+	})
+
+	// Two failed attempts, then one failure that trips the breaker and a
+	// retry it rejects, then one call it rejects outright.
+	countAttempts(exec)
+	countAttempts(exec)
+	countAttempts(exec)
+
+	got := provider.Metrics().Targets
+
+	// The latencies are covered by TestLatencyHistograms; only count them.
+	if m := got["test_target"]; m.AttemptLatency.Count != 6 || m.ExecutionLatency.Count != 5 {
+		t.Fatalf("expected 6 attempts and 5 calls in the latencies, got %d and %d", m.AttemptLatency.Count, m.ExecutionLatency.Count)
+	}
+	for name, m := range got {
+		m.AttemptLatency, m.ExecutionLatency = goresilience.Histogram{}, goresilience.Histogram{}
+		got[name] = m
+	}
+
+	want := map[string]goresilience.TargetMetrics{
+		"test_target": {
+			Executions:        5,
+			Successes:         2,
+			Failures:          3,
+			Retries:           3,
+			Timeouts:          1,
+			BreakerRejections: 2,
+			Breaker:           "test_cb",
+			BreakerState:      goresilience.StateOpen,
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %+v, got %+v", want, got)
+	}
+
+	got["test_target"] = goresilience.TargetMetrics{}
+	if m := provider.Metrics().Targets["test_target"]; m.Executions != 5 {
+		t.Fatalf("expected the snapshot to be a copy, got %+v", m)
+	}
+}
+
+func TestTargetMetricsConcurrent(t *testing.T) {
+	provider, err := goresilience.FromConfig(goresilience.Config{
+		Targets: map[string]goresilience.PolicyNames{"test_target": {}},
+	})
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	exec := goresilience.NewExecWithPolicy(context.Background(), provider.Policy("test_target"))
+
+	const calls = 200
+	var wg sync.WaitGroup
+	for i := 0; i < calls; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = exec(succeed)
+		}()
+	}
+
+	for i := 0; i < calls; i++ {
+		m := provider.Metrics().Targets["test_target"]
+		if m.Successes+m.Failures > m.Executions {
+			t.Fatalf("expected outcomes not to exceed executions, got %+v", m)
+		}
+	}
+	wg.Wait()
+
+	if m := provider.Metrics().Targets["test_target"]; m.Executions != calls || m.Successes != calls {
+		t.Fatalf("expected %d successful executions, got %+v", calls, m)
+	}
+}
+
+func TestLatencyHistograms(t *testing.T) {
+	provider, err := goresilience.FromConfigWithOptions(goresilience.Config{
+		Retries: map[string]goresilience.Retry{
+			"test_retry": {Duration: "30ms", MaxRetries: 1},
+		},
+		Targets: map[string]goresilience.PolicyNames{
+			"test_target": {Retry: "test_retry"},
+		},
+	}, goresilience.WithLatencyBuckets(100*time.Millisecond, 10*time.Millisecond))
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	exec := goresilience.NewExecWithPolicy(context.Background(), provider.Policy("test_target"))
+
+	// A fast call.
+	_, _ = exec(succeed)
+
+	// A call whose only attempt takes 30ms.
+	_, _ = exec(func(ctx context.Context) (any, error) {
+		time.Sleep(30 * time.Millisecond)
+		return successResult, nil
+	})
+
+	// Two fast attempts, with 30ms of backoff between them.
+	var attempts int
+	_, _ = exec(func(ctx context.Context) (any, error) {
+		attempts++
+		if attempts == 1 {
+			return nil, errors.New("failed")
+		}
+		return successResult, nil
+	})
+
+	m := provider.Metrics().Targets["test_target"]
+
+	wantBounds := []time.Duration{10 * time.Millisecond, 100 * time.Millisecond}
+	if !reflect.DeepEqual(m.AttemptLatency.Bounds, wantBounds) || !reflect.DeepEqual(m.ExecutionLatency.Bounds, wantBounds) {
+		t.Fatalf("expected sorted bounds %v, got %v and %v", wantBounds, m.AttemptLatency.Bounds, m.ExecutionLatency.Bounds)
+	}
+
+	if want := []uint64{3, 1, 0}; !reflect.DeepEqual(m.AttemptLatency.Counts, want) {
+		t.Fatalf("expected attempt latency counts %v, got %v", want, m.AttemptLatency.Counts)
+	}
+	if want := []uint64{1, 2, 0}; !reflect.DeepEqual(m.ExecutionLatency.Counts, want) {
+		t.Fatalf("expected execution latency counts %v, got %v", want, m.ExecutionLatency.Counts)
+	}
+
+	if m.ExecutionLatency.Sum < 60*time.Millisecond || m.ExecutionLatency.Sum < m.AttemptLatency.Sum {
+		t.Fatalf("expected the calls to take at least 60ms and as long as their attempts, got %s and %s",
+			m.ExecutionLatency.Sum, m.AttemptLatency.Sum)
+	}
+}
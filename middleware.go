@@ -0,0 +1,79 @@
+package goresilience
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Middleware wraps an operation the way the built-in stages do, for
+// concerns such as audit logging or quota accounting.
+type Middleware func(next Operation) Operation
+
+// Placement is where a middleware sits in the chain.
+type Placement int
+
+const (
+	// PerAttempt middleware runs once per attempt, with the attempt's
+	// context. It sits innermost, right around the operation, unless the
+	// target's Order lists StageMiddleware to place it among the timeout,
+	// circuit breaker and retry.
+	PerAttempt Placement = iota
+
+	// PerCall middleware runs once per call, outside every stage,
+	// including the cache and fallback, so it sees the call's final result.
+	PerCall
+)
+
+func (pl Placement) String() string {
+	switch pl {
+	case PerAttempt:
+		return "per-attempt"
+	case PerCall:
+		return "per-call"
+	default:
+		return fmt.Sprintf("unknown placement: %d", int(pl))
+	}
+}
+
+// Use adds PerAttempt middleware to target. See UseAt.
+func (p *Provider) Use(target string, mw ...Middleware) {
+	p.UseAt(target, PerAttempt, mw...)
+}
+
+// UseAt adds middleware to target at placement, or to every target if
+// target is empty. Among the middleware at one placement, global ones run
+// outside a target's own, and earlier ones outside later ones.
+func (p *Provider) UseAt(target string, placement Placement, mw ...Middleware) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	defer p.settingsChanged()
+
+	key := middlewareKey{target, placement}
+	p.middleware[key] = append(slices.Clip(p.middleware[key]), mw...)
+}
+
+type middlewareKey struct {
+	target    string
+	placement Placement
+}
+
+// middlewareFor returns the middleware target gets at placement, outermost
+// first. The caller holds mu.
+func (p *Provider) middlewareFor(target string, placement Placement) []Middleware {
+	global := p.middleware[middlewareKey{"", placement}]
+	if target == "" {
+		return slices.Clone(global)
+	}
+
+	return slices.Concat(global, p.middleware[middlewareKey{target, placement}])
+}
+
+// withMiddleware wraps oper in mw, the first outermost.
+func withMiddleware(mw []Middleware, oper Operation) Operation {
+	for i := len(mw) - 1; i >= 0; i-- {
+		oper = mw[i](oper)
+	}
+
+	return oper
+}
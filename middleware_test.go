@@ -0,0 +1,141 @@
+package goresilience_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+
+	goresilience "github.com/rickKoch/go-resilience"
+)
+
+type counting struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (c *counting) middleware(name string) goresilience.Middleware {
+	return func(next goresilience.Operation) goresilience.Operation {
+		return func(ctx context.Context) (any, error) {
+			c.mu.Lock()
+			c.calls = append(c.calls, name)
+			c.mu.Unlock()
+
+			return next(ctx)
+		}
+	}
+}
+
+func (c *counting) count(name string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	n := 0
+	for _, call := range c.calls {
+		if call == name {
+			n++
+		}
+	}
+	return n
+}
+
+func TestMiddlewarePlacement(t *testing.T) {
+	provider, err := goresilience.FromConfig(reloadConfig(2, reloadBreaker))
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	c := &counting{}
+	policy := provider.Policy("test_target")
+	provider.Use("test_target", c.middleware("attempt"))
+	provider.UseAt("test_target", goresilience.PerCall, c.middleware("call"))
+
+	if provider.Policy("test_target") == policy {
+		t.Fatal("expected Use to replace the memoized policy")
+	}
+
+	if n := countAttempts(goresilience.NewExecWithPolicy(context.Background(), provider.Policy("test_target"))); n != 3 {
+		t.Fatalf("expected 3 attempts, got %d", n)
+	}
+
+	if n := c.count("attempt"); n != 3 {
+		t.Fatalf("expected per-attempt middleware to run 3 times, got %d", n)
+	}
+	if n := c.count("call"); n != 1 {
+		t.Fatalf("expected per-call middleware to run once, got %d", n)
+	}
+}
+
+func TestMiddlewareGlobalOrder(t *testing.T) {
+	provider, err := goresilience.FromConfig(reloadConfig(0, reloadBreaker))
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	c := &counting{}
+	provider.Use("test_target", c.middleware("target"))
+	provider.Use("", c.middleware("global 1"), c.middleware("global 2"))
+	provider.Use("other_target", c.middleware("other"))
+
+	_, _ = goresilience.NewExecWithPolicy(context.Background(), provider.Policy("test_target"))(succeed)
+
+	if want := []string{"global 1", "global 2", "target"}; !reflect.DeepEqual(c.calls, want) {
+		t.Fatalf("expected middleware to run in order %q, got %q", want, c.calls)
+	}
+}
+
+func TestMiddlewareOrder(t *testing.T) {
+	cfg := reloadConfig(2, reloadBreaker)
+	cfg.Targets["test_target"] = goresilience.PolicyNames{
+		Retry:          "test_retry",
+		CircuitBreaker: "test_cb",
+		Order: []string{
+			goresilience.StageTimeout,
+			goresilience.StageCircuitBreaker,
+			goresilience.StageRetry,
+			goresilience.StageMiddleware,
+		},
+	}
+
+	provider, err := goresilience.FromConfig(cfg)
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	c := &counting{}
+	provider.Use("test_target", c.middleware("attempt"))
+
+	// Listed after retry, the middleware wraps the whole retry loop.
+	if n := countAttempts(goresilience.NewExecWithPolicy(context.Background(), provider.Policy("test_target"))); n != 3 {
+		t.Fatalf("expected 3 attempts, got %d", n)
+	}
+	if n := c.count("attempt"); n != 1 {
+		t.Fatalf("expected the middleware to run once outside the retry, got %d", n)
+	}
+}
+
+func TestMiddlewareSeesRejections(t *testing.T) {
+	provider, err := goresilience.FromConfig(reloadConfig(0, reloadBreaker))
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	var errs []error
+	provider.UseAt("", goresilience.PerCall, func(next goresilience.Operation) goresilience.Operation {
+		return func(ctx context.Context) (any, error) {
+			res, err := next(ctx)
+			errs = append(errs, err)
+			return res, err
+		}
+	})
+
+	exec := goresilience.NewExecWithPolicy(context.Background(), provider.Policy("test_target"))
+	for i := 0; i < 4; i++ {
+		countAttempts(exec)
+	}
+
+	if len(errs) != 4 || !errors.Is(errs[3], goresilience.ErrOpenState) {
+		t.Fatalf("expected per-call middleware to see the breaker's rejection, got %v", errs)
+	}
+}
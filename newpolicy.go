@@ -0,0 +1,109 @@
+package goresilience
+
+import (
+	"errors"
+	"time"
+)
+
+// PolicyOption adds a stage to a policy built with NewPolicy, or reports
+// why its settings are invalid.
+type PolicyOption func(*Policy) error
+
+// NewPolicy builds a policy in code, without a Config or a Provider:
+//
+//	policy, err := goresilience.NewPolicy(
+//		goresilience.WithTimeoutDuration(2*time.Second),
+//		goresilience.WithConstantRetry(100*time.Millisecond, 3),
+//	)
+//
+// Its stages are the ones FromConfig builds from the same settings, run in
+// the default order, and are validated the same way; the errors of every
+// invalid option are joined. The policy belongs to no target, so it records
+// no metrics and TargetFromContext reports nothing for it.
+func NewPolicy(opts ...PolicyOption) (*Policy, error) {
+	policy := &Policy{recoverPanics: true}
+
+	var errs []error
+	for _, opt := range opts {
+		if err := opt(policy); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	return policy, nil
+}
+
+// WithTimeoutDuration bounds each attempt to d, in the detached mode.
+func WithTimeoutDuration(d time.Duration) PolicyOption {
+	return func(p *Policy) error {
+		if d <= 0 {
+			var errs configErrors
+			errs.add("timeout", "must be positive, got %s", d)
+			return errs.err()
+		}
+
+		p.timeout = &timeout{duration: d}
+		return nil
+	}
+}
+
+// WithTimeoutSpec bounds each attempt as a TimeoutSpec in Config.Timeouts
+// does. Its durations must have a unit.
+func WithTimeoutSpec(spec TimeoutSpec) PolicyOption {
+	return func(p *Policy) error {
+		var errs configErrors
+
+		t, err := newTimeout(spec, 0)
+		errs.nest("timeout", err)
+		if err := errs.err(); err != nil {
+			return err
+		}
+
+		p.timeout = t
+		return nil
+	}
+}
+
+// WithConstantRetry retries a failed call up to maxRetries times, waiting
+// d between attempts.
+func WithConstantRetry(d time.Duration, maxRetries int) PolicyOption {
+	return func(p *Policy) error {
+		var errs configErrors
+
+		spec := Retry{Duration: d.String(), MaxRetries: maxRetries}
+		errs.nest("retry", validateRetry(spec, newOptions(nil)))
+
+		r, err := newRetry(spec, 0)
+		errs.nest("retry", err)
+		if err := errs.err(); err != nil {
+			return err
+		}
+
+		p.retry = r
+		return nil
+	}
+}
+
+// WithCircuitBreakerSettings guards the policy with a circuit breaker of
+// its own, as a CircuitBreaker in Config.CircuitBreakers does. Its
+// durations must have a unit.
+func WithCircuitBreakerSettings(settings CircuitBreaker) PolicyOption {
+	return func(p *Policy) error {
+		var errs configErrors
+
+		errs.nest("circuitBreaker", validateCircuitBreaker(settings, newOptions(nil)))
+
+		cb, err := newCircuitBreaker("", settings, 0)
+		errs.nest("circuitBreaker", err)
+		if err := errs.err(); err != nil {
+			return err
+		}
+
+		p.circuitBreaker = cb
+		return nil
+	}
+}
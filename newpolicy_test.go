@@ -0,0 +1,111 @@
+package goresilience_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	goresilience "github.com/rickKoch/go-resilience"
+)
+
+func TestNewPolicyMatchesConfig(t *testing.T) {
+	provider, err := goresilience.FromConfig(reloadConfig(2, reloadBreaker))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	policy, err := goresilience.NewPolicy(
+		goresilience.WithConstantRetry(time.Millisecond, 2),
+		goresilience.WithCircuitBreakerSettings(reloadBreaker),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	run := func(p *goresilience.Policy) (attempts []int32, open []bool) {
+		for range 3 {
+			exec := goresilience.NewExecutor(context.Background(), p)
+			attempts = append(attempts, countAttempts(exec))
+
+			_, err := exec(succeed)
+			open = append(open, errors.Is(err, goresilience.ErrOpenState))
+		}
+		return attempts, open
+	}
+
+	wantAttempts, wantOpen := run(provider.Policy("test_target"))
+	gotAttempts, gotOpen := run(policy)
+
+	if !slices.Equal(gotAttempts, wantAttempts) || !slices.Equal(gotOpen, wantOpen) {
+		t.Fatalf("expected attempts %v and open %v as built from config, got: %v and %v",
+			wantAttempts, wantOpen, gotAttempts, gotOpen)
+	}
+}
+
+func TestNewPolicyTimeoutMatchesConfig(t *testing.T) {
+	slow := func(ctx context.Context) (any, error) {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(time.Second):
+			return successResult, nil
+		}
+	}
+
+	for _, mode := range []string{goresilience.TimeoutModeDetached, goresilience.TimeoutModeCooperative} {
+		t.Run(mode, func(t *testing.T) {
+			spec := goresilience.TimeoutSpec{Duration: "20ms", Mode: mode}
+			provider := timeoutProvider(t, spec)
+
+			policy, err := goresilience.NewPolicy(goresilience.WithTimeoutSpec(spec))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			_, want := provider.Policy("test_target").Execute(context.Background(), slow)
+			_, got := policy.Execute(context.Background(), slow)
+
+			var timeoutErr *goresilience.TimeoutError
+			if !errors.As(got, &timeoutErr) || timeoutErr.Limit != 20*time.Millisecond {
+				t.Fatalf("expected a timeout of 20ms, got: %v", got)
+			}
+			if errors.Is(want, goresilience.ErrExecutionTimeout) != errors.Is(got, goresilience.ErrExecutionTimeout) {
+				t.Fatalf("expected %v as built from config, got: %v", want, got)
+			}
+		})
+	}
+
+	policy, err := goresilience.NewPolicy(goresilience.WithTimeoutDuration(20 * time.Millisecond))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := policy.Execute(context.Background(), slow); !errors.Is(err, goresilience.ErrExecutionTimeout) {
+		t.Fatalf("expected ErrExecutionTimeout, got: %v", err)
+	}
+}
+
+func TestNewPolicyInvalidOptions(t *testing.T) {
+	_, err := goresilience.NewPolicy(
+		goresilience.WithTimeoutDuration(0),
+		goresilience.WithTimeoutSpec(goresilience.TimeoutSpec{Duration: "1s", Mode: "eventually"}),
+		goresilience.WithConstantRetry(time.Millisecond, 5000),
+		goresilience.WithCircuitBreakerSettings(goresilience.CircuitBreaker{Failures: 3, MaxRequests: 1}),
+	)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	for _, path := range []string{"timeout: must be positive", "timeout.mode:", "retry.maxRetries:", "circuitBreaker.interval:"} {
+		if !strings.Contains(err.Error(), path) {
+			t.Errorf("expected the error to report %q, got: %v", path, err)
+		}
+	}
+
+	var cfgErr *goresilience.ConfigError
+	if !errors.As(err, &cfgErr) {
+		t.Fatalf("expected ConfigErrors, got: %T", err)
+	}
+}
@@ -0,0 +1,172 @@
+package goresilience
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// Observer is told what the policy stages do. Each method is called
+// synchronously from the call that caused it, so implementations must be
+// safe for concurrent use and should return quickly.
+//
+// OnRetry is called after a failed attempt that will be retried, with the
+// attempt's number, starting at 1, and the backoff before the next one.
+// OnTimeout is called when a target's timeout fires, per attempt or
+// overall. OnRejected is called when a stage turns a call away without
+// running it: the circuit breaker, bulkhead, adaptive limit, load shedder,
+// health gate or minimum deadline. err tells which.
+type Observer interface {
+	OnRetry(target string, attempt int, delay time.Duration, err error)
+	OnStateChange(change StateChange)
+	OnTimeout(target string, limit time.Duration)
+	OnRejected(target string, err error)
+}
+
+// ExecutionObserver is an Observer that is also told about every attempt
+// and every finished call, with how long it took and its error, if any.
+// Observers given to WithObserver that implement it receive both; the
+// others are left alone.
+type ExecutionObserver interface {
+	Observer
+	OnAttempt(target string, duration time.Duration, err error)
+	OnDone(target string, duration time.Duration, err error)
+}
+
+// WithObserver reports the provider's policy decisions to o. It may be
+// given more than once, and combined with WithLogger.
+func WithObserver(o Observer) Option {
+	return func(opts *options) {
+		if o != nil {
+			opts.observers = append(opts.observers, o)
+		}
+	}
+}
+
+// WithLogger logs the provider's policy decisions to l: retries at info
+// level, and timeouts, rejections and breakers opening at warn level.
+func WithLogger(l *slog.Logger) Option {
+	return WithObserver(logObserver{l})
+}
+
+// newObserver combines observers into one, or returns nil if there are
+// none, so that stages can skip observing altogether.
+func newObserver(observers []Observer) Observer {
+	switch len(observers) {
+	case 0:
+		return nil
+	case 1:
+		return observers[0]
+	default:
+		return multiObserver(observers)
+	}
+}
+
+// executionObservers picks the observers that implement
+// ExecutionObserver, or returns nil if none do.
+func executionObservers(observers []Observer) executionObserver {
+	var m executionObserver
+	for _, o := range observers {
+		if eo, ok := o.(ExecutionObserver); ok {
+			m = append(m, eo)
+		}
+	}
+
+	return m
+}
+
+type multiObserver []Observer
+
+func (m multiObserver) OnRetry(target string, attempt int, delay time.Duration, err error) {
+	for _, o := range m {
+		o.OnRetry(target, attempt, delay, err)
+	}
+}
+
+func (m multiObserver) OnStateChange(change StateChange) {
+	for _, o := range m {
+		o.OnStateChange(change)
+	}
+}
+
+func (m multiObserver) OnTimeout(target string, limit time.Duration) {
+	for _, o := range m {
+		o.OnTimeout(target, limit)
+	}
+}
+
+func (m multiObserver) OnRejected(target string, err error) {
+	for _, o := range m {
+		o.OnRejected(target, err)
+	}
+}
+
+// executionObserver tells the ExecutionObservers among a provider's
+// observers about attempts and finished calls.
+type executionObserver []ExecutionObserver
+
+func (m executionObserver) onAttempt(target string, duration time.Duration, err error) {
+	for _, o := range m {
+		o.OnAttempt(target, duration, err)
+	}
+}
+
+func (m executionObserver) onDone(target string, duration time.Duration, err error) {
+	for _, o := range m {
+		o.OnDone(target, duration, err)
+	}
+}
+
+// observeAttempts reports every attempt of oper.
+func (m executionObserver) observeAttempts(target string, oper Operation) Operation {
+	return func(ctx context.Context) (any, error) {
+		start := time.Now()
+		res, err := oper(ctx)
+		m.onAttempt(target, time.Since(start), err)
+
+		return res, err
+	}
+}
+
+type logObserver struct {
+	logger *slog.Logger
+}
+
+func (l logObserver) OnRetry(target string, attempt int, delay time.Duration, err error) {
+	l.logger.Info("retrying operation", "target", target, "attempt", attempt, "delay", delay, "error", err)
+}
+
+func (l logObserver) OnStateChange(change StateChange) {
+	level := slog.LevelInfo
+	if change.To == StateOpen || change.To == StateForcedOpen {
+		level = slog.LevelWarn
+	}
+
+	attrs := []any{"target", change.Target, "breaker", change.Breaker, "from", change.From.String(), "to", change.To.String()}
+	if change.Key != "" {
+		attrs = append(attrs, "key", change.Key)
+	}
+	if change.LastError != nil {
+		attrs = append(attrs, "error", change.LastError)
+	}
+
+	l.logger.Log(context.Background(), level, "circuit breaker changed state", attrs...)
+}
+
+func (l logObserver) OnTimeout(target string, limit time.Duration) {
+	l.logger.Warn("operation timed out", "target", target, "limit", limit)
+}
+
+func (l logObserver) OnRejected(target string, err error) {
+	l.logger.Warn("operation rejected", "target", target, "error", err)
+}
+
+// rejected reports a call that stage turned away and returns its error.
+func (p *Policy) rejected(ctx context.Context, stage string, err error) error {
+	if p.observer != nil {
+		p.observer.OnRejected(p.target, err)
+	}
+	traceFailure(ctx, stage, "rejected: %v", err)
+
+	return p.stageError(stage, err)
+}
@@ -0,0 +1,160 @@
+package goresilience_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	goresilience "github.com/rickKoch/go-resilience"
+)
+
+type fakeObserver struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (f *fakeObserver) record(format string, args ...any) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls = append(f.calls, fmt.Sprintf(format, args...))
+}
+
+func (f *fakeObserver) OnRetry(target string, attempt int, delay time.Duration, err error) {
+	f.record("retry %s attempt=%d delay=%s: %v", target, attempt, delay, err)
+}
+
+func (f *fakeObserver) OnStateChange(change goresilience.StateChange) {
+	f.record("state %s %s -> %s", change.Target, change.From, change.To)
+}
+
+func (f *fakeObserver) OnTimeout(target string, limit time.Duration) {
+	f.record("timeout %s limit=%s", target, limit)
+}
+
+func (f *fakeObserver) OnRejected(target string, err error) {
+	f.record("rejected %s open=%t", target, errors.Is(err, goresilience.ErrOpenState))
+}
+
+func (f *fakeObserver) Calls() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.calls
+}
+
+func TestObserverRetriedThenRejected(t *testing.T) {
+	breaker := reloadBreaker
+	breaker.Failures = 2
+
+	observer := &fakeObserver{}
+	provider, err := goresilience.FromConfigWithOptions(reloadConfig(3, breaker), goresilience.WithObserver(observer))
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	// The second failure opens the breaker, which rejects the third attempt
+	// and ends the retries.
+	if n := countAttempts(goresilience.NewExecWithPolicy(context.Background(), provider.Policy("test_target"))); n != 2 {
+		t.Fatalf("expected 2 attempts, got %d", n)
+	}
+
+	want := []string{
+		"retry test_target attempt=1 delay=1ms: failed",
+		"state test_target closed -> open",
+		"retry test_target attempt=2 delay=1ms: failed",
+		"rejected test_target open=true",
+	}
+	if got := observer.Calls(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected calls\n%s\ngot\n%s", strings.Join(want, "\n"), strings.Join(got, "\n"))
+	}
+}
+
+func TestObserverTimeout(t *testing.T) {
+	observer := &fakeObserver{}
+	provider, err := goresilience.FromConfigWithOptions(goresilience.Config{
+		Timeouts: map[string]goresilience.TimeoutSpec{"test_timeout": {Duration: "10ms"}},
+		Targets:  map[string]goresilience.PolicyNames{"test_target": {Timeout: "test_timeout"}},
+	}, goresilience.WithObserver(observer))
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	_, _ = goresilience.NewExecWithPolicy(context.Background(), provider.Policy("test_target"))(func(ctx context.Context) (any, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+
+	if got, want := observer.Calls(), []string{"timeout test_target limit=10ms"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected calls %q, got %q", want, got)
+	}
+}
+
+func TestWithLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+
+	observer := &fakeObserver{}
+	provider, err := goresilience.FromConfigWithOptions(reloadConfig(1, reloadBreaker),
+		goresilience.WithLogger(logger), goresilience.WithObserver(observer))
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	countAttempts(goresilience.NewExecWithPolicy(context.Background(), provider.Policy("test_target")))
+
+	want := "level=INFO msg=\"retrying operation\" target=test_target attempt=1 delay=1ms error=failed\n"
+	if got := buf.String(); got != want {
+		t.Fatalf("expected log %q, got %q", want, got)
+	}
+	if n := len(observer.Calls()); n != 1 {
+		t.Fatalf("expected the observer to be called alongside the logger, got %d calls", n)
+	}
+}
+
+type fakeExecutionObserver struct {
+	fakeObserver
+}
+
+func (f *fakeExecutionObserver) OnAttempt(target string, duration time.Duration, err error) {
+	f.record("attempt %s: %v", target, err)
+}
+
+func (f *fakeExecutionObserver) OnDone(target string, duration time.Duration, err error) {
+	f.record("done %s: %v", target, err)
+}
+
+func TestExecutionObserver(t *testing.T) {
+	observer := &fakeExecutionObserver{}
+	provider, err := goresilience.FromConfigWithOptions(reloadConfig(1, reloadBreaker),
+		goresilience.WithObserver(observer), goresilience.WithObserver(&fakeObserver{}))
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	countAttempts(goresilience.NewExecWithPolicy(context.Background(), provider.Policy("test_target")))
+
+	want := []string{
+		"attempt test_target: failed",
+		"retry test_target attempt=1 delay=1ms: failed",
+		"attempt test_target: failed",
+		"done test_target: retries exhausted after 2 attempts: failed",
+	}
+	if got := observer.Calls(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected calls\n%s\ngot\n%s", strings.Join(want, "\n"), strings.Join(got, "\n"))
+	}
+}
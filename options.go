@@ -0,0 +1,182 @@
+package goresilience
+
+import (
+	"slices"
+	"time"
+)
+
+const defaultSnapshotMaxAge = 10 * time.Minute
+
+type Option func(*options)
+
+type options struct {
+	snapshotMaxAge time.Duration
+	registry       *BreakerRegistry
+	recoverPanics  bool
+
+	allowDanglingRefs  bool
+	configPollInterval time.Duration
+	envExpansion       bool
+	durationUnit       time.Duration
+
+	allowZeroFailures    bool
+	allowZeroMaxRequests bool
+	allowManyRetries     bool
+
+	strictTargets   bool
+	onUnknownTarget func(error)
+
+	observers []Observer
+	traceSize int
+
+	latencyBounds []time.Duration
+}
+
+func newOptions(opts []Option) options {
+	o := options{
+		snapshotMaxAge: defaultSnapshotMaxAge,
+		recoverPanics:  true,
+
+		configPollInterval: defaultConfigPollInterval,
+
+		latencyBounds: defaultLatencyBounds,
+	}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+// WithSnapshotMaxAge sets how old a breaker snapshot may be before
+// FromConfigWithState ignores it.
+func WithSnapshotMaxAge(d time.Duration) Option {
+	return func(o *options) {
+		o.snapshotMaxAge = d
+	}
+}
+
+// WithBreakerRegistry makes the provider share breakers with every other
+// provider built from the same registry.
+func WithBreakerRegistry(reg *BreakerRegistry) Option {
+	return func(o *options) {
+		o.registry = reg
+	}
+}
+
+// WithPanicRecovery controls whether executors convert panics raised by
+// operations into PanicError. It is enabled by default; when disabled,
+// panics propagate to the caller except inside a detached timeout.
+func WithPanicRecovery(enabled bool) Option {
+	return func(o *options) {
+		o.recoverPanics = enabled
+	}
+}
+
+// WithAllowDanglingRefs lets targets reference policies that are not
+// defined, which are then skipped. It is meant for configs assembled
+// dynamically, where a reference may only be filled in later.
+func WithAllowDanglingRefs() Option {
+	return func(o *options) {
+		o.allowDanglingRefs = true
+	}
+}
+
+// WithConfigPollInterval sets how often WatchConfigFile checks the file for
+// changes.
+func WithConfigPollInterval(d time.Duration) Option {
+	return func(o *options) {
+		if d > 0 {
+			o.configPollInterval = d
+		}
+	}
+}
+
+// WithEnvExpansion expands ${VAR} and ${VAR:-default} in the config's
+// string fields from the environment, once they are decoded, failing on
+// variables that are unset and have no default with a ConfigError for the
+// field. Values taken from the environment are not expanded again. Numeric
+// fields are left alone; see ExpandEnv.
+func WithEnvExpansion() Option {
+	return func(o *options) {
+		o.envExpansion = true
+	}
+}
+
+// WithDefaultDurationUnit reads bare integer durations such as "500" in
+// unit. Without it, durations must carry a unit and bare integers are
+// rejected.
+func WithDefaultDurationUnit(unit time.Duration) Option {
+	return func(o *options) {
+		o.durationUnit = unit
+	}
+}
+
+// WithLegacyMicrosecondDurations reads bare integer durations as
+// microseconds, as earlier versions did.
+func WithLegacyMicrosecondDurations() Option {
+	return WithDefaultDurationUnit(time.Microsecond)
+}
+
+// WithAllowZeroFailures accepts circuit breakers without Failures. Such a
+// breaker trips on the first failure.
+func WithAllowZeroFailures() Option {
+	return func(o *options) {
+		o.allowZeroFailures = true
+	}
+}
+
+// WithAllowZeroMaxRequests accepts circuit breakers without MaxRequests,
+// which then let one request through while half-open.
+func WithAllowZeroMaxRequests() Option {
+	return func(o *options) {
+		o.allowZeroMaxRequests = true
+	}
+}
+
+// WithAllowManyRetries lifts the cap of maxRetryLimit retries per call.
+func WithAllowManyRetries() Option {
+	return func(o *options) {
+		o.allowManyRetries = true
+	}
+}
+
+// WithStrictTargets makes Policy panic for a name that PolicyE would reject
+// with ErrUnknownTarget, so that a misspelled target fails loudly instead of
+// running without policies.
+func WithStrictTargets() Option {
+	return func(o *options) {
+		o.strictTargets = true
+		o.onUnknownTarget = func(err error) {
+			panic(err)
+		}
+	}
+}
+
+// WithUnknownTargetHandler is like WithStrictTargets, but passes the error
+// to fn, for example to log it, and lets Policy carry on with an empty
+// policy. A nil fn lets Policy carry on silently, while MustPolicy still
+// panics.
+func WithUnknownTargetHandler(fn func(error)) Option {
+	if fn == nil {
+		fn = func(error) {}
+	}
+
+	return func(o *options) {
+		o.strictTargets = true
+		o.onUnknownTarget = fn
+	}
+}
+
+// WithLatencyBuckets sets the upper bounds of the buckets that count the
+// latency of every target's attempts and calls in Metrics, in place of ones
+// from 5ms to 10s. Observations above the largest bound get a bucket of
+// their own.
+func WithLatencyBuckets(bounds ...time.Duration) Option {
+	bounds = slices.Compact(slices.Sorted(slices.Values(bounds)))
+
+	return func(o *options) {
+		o.latencyBounds = bounds
+	}
+}
@@ -0,0 +1,78 @@
+package goresilience
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Stages a target's Order may rearrange.
+const (
+	StageTimeout        = "timeout"
+	StageCircuitBreaker = "circuitBreaker"
+	StageRetry          = "retry"
+)
+
+// StageMiddleware may be added to a target's Order to place its PerAttempt
+// middleware among the other stages.
+const StageMiddleware = "middleware"
+
+// StageOperation attributes an error to the operation itself rather than
+// to a policy stage.
+const StageOperation = "operation"
+
+// defaultOrder wraps an operation in its timeout, then its circuit breaker,
+// then its retry, so that every attempt is timed and counted on its own.
+var defaultOrder = []string{StageTimeout, StageCircuitBreaker, StageRetry}
+
+// validateOrder checks that order, if set, lists every stage once, and
+// StageMiddleware at most once.
+func validateOrder(order []string) error {
+	if len(order) == 0 {
+		return nil
+	}
+
+	var errs configErrors
+
+	seen := make(map[string]bool)
+	for i, stage := range order {
+		path := fmt.Sprintf("[%d]", i)
+
+		switch {
+		case !slices.Contains(defaultOrder, stage) && stage != StageMiddleware:
+			errs.add(path, "unknown stage %q, must be one of %q or %q", stage, defaultOrder, StageMiddleware)
+		case seen[stage]:
+			errs.add(path, "stage %q is listed twice", stage)
+		}
+		seen[stage] = true
+	}
+
+	for _, stage := range defaultOrder {
+		if !seen[stage] {
+			errs.add("", "stage %q is missing", stage)
+		}
+	}
+
+	return errs.err()
+}
+
+// withStage wraps operation in the named stage, if the policy has it.
+func (p *Policy) withStage(stage string, operation Operation) Operation {
+	switch stage {
+	case StageTimeout:
+		if p.timeout != nil && p.timeout.duration > 0 {
+			return p.withTimeout(operation)
+		}
+	case StageCircuitBreaker:
+		if p.circuitBreaker != nil {
+			return p.withCircuitBreaker(operation)
+		}
+	case StageRetry:
+		if p.retry != nil {
+			return p.withRetry(operation)
+		}
+	case StageMiddleware:
+		return withMiddleware(p.middleware, operation)
+	}
+
+	return operation
+}
@@ -0,0 +1,217 @@
+package goresilience_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+
+	goresilience "github.com/rickKoch/go-resilience"
+)
+
+func orderProvider(t *testing.T, failures int, order ...string) *goresilience.Provider {
+	t.Helper()
+
+	provider, err := goresilience.FromConfig(goresilience.Config{
+		Timeouts: map[string]goresilience.TimeoutSpec{
+			"test_timeout": {Duration: "50ms"},
+		},
+		Retries: map[string]goresilience.Retry{
+			"test_retry": {Duration: "20ms", MaxRetries: 10},
+		},
+		CircuitBreakers: map[string]goresilience.CircuitBreaker{
+			"test_cb": {MaxRequests: 1, Interval: "never", Timeout: "1m", Failures: failures},
+		},
+		Targets: map[string]goresilience.PolicyNames{
+			"test_target": {
+				Timeout:        "test_timeout",
+				Retry:          "test_retry",
+				CircuitBreaker: "test_cb",
+				Order:          order,
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	return provider
+}
+
+func TestOrderTimeoutOutsideRetry(t *testing.T) {
+	tests := []struct {
+		name    string
+		order   []string
+		timeout bool
+	}{
+		{
+			name: "default",
+		},
+		{
+			name:    "timeout outside retry",
+			order:   []string{goresilience.StageCircuitBreaker, goresilience.StageRetry, goresilience.StageTimeout},
+			timeout: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := orderProvider(t, 100, tt.order...)
+
+			var calls atomic.Int32
+			exec := goresilience.NewExecutor(context.Background(), provider.Policy("test_target"))
+			_, err := exec(func(ctx context.Context) (any, error) {
+				calls.Add(1)
+				return nil, testError
+			})
+
+			if got := errors.Is(err, goresilience.ErrExecutionTimeout); got != tt.timeout {
+				t.Fatalf("expected timeout %v, got: %v", tt.timeout, err)
+			}
+			if n := calls.Load(); tt.timeout == (n == 11) {
+				t.Fatalf("expected the timeout to cut the retry loop short only when outside it, got %d attempts", n)
+			}
+		})
+	}
+}
+
+func TestOrderBreakerOutsideRetry(t *testing.T) {
+	tests := []struct {
+		name  string
+		order []string
+		calls int32
+	}{
+		{
+			name:  "default",
+			calls: 1,
+		},
+		{
+			name:  "breaker outside retry",
+			order: []string{goresilience.StageTimeout, goresilience.StageRetry, goresilience.StageCircuitBreaker},
+			calls: 11,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := orderProvider(t, 1, tt.order...)
+
+			exec := goresilience.NewExecutor(context.Background(), provider.Policy("test_target"))
+			if calls := countAttempts(exec); calls != tt.calls {
+				t.Fatalf("expected %d attempts, got %d", tt.calls, calls)
+			}
+		})
+	}
+}
+
+func TestOrderStageAttribution(t *testing.T) {
+	orders := [][]string{
+		{goresilience.StageTimeout, goresilience.StageCircuitBreaker, goresilience.StageRetry},
+		{goresilience.StageTimeout, goresilience.StageRetry, goresilience.StageCircuitBreaker},
+		{goresilience.StageCircuitBreaker, goresilience.StageTimeout, goresilience.StageRetry},
+		{goresilience.StageCircuitBreaker, goresilience.StageRetry, goresilience.StageTimeout},
+		{goresilience.StageRetry, goresilience.StageTimeout, goresilience.StageCircuitBreaker},
+		{goresilience.StageRetry, goresilience.StageCircuitBreaker, goresilience.StageTimeout},
+	}
+
+	tests := []struct {
+		name  string
+		open  bool
+		err   error
+		stage string
+	}{
+		{name: "retries exhausted", err: testError, stage: goresilience.StageRetry},
+		{name: "permanent error", err: backoff.Permanent(testError), stage: goresilience.StageOperation},
+		{name: "breaker open", open: true, err: testError, stage: goresilience.StageCircuitBreaker},
+	}
+
+	for _, order := range orders {
+		for _, tt := range tests {
+			t.Run(strings.Join(order, ",")+"/"+tt.name, func(t *testing.T) {
+				provider, err := goresilience.FromConfig(goresilience.Config{
+					Timeouts: map[string]goresilience.TimeoutSpec{"test_timeout": {Duration: "1s"}},
+					Retries:  map[string]goresilience.Retry{"test_retry": {Duration: "1ms", MaxRetries: 2}},
+					CircuitBreakers: map[string]goresilience.CircuitBreaker{
+						"test_cb": {MaxRequests: 1, Interval: "never", Timeout: "1m", Failures: 100},
+					},
+					Targets: map[string]goresilience.PolicyNames{
+						"test_target": {Timeout: "test_timeout", Retry: "test_retry", CircuitBreaker: "test_cb", Order: order},
+					},
+				})
+				if err != nil {
+					t.Fatalf("failed to create provider: %v", err)
+				}
+
+				if tt.open {
+					if err := provider.ForceOpen("test_cb", time.Minute); err != nil {
+						t.Fatalf("failed to open the breaker: %v", err)
+					}
+				}
+
+				_, err = goresilience.NewExecutor(context.Background(), provider.Policy("test_target"))(func(ctx context.Context) (any, error) {
+					return nil, tt.err
+				})
+
+				var stageErr *goresilience.StageError
+				if !errors.As(err, &stageErr) || stageErr.Stage != tt.stage || stageErr.Target != "test_target" {
+					t.Fatalf("expected the error to be attributed to %q, got %+v", tt.stage, stageErr)
+				}
+				want := testError
+				if tt.open {
+					want = goresilience.ErrOpenState
+				}
+				if !errors.Is(err, want) {
+					t.Fatalf("expected the error to match %v, got: %v", want, err)
+				}
+			})
+		}
+	}
+}
+
+func TestOrderValidation(t *testing.T) {
+	tests := []struct {
+		name       string
+		order      []string
+		expectPath string
+	}{
+		{
+			name:       "unknown stage",
+			order:      []string{"timeout", "bulkhead", "retry"},
+			expectPath: `targets["test_target"].order[1]`,
+		},
+		{
+			name:       "duplicate stage",
+			order:      []string{"timeout", "retry", "retry"},
+			expectPath: `targets["test_target"].order[2]`,
+		},
+		{
+			name:       "duplicate middleware",
+			order:      []string{"middleware", "timeout", "circuitBreaker", "retry", "middleware"},
+			expectPath: `targets["test_target"].order[4]`,
+		},
+		{
+			name:       "missing stage",
+			order:      []string{"retry", "timeout"},
+			expectPath: `targets["test_target"].order`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := goresilience.FromConfig(goresilience.Config{
+				Targets: map[string]goresilience.PolicyNames{
+					"test_target": {Order: tt.order},
+				},
+			})
+
+			var cfgErr *goresilience.ConfigError
+			if !errors.As(err, &cfgErr) || cfgErr.Path != tt.expectPath {
+				t.Fatalf("expected a ConfigError at %s, got: %v", tt.expectPath, err)
+			}
+		})
+	}
+}
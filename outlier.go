@@ -0,0 +1,151 @@
+package goresilience
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	defaultOutlierSensitivity = 2
+	defaultOutlierMinRequests = 5
+
+	// outlierMinKeys is how many keys need enough traffic in a window for
+	// their failure rates to say anything about each other.
+	outlierMinKeys = 3
+)
+
+// outlierDetector ejects keys whose failure rate over a window stands out
+// from their peers. Rates are compared once per window, so that a single
+// failure early in a window cannot make a key look like an outlier.
+type outlierDetector struct {
+	window      time.Duration
+	cooldown    time.Duration
+	sensitivity float64
+	minRequests uint32
+
+	mu        sync.Mutex
+	windowEnd time.Time
+}
+
+type outlierWindow struct {
+	requests uint32
+	failures uint32
+}
+
+func newOutlierDetector(cfg OutlierDetection, maxKeys int, unit time.Duration) (*outlierDetector, error) {
+	if cfg == (OutlierDetection{}) {
+		return nil, nil
+	}
+
+	var errs configErrors
+
+	if maxKeys <= 0 {
+		errs.add("", "outlier detection requires maxKeys")
+	}
+
+	window := errs.requiredDuration("window", cfg.Window, unit)
+	cooldown := errs.requiredDuration("cooldown", cfg.Cooldown, unit)
+
+	if cfg.Sensitivity < 0 {
+		errs.add("sensitivity", "must not be negative, got %v", cfg.Sensitivity)
+	}
+
+	if cfg.MinRequests < 0 {
+		errs.add("minRequests", "must not be negative, got %d", cfg.MinRequests)
+	}
+
+	if err := errs.err(); err != nil {
+		return nil, err
+	}
+
+	d := &outlierDetector{
+		window:      window,
+		cooldown:    cooldown,
+		sensitivity: cfg.Sensitivity,
+		minRequests: uint32(cfg.MinRequests),
+	}
+	if d.sensitivity == 0 {
+		d.sensitivity = defaultOutlierSensitivity
+	}
+	if d.minRequests == 0 {
+		d.minRequests = defaultOutlierMinRequests
+	}
+
+	return d, nil
+}
+
+func (e *breakerEntry) observe(success bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.outliers.requests++
+	if !success {
+		e.outliers.failures++
+	}
+}
+
+func (e *breakerEntry) takeWindow() outlierWindow {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	w := e.outliers
+	e.outliers = outlierWindow{}
+	return w
+}
+
+// detectOutliers closes the current window once it has elapsed, and ejects
+// every closed key whose failure rate is more than sensitivity standard
+// deviations above the mean of its peers, the other keys with enough
+// requests. Leaving the key itself out keeps an outlier from inflating the
+// deviation it is measured against, which with a handful of keys would
+// hide it entirely.
+func (cb *circuitBreaker) detectOutliers(now time.Time) {
+	d := cb.outliers
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.windowEnd.IsZero() {
+		d.windowEnd = now.Add(d.window)
+		return
+	}
+
+	if now.Before(d.windowEnd) {
+		return
+	}
+	d.windowEnd = now.Add(d.window)
+
+	var entries []*breakerEntry
+	var rates []float64
+	cb.keys.each(func(_ string, entry *breakerEntry) {
+		w := entry.takeWindow()
+		if w.requests < d.minRequests {
+			return
+		}
+
+		entries = append(entries, entry)
+		rates = append(rates, float64(w.failures)/float64(w.requests))
+	})
+
+	if len(rates) < outlierMinKeys {
+		return
+	}
+
+	var sum, sumSquares float64
+	for _, r := range rates {
+		sum += r
+		sumSquares += r * r
+	}
+
+	peers := float64(len(rates) - 1)
+	for i, entry := range entries {
+		mean := (sum - rates[i]) / peers
+		variance := (sumSquares-rates[i]*rates[i])/peers - mean*mean
+		stddev := math.Sqrt(max(variance, 0))
+
+		if rates[i] > mean+d.sensitivity*stddev && entry.breaker.State() == StateClosed {
+			entry.breaker.trip(now.Add(d.cooldown))
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package goresilience_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	goresilience "github.com/rickKoch/go-resilience"
+)
+
+func outlierProvider(t *testing.T) (*goresilience.Provider, *fakeClock) {
+	t.Helper()
+
+	provider, err := goresilience.FromConfig(goresilience.Config{
+		CircuitBreakers: map[string]goresilience.CircuitBreaker{
+			"test_cb": {
+				MaxRequests: 1,
+				Interval:    goresilience.IntervalNever,
+				Timeout:     "10s",
+				Failures:    100,
+				MaxKeys:     100,
+				OutlierDetection: goresilience.OutlierDetection{
+					Window:      "1m",
+					Cooldown:    "30s",
+					MinRequests: 10,
+				},
+			},
+		},
+		Targets: map[string]goresilience.PolicyNames{
+			"test_target": {CircuitBreaker: "test_cb"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	clock := &fakeClock{}
+	goresilience.SetBreakerClock(provider, "test_cb", clock.Now)
+
+	return provider, clock
+}
+
+func keyedCall(provider *goresilience.Provider, key string, fail bool) error {
+	ctx := goresilience.WithBreakerKey(context.Background(), key)
+	exec := goresilience.NewExecWithPolicy(ctx, provider.Policy("test_target"))
+	_, err := exec(func(ctx context.Context) (any, error) {
+		if fail {
+			return nil, errors.New("endpoint failed")
+		}
+		return successResult, nil
+	})
+	return err
+}
+
+// runWindow sends 20 calls to each key, failing failures[key] of them, and
+// then closes the window.
+func runWindow(provider *goresilience.Provider, clock *fakeClock, failures map[string]int) {
+	for i := 0; i < 20; i++ {
+		for key, n := range failures {
+			_ = keyedCall(provider, key, i < n)
+		}
+	}
+
+	clock.Advance(time.Minute)
+	_ = keyedCall(provider, "trigger", false)
+}
+
+func TestOutlierEjection(t *testing.T) {
+	provider, clock := outlierProvider(t)
+
+	// Open the first window.
+	_ = keyedCall(provider, "trigger", false)
+
+	failures := map[string]int{"outlier": 12}
+	for i := 0; i < 9; i++ {
+		failures[fmt.Sprintf("peer-%d", i)] = i % 3
+	}
+	runWindow(provider, clock, failures)
+
+	for key := range failures {
+		want := goresilience.StateClosed
+		if key == "outlier" {
+			want = goresilience.StateOpen
+		}
+
+		if state, _ := provider.BreakerState("test_cb", key); state != want {
+			t.Fatalf("key %s: expected %s, got %s", key, want, state)
+		}
+	}
+
+	if err := keyedCall(provider, "outlier", false); !errors.Is(err, goresilience.ErrOpenState) {
+		t.Fatalf("expected the ejected key to be rejected with ErrOpenState, got: %v", err)
+	}
+
+	clock.Advance(30*time.Second + time.Millisecond)
+	if state, _ := provider.BreakerState("test_cb", "outlier"); state != goresilience.StateHalfOpen {
+		t.Fatalf("expected the ejected key to probe after the cooldown, got %s", state)
+	}
+}
+
+func TestOutlierEjectionAmongFewKeys(t *testing.T) {
+	provider, clock := outlierProvider(t)
+
+	_ = keyedCall(provider, "trigger", false)
+
+	// Measured against all three keys, the outlier could never stand more
+	// than two standard deviations above their mean.
+	failures := map[string]int{"outlier": 12, "peer-0": 1, "peer-1": 2}
+	runWindow(provider, clock, failures)
+
+	for key := range failures {
+		want := goresilience.StateClosed
+		if key == "outlier" {
+			want = goresilience.StateOpen
+		}
+
+		if state, _ := provider.BreakerState("test_cb", key); state != want {
+			t.Fatalf("key %s: expected %s, got %s", key, want, state)
+		}
+	}
+}
+
+func TestOutlierUniformFailures(t *testing.T) {
+	provider, clock := outlierProvider(t)
+
+	_ = keyedCall(provider, "trigger", false)
+
+	failures := make(map[string]int)
+	for i := 0; i < 10; i++ {
+		failures[fmt.Sprintf("peer-%d", i)] = 8
+	}
+	runWindow(provider, clock, failures)
+
+	for key := range failures {
+		if state, _ := provider.BreakerState("test_cb", key); state != goresilience.StateClosed {
+			t.Fatalf("key %s: expected no ejection for uniform failures, got %s", key, state)
+		}
+	}
+}
+
+func TestOutlierDetectionRequiresKeys(t *testing.T) {
+	_, err := goresilience.FromConfig(goresilience.Config{
+		CircuitBreakers: map[string]goresilience.CircuitBreaker{
+			"test_cb": {
+				Interval: "10s",
+				Failures: 1,
+				OutlierDetection: goresilience.OutlierDetection{
+					Window:   "1m",
+					Cooldown: "30s",
+				},
+			},
+		},
+	})
+	if err == nil {
+		t.Fatal("expected an error for outlier detection without maxKeys")
+	}
+}
@@ -0,0 +1,36 @@
+package goresilience
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+)
+
+// PanicError is returned in place of a panic raised by an operation. Stack
+// is the goroutine stack captured at the point of recovery.
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("operation panicked: %v", e.Value)
+}
+
+// Unwrap exposes the panic value when it is an error.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+func recoverPanics(oper Operation) Operation {
+	return func(ctx context.Context) (res any, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				res, err = nil, &PanicError{Value: r, Stack: debug.Stack()}
+			}
+		}()
+
+		return oper(ctx)
+	}
+}
@@ -0,0 +1,96 @@
+package goresilience_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	goresilience "github.com/rickKoch/go-resilience"
+)
+
+func TestPanicRecovery(t *testing.T) {
+	tests := []struct {
+		name   string
+		target goresilience.PolicyNames
+	}{
+		{name: "no policy", target: goresilience.PolicyNames{}},
+		{name: "only retry", target: goresilience.PolicyNames{Retry: "test_retry"}},
+		{name: "only breaker", target: goresilience.PolicyNames{CircuitBreaker: "test_cb"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, err := goresilience.FromConfig(goresilience.Config{
+				Retries: map[string]goresilience.Retry{
+					"test_retry": {Duration: "1ms", MaxRetries: 2},
+				},
+				CircuitBreakers: map[string]goresilience.CircuitBreaker{
+					"test_cb": {MaxRequests: 1, Interval: "10s", Timeout: "10s", Failures: 1},
+				},
+				Targets: map[string]goresilience.PolicyNames{
+					"test_target": tt.target,
+				},
+			})
+			if err != nil {
+				t.Fatalf("failed to create provider: %v", err)
+			}
+
+			exec := goresilience.NewExecWithPolicy(context.Background(), provider.Policy("test_target"))
+			_, err = exec(func(ctx context.Context) (any, error) {
+				panic("boom")
+			})
+
+			var panicErr *goresilience.PanicError
+			if !errors.As(err, &panicErr) {
+				t.Fatalf("expected PanicError, got: %v", err)
+			}
+			if panicErr.Value != "boom" {
+				t.Fatalf("expected panic value boom, got %v", panicErr.Value)
+			}
+			if !strings.Contains(string(panicErr.Stack), "panic_test.go") {
+				t.Fatalf("expected stack to include the panicking operation, got:\n%s", panicErr.Stack)
+			}
+
+			if tt.target.CircuitBreaker != "" {
+				if state, _ := provider.BreakerState("test_cb"); state != goresilience.StateOpen {
+					t.Fatalf("expected panic to count as a breaker failure, got %s", state)
+				}
+			}
+		})
+	}
+}
+
+func TestPanicErrorUnwrapsErrorValues(t *testing.T) {
+	provider, err := goresilience.FromConfig(goresilience.Config{})
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	exec := goresilience.NewExecWithPolicy(context.Background(), provider.Policy("test_target"))
+	_, err = exec(func(ctx context.Context) (any, error) {
+		panic(testError)
+	})
+	if !errors.Is(err, testError) {
+		t.Fatalf("expected panic error to unwrap to the panic value, got: %v", err)
+	}
+}
+
+func TestPanicRecoveryDisabled(t *testing.T) {
+	provider, err := goresilience.FromConfigWithOptions(goresilience.Config{}, goresilience.WithPanicRecovery(false))
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	defer func() {
+		if r := recover(); r != "boom" {
+			t.Fatalf("expected panic to propagate, got %v", r)
+		}
+	}()
+
+	exec := goresilience.NewExecWithPolicy(context.Background(), provider.Policy("test_target"))
+	_, _ = exec(func(ctx context.Context) (any, error) {
+		panic("boom")
+	})
+	t.Fatal("expected panic to propagate")
+}
@@ -0,0 +1,99 @@
+package goresilience
+
+import (
+	"errors"
+	"slices"
+	"sync"
+)
+
+var permanentErrors = struct {
+	sync.RWMutex
+	next     uint64
+	errs     []registered[error]
+	matchers []registered[func(error) bool]
+}{}
+
+// registered is a permanent error or matcher, with the id its unregister
+// func removes it by, since neither can be compared reliably.
+type registered[T any] struct {
+	id  uint64
+	val T
+}
+
+// RegisterPermanentError makes retries stop at once on errors that match
+// err with errors.Is. The returned func removes the registration again, for
+// example in a test's cleanup.
+func RegisterPermanentError(err error) (unregister func()) {
+	if err == nil {
+		return func() {}
+	}
+
+	permanentErrors.Lock()
+	defer permanentErrors.Unlock()
+
+	permanentErrors.next++
+	id := permanentErrors.next
+	permanentErrors.errs = append(permanentErrors.errs, registered[error]{id, err})
+
+	return func() {
+		permanentErrors.Lock()
+		defer permanentErrors.Unlock()
+
+		permanentErrors.errs = withoutRegistration(permanentErrors.errs, id)
+	}
+}
+
+// RegisterPermanentMatcher makes retries stop at once on errors match
+// reports true for. The returned func removes the registration again.
+func RegisterPermanentMatcher(match func(error) bool) (unregister func()) {
+	if match == nil {
+		return func() {}
+	}
+
+	permanentErrors.Lock()
+	defer permanentErrors.Unlock()
+
+	permanentErrors.next++
+	id := permanentErrors.next
+	permanentErrors.matchers = append(permanentErrors.matchers, registered[func(error) bool]{id, match})
+
+	return func() {
+		permanentErrors.Lock()
+		defer permanentErrors.Unlock()
+
+		permanentErrors.matchers = withoutRegistration(permanentErrors.matchers, id)
+	}
+}
+
+func withoutRegistration[T any](entries []registered[T], id uint64) []registered[T] {
+	return slices.DeleteFunc(entries, func(e registered[T]) bool {
+		return e.id == id
+	})
+}
+
+func IsErrorPermanent(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if errors.Is(err, ErrOpenState) || errors.Is(err, ErrTooManyRequests) || errors.Is(err, ErrInsufficientDeadline) {
+		return true
+	}
+
+	permanentErrors.RLock()
+	defer permanentErrors.RUnlock()
+
+	for _, target := range permanentErrors.errs {
+		if errors.Is(err, target.val) {
+			return true
+		}
+	}
+
+	for _, match := range permanentErrors.matchers {
+		if match.val(err) {
+			return true
+		}
+	}
+
+	return false
+}
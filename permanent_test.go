@@ -0,0 +1,136 @@
+package goresilience_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	goresilience "github.com/rickKoch/go-resilience"
+)
+
+var errQuotaExceeded = errors.New("quota exceeded")
+
+func permanentRetryExecutor(t *testing.T) goresilience.Executor {
+	t.Helper()
+
+	cfg := goresilience.Config{
+		Retries: map[string]goresilience.Retry{
+			"test_retry": {
+				Duration:   "1ms",
+				MaxRetries: 5,
+			},
+		},
+		Targets: map[string]goresilience.PolicyNames{
+			"test_target": {
+				Retry: "test_retry",
+			},
+		},
+	}
+
+	provider, err := goresilience.FromConfig(cfg)
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	return goresilience.NewExecutor(context.Background(), provider.Policy("test_target"))
+}
+
+func TestRegisterPermanentError(t *testing.T) {
+	errQuota := errors.New("quota exceeded")
+	t.Cleanup(goresilience.RegisterPermanentError(errQuota))
+
+	attempts := atomic.Int32{}
+	exec := permanentRetryExecutor(t)
+	_, err := exec(func(ctx context.Context) (any, error) {
+		attempts.Add(1)
+		return nil, fmt.Errorf("calling api: %w", errQuota)
+	})
+
+	if !errors.Is(err, errQuota) {
+		t.Fatalf("expected quota error, got: %v", err)
+	}
+	if attempts.Load() != 1 {
+		t.Fatalf("expected 1 attempt but got: %d", attempts.Load())
+	}
+}
+
+func TestRegisterPermanentMatcher(t *testing.T) {
+	errAuthRevoked := errors.New("auth revoked")
+	t.Cleanup(goresilience.RegisterPermanentMatcher(func(err error) bool {
+		return strings.Contains(err.Error(), "revoked")
+	}))
+
+	attempts := atomic.Int32{}
+	exec := permanentRetryExecutor(t)
+	_, err := exec(func(ctx context.Context) (any, error) {
+		attempts.Add(1)
+		return nil, errAuthRevoked
+	})
+
+	if !errors.Is(err, errAuthRevoked) {
+		t.Fatalf("expected auth error, got: %v", err)
+	}
+	if attempts.Load() != 1 {
+		t.Fatalf("expected 1 attempt but got: %d", attempts.Load())
+	}
+
+	if goresilience.IsErrorPermanent(testError) {
+		t.Fatal("unregistered errors should not be permanent")
+	}
+	if !goresilience.IsErrorPermanent(goresilience.ErrOpenState) || !goresilience.IsErrorPermanent(goresilience.ErrTooManyRequests) {
+		t.Fatal("breaker errors should always be permanent")
+	}
+}
+
+func TestRegisterPermanentErrorConcurrently(t *testing.T) {
+	exec := permanentRetryExecutor(t)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			t.Cleanup(goresilience.RegisterPermanentError(fmt.Errorf("permanent %d", i)))
+		}()
+		go func() {
+			defer wg.Done()
+			_, _ = exec(func(ctx context.Context) (any, error) {
+				return nil, testError
+			})
+		}()
+	}
+	wg.Wait()
+}
+
+func TestUnregisterPermanentError(t *testing.T) {
+	errGone := errors.New("gone")
+	errAlsoGone := errors.New("also gone")
+
+	unregister := goresilience.RegisterPermanentError(errGone)
+	t.Cleanup(goresilience.RegisterPermanentError(errAlsoGone))
+	unregisterMatcher := goresilience.RegisterPermanentMatcher(func(err error) bool {
+		return errors.Is(err, errGone)
+	})
+
+	unregister()
+	if !goresilience.IsErrorPermanent(errGone) {
+		t.Fatal("expected the matcher to still make the error permanent")
+	}
+
+	unregisterMatcher()
+	if goresilience.IsErrorPermanent(errGone) {
+		t.Fatal("expected the error not to be permanent once unregistered")
+	}
+	if !goresilience.IsErrorPermanent(errAlsoGone) {
+		t.Fatal("expected other registrations to be kept")
+	}
+
+	unregister()
+	if !goresilience.IsErrorPermanent(errAlsoGone) {
+		t.Fatal("expected unregistering twice to be harmless")
+	}
+}
@@ -0,0 +1,58 @@
+package goresilience
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+)
+
+type Permit interface {
+	Success()
+	Failure(err error)
+}
+
+type permit struct {
+	done     func(err error)
+	reported atomic.Bool
+	dupes    *atomic.Uint64
+}
+
+func (p *permit) Success() {
+	p.report(nil)
+}
+
+func (p *permit) Failure(err error) {
+	p.report(err)
+}
+
+func (p *permit) report(err error) {
+	if !p.reported.CompareAndSwap(false, true) {
+		p.dupes.Add(1)
+		return
+	}
+
+	p.done(err)
+}
+
+func (p *Provider) Acquire(target string) (Permit, error) {
+	if p.isClosed() {
+		return nil, ErrProviderClosed
+	}
+
+	policy := p.Policy(target)
+	if policy.circuitBreaker == nil {
+		return nil, fmt.Errorf("target %q has no circuit breaker", target)
+	}
+
+	cb := policy.circuitBreaker
+	done, err := cb.allow(breakerCall{target: target, events: p.events}, cb.entry(context.Background()))
+	if err != nil {
+		return nil, err
+	}
+
+	return &permit{done: done, dupes: &p.duplicateReports}, nil
+}
+
+func (p *Provider) DuplicatePermitReports() uint64 {
+	return p.duplicateReports.Load()
+}
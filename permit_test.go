@@ -0,0 +1,77 @@
+package goresilience_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	goresilience "github.com/rickKoch/go-resilience"
+)
+
+func TestAcquireDrivesBreaker(t *testing.T) {
+	provider := eventsProvider(t)
+
+	for i := 0; i < 2; i++ {
+		permit, err := provider.Acquire("test_target")
+		if err != nil {
+			t.Fatalf("expected permit, got: %v", err)
+		}
+		permit.Failure(testError)
+	}
+
+	if state, _ := provider.BreakerState("test_cb"); state != goresilience.StateOpen {
+		t.Fatalf("expected breaker to be open, got %s", state)
+	}
+
+	_, err := provider.Acquire("test_target")
+	if !errors.Is(err, goresilience.ErrOpenState) {
+		t.Fatalf("expected ErrOpenState, got: %v", err)
+	}
+
+	time.Sleep(150 * time.Millisecond)
+
+	permit, err := provider.Acquire("test_target")
+	if err != nil {
+		t.Fatalf("expected half-open probe permit, got: %v", err)
+	}
+
+	// Only one probe is allowed while half-open.
+	if _, err := provider.Acquire("test_target"); !errors.Is(err, goresilience.ErrTooManyRequests) {
+		t.Fatalf("expected ErrTooManyRequests, got: %v", err)
+	}
+
+	permit.Success()
+
+	if state, _ := provider.BreakerState("test_cb"); state != goresilience.StateClosed {
+		t.Fatalf("expected breaker to close after success, got %s", state)
+	}
+}
+
+func TestPermitIsSingleUse(t *testing.T) {
+	provider := eventsProvider(t)
+
+	permit, err := provider.Acquire("test_target")
+	if err != nil {
+		t.Fatalf("expected permit, got: %v", err)
+	}
+
+	permit.Failure(testError)
+	permit.Failure(testError)
+	permit.Success()
+
+	counts, _ := provider.BreakerCounts("test_cb")
+	if counts.TotalFailures != 1 || counts.TotalSuccesses != 0 {
+		t.Fatalf("expected a single recorded failure, got %+v", counts)
+	}
+	if provider.DuplicatePermitReports() != 2 {
+		t.Fatalf("expected 2 duplicate reports, got %d", provider.DuplicatePermitReports())
+	}
+}
+
+func TestAcquireWithoutBreaker(t *testing.T) {
+	provider := eventsProvider(t)
+
+	if _, err := provider.Acquire("unknown_target"); err == nil {
+		t.Fatal("expected error for target without circuit breaker")
+	}
+}
@@ -2,14 +2,24 @@ package goresilience
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/cenkalti/backoff/v4"
+	"golang.org/x/sync/singleflight"
 )
 
 type Operation func(ctx context.Context) (any, error)
 
+// Executor runs operations under a policy. One executor may be called from
+// any number of goroutines at once: each call builds its own chain of
+// stages and retry backoff, and the state the stages share between calls,
+// such as breakers, bulkheads, caches and counters, is guarded by locks or
+// atomics.
 type Executor func(oper Operation) (any, error)
 
 type operationResult struct {
@@ -17,95 +27,399 @@ type operationResult struct {
 	err   error
 }
 
+// Policy is a target's resolved set of stages. It is never modified once
+// built, so it may be shared by any number of executors and goroutines.
 type Policy struct {
-	timeout        time.Duration
+	target         string
+	name           string // as passed to Policy, possibly an alias of target
+	timeout        *timeout
+	overallTimeout *timeout
+	minDeadline    time.Duration
+	order          []string
 	retry          *retry
 	circuitBreaker *circuitBreaker
+	bulkhead       *bulkhead
+	limiter        *adaptiveLimiter
+	health         *healthGate
+	shedder        *shedder
+	cache          *resultCache
+	flight         *singleflight.Group
+	coalescer      *coalescer
+	fallback       *fallback
+	events         *eventBus
+	recoverPanics  bool
+	inFlight       *atomic.Int64
+	counters       *targetCounters
+	observer       Observer
+	execObserver   executionObserver
+	traces         *traceRing
+	middleware     []Middleware
+	callMiddleware []Middleware
+	interceptors   []Interceptor
+
+	chainOnce sync.Once
+	chainOp   Operation
+
+	// provider, generation and settings let an executor notice that the
+	// provider's config, or a setting such as a fallback, has changed since
+	// the policy was resolved.
+	provider   *Provider
+	generation uint64
+	settings   uint64
 }
 
+// ExecutorCtx is an Executor that takes each call's context, so that one
+// executor can serve many requests.
+type ExecutorCtx func(ctx context.Context, oper Operation) (any, error)
+
+// NewExecutor returns an executor that runs every operation under policy
+// with ctx. Use NewContextExecutor or Policy.Execute when the context
+// differs between calls.
 func NewExecutor(ctx context.Context, policy *Policy) Executor {
-	if policy == nil {
-		return func(oper Operation) (any, error) {
-			return oper(ctx)
-		}
+	return func(oper Operation) (any, error) {
+		return policy.Execute(ctx, oper)
 	}
+}
 
-	return func(oper Operation) (any, error) {
-		operation := oper
+// NewContextExecutor returns an executor that runs every operation under
+// policy with the context of the call.
+func NewContextExecutor(policy *Policy) ExecutorCtx {
+	return policy.Execute
+}
 
-		if policy.timeout > 0 {
-			operation = policy.withTimeout(operation)
-		}
+// Execute runs oper under the policy. Every stage, from the timeouts to the
+// retry backoff, works from ctx. A nil policy runs oper as is.
+func (p *Policy) Execute(ctx context.Context, oper Operation) (any, error) {
+	return p.execute(ctx, oper, nil)
+}
 
-		if policy.circuitBreaker != nil {
-			operation = policy.withCircuitBreaker(operation)
-		}
+// execute runs oper under the policy, recording it in trace if it is set,
+// or in a trace of its own if the provider keeps them.
+func (p *Policy) execute(ctx context.Context, oper Operation, trace *tracer) (any, error) {
+	if p == nil {
+		return oper(ctx)
+	}
 
-		if policy.retry == nil {
-			return operation(ctx)
-		}
+	if p.provider != nil && p.provider.isClosed() {
+		return nil, ErrProviderClosed
+	}
+
+	policy := p.latest()
+	policy.counters.executed()
+
+	start := time.Now()
+
+	if trace == nil && policy.traces != nil {
+		trace = new(tracer)
+	}
+	if trace != nil {
+		ctx = startTrace(ctx, trace, policy.target)
+	}
+	ctx = context.WithValue(ctx, callKey{}, &call{target: policy.target, oper: oper, trace: trace})
+
+	res, err := policy.chain()(ctx)
+	err = policy.attributeError(err)
+	elapsed := time.Since(start)
+	policy.counters.done(elapsed, err)
+	if policy.execObserver != nil {
+		policy.execObserver.onDone(policy.target, elapsed, err)
+	}
+	if policy.traces != nil {
+		policy.traces.add(trace.finish(err))
+	}
+
+	return res, err
+}
+
+// callKey carries a call's target and operation, and its trace if it has
+// one, down a policy's chain, which is built once and shared by every call.
+type callKey struct{}
+
+type call struct {
+	target string
+	oper   Operation
+	trace  *tracer
+}
+
+func callFrom(ctx context.Context) *call {
+	c, _ := ctx.Value(callKey{}).(*call)
+	return c
+}
+
+// runCall is the innermost end of every chain: the operation of the call
+// that ctx belongs to.
+func runCall(ctx context.Context) (any, error) {
+	return callFrom(ctx).oper(ctx)
+}
+
+// chain returns the policy's stages composed around runCall. Only the parts
+// that need the call, such as the retry backoff, are made per call.
+func (p *Policy) chain() Operation {
+	p.chainOnce.Do(func() {
+		p.chainOp = p.buildChain()
+	})
+
+	return p.chainOp
+}
+
+func (p *Policy) buildChain() Operation {
+	operation := Operation(runCall)
+
+	if p.recoverPanics {
+		operation = recoverPanics(operation)
+	}
+
+	if len(p.interceptors) > 0 {
+		operation = p.withInterceptors(operation)
+	}
+
+	if p.counters != nil {
+		operation = p.counters.timeAttempts(operation)
+	}
+
+	if p.execObserver != nil {
+		operation = p.execObserver.observeAttempts(p.target, operation)
+	}
+
+	operation = traceAttempts(operation)
+
+	order := p.order
+	if order == nil {
+		order = defaultOrder
+	}
+
+	if !slices.Contains(order, StageMiddleware) {
+		operation = withMiddleware(p.middleware, operation)
+	}
+
+	for _, stage := range order[:len(order)-1] {
+		operation = p.withStage(stage, operation)
+	}
+
+	if p.bulkhead != nil {
+		operation = p.withBulkhead(operation)
+	}
+
+	if p.limiter != nil {
+		operation = p.withAdaptiveLimit(operation)
+	}
+
+	if p.minDeadline > 0 {
+		operation = p.withMinDeadline(operation)
+	}
+
+	operation = p.withStage(order[len(order)-1], operation)
+
+	if p.overallTimeout != nil && p.overallTimeout.duration > 0 {
+		operation = p.withOverallTimeout(operation)
+	}
+
+	if p.shedder != nil {
+		operation = p.withShed(operation)
+	}
+
+	if p.health != nil {
+		operation = p.withHealthGate(operation)
+	}
+
+	if p.cache != nil {
+		operation = p.withCache(operation)
+	}
+
+	if p.flight != nil {
+		operation = p.withSingleflight(operation)
+	}
+
+	if p.coalescer != nil {
+		operation = p.withCoalesce(operation)
+	}
+
+	if p.fallback != nil {
+		operation = p.withFallback(operation)
+	}
+
+	return withMiddleware(p.callMiddleware, operation)
+}
+
+// latest returns the policy the provider now resolves for p's target, or p
+// itself if nothing has changed since p was resolved.
+func (p *Policy) latest() *Policy {
+	if p.provider == nil {
+		return p
+	}
 
-		return policy.withRetry(ctx, operation)
+	if p.provider.current().generation == p.generation && p.provider.settings.Load() == p.settings {
+		return p
 	}
+
+	return p.provider.policy(p.name)
 }
 
 func NewExecWithPolicy(ctx context.Context, policy *Policy) Executor {
 	return NewExecutor(ctx, policy)
 }
 
-func (p *Policy) withTimeout(oper Operation) Operation {
-	return func(ctx context.Context) (any, error) {
-		timeoutCtx, cancel := context.WithTimeout(ctx, p.timeout)
-		defer cancel()
-
-		resultCh := make(chan operationResult, 1)
-
-		go func() {
-			defer func() {
-				if r := recover(); r != nil {
-					// Handle panics gracefully
-					select {
-					case resultCh <- operationResult{nil, fmt.Errorf("operation panicked: %v", r)}:
-					default:
-					}
-				}
-			}()
+// detached runs an operation in its own goroutine so that it is cut off at
+// timeout even if it ignores its context. Once the timeout fires, the
+// operation gets up to grace to clean up after its cancelled context, and
+// its error is reported as a graceError. With a drain window, the caller
+// additionally waits that long for an abandoned operation to finish, and
+// onLeak is called if it still hasn't.
+type detached struct {
+	timeout  time.Duration
+	grace    time.Duration
+	drain    time.Duration
+	onLeak   func()
+	inFlight *atomic.Int64
+}
+
+// graceError carries the error of an operation that returned within its
+// grace period after the timeout fired.
+type graceError struct {
+	err error
+}
+
+func (e *graceError) Error() string {
+	return fmt.Sprintf("%s, operation cleaned up", context.DeadlineExceeded)
+}
 
-			value, err := oper(timeoutCtx)
+func (e *graceError) Unwrap() []error {
+	return []error{context.DeadlineExceeded, e.err}
+}
 
-			select {
-			case resultCh <- operationResult{value, err}:
-			case <-timeoutCtx.Done():
-				// Operation completed but context already timed out
+// run runs oper under d's deadline. fired reports that the operation was
+// cut short, by that deadline or the caller's, rather than returning on its
+// own; a deadline error oper returns by itself is passed through as is.
+func (d detached) run(ctx context.Context, oper Operation) (res any, fired bool, err error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, d.timeout)
+	defer cancel()
+
+	resultCh := make(chan operationResult, 1)
+	done := make(chan struct{})
+
+	if d.inFlight != nil {
+		d.inFlight.Add(1)
+	}
+
+	go func() {
+		defer close(done)
+		if d.inFlight != nil {
+			defer d.inFlight.Add(-1)
+		}
+		defer func() {
+			if r := recover(); r != nil {
+				// Handle panics gracefully
+				select {
+				case resultCh <- operationResult{nil, fmt.Errorf("operation panicked: %v", r)}:
+				default:
+				}
 			}
 		}()
 
-		// Wait for either operation completion or timeout
+		value, err := oper(timeoutCtx)
+
+		// Never blocks: the channel is buffered and only written once.
+		resultCh <- operationResult{value, err}
+	}()
+
+	// Wait for either operation completion or timeout
+	select {
+	case result := <-resultCh:
+		return result.value, false, result.err
+	case <-timeoutCtx.Done():
+	}
+
+	err = timeoutCtx.Err()
+
+	if d.grace > 0 {
+		grace := time.NewTimer(d.grace)
+		defer grace.Stop()
+
 		select {
 		case result := <-resultCh:
-			return result.value, result.err
-		case <-timeoutCtx.Done():
-			return nil, timeoutCtx.Err()
+			return nil, true, &graceError{err: result.err}
+		case <-grace.C:
 		}
 	}
+
+	if d.drain <= 0 {
+		return nil, true, err
+	}
+
+	drain := time.NewTimer(d.drain)
+	defer drain.Stop()
+
+	select {
+	case <-done:
+	case <-drain.C:
+		if d.onLeak != nil {
+			d.onLeak()
+		}
+	}
+
+	return nil, true, err
 }
 
 func (p *Policy) withCircuitBreaker(oper Operation) Operation {
+	call := breakerCall{target: p.target, events: p.events, counters: p.counters, observer: p.observer}
+
 	return func(ctx context.Context) (any, error) {
-		res, err := p.circuitBreaker.breaker.Execute(func() (any, error) {
-			return oper(ctx)
-		})
+		res, err := p.circuitBreaker.execute(ctx, call, oper)
 
-		if p.retry != nil && IsErrorPermanent(err) {
-			err = backoff.Permanent(err)
+		var open *CircuitOpenError
+		if err != nil && errors.As(err, &open) && open.Breaker == p.circuitBreaker.name {
+			traceFailure(ctx, StageCircuitBreaker, "rejected: %v", open.Err)
+			err = p.stageError(StageCircuitBreaker, err)
 		}
 
 		return res, err
 	}
 }
 
-func (p *Policy) withRetry(ctx context.Context, oper Operation) (any, error) {
-	return OperationRetry(func() (any, error) {
-		return oper(ctx)
-	}, p.retry.backoff(ctx))
+func (p *Policy) withRetry(oper Operation) Operation {
+	return func(ctx context.Context) (any, error) {
+		attempts := 0
+		permanent := false
+
+		attempt := func() (any, error) {
+			if attempts > 0 {
+				p.counters.retried()
+			}
+			attempts++
+
+			res, err := oper(ctx)
+
+			var perm *backoff.PermanentError
+			permanent = errors.As(err, &perm)
+			if !permanent && IsErrorPermanent(err) {
+				err = backoff.Permanent(err)
+				permanent = true
+			}
+
+			return res, err
+		}
+
+		var (
+			res any
+			err error
+		)
+		if p.observer == nil && p.traces == nil {
+			res, err = OperationRetry(attempt, p.retry.backoff(ctx))
+		} else {
+			res, err = backoff.RetryNotifyWithData(attempt, p.retry.backoff(ctx), func(err error, delay time.Duration) {
+				if p.observer != nil {
+					p.observer.OnRetry(p.target, attempts, delay, err)
+				}
+				traceStep(ctx, StageRetry, "backing off %s after attempt %d", delay, attempts)
+			})
+		}
+
+		// Retries that ended on a permanent error or with ctx did not
+		// run out.
+		if err != nil && !permanent && ctx.Err() == nil && p.retry.maxRetries != 0 {
+			err = p.stageError(StageRetry, fmt.Errorf("%w after %d attempts: %w", ErrRetriesExhausted, attempts, err))
+		}
+
+		return res, err
+	}
 }
@@ -0,0 +1,309 @@
+package goresilience_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	goresilience "github.com/rickKoch/go-resilience"
+)
+
+func TestPolicyMemoized(t *testing.T) {
+	provider, err := goresilience.FromConfig(reloadConfig(1, reloadBreaker))
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	policy := provider.Policy("test_target")
+	if provider.Policy("test_target") != policy {
+		t.Fatal("expected repeated lookups to return the same policy")
+	}
+	if provider.Policy("other_target") == policy {
+		t.Fatal("expected another target to get its own policy")
+	}
+
+	if err := provider.UpdateConfig(reloadConfig(2, reloadBreaker)); err != nil {
+		t.Fatalf("failed to update config: %v", err)
+	}
+
+	updated := provider.Policy("test_target")
+	if updated == policy {
+		t.Fatal("expected a new policy after a config update")
+	}
+
+	provider.SetFallback("test_target", func(ctx context.Context, err error) (any, error) {
+		return successResult, nil
+	})
+	if provider.Policy("test_target") == updated {
+		t.Fatal("expected a new policy after setting a fallback")
+	}
+}
+
+func BenchmarkProviderPolicy(b *testing.B) {
+	provider, err := goresilience.FromConfig(reloadConfig(1, reloadBreaker))
+	if err != nil {
+		b.Fatalf("failed to create provider: %v", err)
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		provider.Policy("test_target")
+	}
+}
+
+func strictConfig() goresilience.Config {
+	cfg := reloadConfig(1, reloadBreaker)
+	cfg.Aliases = map[string][]string{"test_target": {"pkg.Test/Call"}}
+	cfg.Groups = map[string]goresilience.TargetGroup{
+		"grouped": {PolicyNames: goresilience.PolicyNames{Retry: "test_retry"}, Members: []string{"db.*"}},
+	}
+	return cfg
+}
+
+func TestPolicyE(t *testing.T) {
+	provider, err := goresilience.FromConfig(strictConfig())
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	for _, name := range []string{"test_target", "pkg.Test/Call", "db.users"} {
+		if policy, err := provider.PolicyE(name); err != nil || policy != provider.Policy(name) {
+			t.Errorf("%s: expected the target's policy, got %v", name, err)
+		}
+	}
+
+	if _, err := provider.PolicyE("test_targte"); !errors.Is(err, goresilience.ErrUnknownTarget) {
+		t.Fatalf("expected ErrUnknownTarget, got: %v", err)
+	}
+
+	// Without strict targets, Policy still falls back to an empty policy.
+	exec := goresilience.NewExecWithPolicy(context.Background(), provider.Policy("test_targte"))
+	if calls := countAttempts(exec); calls != 1 {
+		t.Fatalf("expected no retries for an unknown target, got %d attempts", calls)
+	}
+}
+
+func TestPolicyEDefaultTimeout(t *testing.T) {
+	cfg := strictConfig()
+	cfg.Defaults.Timeout = "1s"
+
+	provider, err := goresilience.FromConfig(cfg)
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	if _, err := provider.PolicyE("anything"); err != nil {
+		t.Fatalf("expected the default timeout to cover every name, got: %v", err)
+	}
+
+	cfg.Defaults.Timeout = goresilience.TimeoutNone
+	if err := provider.UpdateConfig(cfg); err != nil {
+		t.Fatalf("failed to update config: %v", err)
+	}
+
+	if _, err := provider.PolicyE("anything"); !errors.Is(err, goresilience.ErrUnknownTarget) {
+		t.Fatalf("expected ErrUnknownTarget without a default timeout, got: %v", err)
+	}
+}
+
+func TestStrictTargets(t *testing.T) {
+	provider, err := goresilience.FromConfigWithOptions(strictConfig(), goresilience.WithStrictTargets())
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	provider.Policy("test_target")
+	provider.Policy("db.users")
+
+	defer func() {
+		if err, ok := recover().(error); !ok || !errors.Is(err, goresilience.ErrUnknownTarget) {
+			t.Fatalf("expected a panic with ErrUnknownTarget, got %v", err)
+		}
+	}()
+	provider.Policy("test_targte")
+}
+
+func TestUnknownTargetHandler(t *testing.T) {
+	var reported []error
+	provider, err := goresilience.FromConfigWithOptions(strictConfig(), goresilience.WithUnknownTargetHandler(func(err error) {
+		reported = append(reported, err)
+	}))
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	provider.Policy("test_target")
+	if policy := provider.Policy("test_targte"); policy == nil {
+		t.Fatal("expected an empty policy for the unknown target")
+	}
+
+	if len(reported) != 1 || !errors.Is(reported[0], goresilience.ErrUnknownTarget) {
+		t.Fatalf("expected one unknown target to be reported, got %v", reported)
+	}
+}
+
+func TestUnknownTargetHandlerNil(t *testing.T) {
+	provider, err := goresilience.FromConfigWithOptions(strictConfig(), goresilience.WithUnknownTargetHandler(nil))
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	if policy := provider.Policy("test_targte"); policy == nil {
+		t.Fatal("expected an empty policy for the unknown target")
+	}
+
+	defer func() {
+		if err, ok := recover().(error); !ok || !errors.Is(err, goresilience.ErrUnknownTarget) {
+			t.Fatalf("expected MustPolicy to stay strict, got %v", err)
+		}
+	}()
+	provider.MustPolicy("test_targte")
+}
+
+func TestMustPolicy(t *testing.T) {
+	provider, err := goresilience.FromConfigWithOptions(strictConfig(), goresilience.WithUnknownTargetHandler(func(error) {}))
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	for _, name := range []string{"test_target", "pkg.Test/Call", "db.users"} {
+		if policy := provider.MustPolicy(name); policy != provider.Policy(name) {
+			t.Errorf("%s: expected the target's policy", name)
+		}
+	}
+
+	defer func() {
+		err, ok := recover().(error)
+		if !ok || !errors.Is(err, goresilience.ErrUnknownTarget) {
+			t.Fatalf("expected a panic with ErrUnknownTarget, got %v", err)
+		}
+		if !strings.Contains(err.Error(), `"test_targte"`) || !strings.Contains(err.Error(), "[test_target]") {
+			t.Fatalf("expected the panic to name the target and the known ones, got %q", err)
+		}
+	}()
+	provider.MustPolicy("test_targte")
+}
+
+func TestMustPolicyLenient(t *testing.T) {
+	provider, err := goresilience.FromConfig(strictConfig())
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	if policy := provider.MustPolicy("test_targte"); policy != provider.Policy("test_targte") {
+		t.Fatal("expected MustPolicy to behave like Policy without strict targets")
+	}
+}
+
+func TestPolicyOrDefault(t *testing.T) {
+	cfg := strictConfig()
+	cfg.Targets["generic"] = goresilience.PolicyNames{}
+
+	provider, err := goresilience.FromConfigWithOptions(cfg, goresilience.WithStrictTargets())
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	tests := map[string]string{
+		"test_target":   "test_target",
+		"pkg.Test/Call": "pkg.Test/Call",
+		"db.users":      "db.users",
+		"cache.users":   "generic",
+	}
+	for name, want := range tests {
+		if policy := provider.PolicyOrDefault(name, "generic"); policy != provider.Policy(want) {
+			t.Errorf("%s: expected the policy of %s", name, want)
+		}
+	}
+
+	defer func() {
+		if err, ok := recover().(error); !ok || !errors.Is(err, goresilience.ErrUnknownTarget) {
+			t.Fatalf("expected an unknown fallback to panic, got %v", err)
+		}
+	}()
+	provider.PolicyOrDefault("cache.users", "generik")
+}
+
+func TestPolicyOrDefaultDefaultTimeout(t *testing.T) {
+	cfg := strictConfig()
+	cfg.Defaults.Timeout = "1s"
+
+	provider, err := goresilience.FromConfig(cfg)
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	if policy := provider.PolicyOrDefault("anything", "test_target"); policy != provider.Policy("anything") {
+		t.Fatal("expected the default timeout to cover the primary name")
+	}
+}
+
+func TestContextExecutorPerCallDeadline(t *testing.T) {
+	provider, err := goresilience.FromConfig(goresilience.Config{
+		Retries: map[string]goresilience.Retry{"test_retry": {Duration: "1ms", MaxRetries: -1}},
+		Targets: map[string]goresilience.PolicyNames{"test_target": {Retry: "test_retry"}},
+	})
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	exec := goresilience.NewContextExecutor(provider.Policy("test_target"))
+
+	// Retrying forever, each call ends when its own deadline does.
+	deadlines := []time.Duration{20 * time.Millisecond, 200 * time.Millisecond}
+	elapsed := make([]time.Duration, len(deadlines))
+
+	var wg sync.WaitGroup
+	for i, d := range deadlines {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			ctx, cancel := context.WithTimeout(context.Background(), d)
+			defer cancel()
+
+			start := time.Now()
+			_, err := exec(ctx, func(ctx context.Context) (any, error) {
+				if deadline, _ := ctx.Deadline(); time.Until(deadline) > d {
+					t.Errorf("expected the operation to see the call's %s deadline", d)
+				}
+				return nil, errors.New("failed")
+			})
+			elapsed[i] = time.Since(start)
+
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("expected the %s call to end at its deadline, got %v", d, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if elapsed[0] >= deadlines[1] {
+		t.Fatalf("expected the short call to end before the long call's deadline, took %s", elapsed[0])
+	}
+	if elapsed[1] < deadlines[1] {
+		t.Fatalf("expected the long call to run until its own deadline, took %s", elapsed[1])
+	}
+}
+
+func TestPolicyExecute(t *testing.T) {
+	type key struct{}
+
+	provider, err := goresilience.FromConfig(reloadConfig(1, reloadBreaker))
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	for _, policy := range []*goresilience.Policy{provider.Policy("test_target"), nil} {
+		ctx := context.WithValue(context.Background(), key{}, "request-1")
+		res, err := policy.Execute(ctx, func(ctx context.Context) (any, error) {
+			return ctx.Value(key{}), nil
+		})
+		if err != nil || res != "request-1" {
+			t.Fatalf("expected the operation to get the call's context, got %v, %v", res, err)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package goresilience
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// Presets are named combinations of a timeout, a retry and a circuit
+// breaker, each registered under the preset's name.
+var presets = map[string]struct {
+	timeout        TimeoutSpec
+	retry          Retry
+	circuitBreaker CircuitBreaker
+}{
+	// standard suits most calls: retries a few transient failures, backing
+	// off further each time, and opens after a short run of consecutive
+	// ones.
+	"standard": {
+		timeout:        TimeoutSpec{Duration: "5s"},
+		retry:          Retry{Duration: "200ms", MaxRetries: 3, Backoff: RetryBackoffExponential, MaxInterval: "2s"},
+		circuitBreaker: CircuitBreaker{MaxRequests: 1, Interval: "60s", Timeout: "30s", Failures: 5},
+	},
+	// aggressive fails fast for latency-sensitive paths.
+	"aggressive": {
+		timeout:        TimeoutSpec{Duration: "1s"},
+		retry:          Retry{Duration: "50ms", MaxRetries: 1},
+		circuitBreaker: CircuitBreaker{MaxRequests: 1, Interval: "30s", Timeout: "10s", Failures: 3},
+	},
+	// readonly retries idempotent reads more persistently and tolerates
+	// more failures before opening.
+	"readonly": {
+		timeout:        TimeoutSpec{Duration: "2s"},
+		retry:          Retry{Duration: "100ms", MaxRetries: 5},
+		circuitBreaker: CircuitBreaker{MaxRequests: 3, Interval: "60s", Timeout: "15s", Failures: 10},
+	},
+}
+
+// PresetNames lists the presets PresetConfig accepts.
+func PresetNames() []string {
+	return slices.Sorted(maps.Keys(presets))
+}
+
+// PresetConfig returns the timeouts, retries and circuit breakers of the
+// named presets, or of every preset when no names are given. Merge the
+// result into your own Config and reference the entries from Targets by the
+// preset's name. Unknown preset names are an error.
+func PresetConfig(names ...string) (Config, error) {
+	if len(names) == 0 {
+		names = PresetNames()
+	}
+
+	cfg := Config{
+		Timeouts:        make(map[string]TimeoutSpec),
+		Retries:         make(map[string]Retry),
+		CircuitBreakers: make(map[string]CircuitBreaker),
+	}
+
+	var errs []error
+	for _, name := range names {
+		preset, ok := presets[name]
+		if !ok {
+			errs = append(errs, fmt.Errorf("unknown preset %q: must be one of %q", name, PresetNames()))
+			continue
+		}
+
+		cfg.Timeouts[name] = preset.timeout
+		cfg.Retries[name] = preset.retry
+		cfg.CircuitBreakers[name] = preset.circuitBreaker
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return Config{}, err
+	}
+
+	return cfg, nil
+}
+
+// Merge returns a config holding the entries of both c and other. An entry
+// defined in both under the same name is an error rather than being
+// overwritten, as is a default set in both.
+func (c Config) Merge(other Config) (Config, error) {
+	var errs []error
+
+	merged := Config{
+		Timeouts:        mergeEntries("timeout", c.Timeouts, other.Timeouts, &errs),
+		Retries:         mergeEntries("retry", c.Retries, other.Retries, &errs),
+		CircuitBreakers: mergeEntries("circuit breaker", c.CircuitBreakers, other.CircuitBreakers, &errs),
+		Bulkheads:       mergeEntries("bulkhead", c.Bulkheads, other.Bulkheads, &errs),
+		Caches:          mergeEntries("cache", c.Caches, other.Caches, &errs),
+		AdaptiveLimits:  mergeEntries("adaptive limit", c.AdaptiveLimits, other.AdaptiveLimits, &errs),
+		Sheds:           mergeEntries("shed", c.Sheds, other.Sheds, &errs),
+		Custom:          mergeEntries("custom policy", c.Custom, other.Custom, &errs),
+		Targets:         mergeEntries("target", c.Targets, other.Targets, &errs),
+		Chains:          mergeEntries("chain", c.Chains, other.Chains, &errs),
+		Aliases:         mergeEntries("alias list", c.Aliases, other.Aliases, &errs),
+		Groups:          mergeEntries("group", c.Groups, other.Groups, &errs),
+		Defaults:        c.Defaults,
+	}
+
+	merged.Version = c.Version
+	if other.Version != "" {
+		if c.Version != "" && c.Version != other.Version {
+			errs = append(errs, fmt.Errorf("config versions %q and %q differ", c.Version, other.Version))
+		}
+		merged.Version = other.Version
+	}
+
+	if other.Defaults != (Defaults{}) {
+		if c.Defaults != (Defaults{}) {
+			errs = append(errs, errors.New("defaults are set in both configs"))
+		}
+		merged.Defaults = other.Defaults
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return Config{}, err
+	}
+
+	return merged, nil
+}
+
+func mergeEntries[V any](kind string, a, b map[string]V, errs *[]error) map[string]V {
+	if len(a) == 0 && len(b) == 0 {
+		return nil
+	}
+
+	merged := maps.Clone(a)
+	if merged == nil {
+		merged = make(map[string]V, len(b))
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(b)) {
+		if _, ok := merged[name]; ok {
+			*errs = append(*errs, fmt.Errorf("%s %q is defined in both configs", kind, name))
+			continue
+		}
+		merged[name] = b[name]
+	}
+
+	return merged
+}
@@ -0,0 +1,97 @@
+package goresilience_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	goresilience "github.com/rickKoch/go-resilience"
+)
+
+func TestPresetTarget(t *testing.T) {
+	preset, err := goresilience.PresetConfig("standard")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cfg, err := goresilience.Config{
+		Targets: map[string]goresilience.PolicyNames{
+			"test_target": {Timeout: "standard", Retry: "standard", CircuitBreaker: "standard"},
+		},
+	}.Merge(preset)
+	if err != nil {
+		t.Fatalf("failed to merge preset: %v", err)
+	}
+
+	provider, err := goresilience.FromConfig(cfg)
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	var calls atomic.Int32
+	exec := goresilience.NewExecWithPolicy(context.Background(), provider.Policy("test_target"))
+	_, err = exec(func(ctx context.Context) (any, error) {
+		if _, ok := ctx.Deadline(); !ok {
+			t.Error("expected the preset timeout to set a deadline")
+		}
+		if calls.Add(1) < 3 {
+			return nil, errors.New("transient")
+		}
+		return successResult, nil
+	})
+	if err != nil {
+		t.Fatalf("expected the preset retry to recover, got: %v", err)
+	}
+	if calls.Load() != 3 {
+		t.Fatalf("expected 3 attempts, got %d", calls.Load())
+	}
+	if counts, _ := provider.BreakerCounts("standard"); counts.TotalFailures != 2 || counts.TotalSuccesses != 1 {
+		t.Fatalf("expected the preset breaker to see every attempt, got %+v", counts)
+	}
+
+	d, _ := provider.Describe("test_target")
+	if d.Retry == nil || d.Retry.Backoff != goresilience.RetryBackoffExponential {
+		t.Fatalf("expected the standard retry to back off exponentially, got %+v", d.Retry)
+	}
+}
+
+func TestPresetConfigAll(t *testing.T) {
+	cfg, err := goresilience.PresetConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, name := range goresilience.PresetNames() {
+		if _, ok := cfg.Retries[name]; !ok {
+			t.Fatalf("expected preset %q in the config", name)
+		}
+	}
+
+	if _, err := goresilience.FromConfig(cfg); err != nil {
+		t.Fatalf("expected presets to be valid, got: %v", err)
+	}
+}
+
+func TestPresetMergeConflict(t *testing.T) {
+	preset, err := goresilience.PresetConfig("standard")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, err = goresilience.Config{
+		Retries: map[string]goresilience.Retry{
+			"standard": {Duration: "1s", MaxRetries: 10},
+		},
+	}.Merge(preset)
+	if err == nil || !strings.Contains(err.Error(), `retry "standard"`) {
+		t.Fatalf("expected a conflict on the standard retry, got: %v", err)
+	}
+}
+
+func TestPresetUnknown(t *testing.T) {
+	_, err := goresilience.PresetConfig("standard", "missing")
+	if err == nil || !strings.Contains(err.Error(), `unknown preset "missing"`) {
+		t.Fatalf("expected an error for the unknown preset, got: %v", err)
+	}
+}
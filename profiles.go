@@ -0,0 +1,236 @@
+package goresilience
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultProfile is the profile every other profile is merged over.
+const DefaultProfile = "default"
+
+// Profiles holds flavors of one config by name, such as "dev" and "prod",
+// each written as changes to the DefaultProfile.
+type Profiles map[string]Config
+
+// Resolve returns the named profile merged over the default one. Sections
+// are merged as MergeConfigs does, except that an entry both define is
+// merged field by field: the profile's settings win and the ones it leaves
+// unset come from the default. Setting one of Retry and RetrySpec, of
+// CircuitBreaker and CircuitBreakerSpec, or of MaxRetries and MaxAttempts
+// drops the other. Chains and aliases are replaced as a whole.
+//
+// A setting at its zero value counts as unset, so a profile cannot turn a
+// default's setting back to false or zero; ResolveProfileYAML, which can
+// tell the two apart, can.
+func (p Profiles) Resolve(name string) (Config, error) {
+	profile, ok := p[name]
+	if !ok {
+		return Config{}, fmt.Errorf("unknown profile %q, must be one of %q", name, slices.Sorted(maps.Keys(p)))
+	}
+
+	base := p[DefaultProfile]
+	if name == DefaultProfile {
+		return cloneConfig(base), nil
+	}
+
+	profile = cloneConfig(profile)
+	profile.Timeouts = fillEntries(profile.Timeouts, base.Timeouts, fillFields[TimeoutSpec])
+	profile.Retries = fillEntries(profile.Retries, base.Retries, fillRetry)
+	profile.CircuitBreakers = fillEntries(profile.CircuitBreakers, base.CircuitBreakers, fillFields[CircuitBreaker])
+	profile.Bulkheads = fillEntries(profile.Bulkheads, base.Bulkheads, fillFields[Bulkhead])
+	profile.Caches = fillEntries(profile.Caches, base.Caches, fillFields[Cache])
+	profile.AdaptiveLimits = fillEntries(profile.AdaptiveLimits, base.AdaptiveLimits, fillFields[AdaptiveLimit])
+	profile.Sheds = fillEntries(profile.Sheds, base.Sheds, fillFields[Shed])
+	profile.Targets = fillEntries(profile.Targets, base.Targets, fillPolicyNames)
+	profile.Groups = fillEntries(profile.Groups, base.Groups, fillGroup)
+	profile.Defaults = fillFields(profile.Defaults, base.Defaults)
+
+	// Replacing the default's entries is what profiles are for.
+	merged, _ := MergeConfigs(base, profile)
+
+	return cloneConfig(merged), nil
+}
+
+// FromProfile builds a provider from the named profile, resolved as
+// Profiles.Resolve does.
+func FromProfile(profiles Profiles, name string, opts ...Option) (*Provider, error) {
+	cfg, err := profiles.Resolve(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return FromConfigWithOptions(cfg, opts...)
+}
+
+func fillEntries[V any](entries, defaults map[string]V, fill func(entry, def V) V) map[string]V {
+	for name, entry := range entries {
+		if def, ok := defaults[name]; ok {
+			entries[name] = fill(entry, def)
+		}
+	}
+
+	return entries
+}
+
+// fillRetry fills a profile's retry like fillFields, except that the retry
+// count is taken as a whole, since MaxRetries and MaxAttempts exclude each
+// other.
+func fillRetry(entry, def Retry) Retry {
+	if entry.MaxRetries != 0 || entry.MaxAttempts != 0 {
+		def.MaxRetries, def.MaxAttempts = entry.MaxRetries, entry.MaxAttempts
+	}
+
+	return fillFields(entry, def)
+}
+
+// fillPolicyNames fills a profile's target like fillFields, except that a
+// policy the profile references by name or defines inline replaces the
+// default's either way.
+func fillPolicyNames(entry, def PolicyNames) PolicyNames {
+	if entry.Retry != "" || entry.RetrySpec != nil {
+		def.Retry, def.RetrySpec = entry.Retry, entry.RetrySpec
+	}
+	if entry.CircuitBreaker != "" || entry.CircuitBreakerSpec != nil {
+		def.CircuitBreaker, def.CircuitBreakerSpec = entry.CircuitBreaker, entry.CircuitBreakerSpec
+	}
+
+	return fillFields(entry, def)
+}
+
+func fillGroup(entry, def TargetGroup) TargetGroup {
+	entry.PolicyNames = fillPolicyNames(entry.PolicyNames, def.PolicyNames)
+	if len(entry.Members) == 0 {
+		entry.Members = def.Members
+	}
+
+	return entry
+}
+
+// fillFields sets every field of entry that is at its zero value to the
+// field of def.
+func fillFields[V any](entry, def V) V {
+	out := reflect.ValueOf(&entry).Elem()
+	from := reflect.ValueOf(def)
+
+	for i := 0; i < out.NumField(); i++ {
+		if out.Field(i).CanSet() && out.Field(i).IsZero() {
+			out.Field(i).Set(from.Field(i))
+		}
+	}
+
+	return entry
+}
+
+// ResolveProfileYAML resolves the named profile of a YAML document that maps
+// profile names to configs, as Profiles.Resolve does. The profile is merged
+// over the default one before it is decoded, so a setting the profile
+// writes out, even as false or 0, always wins over the default's.
+func ResolveProfileYAML(data []byte, name string) (Config, error) {
+	var doc map[string]map[string]any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return Config{}, fmt.Errorf("invalid yaml profiles: %w", err)
+	}
+
+	profile, ok := doc[name]
+	if !ok {
+		return Config{}, fmt.Errorf("unknown profile %q, must be one of %q", name, slices.Sorted(maps.Keys(doc)))
+	}
+
+	if name != DefaultProfile {
+		profile = mergeProfile(doc[DefaultProfile], profile)
+	}
+
+	merged, err := yaml.Marshal(profile)
+	if err != nil {
+		return Config{}, fmt.Errorf("profile %q: %w", name, err)
+	}
+
+	cfg, err := ParseConfigYAML(merged)
+	if err != nil {
+		return Config{}, fmt.Errorf("profile %q: %w", name, err)
+	}
+
+	return cfg, nil
+}
+
+// profileEntrySections are the sections whose entries a profile merges
+// field by field; the entries of the others are replaced as a whole.
+var profileEntrySections = []string{"timeouts", "retries", "circuitBreakers", "bulkheads", "caches", "adaptiveLimits", "sheds", "targets", "groups"}
+
+// exclusiveFields are settings of which an entry sets one at most, so a
+// profile setting one drops the others it would inherit.
+var exclusiveFields = [][]string{
+	{"retry", "retrySpec"},
+	{"circuitBreaker", "circuitBreakerSpec"},
+	{"maxRetries", "maxAttempts"},
+}
+
+// mergeProfile merges a decoded profile over the decoded default profile.
+func mergeProfile(base, profile map[string]any) map[string]any {
+	merged := maps.Clone(base)
+	if merged == nil {
+		merged = make(map[string]any, len(profile))
+	}
+
+	for key, val := range profile {
+		switch {
+		case key == "defaults":
+			merged[key] = mergeProfileFields(base[key], val)
+		case slices.Contains(profileEntrySections, key):
+			merged[key] = mergeProfileEntries(base[key], val, mergeProfileFields)
+		case key == "version":
+			merged[key] = val
+		default:
+			merged[key] = mergeProfileEntries(base[key], val, func(_, entry any) any { return entry })
+		}
+	}
+
+	return merged
+}
+
+// mergeProfileEntries merges the entries of a section by name with merge.
+// A section that is not a mapping on both sides is replaced.
+func mergeProfileEntries(base, profile any, merge func(base, entry any) any) any {
+	b, ok := base.(map[string]any)
+	p, isMap := profile.(map[string]any)
+	if !ok || !isMap {
+		return profile
+	}
+
+	merged := maps.Clone(b)
+	for name, entry := range p {
+		if def, ok := b[name]; ok {
+			entry = merge(def, entry)
+		}
+		merged[name] = entry
+	}
+
+	return merged
+}
+
+// mergeProfileFields merges an entry field by field, the profile's fields
+// winning. An entry that is not a mapping on both sides, such as a timeout
+// written as a bare duration, is replaced.
+func mergeProfileFields(base, profile any) any {
+	b, ok := base.(map[string]any)
+	p, isMap := profile.(map[string]any)
+	if !ok || !isMap {
+		return profile
+	}
+
+	merged := maps.Clone(b)
+	for _, fields := range exclusiveFields {
+		if slices.ContainsFunc(fields, func(f string) bool { _, ok := p[f]; return ok }) {
+			for _, f := range fields {
+				delete(merged, f)
+			}
+		}
+	}
+	maps.Copy(merged, p)
+
+	return merged
+}
@@ -0,0 +1,173 @@
+package goresilience_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	goresilience "github.com/rickKoch/go-resilience"
+)
+
+var testProfiles = goresilience.Profiles{
+	goresilience.DefaultProfile: {
+		Timeouts: map[string]goresilience.TimeoutSpec{
+			"fast": {Duration: "1s"},
+		},
+		Retries: map[string]goresilience.Retry{
+			"std": {Duration: "1ms", MaxRetries: 2},
+		},
+		Targets: map[string]goresilience.PolicyNames{
+			"users": {Timeout: "fast", Retry: "std"},
+		},
+	},
+	"prod": {
+		Retries: map[string]goresilience.Retry{
+			"std": {MaxRetries: 4},
+		},
+	},
+}
+
+func TestProfileOverridesField(t *testing.T) {
+	cfg, err := testProfiles.Resolve("prod")
+	if err != nil {
+		t.Fatalf("failed to resolve profile: %v", err)
+	}
+
+	if got, want := cfg.Retries["std"], (goresilience.Retry{Duration: "1ms", MaxRetries: 4}); got != want {
+		t.Fatalf("expected retry %+v, got %+v", want, got)
+	}
+	if got := cfg.Targets["users"]; got.Timeout != "fast" || got.Retry != "std" {
+		t.Fatalf("expected the target to be inherited, got %+v", got)
+	}
+	if got := cfg.Timeouts["fast"].Duration; got != "1s" {
+		t.Fatalf("expected the timeout to be inherited, got %q", got)
+	}
+
+	if got := testProfiles[goresilience.DefaultProfile].Retries["std"].MaxRetries; got != 2 {
+		t.Fatalf("expected the default profile to be left alone, got %d retries", got)
+	}
+}
+
+func TestFromProfile(t *testing.T) {
+	for name, want := range map[string]int32{goresilience.DefaultProfile: 3, "prod": 5} {
+		provider, err := goresilience.FromProfile(testProfiles, name)
+		if err != nil {
+			t.Fatalf("%s: failed to create provider: %v", name, err)
+		}
+
+		exec := goresilience.NewExecWithPolicy(context.Background(), provider.Policy("users"))
+		if calls := countAttempts(exec); calls != want {
+			t.Errorf("%s: expected %d attempts, got %d", name, want, calls)
+		}
+	}
+}
+
+func TestFromProfileUnknown(t *testing.T) {
+	_, err := goresilience.FromProfile(testProfiles, "staging")
+	if err == nil || !strings.Contains(err.Error(), `unknown profile "staging"`) {
+		t.Fatalf("expected an unknown profile error, got: %v", err)
+	}
+}
+
+func TestProfileReplacesInlinePolicy(t *testing.T) {
+	profiles := goresilience.Profiles{
+		goresilience.DefaultProfile: {
+			Retries: map[string]goresilience.Retry{
+				"std": {Duration: "1ms", MaxRetries: 2},
+			},
+			Targets: map[string]goresilience.PolicyNames{
+				"users": {Retry: "std"},
+			},
+		},
+		"prod": {
+			Targets: map[string]goresilience.PolicyNames{
+				"users": {RetrySpec: &goresilience.Retry{Duration: "1ms", MaxRetries: 4}},
+			},
+		},
+	}
+
+	cfg, err := profiles.Resolve("prod")
+	if err != nil {
+		t.Fatalf("failed to resolve profile: %v", err)
+	}
+	if got := cfg.Targets["users"]; got.Retry != "" || got.RetrySpec == nil {
+		t.Fatalf("expected the inline retry to replace the named one, got %+v", got)
+	}
+
+	if _, err := goresilience.FromConfig(cfg); err != nil {
+		t.Fatalf("expected the resolved profile to be valid, got: %v", err)
+	}
+}
+
+const yamlProfiles = `
+default:
+  retries:
+    std:
+      duration: 1ms
+      maxRetries: 2
+  circuitBreakers:
+    cb:
+      maxRequests: 1
+      interval: 10s
+      timeout: 10s
+      failures: 3
+      dryRun: true
+  targets:
+    users:
+      retry: std
+      circuitBreaker: cb
+prod:
+  retries:
+    std:
+      maxAttempts: 1
+  circuitBreakers:
+    cb:
+      dryRun: false
+  targets:
+    users:
+      retrySpec:
+        duration: 1ms
+        maxRetries: 0
+`
+
+func TestResolveProfileYAML(t *testing.T) {
+	cfg, err := goresilience.ResolveProfileYAML([]byte(yamlProfiles), "prod")
+	if err != nil {
+		t.Fatalf("failed to resolve profile: %v", err)
+	}
+
+	if got, want := cfg.Retries["std"], (goresilience.Retry{Duration: "1ms", MaxAttempts: 1}); got != want {
+		t.Fatalf("expected retry %+v, got %+v", want, got)
+	}
+	if got := cfg.CircuitBreakers["cb"]; got.DryRun || got.Failures != 3 {
+		t.Fatalf("expected dryRun to be turned off and the rest inherited, got %+v", got)
+	}
+	if got := cfg.Targets["users"]; got.Retry != "" || got.RetrySpec == nil || got.RetrySpec.MaxRetries != 0 || got.CircuitBreaker != "cb" {
+		t.Fatalf("expected the inline retry to replace the named one, got %+v", got)
+	}
+
+	if _, err := goresilience.FromConfig(cfg); err != nil {
+		t.Fatalf("expected the resolved profile to be valid, got: %v", err)
+	}
+
+	def, err := goresilience.ResolveProfileYAML([]byte(yamlProfiles), goresilience.DefaultProfile)
+	if err != nil {
+		t.Fatalf("failed to resolve profile: %v", err)
+	}
+	if !def.CircuitBreakers["cb"].DryRun || def.Targets["users"].Retry != "std" {
+		t.Fatalf("expected the default profile as written, got %+v", def)
+	}
+}
+
+func TestResolveProfileYAMLErrors(t *testing.T) {
+	if _, err := goresilience.ResolveProfileYAML([]byte(yamlProfiles), "staging"); err == nil || !strings.Contains(err.Error(), `unknown profile "staging"`) {
+		t.Fatalf("expected an unknown profile error, got: %v", err)
+	}
+
+	_, err := goresilience.ResolveProfileYAML([]byte("default: {}\nprod:\n  retries:\n    std:\n      maxRetires: 3\n"), "prod")
+	var cfgErr *goresilience.ConfigError
+	if !errors.As(err, &cfgErr) || cfgErr.Path != `retries["std"].maxRetires` {
+		t.Fatalf("expected a ConfigError for the unknown key, got: %v", err)
+	}
+}
@@ -0,0 +1,159 @@
+// Package promgoresilience exports a goresilience provider's policy
+// decisions as Prometheus metrics.
+package promgoresilience
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	goresilience "github.com/rickKoch/go-resilience"
+)
+
+// Collector is a prometheus.Collector fed by the provider's observer hook,
+// so its counts are exact rather than sampled. Pass it to the provider with
+// goresilience.WithObserver and register it with a prometheus.Registerer:
+//
+//	collector := promgoresilience.NewCollector()
+//	provider, err := goresilience.FromConfigWithOptions(cfg, goresilience.WithObserver(collector))
+//	prometheus.MustRegister(collector)
+//
+// It exports, labeled by target:
+//
+//	goresilience_attempts_total             attempts of an operation
+//	goresilience_successes_total            calls that succeeded
+//	goresilience_failures_total             calls that failed
+//	goresilience_retries_total              failed attempts that were retried
+//	goresilience_timeouts_total             timeouts that fired
+//	goresilience_rejections_total           calls a stage turned away
+//	goresilience_breaker_state              the breaker's State, also labeled by breaker
+//	goresilience_attempt_duration_seconds   how long attempts took
+//	goresilience_execution_duration_seconds how long calls took, backoff included
+//	goresilience_backoff_seconds            the backoff before each retry
+type Collector struct {
+	attempts          *prometheus.CounterVec
+	successes         *prometheus.CounterVec
+	failures          *prometheus.CounterVec
+	retries           *prometheus.CounterVec
+	timeouts          *prometheus.CounterVec
+	rejections        *prometheus.CounterVec
+	breakerState      *prometheus.GaugeVec
+	attemptDuration   *prometheus.HistogramVec
+	executionDuration *prometheus.HistogramVec
+	backoff           *prometheus.HistogramVec
+}
+
+var (
+	_ prometheus.Collector           = (*Collector)(nil)
+	_ goresilience.ExecutionObserver = (*Collector)(nil)
+)
+
+// NewCollector returns a Collector with no samples yet. A target's series
+// appear once it is first called.
+func NewCollector() *Collector {
+	counter := func(name, help string) *prometheus.CounterVec {
+		return prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "goresilience",
+			Name:      name,
+			Help:      help,
+		}, []string{"target"})
+	}
+
+	return &Collector{
+		attempts:   counter("attempts_total", "Attempts of an operation, retries included."),
+		successes:  counter("successes_total", "Calls that succeeded."),
+		failures:   counter("failures_total", "Calls that failed."),
+		retries:    counter("retries_total", "Failed attempts that were retried."),
+		timeouts:   counter("timeouts_total", "Timeouts that fired, per attempt or overall."),
+		rejections: counter("rejections_total", "Calls turned away by a breaker, bulkhead, limit, shedder, health gate or minimum deadline."),
+		breakerState: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "goresilience",
+			Name:      "breaker_state",
+			Help:      "Circuit breaker state: 0 closed, 1 half-open, 2 open, 3 forced open, 4 forced closed, 5 disabled.",
+		}, []string{"target", "breaker"}),
+		attemptDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "goresilience",
+			Name:      "attempt_duration_seconds",
+			Help:      "How long attempts of an operation took.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"target"}),
+		executionDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "goresilience",
+			Name:      "execution_duration_seconds",
+			Help:      "How long calls took, every attempt and backoff included.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"target"}),
+		backoff: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "goresilience",
+			Name:      "backoff_seconds",
+			Help:      "The backoff slept before each retry.",
+			Buckets:   prometheus.ExponentialBuckets(0.001, 4, 8),
+		}, []string{"target"}),
+	}
+}
+
+func (c *Collector) collectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		c.attempts, c.successes, c.failures, c.retries, c.timeouts, c.rejections,
+		c.breakerState, c.attemptDuration, c.executionDuration, c.backoff,
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	for _, col := range c.collectors() {
+		col.Describe(ch)
+	}
+}
+
+// Collect implements prometheus.Collector.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	for _, col := range c.collectors() {
+		col.Collect(ch)
+	}
+}
+
+// OnAttempt implements goresilience.ExecutionObserver.
+func (c *Collector) OnAttempt(target string, duration time.Duration, err error) {
+	c.attempts.WithLabelValues(target).Inc()
+	c.attemptDuration.WithLabelValues(target).Observe(duration.Seconds())
+}
+
+// OnDone implements goresilience.ExecutionObserver.
+func (c *Collector) OnDone(target string, duration time.Duration, err error) {
+	c.executionDuration.WithLabelValues(target).Observe(duration.Seconds())
+
+	if err != nil {
+		c.failures.WithLabelValues(target).Inc()
+		return
+	}
+
+	c.successes.WithLabelValues(target).Inc()
+}
+
+// OnRetry implements goresilience.Observer.
+func (c *Collector) OnRetry(target string, attempt int, delay time.Duration, err error) {
+	c.retries.WithLabelValues(target).Inc()
+	c.backoff.WithLabelValues(target).Observe(delay.Seconds())
+}
+
+// OnStateChange implements goresilience.Observer. Changes of per-key
+// breakers are left out, since their keys would make the label set
+// unbounded.
+func (c *Collector) OnStateChange(change goresilience.StateChange) {
+	if change.Key != "" {
+		return
+	}
+
+	c.breakerState.WithLabelValues(change.Target, change.Breaker).Set(float64(change.To))
+}
+
+// OnTimeout implements goresilience.Observer.
+func (c *Collector) OnTimeout(target string, limit time.Duration) {
+	c.timeouts.WithLabelValues(target).Inc()
+}
+
+// OnRejected implements goresilience.Observer.
+func (c *Collector) OnRejected(target string, err error) {
+	c.rejections.WithLabelValues(target).Inc()
+}
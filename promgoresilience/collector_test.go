@@ -0,0 +1,95 @@
+package promgoresilience_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	goresilience "github.com/rickKoch/go-resilience"
+	"github.com/rickKoch/go-resilience/promgoresilience"
+)
+
+func TestCollector(t *testing.T) {
+	collector := promgoresilience.NewCollector()
+	provider, err := goresilience.FromConfigWithOptions(goresilience.Config{
+		Retries: map[string]goresilience.Retry{
+			"test_retry": {Duration: "1ms", MaxRetries: 1},
+		},
+		CircuitBreakers: map[string]goresilience.CircuitBreaker{
+			"test_cb": {MaxRequests: 1, Interval: "10s", Timeout: "10s", Failures: 3},
+		},
+		Targets: map[string]goresilience.PolicyNames{
+			"test_target": {Retry: "test_retry", CircuitBreaker: "test_cb"},
+		},
+	}, goresilience.WithObserver(collector))
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collector)
+
+	exec := goresilience.NewContextExecutor(provider.Policy("test_target"))
+	fail := func(ctx context.Context) (any, error) { return nil, errors.New("failed") }
+
+	// One success, then a failure that is retried once, then a failure
+	// whose third consecutive error opens the breaker, which rejects the
+	// retry.
+	_, _ = exec(context.Background(), func(ctx context.Context) (any, error) { return "ok", nil })
+	_, _ = exec(context.Background(), fail)
+	_, _ = exec(context.Background(), fail)
+
+	expected := `
+# HELP goresilience_attempts_total Attempts of an operation, retries included.
+# TYPE goresilience_attempts_total counter
+goresilience_attempts_total{target="test_target"} 4
+# HELP goresilience_successes_total Calls that succeeded.
+# TYPE goresilience_successes_total counter
+goresilience_successes_total{target="test_target"} 1
+# HELP goresilience_failures_total Calls that failed.
+# TYPE goresilience_failures_total counter
+goresilience_failures_total{target="test_target"} 2
+# HELP goresilience_retries_total Failed attempts that were retried.
+# TYPE goresilience_retries_total counter
+goresilience_retries_total{target="test_target"} 2
+# HELP goresilience_rejections_total Calls turned away by a breaker, bulkhead, limit, shedder, health gate or minimum deadline.
+# TYPE goresilience_rejections_total counter
+goresilience_rejections_total{target="test_target"} 1
+# HELP goresilience_breaker_state Circuit breaker state: 0 closed, 1 half-open, 2 open, 3 forced open, 4 forced closed, 5 disabled.
+# TYPE goresilience_breaker_state gauge
+goresilience_breaker_state{breaker="test_cb",target="test_target"} 2
+`
+	if err := testutil.CollectAndCompare(collector, strings.NewReader(expected),
+		"goresilience_attempts_total", "goresilience_successes_total", "goresilience_failures_total",
+		"goresilience_retries_total", "goresilience_rejections_total", "goresilience_breaker_state"); err != nil {
+		t.Fatal(err)
+	}
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("failed to gather metrics: %v", err)
+	}
+
+	samples := make(map[string]uint64)
+	for _, family := range families {
+		for _, m := range family.GetMetric() {
+			if h := m.GetHistogram(); h != nil {
+				samples[family.GetName()] = h.GetSampleCount()
+			}
+		}
+	}
+
+	if got := samples["goresilience_attempt_duration_seconds"]; got != 4 {
+		t.Fatalf("expected 4 attempt durations, got %d", got)
+	}
+	if got := samples["goresilience_execution_duration_seconds"]; got != 3 {
+		t.Fatalf("expected 3 execution durations, got %d", got)
+	}
+	if got := samples["goresilience_backoff_seconds"]; got != 2 {
+		t.Fatalf("expected 2 backoffs, got %d", got)
+	}
+}
@@ -1,109 +1,877 @@
 package goresilience
 
 import (
+	"errors"
 	"fmt"
+	"maps"
+	"slices"
 	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
+
+	"golang.org/x/sync/singleflight"
 )
 
 type target struct {
 	timeout        string
+	overallTimeout string
+	literals       literalTimeouts
+	minDeadline    time.Duration
+	order          []string
 	retry          string
 	circuitBreaker string
+	bulkhead       string
+	adaptiveLimit  string
+	shed           string
+	cache          string
+	flight         *singleflight.Group
+	coalescer      *coalescer
+	fallbackOnAny  bool
+	custom         []string
 }
 
-type Provider struct {
-	timeouts        map[string]time.Duration
+// providerState is everything built from a Config. Apart from its policy
+// cache it is never modified once built; UpdateConfig replaces it as a
+// whole.
+type providerState struct {
+	config     Config
+	generation uint64
+
+	timeouts        map[string]*timeout
 	retries         map[string]*retry
 	circuitBreakers map[string]*circuitBreaker
+	bulkheads       map[string]*bulkhead
+	caches          map[string]*resultCache
+	limiters        map[string]*adaptiveLimiter
+	shedders        map[string]*shedder
+	custom          map[string]Middleware
 	targets         map[string]target
+	chains          map[string][]string
+	aliases         map[string]string
+	groups          []group
+	diff            ConfigDiff // from the state it replaced
+
+	// policies caches Provider.Policy's results by target name. It is the
+	// only part of the state that changes after build.
+	policies       sync.Map
+	warnings       []string
+	defaultTimeout string
+	defaultLiteral *timeout
+}
+
+type Provider struct {
+	// state is swapped whole by updates and, apart from its policy cache,
+	// never modified in place, so lookups only need to load it.
+	state    atomic.Pointer[providerState]
+	updateMu sync.Mutex
+
+	mu           sync.RWMutex
+	fallbacks    map[string]FallbackFunc
+	healthGates  map[string]*healthGate
+	middleware   map[middlewareKey][]Middleware
+	interceptors map[string][]Interceptor
+	closed       bool
+	done         chan struct{}
+	watchers     sync.WaitGroup
+
+	// settings counts the changes made under mu, so that policies
+	// resolved before one are resolved again.
+	settings atomic.Uint64
+
+	opts             options
+	observer         Observer
+	execObserver     executionObserver
+	events           *eventBus
+	duplicateReports atomic.Uint64
+
+	gaugeMu  sync.Mutex
+	inFlight map[string]*atomic.Int64
+	counters map[string]*targetCounters
+	traces   map[string]*traceRing
 }
 
 func FromConfig(cfg Config) (*Provider, error) {
+	return FromConfigWithOptions(cfg)
+}
+
+func FromConfigWithOptions(cfg Config, opts ...Option) (*Provider, error) {
+	return newProvider(cfg, newOptions(opts))
+}
+
+func newProvider(cfg Config, opts options) (*Provider, error) {
 	p := &Provider{
-		timeouts:        make(map[string]time.Duration),
-		retries:         make(map[string]*retry),
-		circuitBreakers: make(map[string]*circuitBreaker),
-		targets:         make(map[string]target),
+		fallbacks:    make(map[string]FallbackFunc),
+		healthGates:  make(map[string]*healthGate),
+		middleware:   make(map[middlewareKey][]Middleware),
+		interceptors: make(map[string][]Interceptor),
+		done:         make(chan struct{}),
+		opts:         opts,
+		events:       newEventBus(defaultEventBuffer),
+		inFlight:     make(map[string]*atomic.Int64),
+		counters:     make(map[string]*targetCounters),
+		traces:       make(map[string]*traceRing),
+	}
+
+	p.observer = newObserver(p.opts.observers)
+	p.execObserver = executionObservers(p.opts.observers)
+	if p.observer != nil {
+		p.OnStateChange(p.observer.OnStateChange)
 	}
 
-	if err := p.configure(cfg); err != nil {
+	state, err := p.build(cfg, nil)
+	if err != nil {
 		return nil, err
 	}
+	p.state.Store(state)
 
 	return p, nil
 }
 
+func (p *Provider) isClosed() bool {
+	select {
+	case <-p.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// settingsChanged makes executors resolve their policies again after a
+// change to the fallbacks, health checks, middleware or interceptors. The
+// caller holds mu.
+func (p *Provider) settingsChanged() {
+	p.settings.Add(1)
+	p.current().policies.Clear()
+}
+
+// current returns the provider's state. The state itself is immutable, so
+// callers may keep using it after an update has replaced it.
+func (p *Provider) current() *providerState {
+	return p.state.Load()
+}
+
+// SetFallback sets the fallback of target's calls, or removes it if fn is
+// nil. Executors already holding the target's policy use it from their
+// next call on.
+func (p *Provider) SetFallback(target string, fn FallbackFunc) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	defer p.settingsChanged()
+
+	if fn == nil {
+		delete(p.fallbacks, target)
+		return
+	}
+
+	p.fallbacks[target] = fn
+}
+
+// SetHealthCheck polls fn every interval (10s if not positive) in the
+// background, and executions for target fail fast with ErrUnhealthy while
+// the latest check failed. Each check gets a context that expires after
+// interval. A nil fn removes the check. Executors already holding the
+// target's policy use the new check from their next call on, and the
+// previous check has stopped once SetHealthCheck returns. Close stops all
+// checks.
+func (p *Provider) SetHealthCheck(target string, fn HealthCheck, interval time.Duration) {
+	if interval <= 0 {
+		interval = defaultHealthInterval
+	}
+
+	if prev := p.replaceHealthGate(target, fn, interval); prev != nil {
+		prev.stop()
+	}
+}
+
+// replaceHealthGate starts the health gate for target and returns the one
+// it replaces, for the caller to stop without holding mu.
+func (p *Provider) replaceHealthGate(target string, fn HealthCheck, interval time.Duration) *healthGate {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return nil
+	}
+
+	prev := p.healthGates[target]
+	delete(p.healthGates, target)
+
+	if fn != nil {
+		p.healthGates[target] = startHealthGate(fn, interval)
+	}
+
+	p.settingsChanged()
+	return prev
+}
+
+// ErrProviderClosed is returned by executions started after the provider
+// was closed.
+var ErrProviderClosed = errors.New("provider closed")
+
+// Close stops the provider's background health checks and config file
+// watchers, waits for them to return and closes the Events channel.
+// Executions already running finish normally; later ones fail fast with
+// ErrProviderClosed rather than run without their policies. Close may be
+// called more than once, and concurrently.
+func (p *Provider) Close() error {
+	p.mu.Lock()
+	if !p.closed {
+		p.closed = true
+		close(p.done)
+	}
+	gates := p.healthGates
+	p.healthGates = make(map[string]*healthGate)
+	p.settingsChanged()
+	p.mu.Unlock()
+
+	for _, g := range gates {
+		g.stop()
+	}
+	p.watchers.Wait()
+	p.events.close()
+
+	return nil
+}
+
+// ErrUnknownTarget is returned by PolicyE for a name the config does not
+// cover.
+var ErrUnknownTarget = errors.New("unknown target")
+
+// Policy returns the policies target resolves to. Repeated calls return the
+// same *Policy until the config, or the target's fallback or health check,
+// changes. A name the config does not cover gets an empty policy, unless
+// the provider is built WithStrictTargets.
 func (p *Provider) Policy(target string) *Policy {
-	policy := &Policy{}
+	if p.opts.strictTargets {
+		if _, err := p.PolicyE(target); err != nil {
+			p.opts.onUnknownTarget(err)
+		}
+	}
 
-	if cfg, ok := p.targets[target]; ok {
-		if cfg.timeout != "" {
-			if timeout, exists := p.timeouts[cfg.timeout]; exists {
-				policy.timeout = timeout
-			}
+	return p.policy(target)
+}
+
+// PolicyE is like Policy, but returns an error wrapping ErrUnknownTarget
+// for a name that is neither a target, an alias nor a group member. When
+// the config has a default timeout every name is covered by it, so every
+// name is known.
+func (p *Provider) PolicyE(target string) (*Policy, error) {
+	if !p.current().known(target) {
+		return nil, fmt.Errorf("%w %q", ErrUnknownTarget, target)
+	}
+
+	return p.policy(target), nil
+}
+
+// MustPolicy is like Policy, but on a provider built WithStrictTargets it
+// always panics for an unknown name, even if WithUnknownTargetHandler
+// would carry on. Without strict targets it behaves exactly like Policy.
+func (p *Provider) MustPolicy(target string) *Policy {
+	if !p.opts.strictTargets {
+		return p.policy(target)
+	}
+
+	policy, err := p.PolicyE(target)
+	if err != nil {
+		panic(fmt.Errorf("goresilience: MustPolicy: %w, known targets are %v", err, p.Targets()))
+	}
+
+	return policy
+}
+
+// PolicyOrDefault returns target's policies if the config covers target,
+// as PolicyE decides, and fallbackTarget's otherwise. Only fallbackTarget
+// is subject to WithStrictTargets.
+func (p *Provider) PolicyOrDefault(target, fallbackTarget string) *Policy {
+	if policy, err := p.PolicyE(target); err == nil {
+		return policy
+	}
+
+	return p.Policy(fallbackTarget)
+}
+
+func (s *providerState) known(name string) bool {
+	if _, ok := s.targets[name]; ok {
+		return true
+	}
+
+	if _, ok := s.aliases[name]; ok {
+		return true
+	}
+
+	if _, ok := s.groupOf(name); ok {
+		return true
+	}
+
+	_, ok := s.timeout(s.defaultTimeout, s.defaultLiteral)
+	return ok
+}
+
+func (p *Provider) policy(target string) *Policy {
+	state := p.current()
+	if policy, ok := state.policies.Load(target); ok {
+		return policy.(*Policy)
+	}
+
+	// Holding mu keeps SetFallback, SetHealthCheck, UseAt and Intercept from
+	// clearing the cache between reading their settings and storing the
+	// policy.
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	policy, _ := state.policies.LoadOrStore(target, p.newPolicy(state, target))
+	return policy.(*Policy)
+}
+
+// newPolicy resolves target's policies in state. The caller holds mu. The
+// policy is not modified afterwards, so that it can be shared.
+func (p *Provider) newPolicy(state *providerState, target string) *Policy {
+	name := target
+	if canonical, ok := state.aliases[target]; ok {
+		target = canonical
+	}
+
+	policy := &Policy{
+		target:        target,
+		name:          name,
+		events:        p.events,
+		recoverPanics: p.opts.recoverPanics,
+		counters:      p.targetCounters(target),
+		observer:      p.observer,
+		execObserver:  p.execObserver,
+		traces:        p.traceRing(target),
+		provider:      p,
+		generation:    state.generation,
+		settings:      p.settings.Load(),
+	}
+
+	fn := p.fallbacks[target]
+	policy.health = p.healthGates[target]
+	policy.middleware = p.middlewareFor(target, PerAttempt)
+	policy.callMiddleware = p.middlewareFor(target, PerCall)
+	policy.interceptors = p.interceptorsFor(target)
+
+	cfg, ok := state.targets[target]
+	if !ok {
+		var g group
+		g, ok = state.groupOf(target)
+		cfg = g.target
+	}
+
+	if fn != nil {
+		policy.fallback = &fallback{fn: fn, onAny: cfg.fallbackOnAny}
+	}
+
+	timeoutRef, literal := state.defaultTimeout, state.defaultLiteral
+	if ok && cfg.timeout != "" {
+		timeoutRef, literal = cfg.timeout, cfg.literals.timeout
+	}
+
+	if timeout, exists := state.timeout(timeoutRef, literal); exists {
+		policy.timeout = timeout
+	}
+
+	if ok {
+		policy.minDeadline = cfg.minDeadline
+		policy.order = cfg.order
+
+		if timeout, exists := state.timeout(cfg.overallTimeout, cfg.literals.overallTimeout); exists {
+			policy.overallTimeout = timeout
 		}
 
 		if cfg.retry != "" {
-			if retry, exists := p.retries[cfg.retry]; exists {
+			if retry, exists := state.retries[cfg.retry]; exists {
 				policy.retry = retry
 			}
 		}
 
 		if cfg.circuitBreaker != "" {
-			if cb, exists := p.circuitBreakers[cfg.circuitBreaker]; exists {
+			if cb, exists := state.circuitBreakers[cfg.circuitBreaker]; exists {
 				policy.circuitBreaker = cb
 			}
 		}
+
+		if cfg.bulkhead != "" {
+			if bh, exists := state.bulkheads[cfg.bulkhead]; exists {
+				policy.bulkhead = bh
+			}
+		}
+
+		if cfg.adaptiveLimit != "" {
+			if limiter, exists := state.limiters[cfg.adaptiveLimit]; exists {
+				policy.limiter = limiter
+			}
+		}
+
+		if cfg.shed != "" {
+			if shedder, exists := state.shedders[cfg.shed]; exists {
+				policy.shedder = shedder
+			}
+		}
+
+		policy.middleware = append(policy.middleware, state.customMiddleware(cfg.custom)...)
+		policy.flight = cfg.flight
+		policy.coalescer = cfg.coalescer
+
+		if cfg.cache != "" {
+			if cache, exists := state.caches[cfg.cache]; exists {
+				policy.cache = cache
+			}
+		}
+	}
+
+	if policy.timeout != nil || policy.overallTimeout != nil {
+		policy.inFlight = p.inFlightGauge(target)
 	}
 
 	return policy
 }
 
-func (p *Provider) configure(cfg Config) error {
-	for name, val := range cfg.Timeouts {
-		timeout, err := parseDuration(val)
+// SetLoadFunc makes a shed policy use fn as its load signal instead of its
+// in-flight count. A nil fn restores the in-flight count.
+func (p *Provider) SetLoadFunc(name string, fn LoadFunc) error {
+	shedder, ok := p.current().shedders[name]
+	if !ok {
+		return fmt.Errorf("unknown shed %q", name)
+	}
+
+	if fn == nil {
+		shedder.load.Store(nil)
+		return nil
+	}
+
+	shedder.load.Store(&fn)
+	return nil
+}
+
+func (p *Provider) BreakerState(name string, key ...string) (State, bool) {
+	cb, ok := p.current().circuitBreakers[name]
+	if !ok {
+		return StateClosed, false
+	}
+
+	return cb.State(breakerKey(key)), true
+}
+
+func (p *Provider) BreakerCounts(name string, key ...string) (Counts, bool) {
+	cb, ok := p.current().circuitBreakers[name]
+	if !ok {
+		return Counts{}, false
+	}
+
+	return cb.Counts(breakerKey(key)), true
+}
+
+func (p *Provider) ResetBreaker(name string) error {
+	cb, ok := p.current().circuitBreakers[name]
+	if !ok {
+		return fmt.Errorf("unknown circuit breaker %q", name)
+	}
+
+	cb.reset()
+	return nil
+}
+
+// SetBreakerEnabled turns a breaker off or back on at runtime. While
+// disabled, calls bypass it entirely and its state reports StateDisabled;
+// re-enabling starts from a fresh closed breaker.
+func (p *Provider) SetBreakerEnabled(name string, enabled bool) error {
+	cb, ok := p.current().circuitBreakers[name]
+	if !ok {
+		return fmt.Errorf("unknown circuit breaker %q", name)
+	}
+
+	cb.setEnabled(enabled)
+	return nil
+}
+
+func (p *Provider) ForceOpen(name string, d time.Duration) error {
+	return p.force(name, StateForcedOpen, d)
+}
+
+func (p *Provider) ForceClose(name string, d time.Duration) error {
+	return p.force(name, StateForcedClosed, d)
+}
+
+func (p *Provider) force(name string, state State, d time.Duration) error {
+	cb, ok := p.current().circuitBreakers[name]
+	if !ok {
+		return fmt.Errorf("unknown circuit breaker %q", name)
+	}
+
+	if d <= 0 {
+		return fmt.Errorf("invalid %s duration %s for %q: must be positive", state, d, name)
+	}
+
+	cb.force(state, d)
+	return nil
+}
+
+func breakerKey(key []string) string {
+	if len(key) == 0 {
+		return ""
+	}
+
+	return key[0]
+}
+
+// build turns cfg into a provider state. Stateful policies whose settings
+// are unchanged from prev are carried over, so that an update does not
+// reset breakers, bulkheads and the like.
+func (p *Provider) build(cfg Config, prev *providerState) (*providerState, error) {
+	if prev == nil {
+		prev = &providerState{}
+	}
+
+	if p.opts.envExpansion {
+		expanded, err := expandConfigEnv(cfg)
+		if err != nil {
+			return nil, err
+		}
+		cfg = expanded
+	}
+
+	s := &providerState{
+		timeouts:        make(map[string]*timeout),
+		retries:         make(map[string]*retry),
+		circuitBreakers: make(map[string]*circuitBreaker),
+		bulkheads:       make(map[string]*bulkhead),
+		caches:          make(map[string]*resultCache),
+		limiters:        make(map[string]*adaptiveLimiter),
+		shedders:        make(map[string]*shedder),
+		custom:          make(map[string]Middleware),
+		targets:         make(map[string]target),
+		chains:          make(map[string][]string),
+	}
+
+	// Every problem is collected rather than returned, so that a config is
+	// fixed in one pass instead of one error at a time.
+	var errs configErrors
+
+	unit, err := versionDurationUnit(cfg.Version, p.opts.durationUnit)
+	if err != nil {
+		errs.add("version", "%v", err)
+	}
+
+	// Inline policies are reported under the target that defines them.
+	specs := cfg.Targets
+	retryPath := func(name string) string {
+		if t, ok := specs[name]; ok && t.RetrySpec != nil {
+			return entryPath("targets", name) + ".retrySpec"
+		}
+		return entryPath("retries", name)
+	}
+	breakerPath := func(name string) string {
+		if t, ok := specs[name]; ok && t.CircuitBreakerSpec != nil {
+			return entryPath("targets", name) + ".circuitBreakerSpec"
+		}
+		return entryPath("circuitBreakers", name)
+	}
+
+	if inlined, err := inlineRetries(cfg); err != nil {
+		errs.nest("", err)
+	} else {
+		cfg = inlined
+	}
+
+	if inlined, err := inlineCircuitBreakers(cfg); err != nil {
+		errs.nest("", err)
+	} else {
+		cfg = inlined
+	}
+
+	cfg.Retries = resolveExtends(cfg.Retries, retryPath, func(r Retry) string { return r.Extends }, inheritRetry, &errs)
+	cfg.CircuitBreakers = resolveExtends(cfg.CircuitBreakers, breakerPath, func(c CircuitBreaker) string { return c.Extends }, inheritCircuitBreaker, &errs)
+	s.config = cfg
+
+	prevUnit, _ := versionDurationUnit(prev.config.Version, p.opts.durationUnit)
+	s.diff = diffConfigs(prev.config, prevUnit, cfg, unit)
+
+	for _, name := range slices.Sorted(maps.Keys(cfg.Timeouts)) {
+		timeout, err := newTimeout(cfg.Timeouts[name], unit)
 		if err != nil {
-			return fmt.Errorf("invalid timeout duration %s for %q: %w", val, name, err)
+			errs.nest(entryPath("timeouts", name), err)
+			continue
 		}
-		p.timeouts[name] = timeout
+		s.timeouts[name] = timeout
 	}
 
-	for name, retryCfg := range cfg.Retries {
-		retryInstance, err := newRetry(name, retryCfg)
+	for _, name := range slices.Sorted(maps.Keys(cfg.Retries)) {
+		if err := errors.Join(checkRetryVersion(cfg.Version, cfg.Retries[name]), validateRetry(cfg.Retries[name], p.opts)); err != nil {
+			errs.nest(retryPath(name), err)
+			continue
+		}
+
+		retryInstance, err := newRetry(cfg.Retries[name], unit)
 		if err != nil {
-			return fmt.Errorf("failed to create retry for %q: %w", name, err)
+			errs.nest(retryPath(name), err)
+			continue
 		}
+		s.retries[name] = retryInstance
+	}
 
-		p.retries[name] = retryInstance
+	if !p.opts.allowDanglingRefs {
+		errs.nest("", validateReferences(cfg, unit))
 	}
 
-	for name, cbCfg := range cfg.CircuitBreakers {
-		cb, err := newCircuitBreaker(name, cbCfg)
+	for _, name := range slices.Sorted(maps.Keys(cfg.CircuitBreakers)) {
+		cbCfg := cfg.CircuitBreakers[name]
+
+		if err := validateCircuitBreaker(cbCfg, p.opts); err != nil {
+			errs.nest(breakerPath(name), err)
+
+			// Report the breaker's other problems too, without registering it.
+			_, err := newCircuitBreaker(name, cbCfg, unit)
+			errs.nest(breakerPath(name), err)
+			continue
+		}
+
+		if cb, ok := unchanged(prev.circuitBreakers, s.diff, "circuitBreakers", name); ok {
+			s.circuitBreakers[name] = cb
+			continue
+		}
+
+		cb, err := p.newCircuitBreaker(name, cbCfg, unit)
 		if err != nil {
-			return fmt.Errorf("failed to create circuit breaker for %q: %w", name, err)
+			errs.nest(breakerPath(name), err)
+			continue
 		}
+		s.circuitBreakers[name] = cb
+	}
 
-		p.circuitBreakers[name] = cb
+	for _, name := range slices.Sorted(maps.Keys(cfg.Bulkheads)) {
+		bhCfg := cfg.Bulkheads[name]
+		if bh, ok := unchanged(prev.bulkheads, s.diff, "bulkheads", name); ok {
+			s.bulkheads[name] = bh
+			continue
+		}
+
+		bh, err := newBulkhead(name, bhCfg, unit)
+		if err != nil {
+			errs.nest(entryPath("bulkheads", name), err)
+			continue
+		}
+		s.bulkheads[name] = bh
 	}
 
-	for k, n := range cfg.Targets {
-		p.targets[k] = target{
-			timeout:        n.Timeout,
-			retry:          n.Retry,
-			circuitBreaker: n.CircuitBreaker,
+	for _, name := range slices.Sorted(maps.Keys(cfg.Caches)) {
+		cacheCfg := cfg.Caches[name]
+		if cache, ok := unchanged(prev.caches, s.diff, "caches", name); ok {
+			s.caches[name] = cache
+			continue
 		}
+
+		cache, err := newResultCache(cacheCfg, unit)
+		if err != nil {
+			errs.nest(entryPath("caches", name), err)
+			continue
+		}
+		s.caches[name] = cache
 	}
-	return nil
+
+	for _, name := range slices.Sorted(maps.Keys(cfg.AdaptiveLimits)) {
+		limitCfg := cfg.AdaptiveLimits[name]
+		if limiter, ok := unchanged(prev.limiters, s.diff, "adaptiveLimits", name); ok {
+			s.limiters[name] = limiter
+			continue
+		}
+
+		limiter, err := newAdaptiveLimiter(limitCfg, unit)
+		if err != nil {
+			errs.nest(entryPath("adaptiveLimits", name), err)
+			continue
+		}
+		s.limiters[name] = limiter
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(cfg.Sheds)) {
+		shedCfg := cfg.Sheds[name]
+		if shedder, ok := unchanged(prev.shedders, s.diff, "sheds", name); ok {
+			s.shedders[name] = shedder
+			continue
+		}
+
+		shedder, err := newShedder(name, shedCfg)
+		if err != nil {
+			errs.nest(entryPath("sheds", name), err)
+			continue
+		}
+		s.shedders[name] = shedder
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(cfg.Custom)) {
+		if mw, ok := unchanged(prev.custom, s.diff, "custom", name); ok {
+			s.custom[name] = mw
+			continue
+		}
+
+		mw, err := newCustomPolicy(cfg.Custom[name])
+		if err != nil {
+			errs.nest(entryPath("custom", name), err)
+			continue
+		}
+		s.custom[name] = mw
+	}
+
+	errs.nest("", validateChains(cfg))
+
+	aliases, err := buildAliases(cfg)
+	errs.nest("", err)
+	s.aliases = aliases
+
+	for name, targets := range cfg.Chains {
+		s.chains[name] = slices.Clone(targets)
+	}
+
+	s.defaultTimeout = cfg.Defaults.Timeout
+	s.defaultLiteral = s.literalTimeout(cfg, "defaults", cfg.Defaults.Timeout, unit)
+
+	for _, k := range slices.Sorted(maps.Keys(cfg.Targets)) {
+		s.targets[k] = s.buildTarget(cfg, fmt.Sprintf("target %q", k), entryPath("targets", k), cfg.Targets[k], unit, &errs)
+	}
+
+	s.groups = s.buildGroups(cfg, unit, &errs)
+
+	if err := errs.err(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
 }
 
-func parseDuration(val string) (time.Duration, error) {
+// buildTarget builds the policies a target or group names. owner describes
+// it in warnings and path locates it in errors.
+func (s *providerState) buildTarget(cfg Config, owner, path string, n PolicyNames, unit time.Duration, errs *configErrors) target {
+	onAny, err := parseFallbackOn(n.FallbackOn)
+	if err != nil {
+		errs.nest(joinPath(path, "fallbackOn"), err)
+	}
+
+	minDeadline := errs.duration(joinPath(path, "minDeadline"), n.MinDeadline, unit)
+	errs.nest(joinPath(path, "order"), validateOrder(n.Order))
+
+	literals := literalTimeouts{
+		timeout:        s.literalTimeout(cfg, owner, n.Timeout, unit),
+		overallTimeout: s.literalTimeout(cfg, owner, n.OverallTimeout, unit),
+	}
+
+	coalescer, err := newCoalescer(n.Coalesce, unit)
+	errs.nest(joinPath(path, "coalesce"), err)
+
+	var flight *singleflight.Group
+	if n.Singleflight {
+		flight = new(singleflight.Group)
+	}
+
+	return target{
+		timeout:        n.Timeout,
+		overallTimeout: n.OverallTimeout,
+		literals:       literals,
+		minDeadline:    minDeadline,
+		order:          slices.Clone(n.Order),
+		retry:          n.Retry,
+		circuitBreaker: n.CircuitBreaker,
+		bulkhead:       n.Bulkhead,
+		adaptiveLimit:  n.AdaptiveLimit,
+		shed:           n.Shed,
+		cache:          n.Cache,
+		flight:         flight,
+		coalescer:      coalescer,
+		fallbackOnAny:  onAny,
+		custom:         slices.Clone(n.Custom),
+	}
+}
+
+// unchanged returns the policy built for name from the previous config if
+// diff does not list the entry as changed.
+func unchanged[V any](built map[string]V, diff ConfigDiff, section, name string) (V, bool) {
+	if diff.changed(section, name) {
+		var zero V
+		return zero, false
+	}
+
+	v, ok := built[name]
+	return v, ok
+}
+
+// literalTimeouts holds the timeouts a target references by a duration
+// literal rather than by name. They belong to the target alone, so they
+// never show up among the named timeouts.
+type literalTimeouts struct {
+	timeout        *timeout
+	overallTimeout *timeout
+}
+
+// literalTimeout builds the timeout for ref when it is a duration literal
+// such as "2s", and returns nil otherwise. Named timeouts win; a name that
+// also parses as a duration is reported in Warnings.
+func (s *providerState) literalTimeout(cfg Config, owner, ref string, unit time.Duration) *timeout {
+	if ref == "" || ref == TimeoutNone {
+		return nil
+	}
+
+	d, err := parseDuration(ref, unit)
+	if err != nil {
+		return nil
+	}
+
+	if _, ok := cfg.Timeouts[ref]; ok {
+		s.warnings = append(s.warnings, fmt.Sprintf("%s: timeout %q is both a named timeout and a duration literal, using the named timeout", owner, ref))
+		return nil
+	}
+
+	return &timeout{duration: d}
+}
+
+// timeout resolves ref to the named timeout, or else to literal, the
+// timeout built from ref as a duration literal.
+func (s *providerState) timeout(ref string, literal *timeout) (*timeout, bool) {
+	if ref == "" || ref == TimeoutNone {
+		return nil, false
+	}
+
+	if t, ok := s.timeouts[ref]; ok {
+		return t, true
+	}
+
+	return literal, literal != nil
+}
+
+// Warnings returns non-fatal issues found while reading the config.
+func (p *Provider) Warnings() []string {
+	return slices.Clone(p.current().warnings)
+}
+
+func (p *Provider) newCircuitBreaker(name string, cfg CircuitBreaker, unit time.Duration) (*circuitBreaker, error) {
+	if p.opts.registry != nil {
+		return p.opts.registry.acquire(p, name, cfg, unit)
+	}
+
+	return newCircuitBreaker(name, cfg, unit)
+}
+
+// parseDuration parses a Go duration string or an ISO-8601 duration. A
+// bare integer such as "500" is read in unit, or rejected when unit is zero,
+// since guessing the unit has produced timeouts a thousand times shorter
+// than intended.
+func parseDuration(val string, unit time.Duration) (time.Duration, error) {
 	if val == "" {
 		return 0, nil
 	}
 
+	if strings.HasPrefix(val, "P") {
+		return parseISODuration(val)
+	}
+
 	if i, err := strconv.ParseInt(val, 10, 64); err == nil {
-		return time.Duration(i) * time.Microsecond, nil
+		if unit == 0 {
+			return 0, fmt.Errorf("duration %q has no unit, write it as e.g. %q", val, val+"ms")
+		}
+
+		return time.Duration(i) * unit, nil
 	}
 
 	return time.ParseDuration(val)
@@ -0,0 +1,70 @@
+package goresilience
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrProviderRegistered is returned by Register for a name that is taken.
+var ErrProviderRegistered = errors.New("provider already registered")
+
+// providers is the process-wide set of named providers.
+var providers = struct {
+	sync.RWMutex
+	named map[string]*Provider
+	def   *Provider
+}{named: make(map[string]*Provider)}
+
+// Register makes p available to Lookup under name, so that code which did
+// not build a provider, such as middleware, can find it. A name can only be
+// registered once until it is deregistered.
+func Register(name string, p *Provider) error {
+	if p == nil {
+		return fmt.Errorf("cannot register a nil provider as %q", name)
+	}
+
+	providers.Lock()
+	defer providers.Unlock()
+
+	if _, ok := providers.named[name]; ok {
+		return fmt.Errorf("%w: %q", ErrProviderRegistered, name)
+	}
+
+	providers.named[name] = p
+	return nil
+}
+
+// Lookup returns the provider registered under name.
+func Lookup(name string) (*Provider, bool) {
+	providers.RLock()
+	defer providers.RUnlock()
+
+	p, ok := providers.named[name]
+	return p, ok
+}
+
+// Deregister frees name. It does not close the provider.
+func Deregister(name string) {
+	providers.Lock()
+	defer providers.Unlock()
+
+	delete(providers.named, name)
+}
+
+// SetDefault makes p the provider Default returns, replacing any previous
+// one. A nil p clears it.
+func SetDefault(p *Provider) {
+	providers.Lock()
+	defer providers.Unlock()
+
+	providers.def = p
+}
+
+// Default returns the provider set with SetDefault, or nil.
+func Default() *Provider {
+	providers.RLock()
+	defer providers.RUnlock()
+
+	return providers.def
+}
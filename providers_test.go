@@ -0,0 +1,99 @@
+package goresilience_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	goresilience "github.com/rickKoch/go-resilience"
+)
+
+func TestRegister(t *testing.T) {
+	payments, err := goresilience.FromConfig(reloadConfig(1, reloadBreaker))
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+	other, err := goresilience.FromConfig(reloadConfig(2, reloadBreaker))
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	if err := goresilience.Register("payments", payments); err != nil {
+		t.Fatalf("failed to register provider: %v", err)
+	}
+	t.Cleanup(func() { goresilience.Deregister("payments") })
+
+	if err := goresilience.Register("payments", other); !errors.Is(err, goresilience.ErrProviderRegistered) {
+		t.Fatalf("expected ErrProviderRegistered for a duplicate name, got %v", err)
+	}
+	if err := goresilience.Register("nil", nil); err == nil {
+		t.Fatal("expected registering a nil provider to fail")
+	}
+
+	if p, ok := goresilience.Lookup("payments"); !ok || p != payments {
+		t.Fatal("expected the first registration to be kept")
+	}
+	if _, ok := goresilience.Lookup("ledger"); ok {
+		t.Fatal("expected no provider for an unregistered name")
+	}
+
+	goresilience.Deregister("payments")
+	if _, ok := goresilience.Lookup("payments"); ok {
+		t.Fatal("expected the name to be free after Deregister")
+	}
+	if err := goresilience.Register("payments", other); err != nil {
+		t.Fatalf("expected the name to be reusable after Deregister, got %v", err)
+	}
+}
+
+func TestRegisterConcurrent(t *testing.T) {
+	provider, err := goresilience.FromConfig(reloadConfig(1, reloadBreaker))
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+	t.Cleanup(func() { goresilience.Deregister("concurrent") })
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	registered := 0
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if goresilience.Register("concurrent", provider) == nil {
+				mu.Lock()
+				registered++
+				mu.Unlock()
+			}
+			goresilience.Lookup("concurrent")
+		}()
+	}
+	wg.Wait()
+
+	if registered != 1 {
+		t.Fatalf("expected exactly one registration to succeed, got %d", registered)
+	}
+}
+
+func TestDefaultProvider(t *testing.T) {
+	if p := goresilience.Default(); p != nil {
+		t.Fatalf("expected no default provider, got %p", p)
+	}
+
+	provider, err := goresilience.FromConfig(reloadConfig(1, reloadBreaker))
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	goresilience.SetDefault(provider)
+	t.Cleanup(func() { goresilience.SetDefault(nil) })
+
+	if goresilience.Default() != provider {
+		t.Fatal("expected Default to return the provider set with SetDefault")
+	}
+
+	goresilience.SetDefault(nil)
+	if p := goresilience.Default(); p != nil {
+		t.Fatalf("expected SetDefault(nil) to clear the default, got %p", p)
+	}
+}
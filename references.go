@@ -0,0 +1,66 @@
+package goresilience
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+)
+
+// validateReferences reports every policy a target or group references by a name
+// that is not defined, since Policy would otherwise silently skip it.
+func validateReferences(cfg Config, unit time.Duration) error {
+	var errs configErrors
+
+	timeoutRef := func(owner, field, ref string) {
+		if ref == "" || ref == TimeoutNone {
+			return
+		}
+
+		if _, ok := cfg.Timeouts[ref]; ok {
+			return
+		}
+
+		if _, err := parseDuration(ref, unit); err == nil {
+			return
+		}
+
+		errs.add(joinPath(owner, field), "%q is not defined", ref)
+	}
+
+	timeoutRef("defaults", "timeout", cfg.Defaults.Timeout)
+
+	policyRefs := func(owner string, t PolicyNames) {
+		timeoutRef(owner, "timeout", t.Timeout)
+		timeoutRef(owner, "overallTimeout", t.OverallTimeout)
+		checkReference(&errs, owner, "retry", t.Retry, cfg.Retries)
+		checkReference(&errs, owner, "circuitBreaker", t.CircuitBreaker, cfg.CircuitBreakers)
+		checkReference(&errs, owner, "bulkhead", t.Bulkhead, cfg.Bulkheads)
+		checkReference(&errs, owner, "cache", t.Cache, cfg.Caches)
+		checkReference(&errs, owner, "adaptiveLimit", t.AdaptiveLimit, cfg.AdaptiveLimits)
+		checkReference(&errs, owner, "shed", t.Shed, cfg.Sheds)
+		for i, ref := range t.Custom {
+			checkReference(&errs, owner, fmt.Sprintf("custom[%d]", i), ref, cfg.Custom)
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(cfg.Targets)) {
+		policyRefs(entryPath("targets", name), cfg.Targets[name])
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(cfg.Groups)) {
+		policyRefs(entryPath("groups", name), cfg.Groups[name].PolicyNames)
+	}
+
+	return errs.err()
+}
+
+func checkReference[V any](errs *configErrors, owner, field, ref string, defined map[string]V) {
+	if ref == "" {
+		return
+	}
+
+	if _, ok := defined[ref]; !ok {
+		errs.add(joinPath(owner, field), "%q is not defined", ref)
+	}
+}
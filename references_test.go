@@ -0,0 +1,69 @@
+package goresilience_test
+
+import (
+	"strings"
+	"testing"
+
+	goresilience "github.com/rickKoch/go-resilience"
+)
+
+func danglingConfig(targets map[string]goresilience.PolicyNames) goresilience.Config {
+	return goresilience.Config{
+		Timeouts: map[string]goresilience.TimeoutSpec{
+			"test_timeout": {Duration: "1s"},
+		},
+		Retries: map[string]goresilience.Retry{
+			"standard": {Duration: "10ms", MaxRetries: 3},
+		},
+		CircuitBreakers: map[string]goresilience.CircuitBreaker{
+			"test_cb": {Interval: "10s", MaxRequests: 1, Failures: 1},
+		},
+		Targets: targets,
+	}
+}
+
+func TestDanglingReference(t *testing.T) {
+	_, err := goresilience.FromConfig(danglingConfig(map[string]goresilience.PolicyNames{
+		"test_target": {Retry: "standrd"},
+	}))
+	if err == nil || !strings.Contains(err.Error(), `targets["test_target"].retry: "standrd" is not defined`) {
+		t.Fatalf("expected a dangling retry error, got: %v", err)
+	}
+}
+
+func TestDanglingReferencesListed(t *testing.T) {
+	_, err := goresilience.FromConfig(danglingConfig(map[string]goresilience.PolicyNames{
+		"a": {Timeout: "tiemout", CircuitBreaker: "test_cb"},
+		"b": {Retry: "standard", CircuitBreaker: "missing_cb", OverallTimeout: "overall"},
+		"c": {Timeout: "250ms", OverallTimeout: goresilience.TimeoutNone},
+	}))
+	if err == nil {
+		t.Fatal("expected dangling reference errors")
+	}
+
+	for _, want := range []string{
+		`targets["a"].timeout: "tiemout" is not defined`,
+		`targets["b"].overallTimeout: "overall" is not defined`,
+		`targets["b"].circuitBreaker: "missing_cb" is not defined`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("expected %q in the error, got: %v", want, err)
+		}
+	}
+	if strings.Contains(err.Error(), `"c"`) {
+		t.Fatalf("expected literal and none timeouts to be accepted, got: %v", err)
+	}
+}
+
+func TestAllowDanglingRefs(t *testing.T) {
+	provider, err := goresilience.FromConfigWithOptions(danglingConfig(map[string]goresilience.PolicyNames{
+		"test_target": {Retry: "later", CircuitBreaker: "test_cb"},
+	}), goresilience.WithAllowDanglingRefs())
+	if err != nil {
+		t.Fatalf("expected dangling references to be allowed, got: %v", err)
+	}
+
+	if provider.Policy("test_target") == nil {
+		t.Fatal("expected a policy for the target")
+	}
+}
@@ -0,0 +1,165 @@
+package goresilience
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+	"weak"
+)
+
+type BreakerRegistry struct {
+	mu       sync.Mutex
+	breakers map[string]*registeredBreaker
+}
+
+type registeredBreaker struct {
+	config CircuitBreaker
+	unit   time.Duration
+	cb     *circuitBreaker
+	users  []weak.Pointer[Provider]
+}
+
+func NewBreakerRegistry() *BreakerRegistry {
+	return &BreakerRegistry{breakers: make(map[string]*registeredBreaker)}
+}
+
+// acquire returns the breaker registered under name, registering it for p
+// first if there is none. unit is the one p reads bare integers in for the
+// config being built, which depends on its version. A breaker only p holds
+// is replaced when its settings change, as it would be without a registry.
+func (r *BreakerRegistry) acquire(p *Provider, name string, cfg CircuitBreaker, unit time.Duration) (*circuitBreaker, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if rb, ok := r.breakers[name]; ok {
+		sameSettings := newBreakerSettingsKey(rb.config) == newBreakerSettingsKey(cfg)
+		if sameSettings && rb.unit == unit {
+			if user := weak.Make(p); !slices.Contains(rb.users, user) {
+				rb.users = append(rb.users, user)
+			}
+			return rb.cb, nil
+		}
+
+		if rb.heldByOthers(p) {
+			if !sameSettings {
+				return nil, fmt.Errorf("circuit breaker %q is already registered with different settings: registered %+v, got %+v", name, rb.config, cfg)
+			}
+			return nil, fmt.Errorf("circuit breaker %q is already registered with duration unit %v, got %v", name, rb.unit, unit)
+		}
+	}
+
+	cb, err := newCircuitBreaker(name, cfg, unit)
+	if err != nil {
+		return nil, err
+	}
+
+	r.breakers[name] = &registeredBreaker{
+		config: cfg,
+		unit:   unit,
+		cb:     cb,
+		users:  []weak.Pointer[Provider]{weak.Make(p)},
+	}
+
+	return cb, nil
+}
+
+// release drops p's hold on cb, registered as name, once p no longer uses
+// it. The breaker is removed when no live provider holds it.
+func (r *BreakerRegistry) release(p *Provider, name string, cb *circuitBreaker) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rb, ok := r.breakers[name]
+	if !ok || rb.cb != cb {
+		return
+	}
+
+	rb.users = slices.DeleteFunc(rb.users, func(user weak.Pointer[Provider]) bool {
+		held := user.Value()
+		return held == nil || held == p
+	})
+	if len(rb.users) == 0 {
+		delete(r.breakers, name)
+	}
+}
+
+// heldByOthers reports whether a live provider other than p holds rb.
+func (rb *registeredBreaker) heldByOthers(p *Provider) bool {
+	for _, user := range rb.users {
+		if held := user.Value(); held != nil && held != p {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Prune drops breakers whose providers have all been garbage collected and
+// returns how many were removed.
+func (r *BreakerRegistry) Prune() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	pruned := 0
+	for name, rb := range r.breakers {
+		live := rb.users[:0]
+		for _, user := range rb.users {
+			if user.Value() != nil {
+				live = append(live, user)
+			}
+		}
+		rb.users = live
+
+		if len(live) == 0 {
+			delete(r.breakers, name)
+			pruned++
+		}
+	}
+
+	return pruned
+}
+
+func (r *BreakerRegistry) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return len(r.breakers)
+}
+
+// breakerSettingsKey holds the settings that decide how a breaker
+// behaves, which every provider sharing it through a registry must agree
+// on. Settings added to CircuitBreaker need adding here too if they change
+// behavior. Extends is left out, since it has been resolved by the time a
+// breaker is built.
+type breakerSettingsKey struct {
+	maxRequests    int
+	interval       string
+	timeout        string
+	failures       int
+	maxKeys        int
+	keyIdleTimeout string
+	rampUp         string
+	countTimeouts  bool
+	warmUp         string
+	probeTimeout   string
+	dryRun         bool
+	outliers       OutlierDetection
+}
+
+func newBreakerSettingsKey(c CircuitBreaker) breakerSettingsKey {
+	return breakerSettingsKey{
+		maxRequests:    c.MaxRequests,
+		interval:       c.Interval,
+		timeout:        c.Timeout,
+		failures:       c.Failures,
+		maxKeys:        c.MaxKeys,
+		keyIdleTimeout: c.KeyIdleTimeout,
+		rampUp:         c.RampUp,
+		countTimeouts:  c.CountTimeouts,
+		warmUp:         c.WarmUp,
+		probeTimeout:   c.ProbeTimeout,
+		dryRun:         c.DryRun,
+		outliers:       c.OutlierDetection,
+	}
+}
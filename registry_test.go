@@ -0,0 +1,211 @@
+package goresilience_test
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync"
+	"testing"
+
+	goresilience "github.com/rickKoch/go-resilience"
+)
+
+func tenantConfig(failures int) goresilience.Config {
+	return goresilience.Config{
+		CircuitBreakers: map[string]goresilience.CircuitBreaker{
+			"shared_db": {
+				MaxRequests: 1,
+				Interval:    "10s",
+				Timeout:     "10s",
+				Failures:    failures,
+			},
+		},
+		Targets: map[string]goresilience.PolicyNames{
+			"db": {
+				CircuitBreaker: "shared_db",
+			},
+		},
+	}
+}
+
+func TestBreakerRegistrySharesState(t *testing.T) {
+	reg := goresilience.NewBreakerRegistry()
+
+	providerA, err := goresilience.FromConfigWithOptions(tenantConfig(1), goresilience.WithBreakerRegistry(reg))
+	if err != nil {
+		t.Fatalf("failed to create provider A: %v", err)
+	}
+
+	providerB, err := goresilience.FromConfigWithOptions(tenantConfig(1), goresilience.WithBreakerRegistry(reg))
+	if err != nil {
+		t.Fatalf("failed to create provider B: %v", err)
+	}
+
+	execA := goresilience.NewExecutor(context.Background(), providerA.Policy("db"))
+	_, _ = execA(func(ctx context.Context) (any, error) {
+		return nil, testError
+	})
+
+	execB := goresilience.NewExecutor(context.Background(), providerB.Policy("db"))
+	_, err = execB(func(ctx context.Context) (any, error) {
+		t.Error("operation should not be executed when the shared circuit is open")
+		return nil, nil
+	})
+	if !errors.Is(err, goresilience.ErrOpenState) {
+		t.Fatalf("expected ErrOpenState via provider B, got: %v", err)
+	}
+}
+
+func TestBreakerRegistrySettingsMismatch(t *testing.T) {
+	reg := goresilience.NewBreakerRegistry()
+
+	if _, err := goresilience.FromConfigWithOptions(tenantConfig(1), goresilience.WithBreakerRegistry(reg)); err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	_, err := goresilience.FromConfigWithOptions(tenantConfig(5), goresilience.WithBreakerRegistry(reg))
+	if err == nil {
+		t.Fatal("expected error for mismatched breaker settings")
+	}
+}
+
+func TestBreakerRegistrySettingsCompared(t *testing.T) {
+	reg := goresilience.NewBreakerRegistry()
+
+	if _, err := goresilience.FromConfigWithOptions(tenantConfig(1), goresilience.WithBreakerRegistry(reg)); err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	// The same settings, inherited rather than written out, share the breaker.
+	inherited := tenantConfig(1)
+	inherited.CircuitBreakers = map[string]goresilience.CircuitBreaker{
+		"base":      inherited.CircuitBreakers["shared_db"],
+		"shared_db": {Extends: "base"},
+	}
+	if _, err := goresilience.FromConfigWithOptions(inherited, goresilience.WithBreakerRegistry(reg)); err != nil {
+		t.Fatalf("expected inherited settings to match, got: %v", err)
+	}
+
+	dryRun := tenantConfig(1)
+	cb := dryRun.CircuitBreakers["shared_db"]
+	cb.DryRun = true
+	dryRun.CircuitBreakers["shared_db"] = cb
+	if _, err := goresilience.FromConfigWithOptions(dryRun, goresilience.WithBreakerRegistry(reg)); err == nil {
+		t.Fatal("expected error for a breaker that differs only in dryRun")
+	}
+}
+
+func TestBreakerRegistryReload(t *testing.T) {
+	reg := goresilience.NewBreakerRegistry()
+
+	provider, err := goresilience.FromConfigWithOptions(tenantConfig(1), goresilience.WithBreakerRegistry(reg))
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	// The provider is the breaker's only user, so it may change it.
+	if err := provider.UpdateConfig(tenantConfig(5)); err != nil {
+		t.Fatalf("expected the breaker to be changed, got: %v", err)
+	}
+
+	exec := goresilience.NewExecutor(context.Background(), provider.Policy("db"))
+	_, _ = exec(func(ctx context.Context) (any, error) {
+		return nil, testError
+	})
+	if state, _ := provider.BreakerState("shared_db"); state != goresilience.StateClosed {
+		t.Fatalf("expected the new settings to keep the breaker closed, got %s", state)
+	}
+
+	// Another provider now shares the changed breaker.
+	other, err := goresilience.FromConfigWithOptions(tenantConfig(5), goresilience.WithBreakerRegistry(reg))
+	if err != nil {
+		t.Fatalf("expected the changed settings to be registered, got: %v", err)
+	}
+
+	if err := provider.UpdateConfig(goresilience.Config{}); err != nil {
+		t.Fatalf("failed to remove the breaker: %v", err)
+	}
+	if reg.Len() != 1 {
+		t.Fatalf("expected the breaker to stay registered for the other provider, got %d breakers", reg.Len())
+	}
+	runtime.KeepAlive(other)
+}
+
+func TestBreakerRegistryReloadShared(t *testing.T) {
+	reg := goresilience.NewBreakerRegistry()
+
+	providerA, err := goresilience.FromConfigWithOptions(tenantConfig(1), goresilience.WithBreakerRegistry(reg))
+	if err != nil {
+		t.Fatalf("failed to create provider A: %v", err)
+	}
+
+	providerB, err := goresilience.FromConfigWithOptions(tenantConfig(1), goresilience.WithBreakerRegistry(reg))
+	if err != nil {
+		t.Fatalf("failed to create provider B: %v", err)
+	}
+
+	// Provider B still holds the old settings.
+	if err := providerA.UpdateConfig(tenantConfig(5)); err == nil {
+		t.Fatal("expected error for changing a breaker another provider holds")
+	}
+
+	for _, provider := range []*goresilience.Provider{providerA, providerB} {
+		if err := provider.UpdateConfig(goresilience.Config{}); err != nil {
+			t.Fatalf("failed to remove the breaker: %v", err)
+		}
+	}
+	if reg.Len() != 0 {
+		t.Fatalf("expected the removed breaker to be released, got %d breakers", reg.Len())
+	}
+	if pruned := reg.Prune(); pruned != 0 {
+		t.Fatalf("expected nothing left to prune, got %d", pruned)
+	}
+}
+
+func TestBreakerRegistryPrune(t *testing.T) {
+	reg := goresilience.NewBreakerRegistry()
+
+	provider, err := goresilience.FromConfigWithOptions(tenantConfig(1), goresilience.WithBreakerRegistry(reg))
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	if reg.Prune() != 0 {
+		t.Fatal("breakers referenced by a live provider should not be pruned")
+	}
+	runtime.KeepAlive(provider)
+
+	provider = nil
+	runtime.GC()
+
+	if pruned := reg.Prune(); pruned != 1 {
+		t.Fatalf("expected 1 pruned breaker, got %d", pruned)
+	}
+	if reg.Len() != 0 {
+		t.Fatalf("expected empty registry, got %d breakers", reg.Len())
+	}
+}
+
+func TestBreakerRegistryConcurrentProviders(t *testing.T) {
+	reg := goresilience.NewBreakerRegistry()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := goresilience.FromConfigWithOptions(tenantConfig(1), goresilience.WithBreakerRegistry(reg))
+			errs <- err
+			reg.Prune()
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+}
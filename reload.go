@@ -0,0 +1,91 @@
+package goresilience
+
+import (
+	"fmt"
+	"maps"
+	"time"
+)
+
+// UpdateConfig replaces the provider's config at runtime. Circuit breakers,
+// bulkheads, caches, adaptive limits and shed policies whose settings are
+// unchanged keep their state; changed ones start over and removed ones are
+// dropped, from a breaker registry too, where a breaker another provider
+// still holds cannot be changed. Executors pick up the new config on their
+// next call, and a ConfigReloaded event carries the diff from the previous
+// config. If cfg is invalid, the error is returned and the current config
+// stays in place.
+func (p *Provider) UpdateConfig(cfg Config) error {
+	return p.updateConfig(func(Config) (Config, error) {
+		return cfg, nil
+	})
+}
+
+// AddTarget adds a target to the running config, as UpdateConfig would with
+// the target added. Its references are validated like the rest of the
+// config, and every other policy keeps its state.
+func (p *Provider) AddTarget(name string, policies PolicyNames) error {
+	return p.updateConfig(func(cfg Config) (Config, error) {
+		if _, ok := cfg.Targets[name]; ok {
+			return Config{}, fmt.Errorf("target %q already exists", name)
+		}
+
+		cfg.Targets = maps.Clone(cfg.Targets)
+		if cfg.Targets == nil {
+			cfg.Targets = make(map[string]PolicyNames)
+		}
+		cfg.Targets[name] = policies
+
+		return cfg, nil
+	})
+}
+
+// RemoveTarget removes a target from the running config, after which
+// Policy resolves it like any unknown target. The policies it referenced
+// stay defined, and keep their state, for other targets to use.
+func (p *Provider) RemoveTarget(name string) error {
+	return p.updateConfig(func(cfg Config) (Config, error) {
+		if _, ok := cfg.Targets[name]; !ok {
+			return Config{}, fmt.Errorf("unknown target %q", name)
+		}
+
+		cfg.Targets = maps.Clone(cfg.Targets)
+		delete(cfg.Targets, name)
+
+		return cfg, nil
+	})
+}
+
+// updateConfig builds the config update returns for the current one and
+// swaps it in. Updates are serialized, so update sees the config the
+// previous one left.
+func (p *Provider) updateConfig(update func(Config) (Config, error)) error {
+	p.updateMu.Lock()
+	defer p.updateMu.Unlock()
+
+	prev := p.current()
+
+	cfg, err := update(prev.config)
+	if err != nil {
+		return err
+	}
+
+	state, err := p.build(cfg, prev)
+	if err != nil {
+		return err
+	}
+	state.generation = prev.generation + 1
+
+	p.state.Store(state)
+
+	if p.opts.registry != nil {
+		for name, cb := range prev.circuitBreakers {
+			if state.circuitBreakers[name] != cb {
+				p.opts.registry.release(p, name, cb)
+			}
+		}
+	}
+
+	p.events.emit(Event{Type: ConfigReloaded, Time: time.Now(), Diff: &state.diff})
+
+	return nil
+}
@@ -0,0 +1,263 @@
+package goresilience_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	goresilience "github.com/rickKoch/go-resilience"
+)
+
+func reloadConfig(maxRetries int, breaker goresilience.CircuitBreaker) goresilience.Config {
+	return goresilience.Config{
+		Retries: map[string]goresilience.Retry{
+			"test_retry": {Duration: "1ms", MaxRetries: maxRetries},
+		},
+		CircuitBreakers: map[string]goresilience.CircuitBreaker{
+			"test_cb": breaker,
+		},
+		Targets: map[string]goresilience.PolicyNames{
+			"test_target": {Retry: "test_retry", CircuitBreaker: "test_cb"},
+		},
+	}
+}
+
+var reloadBreaker = goresilience.CircuitBreaker{MaxRequests: 1, Interval: "10s", Timeout: "10s", Failures: 3}
+
+func countAttempts(exec goresilience.Executor) int32 {
+	var calls atomic.Int32
+	_, _ = exec(func(ctx context.Context) (any, error) {
+		calls.Add(1)
+		return nil, errors.New("failed")
+	})
+	return calls.Load()
+}
+
+func TestUpdateConfigKeepsUnchangedBreaker(t *testing.T) {
+	provider, err := goresilience.FromConfig(reloadConfig(1, reloadBreaker))
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	// Two failed attempts, one short of tripping.
+	countAttempts(goresilience.NewExecWithPolicy(context.Background(), provider.Policy("test_target")))
+
+	if err := provider.UpdateConfig(reloadConfig(4, reloadBreaker)); err != nil {
+		t.Fatalf("failed to update config: %v", err)
+	}
+
+	if counts, _ := provider.BreakerCounts("test_cb"); counts.ConsecutiveFailures != 2 {
+		t.Fatalf("expected the unchanged breaker to keep its counts, got %+v", counts)
+	}
+
+	changed := reloadBreaker
+	changed.Timeout = "20s"
+	if err := provider.UpdateConfig(reloadConfig(4, changed)); err != nil {
+		t.Fatalf("failed to update config: %v", err)
+	}
+
+	if counts, _ := provider.BreakerCounts("test_cb"); counts != (goresilience.Counts{}) {
+		t.Fatalf("expected the changed breaker to start over, got %+v", counts)
+	}
+
+	cfg := reloadConfig(4, changed)
+	delete(cfg.CircuitBreakers, "test_cb")
+	cfg.Targets["test_target"] = goresilience.PolicyNames{Retry: "test_retry"}
+	if err := provider.UpdateConfig(cfg); err != nil {
+		t.Fatalf("failed to update config: %v", err)
+	}
+
+	if _, ok := provider.BreakerState("test_cb"); ok {
+		t.Fatal("expected the removed breaker to be dropped")
+	}
+}
+
+func TestUpdateConfigExistingExecutor(t *testing.T) {
+	provider, err := goresilience.FromConfig(reloadConfig(1, reloadBreaker))
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	exec := goresilience.NewExecWithPolicy(context.Background(), provider.Policy("test_target"))
+	if got := countAttempts(exec); got != 2 {
+		t.Fatalf("expected 2 attempts, got %d", got)
+	}
+
+	if err := provider.ResetBreaker("test_cb"); err != nil {
+		t.Fatalf("failed to reset breaker: %v", err)
+	}
+
+	breaker := reloadBreaker
+	breaker.Failures = 10
+	if err := provider.UpdateConfig(reloadConfig(3, breaker)); err != nil {
+		t.Fatalf("failed to update config: %v", err)
+	}
+
+	if got := countAttempts(exec); got != 4 {
+		t.Fatalf("expected the existing executor to use the new retry, got %d attempts", got)
+	}
+}
+
+func TestUpdateConfigRollback(t *testing.T) {
+	provider, err := goresilience.FromConfig(reloadConfig(1, reloadBreaker))
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	countAttempts(goresilience.NewExecWithPolicy(context.Background(), provider.Policy("test_target")))
+
+	invalid := reloadConfig(5, reloadBreaker)
+	invalid.Retries["test_retry"] = goresilience.Retry{Duration: "soon", MaxRetries: 5}
+	if err := provider.UpdateConfig(invalid); err == nil {
+		t.Fatal("expected an invalid config to be rejected")
+	}
+
+	dangling := reloadConfig(5, reloadBreaker)
+	dangling.Targets["test_target"] = goresilience.PolicyNames{Retry: "missing"}
+	if err := provider.UpdateConfig(dangling); err == nil {
+		t.Fatal("expected a dangling reference to be rejected")
+	}
+
+	if counts, _ := provider.BreakerCounts("test_cb"); counts.ConsecutiveFailures != 2 {
+		t.Fatalf("expected the breaker to be untouched, got %+v", counts)
+	}
+
+	if err := provider.ResetBreaker("test_cb"); err != nil {
+		t.Fatalf("failed to reset breaker: %v", err)
+	}
+	if got := countAttempts(goresilience.NewExecWithPolicy(context.Background(), provider.Policy("test_target"))); got != 2 {
+		t.Fatalf("expected the old retry to stay in place, got %d attempts", got)
+	}
+}
+
+func TestAddRemoveTarget(t *testing.T) {
+	provider, err := goresilience.FromConfig(reloadConfig(1, reloadBreaker))
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	// Two failed attempts, one short of tripping.
+	countAttempts(goresilience.NewExecWithPolicy(context.Background(), provider.Policy("test_target")))
+
+	if err := provider.AddTarget("upstream", goresilience.PolicyNames{Retry: "test_retry", CircuitBreaker: "test_cb"}); err != nil {
+		t.Fatalf("failed to add target: %v", err)
+	}
+
+	exec := goresilience.NewExecWithPolicy(context.Background(), provider.Policy("upstream"))
+	if calls := countAttempts(exec); calls != 1 {
+		t.Fatalf("expected the shared breaker to open after one more failure, got %d attempts", calls)
+	}
+
+	if err := provider.RemoveTarget("upstream"); err != nil {
+		t.Fatalf("failed to remove target: %v", err)
+	}
+
+	if calls := countAttempts(exec); calls != 1 {
+		t.Fatalf("expected a removed target to run without policies, got %d attempts", calls)
+	}
+
+	if state, ok := provider.BreakerState("test_cb"); !ok || state != goresilience.StateOpen {
+		t.Fatalf("expected the breaker test_target still uses to stay open, got %v", state)
+	}
+}
+
+func TestAddRemoveTargetValidation(t *testing.T) {
+	provider, err := goresilience.FromConfig(reloadConfig(1, reloadBreaker))
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	var cfgErr *goresilience.ConfigError
+	if err := provider.AddTarget("upstream", goresilience.PolicyNames{Retry: "missing"}); !errors.As(err, &cfgErr) || cfgErr.Path != `targets["upstream"].retry` {
+		t.Fatalf("expected a ConfigError for the undefined retry, got: %v", err)
+	}
+	if _, ok := provider.Describe("upstream"); ok {
+		t.Fatal("expected the invalid target not to be added")
+	}
+
+	if err := provider.AddTarget("test_target", goresilience.PolicyNames{}); err == nil {
+		t.Fatal("expected an error adding an existing target")
+	}
+	if err := provider.RemoveTarget("upstream"); err == nil {
+		t.Fatal("expected an error removing an unknown target")
+	}
+}
+
+func TestAddRemoveTargetConcurrent(t *testing.T) {
+	provider, err := goresilience.FromConfig(reloadConfig(1, reloadBreaker))
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				provider.Policy("upstream")
+			}
+		}()
+	}
+
+	for i := 0; i < 50; i++ {
+		if err := provider.AddTarget("upstream", goresilience.PolicyNames{Retry: "test_retry"}); err != nil {
+			t.Fatalf("failed to add target: %v", err)
+		}
+		if err := provider.RemoveTarget("upstream"); err != nil {
+			t.Fatalf("failed to remove target: %v", err)
+		}
+	}
+
+	wg.Wait()
+}
+
+func TestUpdateConfigConcurrentReads(t *testing.T) {
+	provider, err := goresilience.FromConfig(reloadConfig(1, reloadBreaker))
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+
+				exec := goresilience.NewExecWithPolicy(context.Background(), provider.Policy("test_target"))
+				_, _ = exec(func(ctx context.Context) (any, error) { return successResult, nil })
+
+				// Each lookup sees one config or the other, never a mix.
+				d, _ := provider.Describe("test_target")
+				if d.Retry == nil || (d.Retry.MaxRetries != 1 && d.Retry.MaxRetries != 4) {
+					t.Errorf("expected 1 or 4 retries, got %+v", d.Retry)
+				}
+
+				if _, err := provider.PolicyE("test_target"); err != nil {
+					t.Errorf("expected test_target to stay known: %v", err)
+				}
+				_ = provider.Targets()
+				_ = provider.Metrics()
+				provider.SetFallback("other", nil)
+			}
+		}()
+	}
+
+	for i := 0; i < 50; i++ {
+		if err := provider.UpdateConfig(reloadConfig(1+3*(i%2), reloadBreaker)); err != nil {
+			t.Fatalf("failed to update config: %v", err)
+		}
+	}
+
+	close(stop)
+	wg.Wait()
+}
@@ -0,0 +1,95 @@
+package goresilience
+
+import (
+	"maps"
+	"slices"
+)
+
+// HealthReport is a provider's view of its dependencies, suitable for a
+// readiness endpoint. It marshals to JSON as is.
+type HealthReport struct {
+	Breakers map[string]BreakerHealth `json:"breakers"`
+	Targets  map[string]TargetHealth  `json:"targets"`
+}
+
+// BreakerHealth is the state of a circuit breaker's shared entry and the
+// counts of its current interval.
+type BreakerHealth struct {
+	State  State  `json:"state"`
+	Counts Counts `json:"counts"`
+}
+
+// TargetHealth reports the share of failures among a target's latest
+// executions, up to 100 of them, and names its breaker, if any.
+type TargetHealth struct {
+	Breaker          string  `json:"breaker,omitempty"`
+	FailureRatio     float64 `json:"failureRatio"`
+	RecentExecutions int     `json:"recentExecutions"`
+}
+
+// Health reports the state of every circuit breaker, and the recent
+// failure ratio of every configured target and of any other name that has
+// executed.
+func (p *Provider) Health() HealthReport {
+	state := p.current()
+
+	r := HealthReport{
+		Breakers: make(map[string]BreakerHealth, len(state.circuitBreakers)),
+		Targets:  make(map[string]TargetHealth, len(state.targets)),
+	}
+
+	for name, cb := range state.circuitBreakers {
+		r.Breakers[name] = BreakerHealth{State: cb.State(""), Counts: cb.Counts("")}
+	}
+
+	p.gaugeMu.Lock()
+	counters := maps.Clone(p.counters)
+	p.gaugeMu.Unlock()
+
+	for name := range state.targets {
+		if _, ok := counters[name]; !ok {
+			counters[name] = nil
+		}
+	}
+
+	for name, c := range counters {
+		var h TargetHealth
+		if c != nil {
+			h.FailureRatio, h.RecentExecutions = c.failureRatio()
+		}
+
+		if _, configured := state.targets[name]; !configured && h.RecentExecutions == 0 {
+			continue
+		}
+
+		if cb := p.policy(name).circuitBreaker; cb != nil {
+			h.Breaker = cb.name
+		}
+
+		r.Targets[name] = h
+	}
+
+	return r
+}
+
+// Healthy reports whether none of the critical targets has an open
+// breaker, forced or not. Without critical targets every target is
+// critical. Targets missing from the report are healthy.
+func (r HealthReport) Healthy(criticalTargets ...string) bool {
+	if len(criticalTargets) == 0 {
+		criticalTargets = slices.Collect(maps.Keys(r.Targets))
+	}
+
+	for _, name := range criticalTargets {
+		t, ok := r.Targets[name]
+		if !ok || t.Breaker == "" {
+			continue
+		}
+
+		if state := r.Breakers[t.Breaker].State; state == StateOpen || state == StateForcedOpen {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,75 @@
+package goresilience_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	goresilience "github.com/rickKoch/go-resilience"
+)
+
+func TestHealthReport(t *testing.T) {
+	cfg := reloadConfig(0, reloadBreaker)
+	cfg.Targets["other_target"] = goresilience.PolicyNames{}
+
+	provider, err := goresilience.FromConfig(cfg)
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	exec := goresilience.NewExecWithPolicy(context.Background(), provider.Policy("test_target"))
+	_, _ = exec(succeed)
+
+	report := provider.Health()
+	if !report.Healthy() || !report.Healthy("test_target") {
+		t.Fatalf("expected a healthy report, got %+v", report)
+	}
+	if h := report.Targets["other_target"]; h != (goresilience.TargetHealth{}) {
+		t.Fatalf("expected an idle target without a breaker, got %+v", h)
+	}
+
+	// Three failures open the breaker and the next call is rejected.
+	for i := 0; i < 4; i++ {
+		_, _ = exec(func(ctx context.Context) (any, error) {
+			return nil, errors.New("failed")
+		})
+	}
+
+	report = provider.Health()
+	want := goresilience.TargetHealth{Breaker: "test_cb", FailureRatio: 0.8, RecentExecutions: 5}
+	if h := report.Targets["test_target"]; h != want {
+		t.Fatalf("expected %+v, got %+v", want, h)
+	}
+	if report.Healthy("test_target") || report.Healthy() {
+		t.Fatal("expected the open breaker to make the critical target unhealthy")
+	}
+	if !report.Healthy("other_target", "unknown_target") {
+		t.Fatal("expected targets without an open breaker to stay healthy")
+	}
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		t.Fatalf("failed to marshal report: %v", err)
+	}
+
+	var decoded struct {
+		Breakers map[string]struct {
+			State  string
+			Counts goresilience.Counts
+		}
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("failed to unmarshal report: %v", err)
+	}
+	if cb := decoded.Breakers["test_cb"]; cb.State != "open" {
+		t.Fatalf("expected the breaker state to marshal as a name, got %s", data)
+	}
+
+	if err := provider.ResetBreaker("test_cb"); err != nil {
+		t.Fatalf("failed to reset breaker: %v", err)
+	}
+	if !provider.Health().Healthy("test_target") {
+		t.Fatal("expected the target to be healthy again once its breaker closes")
+	}
+}
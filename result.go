@@ -0,0 +1,56 @@
+package goresilience
+
+import (
+	"context"
+	"time"
+)
+
+// ExecutionResult describes how an execution went. Stage names where a
+// failed execution's error came from, as ExecutionTrace.Stage does, and is
+// empty on success. Cached reports a result served from the cache, and
+// Breaker and BreakerState the target's breaker, if any, after the call.
+type ExecutionResult struct {
+	Value        any
+	Attempts     int
+	Duration     time.Duration
+	Stage        string
+	Cached       bool
+	Breaker      string
+	BreakerState State
+}
+
+// ExecuteDetailed is like Policy.Execute, but also reports how the result
+// came about. Only calls made through it collect the details.
+func ExecuteDetailed(ctx context.Context, policy *Policy, op Operation) (ExecutionResult, error) {
+	if policy == nil {
+		start := time.Now()
+		res, err := op(ctx)
+
+		r := ExecutionResult{Value: res, Attempts: 1, Duration: time.Since(start)}
+		if err != nil {
+			r.Stage = StageOperation
+		}
+
+		return r, err
+	}
+
+	t := new(tracer)
+	res, err := policy.execute(ctx, op, t)
+	trace := t.finish(err)
+
+	r := ExecutionResult{
+		Value:    res,
+		Attempts: len(trace.Attempts),
+		Duration: trace.Duration,
+		Stage:    trace.Stage,
+		Cached:   trace.Cached,
+	}
+
+	if cb := policy.latest().circuitBreaker; cb != nil {
+		key, _ := BreakerKeyFromContext(ctx)
+		r.Breaker = cb.name
+		r.BreakerState = cb.State(key)
+	}
+
+	return r, err
+}
@@ -0,0 +1,93 @@
+package goresilience_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	goresilience "github.com/rickKoch/go-resilience"
+)
+
+func TestExecuteDetailed(t *testing.T) {
+	provider, err := goresilience.FromConfig(reloadConfig(2, reloadBreaker))
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	policy := provider.Policy("test_target")
+
+	r, err := goresilience.ExecuteDetailed(context.Background(), policy, succeed)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := goresilience.ExecutionResult{Value: successResult, Attempts: 1, Breaker: "test_cb", BreakerState: goresilience.StateClosed}
+	if r.Duration <= 0 {
+		t.Errorf("expected the duration to be measured, got %s", r.Duration)
+	}
+	r.Duration = 0
+	if r != want {
+		t.Fatalf("first try: expected %+v, got %+v", want, r)
+	}
+
+	attempts := 0
+	r, err = goresilience.ExecuteDetailed(context.Background(), policy, func(ctx context.Context) (any, error) {
+		attempts++
+		if attempts < 3 {
+			return nil, errors.New("failed")
+		}
+		return successResult, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want.Attempts = 3
+	r.Duration = 0
+	if r != want {
+		t.Fatalf("after retries: expected %+v, got %+v", want, r)
+	}
+
+	// Three failures open the breaker, which rejects the next call.
+	r, err = goresilience.ExecuteDetailed(context.Background(), policy, func(ctx context.Context) (any, error) {
+		return nil, errors.New("failed")
+	})
+	if r.Attempts != 3 || r.Stage != goresilience.StageOperation || r.BreakerState != goresilience.StateOpen {
+		t.Fatalf("after failures: expected 3 attempts failed by the operation, got %+v (%v)", r, err)
+	}
+
+	r, err = goresilience.ExecuteDetailed(context.Background(), policy, succeed)
+	if !errors.Is(err, goresilience.ErrOpenState) {
+		t.Fatalf("expected the breaker to reject the call, got %v", err)
+	}
+	want = goresilience.ExecutionResult{Stage: goresilience.StageCircuitBreaker, Breaker: "test_cb", BreakerState: goresilience.StateOpen}
+	r.Duration = 0
+	if r != want {
+		t.Fatalf("rejected: expected %+v, got %+v", want, r)
+	}
+}
+
+func TestExecuteDetailedCached(t *testing.T) {
+	provider, _ := cacheProvider(t, goresilience.Cache{TTL: "1m", Always: true})
+	policy := provider.Policy("test_target")
+	ctx := goresilience.WithCacheKey(context.Background(), "user-1")
+
+	if _, err := goresilience.ExecuteDetailed(ctx, policy, succeed); err != nil {
+		t.Fatalf("failed to fill the cache: %v", err)
+	}
+
+	r, _ := goresilience.ExecuteDetailed(ctx, policy, succeed)
+	if !r.Cached || r.Attempts != 0 || r.Value != successResult {
+		t.Fatalf("expected a cached result without attempts, got %+v", r)
+	}
+}
+
+func TestExecuteDetailedTimeout(t *testing.T) {
+	provider := timeoutProvider(t, goresilience.TimeoutSpec{Duration: "10ms", Mode: goresilience.TimeoutModeCooperative})
+
+	r, err := goresilience.ExecuteDetailed(context.Background(), provider.Policy("test_target"), func(ctx context.Context) (any, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	if !errors.Is(err, goresilience.ErrExecutionTimeout) || r.Stage != goresilience.StageTimeout || r.Attempts != 1 {
+		t.Fatalf("expected one attempt failed by the timeout, got %+v (%v)", r, err)
+	}
+}
@@ -2,28 +2,145 @@ package goresilience
 
 import (
 	"context"
-	"fmt"
+	"errors"
+	"maps"
+	"slices"
 	"time"
 
 	"github.com/cenkalti/backoff/v4"
 )
 
+// ErrRetriesExhausted is matched by the error of a call whose retry policy
+// used up its retries, alongside the last attempt's error. A call whose
+// retries ended early, on a permanent error or with its context, or that
+// had no retries to make, does not match it.
+var ErrRetriesExhausted = errors.New("retries exhausted")
+
+const (
+	RetryBackoffConstant    = "constant"
+	RetryBackoffExponential = "exponential"
+)
+
+// maxRetryLimit is the most retries a call may make unless the provider is
+// built WithAllowManyRetries; more is almost certainly a typo.
+const maxRetryLimit = 1000
+
 type retry struct {
-	duration   time.Duration
-	maxRetries int
+	duration    time.Duration
+	maxRetries  int
+	exponential bool
+	maxInterval time.Duration
+}
+
+func newRetry(r Retry, unit time.Duration) (*retry, error) {
+	var errs configErrors
+
+	duration := errs.duration("duration", r.Duration, unit)
+	maxInterval := errs.duration("maxInterval", r.MaxInterval, unit)
+
+	switch r.Backoff {
+	case "", RetryBackoffConstant:
+		if r.MaxInterval != "" {
+			errs.add("maxInterval", "only applies to the %q backoff", RetryBackoffExponential)
+		}
+	case RetryBackoffExponential:
+		if maxInterval == 0 {
+			maxInterval = backoff.DefaultMaxInterval
+		}
+	default:
+		errs.add("backoff", "invalid backoff %q: must be %q or %q", r.Backoff, RetryBackoffConstant, RetryBackoffExponential)
+	}
+
+	maxRetries := r.MaxRetries
+	if r.MaxAttempts != 0 {
+		if r.MaxRetries != 0 {
+			errs.add("maxAttempts", "maxRetries and maxAttempts are mutually exclusive")
+		}
+		maxRetries = attemptsToRetries(r.MaxAttempts)
+	}
+
+	if err := errs.err(); err != nil {
+		return nil, err
+	}
+
+	return &retry{
+		duration:    duration,
+		maxRetries:  maxRetries,
+		exponential: r.Backoff == RetryBackoffExponential,
+		maxInterval: maxInterval,
+	}, nil
 }
 
-func newRetry(name string, r Retry) (*retry, error) {
-	duration, err := parseDuration(r.Duration)
-	if err != nil {
-		return nil, fmt.Errorf("invalid retry duration %s for '%q': %w", r.Duration, name, err)
+// validateRetry checks that a retry's count is in range.
+func validateRetry(r Retry, opts options) error {
+	var errs configErrors
+
+	if !opts.allowManyRetries {
+		if r.MaxRetries > maxRetryLimit {
+			errs.add("maxRetries", "must be at most %d, see WithAllowManyRetries, got %d", maxRetryLimit, r.MaxRetries)
+		}
+		if r.MaxAttempts > maxRetryLimit+1 {
+			errs.add("maxAttempts", "must be at most %d, see WithAllowManyRetries, got %d", maxRetryLimit+1, r.MaxAttempts)
+		}
+	}
+
+	return errs.err()
+}
+
+// inlineRetries returns a copy of cfg in which inline retry specs are
+// registered under their target's name and referenced from it.
+func inlineRetries(cfg Config) (Config, error) {
+	retries := maps.Clone(cfg.Retries)
+	targets := maps.Clone(cfg.Targets)
+
+	var errs configErrors
+	for _, name := range slices.Sorted(maps.Keys(cfg.Targets)) {
+		t := cfg.Targets[name]
+		if t.RetrySpec == nil {
+			continue
+		}
+
+		path := entryPath("targets", name) + ".retrySpec"
+
+		if t.Retry != "" {
+			errs.add(path, "retry and retrySpec are mutually exclusive")
+			continue
+		}
+
+		if _, ok := cfg.Retries[name]; ok {
+			errs.add(path, "inline retry conflicts with the retry of the same name")
+			continue
+		}
+
+		if retries == nil {
+			retries = make(map[string]Retry)
+		}
+		retries[name] = *t.RetrySpec
+
+		t.Retry = name
+		t.RetrySpec = nil
+		targets[name] = t
 	}
 
-	return &retry{duration, r.MaxRetries}, nil
+	if err := errs.err(); err != nil {
+		return cfg, err
+	}
+
+	cfg.Retries = retries
+	cfg.Targets = targets
+	return cfg, nil
 }
 
 func (r *retry) backoff(ctx context.Context) backoff.BackOff {
 	var b backoff.BackOff = backoff.NewConstantBackOff(r.duration)
+	if r.exponential {
+		b = backoff.NewExponentialBackOff(
+			backoff.WithInitialInterval(r.duration),
+			backoff.WithMultiplier(2),
+			backoff.WithMaxInterval(r.maxInterval),
+			backoff.WithMaxElapsedTime(0),
+		)
+	}
 
 	if r.maxRetries >= 0 {
 		b = backoff.WithMaxRetries(b, uint64(r.maxRetries))
@@ -4,10 +4,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/cenkalti/backoff/v4"
+
 	goresilience "github.com/rickKoch/go-resilience"
 )
 
@@ -41,9 +44,12 @@ func TestResilienceRetry(t *testing.T) {
 		return "", example_error
 	})
 
-	if err != example_error {
+	if !errors.Is(err, example_error) {
 		t.Fatalf("it should've failed with retry error, but exited with: %s", err)
 	}
+	if !errors.Is(err, goresilience.ErrRetriesExhausted) {
+		t.Fatalf("expected the retries to be exhausted, got: %s", err)
+	}
 
 	if attempts.Load() != 4 {
 		t.Fatal("it should've retry it 3 times")
@@ -135,6 +141,10 @@ func TestRetryWithZeroMaxRetries(t *testing.T) {
 	if attempts.Load() != 1 {
 		t.Fatalf("expected 1 attempt but got: %d", attempts.Load())
 	}
+
+	if errors.Is(err, goresilience.ErrRetriesExhausted) {
+		t.Fatalf("expected a single attempt not to exhaust retries, got: %s", err)
+	}
 }
 
 func TestRetryWithContextCancellation(t *testing.T) {
@@ -178,6 +188,9 @@ func TestRetryWithContextCancellation(t *testing.T) {
 	if !errors.Is(err, context.DeadlineExceeded) {
 		t.Fatalf("expected context deadline exceeded but got: %s", err)
 	}
+	if errors.Is(err, goresilience.ErrRetriesExhausted) {
+		t.Fatalf("expected a cancelled retry loop not to be exhausted, got: %s", err)
+	}
 
 	// Should have attempted at least once but not all 10 times due to context cancellation
 	if attempts.Load() < 1 {
@@ -185,6 +198,32 @@ func TestRetryWithContextCancellation(t *testing.T) {
 	}
 }
 
+func TestRetryPermanentErrorNotExhausted(t *testing.T) {
+	provider, err := goresilience.FromConfig(reloadConfig(3, reloadBreaker))
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	attempts := 0
+	_, err = goresilience.NewExecutor(context.Background(), provider.Policy("test_target"))(func(ctx context.Context) (any, error) {
+		attempts++
+		if attempts == 2 {
+			return nil, backoff.Permanent(errQuotaExceeded)
+		}
+		return nil, errors.New("failed")
+	})
+
+	if attempts != 2 {
+		t.Fatalf("expected the permanent error to end the retries, got %d attempts", attempts)
+	}
+	if !errors.Is(err, errQuotaExceeded) {
+		t.Fatalf("expected the permanent error, got: %v", err)
+	}
+	if errors.Is(err, goresilience.ErrRetriesExhausted) {
+		t.Fatalf("expected retries ended early not to be exhausted, got: %v", err)
+	}
+}
+
 func TestRetryWithInvalidDuration(t *testing.T) {
 	cfg := goresilience.Config{
 		Retries: map[string]goresilience.Retry{
@@ -205,10 +244,68 @@ func TestRetryWithInvalidDuration(t *testing.T) {
 		t.Fatal("expected error for invalid duration but got none")
 	}
 
-	if fmt.Sprintf("%s", err) == "" {
-		t.Fatal("expected non-empty error message")
+	var cfgErr *goresilience.ConfigError
+	if !errors.As(err, &cfgErr) || cfgErr.Path != `retries["invalid_retry"].duration` {
+		t.Fatalf("expected a ConfigError for the retry duration, got: %v", err)
+	}
+}
+
+// delayObserver records the backoff before each retry.
+type delayObserver struct {
+	fakeObserver
+	delays []time.Duration
+}
+
+func (o *delayObserver) OnRetry(target string, attempt int, delay time.Duration, err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.delays = append(o.delays, delay)
+}
+
+func TestRetryExponentialBackoff(t *testing.T) {
+	observer := &delayObserver{}
+	provider, err := goresilience.FromConfigWithOptions(goresilience.Config{
+		Retries: map[string]goresilience.Retry{
+			"test_retry": {Duration: "2ms", MaxRetries: 4, Backoff: goresilience.RetryBackoffExponential, MaxInterval: "8ms"},
+		},
+		Targets: map[string]goresilience.PolicyNames{
+			"test_target": {Retry: "test_retry"},
+		},
+	}, goresilience.WithObserver(observer))
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	if n := countAttempts(goresilience.NewExecutor(context.Background(), provider.Policy("test_target"))); n != 5 {
+		t.Fatalf("expected 5 attempts, got %d", n)
+	}
+
+	// Each wait doubles up to MaxInterval and is randomized by up to half.
+	base := []time.Duration{2 * time.Millisecond, 4 * time.Millisecond, 8 * time.Millisecond, 8 * time.Millisecond}
+	if len(observer.delays) != len(base) {
+		t.Fatalf("expected %d retries, got %v", len(base), observer.delays)
+	}
+	for i, d := range observer.delays {
+		if d < base[i]/2 || d > base[i]*3/2 {
+			t.Fatalf("retry %d: expected a wait around %s, got %s", i+1, base[i], d)
+		}
+	}
+}
+
+func TestRetryInvalidBackoff(t *testing.T) {
+	_, err := goresilience.FromConfig(goresilience.Config{
+		Retries: map[string]goresilience.Retry{
+			"unknown":  {Duration: "1ms", MaxRetries: 1, Backoff: "linear"},
+			"constant": {Duration: "1ms", MaxRetries: 1, MaxInterval: "1s"},
+		},
+	})
+
+	for _, path := range []string{`retries["unknown"].backoff`, `retries["constant"].maxInterval`} {
+		if err == nil || !strings.Contains(err.Error(), path) {
+			t.Errorf("expected an error at %s, got: %v", path, err)
+		}
 	}
-	t.Logf("Got expected error: %s", err)
 }
 
 func TestRetryWithDifferentDurations(t *testing.T) {
@@ -364,6 +461,57 @@ func TestRetryWithNegativeMaxRetries(t *testing.T) {
 	}
 }
 
+func TestRetryMaxRetriesLimit(t *testing.T) {
+	tests := []struct {
+		name       string
+		retry      goresilience.Retry
+		opts       []goresilience.Option
+		expectPath string
+	}{
+		{
+			name:  "at limit",
+			retry: goresilience.Retry{Duration: "1ms", MaxRetries: 1000},
+		},
+		{
+			name:       "above limit",
+			retry:      goresilience.Retry{Duration: "1ms", MaxRetries: 1001},
+			expectPath: `retries["test_retry"].maxRetries`,
+		},
+		{
+			name:       "attempts above limit",
+			retry:      goresilience.Retry{Duration: "1ms", MaxAttempts: 1002},
+			expectPath: `retries["test_retry"].maxAttempts`,
+		},
+		{
+			name:  "above limit allowed",
+			retry: goresilience.Retry{Duration: "1ms", MaxRetries: 5000},
+			opts:  []goresilience.Option{goresilience.WithAllowManyRetries()},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := goresilience.FromConfigWithOptions(goresilience.Config{
+				Retries: map[string]goresilience.Retry{
+					"test_retry": tt.retry,
+				},
+			}, tt.opts...)
+
+			if tt.expectPath == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			var cfgErr *goresilience.ConfigError
+			if !errors.As(err, &cfgErr) || cfgErr.Path != tt.expectPath {
+				t.Fatalf("expected a ConfigError at %s, got: %v", tt.expectPath, err)
+			}
+		})
+	}
+}
+
 func TestRetryWithNoTargetConfig(t *testing.T) {
 	cfg := goresilience.Config{
 		Retries: map[string]goresilience.Retry{
@@ -467,6 +615,7 @@ func BenchmarkRetrySuccess(b *testing.B) {
 	policyProvider, _ := goresilience.FromConfig(cfg)
 	policy := policyProvider.Policy("bench_target")
 
+	b.ReportAllocs()
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
 		exec := goresilience.NewExecutor(context.Background(), policy)
@@ -476,6 +625,35 @@ func BenchmarkRetrySuccess(b *testing.B) {
 	}
 }
 
+// BenchmarkRetrySuccessFullChain reuses one executor for a target with a
+// timeout, circuit breaker and retry, the common high-QPS setup.
+func BenchmarkRetrySuccessFullChain(b *testing.B) {
+	policyProvider, _ := goresilience.FromConfig(goresilience.Config{
+		Timeouts: map[string]goresilience.TimeoutSpec{
+			"bench_timeout": {Duration: "1s", Mode: goresilience.TimeoutModeCooperative},
+		},
+		Retries: map[string]goresilience.Retry{
+			"bench_retry": {Duration: "1ms", MaxRetries: 3},
+		},
+		CircuitBreakers: map[string]goresilience.CircuitBreaker{
+			"bench_cb": {MaxRequests: 1, Interval: "10s", Timeout: "10s", Failures: 5},
+		},
+		Targets: map[string]goresilience.PolicyNames{
+			"bench_target": {Timeout: "bench_timeout", Retry: "bench_retry", CircuitBreaker: "bench_cb"},
+		},
+	})
+	exec := goresilience.NewExecutor(context.Background(), policyProvider.Policy("bench_target"))
+	op := func(ctx context.Context) (any, error) {
+		return "success", nil
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = exec(op)
+	}
+}
+
 func BenchmarkRetryFailure(b *testing.B) {
 	cfg := goresilience.Config{
 		Retries: map[string]goresilience.Retry{
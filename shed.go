@@ -0,0 +1,111 @@
+package goresilience
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync/atomic"
+)
+
+var ErrShed = errors.New("request shed")
+
+// Priority ranks calls for load shedding. The zero value is PriorityNormal.
+type Priority int
+
+const (
+	PriorityLow Priority = iota - 1
+	PriorityNormal
+	PriorityHigh
+)
+
+func (p Priority) String() string {
+	switch p {
+	case PriorityLow:
+		return "low"
+	case PriorityNormal:
+		return "normal"
+	case PriorityHigh:
+		return "high"
+	default:
+		return fmt.Sprintf("priority(%d)", int(p))
+	}
+}
+
+// LoadFunc reports the current load for a shed policy, replacing its own
+// count of in-flight calls.
+type LoadFunc func() float64
+
+type shedder struct {
+	name       string
+	thresholds map[Priority]float64
+	inFlight   atomic.Int64
+	load       atomic.Pointer[LoadFunc]
+}
+
+func newShedder(name string, s Shed) (*shedder, error) {
+	thresholds := map[Priority]float64{
+		PriorityLow:    s.Low,
+		PriorityNormal: s.Normal,
+		PriorityHigh:   s.High,
+	}
+
+	var errs configErrors
+
+	prev := 0.0
+	for _, p := range []Priority{PriorityLow, PriorityNormal, PriorityHigh} {
+		if thresholds[p] < 0 {
+			errs.add(p.String(), "must not be negative, got %v", thresholds[p])
+			continue
+		}
+
+		if thresholds[p] == 0 {
+			thresholds[p] = math.Inf(1)
+		}
+
+		if thresholds[p] < prev {
+			errs.add(p.String(), "must not be below lower priorities, got %v", thresholds[p])
+		}
+
+		prev = thresholds[p]
+	}
+
+	if err := errs.err(); err != nil {
+		return nil, err
+	}
+
+	return &shedder{name: name, thresholds: thresholds}, nil
+}
+
+func (s *shedder) currentLoad() float64 {
+	if fn := s.load.Load(); fn != nil {
+		return (*fn)()
+	}
+
+	return float64(s.inFlight.Load())
+}
+
+func (s *shedder) threshold(p Priority) float64 {
+	switch {
+	case p < PriorityLow:
+		p = PriorityLow
+	case p > PriorityHigh:
+		p = PriorityHigh
+	}
+
+	return s.thresholds[p]
+}
+
+func (p *Policy) withShed(oper Operation) Operation {
+	return func(ctx context.Context) (any, error) {
+		priority := PriorityFromContext(ctx)
+		if load := p.shedder.currentLoad(); load >= p.shedder.threshold(priority) {
+			return nil, p.rejected(ctx, "shed", fmt.Errorf("%w: %s priority at load %v", ErrShed, priority, load))
+		}
+
+		p.shedder.inFlight.Add(1)
+		defer p.shedder.inFlight.Add(-1)
+
+		return oper(ctx)
+	}
+}
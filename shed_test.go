@@ -0,0 +1,111 @@
+package goresilience_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	goresilience "github.com/rickKoch/go-resilience"
+)
+
+func shedProvider(t *testing.T, shed goresilience.Shed) *goresilience.Provider {
+	t.Helper()
+
+	provider, err := goresilience.FromConfig(goresilience.Config{
+		Sheds: map[string]goresilience.Shed{
+			"test_shed": shed,
+		},
+		Targets: map[string]goresilience.PolicyNames{
+			"test_target": {Shed: "test_shed"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	return provider
+}
+
+func shedCall(provider *goresilience.Provider, priority goresilience.Priority) error {
+	ctx := goresilience.WithPriority(context.Background(), priority)
+	exec := goresilience.NewExecWithPolicy(ctx, provider.Policy("test_target"))
+	_, err := exec(func(ctx context.Context) (any, error) {
+		return successResult, nil
+	})
+	return err
+}
+
+func TestShedOrderByPriority(t *testing.T) {
+	provider := shedProvider(t, goresilience.Shed{Low: 0.5, Normal: 0.8, High: 0.95})
+
+	var load float64
+	if err := provider.SetLoadFunc("test_shed", func() float64 { return load }); err != nil {
+		t.Fatalf("failed to set load func: %v", err)
+	}
+
+	tests := []struct {
+		load float64
+		shed map[goresilience.Priority]bool
+	}{
+		{load: 0.2, shed: map[goresilience.Priority]bool{}},
+		{load: 0.6, shed: map[goresilience.Priority]bool{goresilience.PriorityLow: true}},
+		{load: 0.9, shed: map[goresilience.Priority]bool{goresilience.PriorityLow: true, goresilience.PriorityNormal: true}},
+		{load: 1, shed: map[goresilience.Priority]bool{goresilience.PriorityLow: true, goresilience.PriorityNormal: true, goresilience.PriorityHigh: true}},
+	}
+
+	for _, tt := range tests {
+		load = tt.load
+		for _, priority := range []goresilience.Priority{goresilience.PriorityLow, goresilience.PriorityNormal, goresilience.PriorityHigh} {
+			err := shedCall(provider, priority)
+			if errors.Is(err, goresilience.ErrShed) != tt.shed[priority] {
+				t.Fatalf("load %v, %s priority: expected shed %v, got: %v", tt.load, priority, tt.shed[priority], err)
+			}
+		}
+	}
+}
+
+func TestShedInFlight(t *testing.T) {
+	provider := shedProvider(t, goresilience.Shed{Low: 1, Normal: 2})
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		exec := goresilience.NewExecWithPolicy(context.Background(), provider.Policy("test_target"))
+		_, _ = exec(func(ctx context.Context) (any, error) {
+			close(started)
+			<-release
+			return successResult, nil
+		})
+	}()
+	<-started
+
+	if err := shedCall(provider, goresilience.PriorityLow); !errors.Is(err, goresilience.ErrShed) {
+		t.Fatalf("expected low priority to be shed with one call in flight, got: %v", err)
+	}
+	if err := shedCall(provider, goresilience.PriorityNormal); err != nil {
+		t.Fatalf("expected normal priority to pass, got: %v", err)
+	}
+	if err := shedCall(provider, goresilience.PriorityHigh); err != nil {
+		t.Fatalf("expected high priority never to be shed, got: %v", err)
+	}
+
+	close(release)
+	<-done
+
+	if err := shedCall(provider, goresilience.PriorityLow); err != nil {
+		t.Fatalf("expected low priority to pass once idle, got: %v", err)
+	}
+}
+
+func TestShedValidation(t *testing.T) {
+	_, err := goresilience.FromConfig(goresilience.Config{
+		Sheds: map[string]goresilience.Shed{
+			"test_shed": {Low: 10, High: 5},
+		},
+	})
+	if err == nil {
+		t.Fatal("expected an error for high priority shedding before low")
+	}
+}
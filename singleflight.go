@@ -0,0 +1,27 @@
+package goresilience
+
+import "context"
+
+// withSingleflight collapses concurrent calls that share a call key into a
+// single execution. The shared call is detached from the cancellation of
+// whichever caller started it, so a caller that gives up only stops waiting.
+func (p *Policy) withSingleflight(oper Operation) Operation {
+	return func(ctx context.Context) (any, error) {
+		key, ok := CallKeyFromContext(ctx)
+		if !ok {
+			return oper(ctx)
+		}
+
+		// Members of a group share its singleflight group.
+		ch := p.flight.DoChan(p.target+"\x00"+key, func() (any, error) {
+			return oper(context.WithoutCancel(ctx))
+		})
+
+		select {
+		case res := <-ch:
+			return res.Val, res.Err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
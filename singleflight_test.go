@@ -0,0 +1,199 @@
+package goresilience_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	goresilience "github.com/rickKoch/go-resilience"
+)
+
+func singleflightProvider(t *testing.T) *goresilience.Provider {
+	t.Helper()
+
+	provider, err := goresilience.FromConfig(goresilience.Config{
+		Targets: map[string]goresilience.PolicyNames{
+			"test_target": {Singleflight: true},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	return provider
+}
+
+func TestSingleflightCollapsesCalls(t *testing.T) {
+	provider := singleflightProvider(t)
+
+	var calls atomic.Int32
+	release := make(chan struct{})
+	results := make(chan any, 50)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx := goresilience.WithCallKey(context.Background(), "user-1")
+			exec := goresilience.NewExecWithPolicy(ctx, provider.Policy("test_target"))
+			res, err := exec(func(ctx context.Context) (any, error) {
+				calls.Add(1)
+				<-release
+				return successResult, nil
+			})
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			results <- res
+		}()
+	}
+
+	// Give every caller time to join the in-flight call before it returns.
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(results)
+
+	if calls.Load() != 1 {
+		t.Fatalf("expected exactly one execution, got %d", calls.Load())
+	}
+	for res := range results {
+		if res != successResult {
+			t.Fatalf("expected every caller to get the shared result, got %v", res)
+		}
+	}
+}
+
+func TestSingleflightSharesError(t *testing.T) {
+	provider := singleflightProvider(t)
+	errShared := errors.New("shared failure")
+
+	release := make(chan struct{})
+	errs := make(chan error, 10)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx := goresilience.WithCacheKey(context.Background(), "user-1")
+			exec := goresilience.NewExecWithPolicy(ctx, provider.Policy("test_target"))
+			_, err := exec(func(ctx context.Context) (any, error) {
+				<-release
+				return nil, errShared
+			})
+			errs <- err
+		}()
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if !errors.Is(err, errShared) {
+			t.Fatalf("expected every caller to get the shared error, got: %v", err)
+		}
+	}
+}
+
+func TestSingleflightCancelledWaiter(t *testing.T) {
+	provider := singleflightProvider(t)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var sharedErr atomic.Value
+
+	leaderCtx, cancelLeader := context.WithCancel(goresilience.WithCallKey(context.Background(), "user-1"))
+	leaderDone := make(chan error, 1)
+	go func() {
+		exec := goresilience.NewExecWithPolicy(leaderCtx, provider.Policy("test_target"))
+		_, err := exec(func(ctx context.Context) (any, error) {
+			close(started)
+			select {
+			case <-release:
+				return successResult, nil
+			case <-ctx.Done():
+				sharedErr.Store(ctx.Err())
+				return nil, ctx.Err()
+			}
+		})
+		leaderDone <- err
+	}()
+	<-started
+
+	follower := &joinContext{
+		Context: goresilience.WithCallKey(context.Background(), "user-1"),
+		joined:  make(chan struct{}),
+	}
+	followerDone := make(chan any, 1)
+	go func() {
+		exec := goresilience.NewExecWithPolicy(follower, provider.Policy("test_target"))
+		res, _ := exec(func(ctx context.Context) (any, error) {
+			t.Error("expected the follower to join the in-flight call")
+			return nil, nil
+		})
+		followerDone <- res
+	}()
+
+	<-follower.joined
+
+	cancelLeader()
+	if err := <-leaderDone; !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the cancelled caller to detach, got: %v", err)
+	}
+
+	close(release)
+	if res := <-followerDone; res != successResult {
+		t.Fatalf("expected the shared call to finish for the follower, got %v", res)
+	}
+	if err := sharedErr.Load(); err != nil {
+		t.Fatalf("expected the shared call not to be cancelled, got: %v", err)
+	}
+}
+
+func TestSingleflightWithoutKey(t *testing.T) {
+	provider := singleflightProvider(t)
+
+	var calls atomic.Int32
+	release := make(chan struct{})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			exec := goresilience.NewExecWithPolicy(context.Background(), provider.Policy("test_target"))
+			_, _ = exec(func(ctx context.Context) (any, error) {
+				calls.Add(1)
+				<-release
+				return successResult, nil
+			})
+		}()
+	}
+
+	for calls.Load() != 5 {
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+}
+
+// joinContext closes joined the first time its Done channel is asked for,
+// which the single-flight stage only does once the caller has joined the
+// call in flight.
+type joinContext struct {
+	context.Context
+	once   sync.Once
+	joined chan struct{}
+}
+
+func (c *joinContext) Done() <-chan struct{} {
+	c.once.Do(func() { close(c.joined) })
+	return c.Context.Done()
+}
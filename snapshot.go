@@ -0,0 +1,103 @@
+package goresilience
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+)
+
+type breakerSnapshot struct {
+	TakenAt  time.Time              `json:"takenAt"`
+	Breakers []breakerSnapshotEntry `json:"breakers"`
+}
+
+type breakerSnapshotEntry struct {
+	Name      string    `json:"name"`
+	Key       string    `json:"key,omitempty"`
+	State     string    `json:"state"`
+	Counts    Counts    `json:"counts"`
+	OpenUntil time.Time `json:"openUntil,omitzero"`
+}
+
+func (p *Provider) SnapshotBreakers() ([]byte, error) {
+	snapshot := breakerSnapshot{TakenAt: time.Now()}
+
+	breakers := p.current().circuitBreakers
+	for _, name := range slices.Sorted(maps.Keys(breakers)) {
+		cb := breakers[name]
+
+		snapshot.Breakers = append(snapshot.Breakers, cb.snapshot("", cb.shared.Load()))
+		if cb.keys != nil {
+			cb.keys.each(func(key string, entry *breakerEntry) {
+				snapshot.Breakers = append(snapshot.Breakers, cb.snapshot(key, entry))
+			})
+		}
+	}
+
+	return json.Marshal(snapshot)
+}
+
+func FromConfigWithState(cfg Config, snapshot []byte, opts ...Option) (*Provider, error) {
+	p, err := FromConfigWithOptions(cfg, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(snapshot) == 0 {
+		return p, nil
+	}
+
+	var s breakerSnapshot
+	if err := json.Unmarshal(snapshot, &s); err != nil {
+		return nil, fmt.Errorf("invalid breaker snapshot: %w", err)
+	}
+
+	if maxAge := p.opts.snapshotMaxAge; maxAge > 0 && time.Since(s.TakenAt) > maxAge {
+		return p, nil
+	}
+
+	now := time.Now()
+	for _, b := range s.Breakers {
+		if b.State != StateOpen.String() || !b.OpenUntil.After(now) {
+			continue
+		}
+
+		cb, ok := p.current().circuitBreakers[b.Name]
+		if !ok {
+			continue
+		}
+
+		entry := cb.shared.Load()
+		if b.Key != "" {
+			if cb.keys == nil {
+				continue
+			}
+			entry = cb.keys.get(b.Key, func() *breakerEntry {
+				return cb.newEntry(b.Key)
+			})
+		}
+
+		entry.breaker.trip(b.OpenUntil)
+	}
+
+	return p, nil
+}
+
+func (cb *circuitBreaker) snapshot(key string, entry *breakerEntry) breakerSnapshotEntry {
+	state := entry.state()
+
+	s := breakerSnapshotEntry{
+		Name:   cb.name,
+		Key:    key,
+		State:  state.String(),
+		Counts: entry.breaker.Counts(),
+	}
+
+	if state == StateOpen {
+		s.OpenUntil = entry.breaker.openUntil()
+	}
+
+	return s
+}
@@ -0,0 +1,123 @@
+package goresilience_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	goresilience "github.com/rickKoch/go-resilience"
+)
+
+var snapshotConfig = goresilience.Config{
+	CircuitBreakers: map[string]goresilience.CircuitBreaker{
+		"test_cb": {
+			MaxRequests: 1,
+			Interval:    "10s",
+			Timeout:     "5s",
+			Failures:    1,
+		},
+	},
+	Targets: map[string]goresilience.PolicyNames{
+		"test_target": {
+			CircuitBreaker: "test_cb",
+		},
+	},
+}
+
+func trippedSnapshot(t *testing.T) []byte {
+	t.Helper()
+
+	provider, err := goresilience.FromConfig(snapshotConfig)
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	exec := goresilience.NewExecutor(context.Background(), provider.Policy("test_target"))
+	_, _ = exec(func(ctx context.Context) (any, error) {
+		return nil, testError
+	})
+
+	snapshot, err := provider.SnapshotBreakers()
+	if err != nil {
+		t.Fatalf("failed to snapshot breakers: %v", err)
+	}
+
+	return snapshot
+}
+
+func TestSnapshotBreakersContents(t *testing.T) {
+	var decoded struct {
+		TakenAt  time.Time `json:"takenAt"`
+		Breakers []struct {
+			Name      string              `json:"name"`
+			State     string              `json:"state"`
+			Counts    goresilience.Counts `json:"counts"`
+			OpenUntil time.Time           `json:"openUntil"`
+		} `json:"breakers"`
+	}
+
+	if err := json.Unmarshal(trippedSnapshot(t), &decoded); err != nil {
+		t.Fatalf("failed to decode snapshot: %v", err)
+	}
+
+	if len(decoded.Breakers) != 1 {
+		t.Fatalf("expected 1 breaker, got %d", len(decoded.Breakers))
+	}
+
+	b := decoded.Breakers[0]
+	if b.Name != "test_cb" || b.State != "open" {
+		t.Fatalf("unexpected breaker snapshot: %+v", b)
+	}
+	if b.OpenUntil.Before(time.Now().Add(4 * time.Second)) {
+		t.Fatalf("expected open-until about 5s from now, got %v", b.OpenUntil)
+	}
+}
+
+func TestRestoreBreakersFromSnapshot(t *testing.T) {
+	snapshot := trippedSnapshot(t)
+
+	restored, err := goresilience.FromConfigWithState(snapshotConfig, snapshot)
+	if err != nil {
+		t.Fatalf("failed to restore provider: %v", err)
+	}
+
+	if state, _ := restored.BreakerState("test_cb"); state != goresilience.StateOpen {
+		t.Fatalf("expected restored breaker to be open, got %s", state)
+	}
+
+	exec := goresilience.NewExecutor(context.Background(), restored.Policy("test_target"))
+	_, err = exec(func(ctx context.Context) (any, error) {
+		t.Error("operation should not be executed after restoring an open breaker")
+		return nil, nil
+	})
+	if !errors.Is(err, goresilience.ErrOpenState) {
+		t.Fatalf("expected ErrOpenState, got: %v", err)
+	}
+
+	var openErr *goresilience.CircuitOpenError
+	if !errors.As(err, &openErr) || openErr.Until.Before(time.Now().Add(4*time.Second)) {
+		t.Fatalf("expected remaining open time to be preserved, got: %v", err)
+	}
+}
+
+func TestRestoreIgnoresStaleSnapshot(t *testing.T) {
+	snapshot := trippedSnapshot(t)
+	time.Sleep(10 * time.Millisecond)
+
+	restored, err := goresilience.FromConfigWithState(snapshotConfig, snapshot, goresilience.WithSnapshotMaxAge(time.Millisecond))
+	if err != nil {
+		t.Fatalf("failed to restore provider: %v", err)
+	}
+
+	if state, _ := restored.BreakerState("test_cb"); state != goresilience.StateClosed {
+		t.Fatalf("expected stale snapshot to be ignored, got %s", state)
+	}
+}
+
+func TestRestoreInvalidSnapshot(t *testing.T) {
+	if _, err := goresilience.FromConfigWithState(snapshotConfig, []byte("{not json")); err == nil {
+		t.Fatal("expected error for invalid snapshot")
+	}
+}
@@ -0,0 +1,41 @@
+package goresilience
+
+import "errors"
+
+// StageError attributes a failed call to the stage that ended it, whatever
+// the target's Order: a stage that turned it away, such as StageCircuitBreaker
+// or "bulkhead", a timeout (StageTimeout or "overallTimeout"), StageRetry
+// when the retries ran out, or StageOperation when the operation's own error
+// came through. Its message is the underlying error's, and errors.Is still
+// matches Err.
+type StageError struct {
+	Stage  string
+	Target string
+	Err    error
+}
+
+func (e *StageError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *StageError) Unwrap() error {
+	return e.Err
+}
+
+func (p *Policy) stageError(stage string, err error) error {
+	return &StageError{Stage: stage, Target: p.target, Err: err}
+}
+
+// attributeError attributes err, which no stage claimed, to the operation.
+// A result served from the cache is not a failure and is left alone.
+func (p *Policy) attributeError(err error) error {
+	var (
+		staged *StageError
+		served *ServedFromCache
+	)
+	if err == nil || errors.As(err, &staged) || errors.As(err, &served) {
+		return err
+	}
+
+	return p.stageError(StageOperation, err)
+}
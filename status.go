@@ -0,0 +1,95 @@
+package goresilience
+
+import (
+	"encoding/json"
+	"maps"
+	"slices"
+	"time"
+)
+
+// BreakerStatus describes a breaker for dashboards. State is one of the
+// State strings ("closed", "open", "half-open", "forced-open",
+// "forced-closed", "disabled"), which stay stable regardless of the
+// underlying enum values.
+type BreakerStatus struct {
+	Name      string
+	Key       string
+	State     string
+	Counts    Counts
+	OpenedAt  time.Time
+	ExpiresAt time.Time
+}
+
+func (s BreakerStatus) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Name      string     `json:"name"`
+		Key       string     `json:"key,omitempty"`
+		State     string     `json:"state"`
+		Counts    Counts     `json:"counts"`
+		OpenedAt  *time.Time `json:"openedAt,omitempty"`
+		ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+	}{
+		Name:      s.Name,
+		Key:       s.Key,
+		State:     s.State,
+		Counts:    s.Counts,
+		OpenedAt:  timeOrNil(s.OpenedAt),
+		ExpiresAt: timeOrNil(s.ExpiresAt),
+	})
+}
+
+func timeOrNil(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+
+	return &t
+}
+
+// BreakerStatuses reports every breaker sorted by name, followed by its
+// keyed entries when keying is enabled.
+func (p *Provider) BreakerStatuses() []BreakerStatus {
+	var statuses []BreakerStatus
+
+	breakers := p.current().circuitBreakers
+	for _, name := range slices.Sorted(maps.Keys(breakers)) {
+		cb := breakers[name]
+
+		statuses = append(statuses, cb.status("", cb.shared.Load()))
+		if cb.keys != nil {
+			cb.keys.each(func(key string, entry *breakerEntry) {
+				statuses = append(statuses, cb.status(key, entry))
+			})
+		}
+	}
+
+	return statuses
+}
+
+func (cb *circuitBreaker) status(key string, entry *breakerEntry) BreakerStatus {
+	status := BreakerStatus{
+		Name:   cb.name,
+		Key:    key,
+		State:  entry.state().String(),
+		Counts: entry.breaker.Counts(),
+	}
+
+	if cb.disabled.Load() {
+		status.State = StateDisabled.String()
+		return status
+	}
+
+	if state, until, ok := cb.forcedState(); ok {
+		status.State = state.String()
+		status.ExpiresAt = until
+		return status
+	}
+
+	status.ExpiresAt = entry.breaker.expiresAt()
+
+	entry.mu.Lock()
+	status.OpenedAt = entry.openedAt
+	entry.mu.Unlock()
+
+	return status
+}
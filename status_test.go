@@ -0,0 +1,113 @@
+package goresilience_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	goresilience "github.com/rickKoch/go-resilience"
+)
+
+const breakerStatusesGolden = `[{"name":"closed_cb","state":"closed","counts":{"requests":1,"totalSuccesses":1,"totalFailures":0,"consecutiveSuccesses":1,"consecutiveFailures":0},"expiresAt":"2024-01-02T03:05:05Z"},{"name":"test_cb","state":"open","counts":{"requests":0,"totalSuccesses":0,"totalFailures":0,"consecutiveSuccesses":0,"consecutiveFailures":0},"openedAt":"2024-01-02T03:04:05Z","expiresAt":"2024-01-02T03:04:15Z"}]`
+
+func TestBreakerStatusesGolden(t *testing.T) {
+	provider, err := goresilience.FromConfig(goresilience.Config{
+		CircuitBreakers: map[string]goresilience.CircuitBreaker{
+			"test_cb": {
+				MaxRequests: 1,
+				Interval:    "never",
+				Timeout:     "10s",
+				Failures:    1,
+			},
+			"closed_cb": {
+				MaxRequests: 1,
+				Interval:    "1m",
+				Timeout:     "10s",
+				Failures:    1,
+			},
+		},
+		Targets: map[string]goresilience.PolicyNames{
+			"test_target": {
+				CircuitBreaker: "test_cb",
+			},
+			"closed_target": {
+				CircuitBreaker: "closed_cb",
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	for _, name := range []string{"test_cb", "closed_cb"} {
+		goresilience.SetBreakerClock(provider, name, func() time.Time { return now })
+		if err := provider.ResetBreaker(name); err != nil {
+			t.Fatalf("failed to reset breaker: %v", err)
+		}
+	}
+
+	permit, err := provider.Acquire("test_target")
+	if err != nil {
+		t.Fatalf("expected permit, got: %v", err)
+	}
+	permit.Failure(testError)
+
+	permit, err = provider.Acquire("closed_target")
+	if err != nil {
+		t.Fatalf("expected permit, got: %v", err)
+	}
+	permit.Success()
+
+	data, err := json.Marshal(provider.BreakerStatuses())
+	if err != nil {
+		t.Fatalf("failed to marshal statuses: %v", err)
+	}
+	if string(data) != breakerStatusesGolden {
+		t.Fatalf("unexpected status JSON:\n got: %s\nwant: %s", data, breakerStatusesGolden)
+	}
+}
+
+func TestBreakerStatusStateStrings(t *testing.T) {
+	provider, clock := stateMachineProvider(t, goresilience.CircuitBreaker{
+		MaxRequests: 1,
+		Interval:    "never",
+		Timeout:     "10s",
+		Failures:    1,
+	})
+
+	status := func() goresilience.BreakerStatus {
+		statuses := provider.BreakerStatuses()
+		if len(statuses) != 1 {
+			t.Fatalf("expected one status, got %d", len(statuses))
+		}
+		return statuses[0]
+	}
+
+	if s := status(); s.State != "closed" || !s.OpenedAt.IsZero() || !s.ExpiresAt.IsZero() {
+		t.Fatalf("unexpected closed status: %+v", s)
+	}
+
+	acquire(t, provider).Failure(testError)
+	opened := clock.Now()
+	if s := status(); s.State != "open" || !s.OpenedAt.Equal(opened) || !s.ExpiresAt.Equal(opened.Add(10*time.Second)) {
+		t.Fatalf("unexpected open status: %+v", s)
+	}
+
+	clock.Advance(11 * time.Second)
+	if s := status(); s.State != "half-open" || !s.OpenedAt.Equal(opened) || !s.ExpiresAt.IsZero() {
+		t.Fatalf("unexpected half-open status: %+v", s)
+	}
+
+	acquire(t, provider).Success()
+	if s := status(); s.State != "closed" || !s.OpenedAt.IsZero() {
+		t.Fatalf("unexpected closed status: %+v", s)
+	}
+
+	if err := provider.ForceOpen("test_cb", time.Minute); err != nil {
+		t.Fatalf("failed to force open: %v", err)
+	}
+	if s := status(); s.State != "forced-open" || !s.ExpiresAt.Equal(clock.Now().Add(time.Minute)) {
+		t.Fatalf("unexpected forced status: %+v", s)
+	}
+}
@@ -0,0 +1,117 @@
+package goresilience
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+)
+
+// Formats accepted by ValidateRaw.
+const (
+	FormatYAML = "yaml"
+	FormatJSON = "json"
+)
+
+// ValidateRaw checks a config document the way FromConfigWithOptions would,
+// after decoding it strictly. It suits configs embedded in a larger
+// application config that is decoded leniently: pass the raw bytes of the
+// resilience section to catch misspelled keys. No provider is built, and
+// breakers are not added to a registry given in opts.
+func ValidateRaw(data []byte, format string, opts ...Option) error {
+	var cfg Config
+	var err error
+
+	switch format {
+	case FormatYAML:
+		cfg, err = ParseConfigYAML(data)
+	case FormatJSON:
+		cfg, err = ParseConfigJSON(data)
+	default:
+		return fmt.Errorf("unsupported config format %q: must be %q or %q", format, FormatYAML, FormatJSON)
+	}
+	if err != nil {
+		return err
+	}
+
+	p := &Provider{opts: newOptions(opts)}
+	p.opts.registry = nil
+
+	_, err = p.build(cfg, nil)
+	return err
+}
+
+// unknownFields reports every key of a generically decoded config that
+// Config does not have.
+func unknownFields(raw any) error {
+	var errs configErrors
+	checkFields(raw, reflect.TypeFor[Config](), "", &errs)
+	return errs.err()
+}
+
+func checkFields(raw any, t reflect.Type, path string, errs *configErrors) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		m, ok := raw.(map[string]any)
+		if !ok {
+			// Not an object, such as a timeout written as a bare duration;
+			// decoding reports it if it is wrong.
+			return
+		}
+
+		fields := structFields(t)
+		for _, key := range slices.Sorted(maps.Keys(m)) {
+			field, ok := fields[key]
+			if !ok {
+				errs.add(joinPath(path, key), "is not a known field")
+				continue
+			}
+			checkFields(m[key], field, joinPath(path, key), errs)
+		}
+
+	case reflect.Map:
+		m, ok := raw.(map[string]any)
+		if !ok {
+			return
+		}
+
+		for _, key := range slices.Sorted(maps.Keys(m)) {
+			checkFields(m[key], t.Elem(), entryPath(path, key), errs)
+		}
+
+	case reflect.Slice:
+		items, ok := raw.([]any)
+		if !ok {
+			return
+		}
+
+		for i, item := range items {
+			checkFields(item, t.Elem(), fmt.Sprintf("%s[%d]", path, i), errs)
+		}
+	}
+}
+
+// structFields returns the types of t's fields by their config key, with
+// embedded structs inlined.
+func structFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			maps.Copy(fields, structFields(f.Type))
+			continue
+		}
+
+		fields[fieldName(f)] = f.Type
+	}
+
+	return fields
+}
@@ -0,0 +1,192 @@
+package goresilience
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+const (
+	TimeoutModeDetached    = "detached"
+	TimeoutModeCooperative = "cooperative"
+
+	// TimeoutNone as a target's timeout reference opts it out of the
+	// default timeout.
+	TimeoutNone = "none"
+)
+
+var ErrExecutionTimeout = errors.New("execution timeout")
+
+// TimeoutError is returned when a target's timeout policy fires, as opposed
+// to the caller's own deadline expiring. It matches both ErrExecutionTimeout
+// and context.DeadlineExceeded. CleanedUp reports that the operation
+// returned within the grace period, in which case Err holds its error.
+type TimeoutError struct {
+	Target    string
+	Limit     time.Duration
+	CleanedUp bool
+	Err       error
+}
+
+func (e *TimeoutError) Error() string {
+	msg := fmt.Sprintf("timeout of %s for target %q exceeded: %s", e.Limit, e.Target, context.DeadlineExceeded)
+	if !e.CleanedUp {
+		return msg
+	}
+
+	if e.Err == nil {
+		return msg + " (cleanup completed)"
+	}
+
+	return fmt.Sprintf("%s (cleanup completed: %s)", msg, e.Err)
+}
+
+func (e *TimeoutError) Unwrap() []error {
+	if e.Err == nil {
+		return []error{ErrExecutionTimeout, context.DeadlineExceeded}
+	}
+
+	return []error{ErrExecutionTimeout, context.DeadlineExceeded, e.Err}
+}
+
+type timeout struct {
+	duration    time.Duration
+	grace       time.Duration
+	drain       time.Duration
+	warnAt      time.Duration
+	cooperative bool
+}
+
+func newTimeout(t TimeoutSpec, unit time.Duration) (*timeout, error) {
+	var errs configErrors
+
+	duration := errs.duration("duration", t.Duration, unit)
+	drain := errs.duration("drainTimeout", t.DrainTimeout, unit)
+	grace := errs.duration("gracePeriod", t.GracePeriod, unit)
+
+	warnAt, err := parseWarnAt(t.WarnAt, duration, unit)
+	if err != nil {
+		errs.add("warnAt", "%v", err)
+	}
+
+	if t.Mode != "" && t.Mode != TimeoutModeDetached && t.Mode != TimeoutModeCooperative {
+		errs.add("mode", "invalid mode %q: must be %q or %q", t.Mode, TimeoutModeDetached, TimeoutModeCooperative)
+	}
+
+	if err := errs.err(); err != nil {
+		return nil, err
+	}
+
+	if t.Mode == TimeoutModeCooperative {
+		return &timeout{duration: duration, warnAt: warnAt, cooperative: true}, nil
+	}
+
+	return &timeout{duration: duration, grace: grace, drain: drain, warnAt: warnAt}, nil
+}
+
+// parseWarnAt accepts a fraction of the timeout in (0, 1) or a duration.
+func parseWarnAt(val string, limit, unit time.Duration) (time.Duration, error) {
+	if f, err := strconv.ParseFloat(val, 64); err == nil && f > 0 && f < 1 {
+		return time.Duration(f * float64(limit)), nil
+	}
+
+	return parseDuration(val, unit)
+}
+
+func (p *Policy) withTimeout(oper Operation) Operation {
+	return p.timed(p.timeout, oper)
+}
+
+// withOverallTimeout bounds the whole call, including every retry attempt
+// and the backoff sleeps between them.
+func (p *Policy) withOverallTimeout(oper Operation) Operation {
+	return p.timed(p.overallTimeout, oper)
+}
+
+func (p *Policy) timed(t *timeout, oper Operation) Operation {
+	if t.warnAt > 0 && t.warnAt < t.duration {
+		oper = p.warnSlow(t, oper)
+	}
+
+	if t.cooperative {
+		return func(ctx context.Context) (any, error) {
+			timeoutCtx, cancel := context.WithTimeout(ctx, t.duration)
+			defer cancel()
+
+			res, err := oper(timeoutCtx)
+			if timeoutCtx.Err() != nil {
+				err = p.timeoutError(ctx, t, err)
+			}
+
+			return res, err
+		}
+	}
+
+	run := detached{
+		timeout: t.duration,
+		grace:   t.grace,
+		drain:   t.drain,
+		onLeak: func() {
+			p.events.leaked(p.target)
+		},
+		inFlight: p.inFlight,
+	}
+
+	return func(ctx context.Context) (any, error) {
+		res, fired, err := run.run(ctx, oper)
+		if fired {
+			err = p.timeoutError(ctx, t, err)
+		}
+
+		return res, err
+	}
+}
+
+// timeoutError attributes a deadline error to the policy once its deadline
+// has fired, unless the caller's expired as well.
+func (p *Policy) timeoutError(ctx context.Context, t *timeout, err error) error {
+	if !errors.Is(err, context.DeadlineExceeded) || ctx.Err() != nil {
+		return err
+	}
+
+	// A per-attempt timeout passing through the overall one fired only once.
+	stage := StageTimeout
+	var inner *TimeoutError
+	if !errors.As(err, &inner) {
+		p.counters.timedOut()
+		if p.observer != nil {
+			p.observer.OnTimeout(p.target, t.duration)
+		}
+
+		if t == p.overallTimeout {
+			stage = "overallTimeout"
+		}
+		traceFailure(ctx, stage, "timed out after %s", t.duration)
+	}
+
+	timeoutErr := &TimeoutError{Target: p.target, Limit: t.duration}
+
+	var grace *graceError
+	if errors.As(err, &grace) {
+		timeoutErr.CleanedUp = true
+		timeoutErr.Err = grace.err
+	}
+
+	return p.stageError(stage, timeoutErr)
+}
+
+// warnSlow fires the slow-operation hooks once if oper is still running when
+// the warn threshold passes. It never affects the result.
+func (p *Policy) warnSlow(t *timeout, oper Operation) Operation {
+	return func(ctx context.Context) (any, error) {
+		start := time.Now()
+		timer := time.AfterFunc(t.warnAt, func() {
+			p.events.slow(p.target, time.Since(start), t.duration)
+		})
+		defer timer.Stop()
+
+		return oper(ctx)
+	}
+}
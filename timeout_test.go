@@ -2,16 +2,19 @@ package goresilience_test
 
 import (
 	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	goresilience "github.com/rickKoch/go-resilience"
 )
 
-var timeouts = map[string]string{
-	"long":  "10s",
-	"mid":   "8s",
-	"short": "1s",
+var timeouts = map[string]goresilience.TimeoutSpec{
+	"long":  {Duration: "10s"},
+	"mid":   {Duration: "8s"},
+	"short": {Duration: "1s"},
 }
 
 func TestResilienceTimeout(t *testing.T) {
@@ -36,7 +39,428 @@ func TestResilienceTimeout(t *testing.T) {
 		time.Sleep(2 * time.Second)
 		return "", nil
 	})
-	if err != context.DeadlineExceeded {
+	if !errors.Is(err, context.DeadlineExceeded) {
 		t.Fatalf("it should've failed with timeout error, but exited with: %s", err)
 	}
 }
+
+func timeoutProvider(t testing.TB, timeout goresilience.TimeoutSpec) *goresilience.Provider {
+	t.Helper()
+
+	provider, err := goresilience.FromConfig(goresilience.Config{
+		Timeouts: map[string]goresilience.TimeoutSpec{
+			"test_timeout": timeout,
+		},
+		Targets: map[string]goresilience.PolicyNames{
+			"test_target": {
+				Timeout: "test_timeout",
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	return provider
+}
+
+func TestCooperativeTimeout(t *testing.T) {
+	provider := timeoutProvider(t, goresilience.TimeoutSpec{Duration: "50ms", Mode: goresilience.TimeoutModeCooperative})
+	exec := goresilience.NewExecWithPolicy(context.Background(), provider.Policy("test_target"))
+
+	start := time.Now()
+	result, err := exec(func(ctx context.Context) (any, error) {
+		select {
+		case <-ctx.Done():
+			return "partial", ctx.Err()
+		case <-time.After(2 * time.Second):
+			return "done", nil
+		}
+	})
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("expected operation to return on cancellation, took %s", elapsed)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got: %v", err)
+	}
+	if result != "partial" {
+		t.Fatalf("expected the operation's own result, got %v", result)
+	}
+}
+
+func TestTimeoutInvalidMode(t *testing.T) {
+	_, err := goresilience.FromConfig(goresilience.Config{
+		Timeouts: map[string]goresilience.TimeoutSpec{
+			"test_timeout": {Duration: "1s", Mode: "eventually"},
+		},
+	})
+	var cfgErr *goresilience.ConfigError
+	if !errors.As(err, &cfgErr) || cfgErr.Path != `timeouts["test_timeout"].mode` {
+		t.Fatalf("expected a ConfigError for the timeout mode, got: %v", err)
+	}
+}
+
+func benchmarkTimeout(b *testing.B, mode string) {
+	provider := timeoutProvider(b, goresilience.TimeoutSpec{Duration: "1s", Mode: mode})
+	exec := goresilience.NewExecWithPolicy(context.Background(), provider.Policy("test_target"))
+	oper := func(ctx context.Context) (any, error) {
+		return nil, nil
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = exec(oper)
+	}
+}
+
+func BenchmarkTimeoutDetached(b *testing.B) {
+	benchmarkTimeout(b, goresilience.TimeoutModeDetached)
+}
+
+func BenchmarkTimeoutCooperative(b *testing.B) {
+	benchmarkTimeout(b, goresilience.TimeoutModeCooperative)
+}
+
+func TestDrainTimeoutWaitsForOperation(t *testing.T) {
+	provider := timeoutProvider(t, goresilience.TimeoutSpec{Duration: "20ms", DrainTimeout: "500ms"})
+	exec := goresilience.NewExecWithPolicy(context.Background(), provider.Policy("test_target"))
+
+	var finished atomic.Bool
+	_, err := exec(func(ctx context.Context) (any, error) {
+		time.Sleep(60 * time.Millisecond)
+		finished.Store(true)
+		return "late", nil
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got: %v", err)
+	}
+	if !finished.Load() {
+		t.Fatal("expected the operation to have finished before returning")
+	}
+	if leaked := provider.LeakedOperations(); leaked != 0 {
+		t.Fatalf("expected no leaked operations, got %d", leaked)
+	}
+}
+
+func TestDrainTimeoutReportsLeak(t *testing.T) {
+	provider := timeoutProvider(t, goresilience.TimeoutSpec{Duration: "20ms", DrainTimeout: "20ms"})
+	events := provider.Events()
+	exec := goresilience.NewExecWithPolicy(context.Background(), provider.Policy("test_target"))
+
+	release := make(chan struct{})
+	defer close(release)
+
+	start := time.Now()
+	_, err := exec(func(ctx context.Context) (any, error) {
+		<-release
+		return nil, nil
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("expected the drain window to bound the wait, took %s", elapsed)
+	}
+	if leaked := provider.LeakedOperations(); leaked != 1 {
+		t.Fatalf("expected one leaked operation, got %d", leaked)
+	}
+
+	e := nextEvent(t, events)
+	if e.Type != goresilience.OperationLeaked || e.Target != "test_target" {
+		t.Fatalf("expected leak event for test_target, got %+v", e)
+	}
+}
+
+func TestTimeoutErrorFromPolicy(t *testing.T) {
+	provider := timeoutProvider(t, goresilience.TimeoutSpec{Duration: "20ms"})
+	exec := goresilience.NewExecWithPolicy(context.Background(), provider.Policy("test_target"))
+
+	_, err := exec(func(ctx context.Context) (any, error) {
+		time.Sleep(200 * time.Millisecond)
+		return nil, nil
+	})
+
+	var timeoutErr *goresilience.TimeoutError
+	if !errors.As(err, &timeoutErr) {
+		t.Fatalf("expected TimeoutError, got: %v", err)
+	}
+	if timeoutErr.Target != "test_target" || timeoutErr.Limit != 20*time.Millisecond {
+		t.Fatalf("unexpected timeout error fields: %+v", timeoutErr)
+	}
+	if !errors.Is(err, goresilience.ErrExecutionTimeout) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected error to match both sentinels, got: %v", err)
+	}
+
+	var stageErr *goresilience.StageError
+	if !errors.As(err, &stageErr) || stageErr.Stage != goresilience.StageTimeout || stageErr.Target != "test_target" {
+		t.Fatalf("expected the error to be attributed to the timeout, got: %+v", stageErr)
+	}
+}
+
+func TestTimeoutErrorNotFromParentDeadline(t *testing.T) {
+	for _, mode := range []string{goresilience.TimeoutModeDetached, goresilience.TimeoutModeCooperative} {
+		t.Run(mode, func(t *testing.T) {
+			provider := timeoutProvider(t, goresilience.TimeoutSpec{Duration: "1s", Mode: mode})
+
+			ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+			defer cancel()
+
+			exec := goresilience.NewExecWithPolicy(ctx, provider.Policy("test_target"))
+			_, err := exec(func(ctx context.Context) (any, error) {
+				<-ctx.Done()
+				return nil, ctx.Err()
+			})
+
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Fatalf("expected deadline exceeded, got: %v", err)
+			}
+			if errors.Is(err, goresilience.ErrExecutionTimeout) {
+				t.Fatalf("parent deadline must not be reported as a policy timeout, got: %v", err)
+			}
+		})
+	}
+}
+
+func TestOverallTimeoutCoversRetries(t *testing.T) {
+	provider, err := goresilience.FromConfig(goresilience.Config{
+		Timeouts: map[string]goresilience.TimeoutSpec{
+			"per_attempt": {Duration: "100ms"},
+			"total":       {Duration: "300ms"},
+		},
+		Retries: map[string]goresilience.Retry{
+			"test_retry": {Duration: "1ms", MaxRetries: 10},
+		},
+		Targets: map[string]goresilience.PolicyNames{
+			"test_target": {
+				Timeout:        "per_attempt",
+				OverallTimeout: "total",
+				Retry:          "test_retry",
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	var attempts atomic.Int32
+	exec := goresilience.NewExecWithPolicy(context.Background(), provider.Policy("test_target"))
+
+	start := time.Now()
+	_, err = exec(func(ctx context.Context) (any, error) {
+		attempts.Add(1)
+		time.Sleep(time.Second)
+		return nil, nil
+	})
+	elapsed := time.Since(start)
+
+	if elapsed >= 400*time.Millisecond {
+		t.Fatalf("expected the total budget to bound the call, took %s", elapsed)
+	}
+	if n := attempts.Load(); n < 2 || n > 4 {
+		t.Fatalf("expected roughly three attempts, got %d", n)
+	}
+
+	var timeoutErr *goresilience.TimeoutError
+	if !errors.As(err, &timeoutErr) || timeoutErr.Limit != 300*time.Millisecond {
+		t.Fatalf("expected the total budget's TimeoutError, got: %v", err)
+	}
+}
+
+func TestSlowOperationWarning(t *testing.T) {
+	tests := []struct {
+		name   string
+		warnAt string
+	}{
+		{name: "fraction", warnAt: "0.2"},
+		{name: "duration", warnAt: "40ms"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := timeoutProvider(t, goresilience.TimeoutSpec{Duration: "200ms", WarnAt: tt.warnAt})
+
+			var warnings atomic.Int32
+			provider.OnSlowOperation(func(target string, elapsed, limit time.Duration) {
+				warnings.Add(1)
+				if target != "test_target" || limit != 200*time.Millisecond || elapsed < 40*time.Millisecond {
+					t.Errorf("unexpected warning: target %q, elapsed %s, limit %s", target, elapsed, limit)
+				}
+			})
+
+			exec := goresilience.NewExecWithPolicy(context.Background(), provider.Policy("test_target"))
+
+			result, err := exec(func(ctx context.Context) (any, error) {
+				return successResult, nil
+			})
+			if err != nil || result != successResult {
+				t.Fatalf("unexpected result %v, %v", result, err)
+			}
+
+			result, err = exec(func(ctx context.Context) (any, error) {
+				time.Sleep(100 * time.Millisecond)
+				return successResult, nil
+			})
+			if err != nil || result != successResult {
+				t.Fatalf("expected the warning not to affect the result, got %v, %v", result, err)
+			}
+
+			if n := warnings.Load(); n != 1 {
+				t.Fatalf("expected exactly one warning, got %d", n)
+			}
+		})
+	}
+}
+
+func TestSlowOperationInvalidWarnAt(t *testing.T) {
+	_, err := goresilience.FromConfig(goresilience.Config{
+		Timeouts: map[string]goresilience.TimeoutSpec{
+			"test_timeout": {Duration: "1s", WarnAt: "1.5"},
+		},
+	})
+	var cfgErr *goresilience.ConfigError
+	if !errors.As(err, &cfgErr) || cfgErr.Path != `timeouts["test_timeout"].warnAt` {
+		t.Fatalf("expected a ConfigError for warnAt, got: %v", err)
+	}
+}
+
+func TestLiteralTimeoutReference(t *testing.T) {
+	provider, err := goresilience.FromConfig(goresilience.Config{
+		Timeouts: map[string]goresilience.TimeoutSpec{
+			"fast": {Duration: "20ms"},
+			"2s":   {Duration: "20ms"},
+		},
+		Targets: map[string]goresilience.PolicyNames{
+			"literal":   {Timeout: "20ms"},
+			"named":     {Timeout: "fast"},
+			"ambiguous": {Timeout: "2s"},
+			"overall":   {OverallTimeout: "20ms"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	for _, target := range []string{"literal", "named", "ambiguous", "overall"} {
+		t.Run(target, func(t *testing.T) {
+			exec := goresilience.NewExecWithPolicy(context.Background(), provider.Policy(target))
+
+			start := time.Now()
+			_, err := exec(func(ctx context.Context) (any, error) {
+				time.Sleep(time.Second)
+				return nil, nil
+			})
+
+			var timeoutErr *goresilience.TimeoutError
+			if !errors.As(err, &timeoutErr) || timeoutErr.Limit != 20*time.Millisecond {
+				t.Fatalf("expected a 20ms timeout, got: %v", err)
+			}
+			if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+				t.Fatalf("expected the call to be cut at 20ms, took %s", elapsed)
+			}
+		})
+	}
+
+	warnings := provider.Warnings()
+	if len(warnings) != 1 || !strings.Contains(warnings[0], `"ambiguous"`) {
+		t.Fatalf("expected one warning about the ambiguous target, got %v", warnings)
+	}
+
+	d, _ := provider.Describe("literal")
+	if d.Timeout == nil || d.Timeout.Name != "20ms" || d.Timeout.Duration != "20ms" {
+		t.Fatalf("expected the literal timeout to be described, got %+v", d.Timeout)
+	}
+	if _, ok := provider.Config().Timeouts["20ms"]; ok {
+		t.Fatal("expected the literal timeout to stay off the named timeouts")
+	}
+}
+
+func TestDefaultTimeout(t *testing.T) {
+	provider, err := goresilience.FromConfig(goresilience.Config{
+		Timeouts: map[string]goresilience.TimeoutSpec{
+			"safety_net": {Duration: "20ms"},
+			"generous":   {Duration: "1s"},
+		},
+		Defaults: goresilience.Defaults{
+			Timeout: "safety_net",
+		},
+		Targets: map[string]goresilience.PolicyNames{
+			"listed":    {},
+			"own":       {Timeout: "generous"},
+			"opted_out": {Timeout: goresilience.TimeoutNone},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	slow := func(ctx context.Context) (any, error) {
+		time.Sleep(100 * time.Millisecond)
+		return successResult, nil
+	}
+
+	for _, target := range []string{"unlisted", "listed"} {
+		exec := goresilience.NewExecWithPolicy(context.Background(), provider.Policy(target))
+		if _, err := exec(slow); !errors.Is(err, goresilience.ErrExecutionTimeout) {
+			t.Fatalf("expected %s to get the default timeout, got: %v", target, err)
+		}
+	}
+
+	for _, target := range []string{"own", "opted_out"} {
+		exec := goresilience.NewExecWithPolicy(context.Background(), provider.Policy(target))
+		if result, err := exec(slow); err != nil || result != successResult {
+			t.Fatalf("expected %s to skip the default timeout, got %v, %v", target, result, err)
+		}
+	}
+}
+
+func TestGracePeriodCleanupCompleted(t *testing.T) {
+	provider := timeoutProvider(t, goresilience.TimeoutSpec{Duration: "20ms", GracePeriod: "500ms"})
+	exec := goresilience.NewExecWithPolicy(context.Background(), provider.Policy("test_target"))
+
+	rollbackErr := errors.New("rolled back")
+	var cleaned atomic.Bool
+	_, err := exec(func(ctx context.Context) (any, error) {
+		<-ctx.Done()
+		time.Sleep(20 * time.Millisecond)
+		cleaned.Store(true)
+		return nil, rollbackErr
+	})
+
+	if !cleaned.Load() {
+		t.Fatal("expected the executor to wait for cleanup")
+	}
+
+	var timeoutErr *goresilience.TimeoutError
+	if !errors.As(err, &timeoutErr) || !timeoutErr.CleanedUp {
+		t.Fatalf("expected a cleaned up TimeoutError, got: %v", err)
+	}
+	if !errors.Is(err, rollbackErr) {
+		t.Fatalf("expected the operation's error to be returned, got: %v", err)
+	}
+}
+
+func TestGracePeriodCleanupExpired(t *testing.T) {
+	provider := timeoutProvider(t, goresilience.TimeoutSpec{Duration: "20ms", GracePeriod: "20ms"})
+	exec := goresilience.NewExecWithPolicy(context.Background(), provider.Policy("test_target"))
+
+	release := make(chan struct{})
+	defer close(release)
+
+	start := time.Now()
+	_, err := exec(func(ctx context.Context) (any, error) {
+		<-release
+		return nil, testError
+	})
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("expected the grace period to bound the wait, took %s", elapsed)
+	}
+
+	var timeoutErr *goresilience.TimeoutError
+	if !errors.As(err, &timeoutErr) || timeoutErr.CleanedUp {
+		t.Fatalf("expected a TimeoutError without cleanup, got: %v", err)
+	}
+	if errors.Is(err, testError) {
+		t.Fatalf("expected no operation error without cleanup, got: %v", err)
+	}
+}
@@ -0,0 +1,271 @@
+package goresilience
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ExecutionTrace records one execution for debugging: each attempt of the
+// operation, and what the stages decided along the way. For a failed
+// execution, Stage is where the error came from: StageOperation, or the
+// stage whose rejection or timeout failed it last. Cached is set when the
+// result came from the cache.
+type ExecutionTrace struct {
+	Target   string
+	Start    time.Time
+	Duration time.Duration
+	Attempts []AttemptTrace
+	Steps    []TraceStep
+	Err      error
+	Stage    string
+	Cached   bool
+}
+
+// AttemptTrace is one call of the operation. Abandoned is set for an
+// attempt that was still running when the execution returned, such as one
+// cut off by a detached timeout.
+type AttemptTrace struct {
+	Start     time.Time
+	Duration  time.Duration
+	Err       error
+	Abandoned bool
+}
+
+// TraceStep is a stage's decision, such as a breaker rejecting an attempt
+// or a retry backing off. Stage is the name the stage has in PolicyNames.
+type TraceStep struct {
+	Time   time.Time
+	Stage  string
+	Detail string
+}
+
+// String renders the trace as a summary line followed by its attempts and
+// steps in order, each at its offset from the start.
+func (t ExecutionTrace) String() string {
+	var b strings.Builder
+
+	outcome := "succeeded"
+	if t.Err != nil {
+		outcome = "failed"
+	}
+	fmt.Fprintf(&b, "%s: %s after %d attempts in %s", t.Target, outcome, len(t.Attempts), t.Duration)
+	if t.Err != nil {
+		fmt.Fprintf(&b, ": %v", t.Err)
+	}
+
+	type line struct {
+		at   time.Time
+		text string
+	}
+
+	lines := make([]line, 0, len(t.Attempts)+len(t.Steps))
+	for i, a := range t.Attempts {
+		text := fmt.Sprintf("attempt %d succeeded after %s", i+1, a.Duration)
+		switch {
+		case a.Abandoned:
+			text = fmt.Sprintf("attempt %d abandoned", i+1)
+		case a.Err != nil:
+			text = fmt.Sprintf("attempt %d failed after %s: %v", i+1, a.Duration, a.Err)
+		}
+		lines = append(lines, line{a.Start, text})
+	}
+	for _, s := range t.Steps {
+		lines = append(lines, line{s.Time, s.Stage + ": " + s.Detail})
+	}
+
+	slices.SortStableFunc(lines, func(a, b line) int {
+		return a.at.Compare(b.at)
+	})
+
+	for _, l := range lines {
+		fmt.Fprintf(&b, "\n  +%s %s", l.at.Sub(t.Start), l.text)
+	}
+
+	return b.String()
+}
+
+// WithExecutionTraces keeps the last size executions of every target for
+// RecentExecutions. Tracing is off by default, and costs nothing then.
+func WithExecutionTraces(size int) Option {
+	return func(o *options) {
+		o.traceSize = max(size, 0)
+	}
+}
+
+// RecentExecutions returns up to n of target's latest executions, oldest
+// first. It returns nothing unless the provider was built
+// WithExecutionTraces.
+func (p *Provider) RecentExecutions(target string, n int) []ExecutionTrace {
+	if canonical, ok := p.current().aliases[target]; ok {
+		target = canonical
+	}
+
+	p.gaugeMu.Lock()
+	ring := p.traces[target]
+	p.gaugeMu.Unlock()
+
+	if ring == nil || n <= 0 {
+		return nil
+	}
+
+	return ring.recent(n)
+}
+
+func (p *Provider) traceRing(target string) *traceRing {
+	if p.opts.traceSize == 0 {
+		return nil
+	}
+
+	p.gaugeMu.Lock()
+	defer p.gaugeMu.Unlock()
+
+	ring, ok := p.traces[target]
+	if !ok {
+		ring = &traceRing{buf: make([]ExecutionTrace, 0, p.opts.traceSize)}
+		p.traces[target] = ring
+	}
+
+	return ring
+}
+
+// traceRing holds a target's latest traces, overwriting the oldest once
+// full.
+type traceRing struct {
+	mu   sync.Mutex
+	buf  []ExecutionTrace
+	next int
+}
+
+func (r *traceRing) add(t ExecutionTrace) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.buf) < cap(r.buf) {
+		r.buf = append(r.buf, t)
+		return
+	}
+
+	r.buf[r.next] = t
+	r.next = (r.next + 1) % len(r.buf)
+}
+
+func (r *traceRing) recent(n int) []ExecutionTrace {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ordered := append(slices.Clone(r.buf[r.next:]), r.buf[:r.next]...)
+	return ordered[max(len(ordered)-n, 0):]
+}
+
+// tracer collects one execution's trace. Stages find it in the context;
+// attempts abandoned by a timeout may still report after finish, so it is
+// locked and finish hands out a copy. stage is where the latest failure
+// came from: a stage that turned the call away, or the operation.
+type tracer struct {
+	mu    sync.Mutex
+	trace ExecutionTrace
+	ended []bool
+	stage string
+}
+
+type tracerKey struct{}
+
+func startTrace(ctx context.Context, t *tracer, target string) context.Context {
+	t.trace = ExecutionTrace{Target: target, Start: time.Now()}
+	return context.WithValue(ctx, tracerKey{}, t)
+}
+
+// traceStep records a stage's decision if ctx belongs to a traced
+// execution.
+func traceStep(ctx context.Context, stage, format string, args ...any) {
+	if t, ok := ctx.Value(tracerKey{}).(*tracer); ok {
+		t.step(stage, false, fmt.Sprintf(format, args...))
+	}
+}
+
+// traceFailure is like traceStep for a decision that fails the attempt or
+// the call, such as a rejection or a timeout.
+func traceFailure(ctx context.Context, stage, format string, args ...any) {
+	if t, ok := ctx.Value(tracerKey{}).(*tracer); ok {
+		t.step(stage, true, fmt.Sprintf(format, args...))
+	}
+}
+
+func (t *tracer) step(stage string, failed bool, detail string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.trace.Steps = append(t.trace.Steps, TraceStep{Time: time.Now(), Stage: stage, Detail: detail})
+	if failed {
+		t.stage = stage
+	}
+}
+
+// traceCached marks the trace of the call that ctx belongs to, if it has
+// one, as answered from the cache.
+func traceCached(ctx context.Context) {
+	if c := callFrom(ctx); c != nil && c.trace != nil {
+		c.trace.mu.Lock()
+		c.trace.trace.Cached = true
+		c.trace.mu.Unlock()
+	}
+}
+
+// traceAttempts records each call of oper as an attempt of the trace of
+// the call that ctx belongs to, if it has one.
+func traceAttempts(oper Operation) Operation {
+	return func(ctx context.Context) (any, error) {
+		if c := callFrom(ctx); c != nil && c.trace != nil {
+			return c.trace.attempt(ctx, oper)
+		}
+
+		return oper(ctx)
+	}
+}
+
+func (t *tracer) attempt(ctx context.Context, oper Operation) (any, error) {
+	t.mu.Lock()
+	i := len(t.trace.Attempts)
+	start := time.Now()
+	t.trace.Attempts = append(t.trace.Attempts, AttemptTrace{Start: start})
+	t.ended = append(t.ended, false)
+	t.mu.Unlock()
+
+	res, err := oper(ctx)
+
+	t.mu.Lock()
+	t.trace.Attempts[i].Duration = time.Since(start)
+	t.trace.Attempts[i].Err = err
+	t.ended[i] = true
+	if err != nil {
+		t.stage = StageOperation
+	}
+	t.mu.Unlock()
+
+	return res, err
+}
+
+func (t *tracer) finish(err error) ExecutionTrace {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	trace := t.trace
+	if !trace.Start.IsZero() {
+		trace.Duration = time.Since(trace.Start)
+	}
+	trace.Attempts = slices.Clone(trace.Attempts)
+	for i, ended := range t.ended {
+		trace.Attempts[i].Abandoned = !ended
+	}
+	trace.Steps = slices.Clone(trace.Steps)
+	trace.Err = err
+	if err != nil {
+		trace.Stage = t.stage
+	}
+
+	return trace
+}
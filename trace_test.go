@@ -0,0 +1,138 @@
+package goresilience_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	goresilience "github.com/rickKoch/go-resilience"
+)
+
+type traceSummary struct {
+	attempts []string
+	steps    []string
+	err      string
+}
+
+func summarize(trace goresilience.ExecutionTrace) traceSummary {
+	var s traceSummary
+	for _, a := range trace.Attempts {
+		switch {
+		case a.Abandoned:
+			s.attempts = append(s.attempts, "abandoned")
+		case a.Err != nil:
+			s.attempts = append(s.attempts, a.Err.Error())
+		default:
+			s.attempts = append(s.attempts, "ok")
+		}
+	}
+	for _, step := range trace.Steps {
+		s.steps = append(s.steps, step.Stage+": "+step.Detail)
+	}
+	if trace.Err != nil {
+		s.err = trace.Err.Error()
+	}
+	return s
+}
+
+func TestRecentExecutions(t *testing.T) {
+	cfg := reloadConfig(2, reloadBreaker)
+	cfg.Aliases = map[string][]string{"test_target": {"test_alias"}}
+
+	provider, err := goresilience.FromConfigWithOptions(cfg, goresilience.WithExecutionTraces(2))
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	exec := goresilience.NewExecWithPolicy(context.Background(), provider.Policy("test_target"))
+
+	// A success, then three failures that open the breaker, then a call
+	// the breaker rejects.
+	_, _ = exec(succeed)
+	countAttempts(exec)
+	countAttempts(goresilience.NewExecWithPolicy(context.Background(), provider.Policy("test_alias")))
+
+	traces := provider.RecentExecutions("test_target", 10)
+	if len(traces) != 2 {
+		t.Fatalf("expected the ring to keep the last 2 executions, got %d", len(traces))
+	}
+
+	want := []traceSummary{
+		{
+			attempts: []string{"failed", "failed", "failed"},
+			steps: []string{
+				"retry: backing off 1ms after attempt 1",
+				"retry: backing off 1ms after attempt 2",
+			},
+			err: "retries exhausted after 3 attempts: failed",
+		},
+		{
+			steps: []string{"circuitBreaker: rejected: circuit breaker is open"},
+			err:   `circuit breaker "test_cb" for target "test_target" rejected the request`,
+		},
+	}
+
+	for i, trace := range traces {
+		got := summarize(trace)
+		if !strings.HasPrefix(got.err, want[i].err) {
+			t.Errorf("trace %d: expected error %q, got %q", i, want[i].err, got.err)
+		}
+		got.err = want[i].err
+		if !reflect.DeepEqual(got, want[i]) {
+			t.Errorf("trace %d: expected %+v, got %+v\n%s", i, want[i], got, trace)
+		}
+		if trace.Target != "test_target" {
+			t.Errorf("trace %d: expected the canonical target, got %q", i, trace.Target)
+		}
+	}
+
+	if latest := provider.RecentExecutions("test_alias", 1); len(latest) != 1 || latest[0].Attempts != nil {
+		t.Fatalf("expected the alias to show the rejected call last, got %v", latest)
+	}
+}
+
+func TestRecentExecutionsTimeout(t *testing.T) {
+	provider, err := goresilience.FromConfigWithOptions(goresilience.Config{
+		Timeouts: map[string]goresilience.TimeoutSpec{"test_timeout": {Duration: "10ms"}},
+		Targets:  map[string]goresilience.PolicyNames{"test_target": {Timeout: "test_timeout"}},
+	}, goresilience.WithExecutionTraces(5))
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	release := make(chan struct{})
+	defer close(release)
+
+	_, _ = goresilience.NewExecWithPolicy(context.Background(), provider.Policy("test_target"))(func(ctx context.Context) (any, error) {
+		<-release
+		return nil, errors.New("late")
+	})
+
+	traces := provider.RecentExecutions("test_target", 5)
+	if len(traces) != 1 {
+		t.Fatalf("expected one trace, got %d", len(traces))
+	}
+
+	got := summarize(traces[0])
+	if !reflect.DeepEqual(got.attempts, []string{"abandoned"}) || !reflect.DeepEqual(got.steps, []string{"timeout: timed out after 10ms"}) {
+		t.Fatalf("expected an abandoned attempt and a timeout, got %+v", got)
+	}
+	if s := traces[0].String(); !strings.Contains(s, "attempt 1 abandoned") || !strings.Contains(s, "timeout: timed out after 10ms") {
+		t.Fatalf("expected the rendered trace to list the attempt and the timeout, got:\n%s", s)
+	}
+}
+
+func TestRecentExecutionsDisabled(t *testing.T) {
+	provider, err := goresilience.FromConfig(reloadConfig(1, reloadBreaker))
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	countAttempts(goresilience.NewExecWithPolicy(context.Background(), provider.Policy("test_target")))
+
+	if traces := provider.RecentExecutions("test_target", 10); traces != nil {
+		t.Fatalf("expected no traces without WithExecutionTraces, got %v", traces)
+	}
+}
@@ -0,0 +1,82 @@
+package goresilience
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// TypedExecutor is an Executor for operations that return a T.
+type TypedExecutor[T any] func(op func(ctx context.Context) (T, error)) (T, error)
+
+// NewTypedExecutor is like NewExecutor for operations that return a T.
+func NewTypedExecutor[T any](ctx context.Context, policy *Policy) TypedExecutor[T] {
+	return func(op func(ctx context.Context) (T, error)) (T, error) {
+		return Execute(ctx, policy, op)
+	}
+}
+
+// Execute runs op under policy and returns its result as a T, or T's zero
+// value on error. A result served from the cache after a failure comes
+// with its *ServedFromCache error. A fallback result that is not a T is an error.
+func Execute[T any](ctx context.Context, policy *Policy, op func(ctx context.Context) (T, error)) (T, error) {
+	res, err := policy.Execute(ctx, func(ctx context.Context) (any, error) {
+		return op(ctx)
+	})
+
+	return typedResult[T](res, err)
+}
+
+func typedResult[T any](res any, err error) (T, error) {
+	var zero T
+
+	var served *ServedFromCache
+	if err != nil && !errors.As(err, &served) {
+		return zero, err
+	}
+
+	// A nil result is the zero value of a pointer or interface T.
+	if res == nil {
+		return zero, err
+	}
+
+	v, ok := res.(T)
+	if !ok {
+		return zero, fmt.Errorf("result of type %T is not a %s", res, reflect.TypeFor[T]())
+	}
+
+	return v, err
+}
+
+// ExecuteVoid runs op, which has no result, under policy.
+func ExecuteVoid(ctx context.Context, policy *Policy, op func(ctx context.Context) error) error {
+	_, err := policy.Execute(ctx, func(ctx context.Context) (any, error) {
+		return nil, op(ctx)
+	})
+
+	return err
+}
+
+// Wrap1 returns fn running under policy, as a drop-in replacement for it:
+//
+//	getUser := goresilience.Wrap1(policy, client.GetUser)
+//	user, err := getUser(ctx, id)
+//
+// Each call runs with its own context and may run concurrently with others.
+func Wrap1[A, R any](policy *Policy, fn func(context.Context, A) (R, error)) func(context.Context, A) (R, error) {
+	return func(ctx context.Context, a A) (R, error) {
+		return Execute(ctx, policy, func(ctx context.Context) (R, error) {
+			return fn(ctx, a)
+		})
+	}
+}
+
+// Wrap2 is Wrap1 for functions of two arguments.
+func Wrap2[A, B, R any](policy *Policy, fn func(context.Context, A, B) (R, error)) func(context.Context, A, B) (R, error) {
+	return func(ctx context.Context, a A, b B) (R, error) {
+		return Execute(ctx, policy, func(ctx context.Context) (R, error) {
+			return fn(ctx, a, b)
+		})
+	}
+}
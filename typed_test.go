@@ -0,0 +1,308 @@
+package goresilience_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	goresilience "github.com/rickKoch/go-resilience"
+)
+
+type account struct {
+	ID      string
+	Balance int
+}
+
+func TestExecuteStruct(t *testing.T) {
+	provider, err := goresilience.FromConfig(reloadConfig(2, reloadBreaker))
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	attempts := 0
+	got, err := goresilience.Execute(context.Background(), provider.Policy("test_target"), func(ctx context.Context) (account, error) {
+		attempts++
+		if attempts < 3 {
+			return account{ID: "partial"}, errors.New("failed")
+		}
+		return account{ID: "acc-1", Balance: 10}, nil
+	})
+	if err != nil {
+		t.Fatalf("expected the third attempt to succeed, got %v", err)
+	}
+	if got != (account{ID: "acc-1", Balance: 10}) {
+		t.Fatalf("expected the account, got %+v", got)
+	}
+
+	// The breaker, now open after three failures, rejects the call.
+	countAttempts(goresilience.NewExecWithPolicy(context.Background(), provider.Policy("test_target")))
+
+	got, err = goresilience.Execute(context.Background(), provider.Policy("test_target"), func(ctx context.Context) (account, error) {
+		return account{ID: "acc-1"}, nil
+	})
+	if !errors.Is(err, goresilience.ErrOpenState) || got != (account{}) {
+		t.Fatalf("expected a zero account and ErrOpenState, got %+v, %v", got, err)
+	}
+}
+
+func TestExecutePointer(t *testing.T) {
+	provider, err := goresilience.FromConfig(reloadConfig(0, reloadBreaker))
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	policy := provider.Policy("test_target")
+
+	got, err := goresilience.Execute(context.Background(), policy, func(ctx context.Context) (*account, error) {
+		return &account{ID: "acc-1"}, nil
+	})
+	if err != nil || got == nil || got.ID != "acc-1" {
+		t.Fatalf("expected the account, got %+v, %v", got, err)
+	}
+
+	got, err = goresilience.Execute(context.Background(), policy, func(ctx context.Context) (*account, error) {
+		return nil, nil
+	})
+	if err != nil || got != nil {
+		t.Fatalf("expected a nil account without error, got %+v, %v", got, err)
+	}
+
+	got, err = goresilience.Execute(context.Background(), policy, func(ctx context.Context) (*account, error) {
+		return &account{ID: "partial"}, errors.New("failed")
+	})
+	if err == nil || got != nil {
+		t.Fatalf("expected a nil account and an error, got %+v, %v", got, err)
+	}
+}
+
+func TestExecuteInterface(t *testing.T) {
+	provider, err := goresilience.FromConfig(reloadConfig(0, reloadBreaker))
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	exec := goresilience.NewTypedExecutor[fmt.Stringer](context.Background(), provider.Policy("test_target"))
+
+	got, err := exec(func(ctx context.Context) (fmt.Stringer, error) {
+		return &strings.Builder{}, nil
+	})
+	if err != nil || got == nil {
+		t.Fatalf("expected a Stringer, got %v, %v", got, err)
+	}
+
+	got, err = exec(func(ctx context.Context) (fmt.Stringer, error) {
+		return nil, nil
+	})
+	if err != nil || got != nil {
+		t.Fatalf("expected a nil Stringer without error, got %v, %v", got, err)
+	}
+}
+
+func TestExecuteFallbackType(t *testing.T) {
+	provider, err := goresilience.FromConfig(fallbackConfig(goresilience.FallbackOnAny))
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	provider.SetFallback("test_target", func(ctx context.Context, err error) (any, error) {
+		return "cached account", nil
+	})
+
+	got, err := goresilience.Execute(context.Background(), provider.Policy("test_target"), func(ctx context.Context) (account, error) {
+		return account{}, errors.New("failed")
+	})
+	if err == nil || !strings.Contains(err.Error(), "result of type string is not a goresilience_test.account") {
+		t.Fatalf("expected a type error for the fallback result, got %+v, %v", got, err)
+	}
+
+	provider.SetFallback("test_target", func(ctx context.Context, err error) (any, error) {
+		return account{ID: "fallback"}, nil
+	})
+
+	got, err = goresilience.Execute(context.Background(), provider.Policy("test_target"), func(ctx context.Context) (account, error) {
+		return account{}, errors.New("failed")
+	})
+	if err != nil || got.ID != "fallback" {
+		t.Fatalf("expected the fallback account, got %+v, %v", got, err)
+	}
+}
+
+func TestExecuteServedFromCache(t *testing.T) {
+	provider, _ := cacheProvider(t, goresilience.Cache{TTL: "1m"})
+	policy := provider.Policy("test_target")
+	ctx := goresilience.WithCacheKey(context.Background(), "acc-1")
+
+	if _, err := goresilience.Execute(ctx, policy, func(ctx context.Context) (account, error) {
+		return account{ID: "acc-1", Balance: 10}, nil
+	}); err != nil {
+		t.Fatalf("failed to fill the cache: %v", err)
+	}
+
+	got, err := goresilience.Execute(ctx, policy, func(ctx context.Context) (account, error) {
+		return account{}, errors.New("failed")
+	})
+	var served *goresilience.ServedFromCache
+	if !errors.As(err, &served) || got.Balance != 10 {
+		t.Fatalf("expected the cached account with ServedFromCache, got %+v, %v", got, err)
+	}
+}
+
+func TestExecuteVoidRetry(t *testing.T) {
+	provider, err := goresilience.FromConfig(reloadConfig(2, reloadBreaker))
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	attempts := 0
+	err = goresilience.ExecuteVoid(context.Background(), provider.Policy("test_target"), func(ctx context.Context) error {
+		attempts++
+		if attempts < 2 {
+			return errors.New("failed")
+		}
+		return nil
+	})
+	if err != nil || attempts != 2 {
+		t.Fatalf("expected success on the second attempt, got %v after %d attempts", err, attempts)
+	}
+
+	errPermanent := errors.New("permanent")
+	t.Cleanup(goresilience.RegisterPermanentError(errPermanent))
+
+	attempts = 0
+	err = goresilience.ExecuteVoid(context.Background(), provider.Policy("test_target"), func(ctx context.Context) error {
+		attempts++
+		return errPermanent
+	})
+	if !errors.Is(err, errPermanent) || attempts != 1 {
+		t.Fatalf("expected a permanent error to stop retries, got %v after %d attempts", err, attempts)
+	}
+}
+
+func TestExecuteVoidBreaker(t *testing.T) {
+	provider, err := goresilience.FromConfig(reloadConfig(0, reloadBreaker))
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	policy := provider.Policy("test_target")
+	for i := 0; i < 3; i++ {
+		if err := goresilience.ExecuteVoid(context.Background(), policy, func(ctx context.Context) error {
+			return errors.New("failed")
+		}); err == nil {
+			t.Fatal("expected the failure to be returned")
+		}
+	}
+
+	called := false
+	err = goresilience.ExecuteVoid(context.Background(), policy, func(ctx context.Context) error {
+		called = true
+		return nil
+	})
+	if !errors.Is(err, goresilience.ErrOpenState) || called {
+		t.Fatalf("expected the open breaker to reject the call, got %v, called %t", err, called)
+	}
+}
+
+func TestExecuteVoidTimeout(t *testing.T) {
+	provider := timeoutProvider(t, goresilience.TimeoutSpec{Duration: "10ms"})
+
+	err := goresilience.ExecuteVoid(context.Background(), provider.Policy("test_target"), func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	if !errors.Is(err, goresilience.ErrExecutionTimeout) {
+		t.Fatalf("expected the policy's timeout, got %v", err)
+	}
+}
+
+type fakeAccounts struct {
+	mu       sync.Mutex
+	failures int
+	calls    int
+}
+
+func (f *fakeAccounts) Get(ctx context.Context, id string) (account, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls++
+	if f.failures > 0 {
+		f.failures--
+		return account{}, errors.New("failed")
+	}
+	return account{ID: id, Balance: 10}, nil
+}
+
+func (f *fakeAccounts) Transfer(ctx context.Context, from, to string) (int, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		return 0, errors.New("expected the call's deadline")
+	}
+
+	_, err := f.Get(ctx, from)
+	return 10, err
+}
+
+func TestWrap1(t *testing.T) {
+	provider, err := goresilience.FromConfig(reloadConfig(2, reloadBreaker))
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	client := &fakeAccounts{failures: 2}
+	get := goresilience.Wrap1(provider.Policy("test_target"), client.Get)
+
+	got, err := get(context.Background(), "acc-1")
+	if err != nil || got.ID != "acc-1" {
+		t.Fatalf("expected the retries to reach the account, got %+v, %v", got, err)
+	}
+	if client.calls != 3 {
+		t.Fatalf("expected 3 calls, got %d", client.calls)
+	}
+
+	// Three failures in a row open the breaker, which rejects the next
+	// call without reaching the client.
+	client.failures, client.calls = 3, 0
+	if _, err := get(context.Background(), "acc-1"); err == nil {
+		t.Fatal("expected the failures to be returned")
+	}
+	if _, err := get(context.Background(), "acc-1"); !errors.Is(err, goresilience.ErrOpenState) {
+		t.Fatalf("expected the open breaker to reject the call, got %v", err)
+	}
+	if client.calls != 3 {
+		t.Fatalf("expected the rejected call not to reach the client, got %d calls", client.calls)
+	}
+}
+
+func TestWrap2Concurrent(t *testing.T) {
+	provider, err := goresilience.FromConfig(reloadConfig(2, reloadBreaker))
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	client := &fakeAccounts{}
+	transfer := goresilience.Wrap2(provider.Policy("test_target"), client.Transfer)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+
+			if n, err := transfer(ctx, "acc-1", "acc-2"); err != nil || n != 10 {
+				t.Errorf("expected the transfer to succeed, got %d, %v", n, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if client.calls != 20 {
+		t.Fatalf("expected 20 calls, got %d", client.calls)
+	}
+}
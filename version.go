@@ -0,0 +1,147 @@
+package goresilience
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Config schema versions. V1 reads bare integer durations as microseconds
+// and counts retries with MaxRetries; V2 requires units on every duration
+// and counts attempts with MaxAttempts. An unversioned config accepts
+// either retry field and reads bare integers as the provider's options say.
+const (
+	ConfigV1 = "v1"
+	ConfigV2 = "v2"
+)
+
+// durationFields are the config fields that hold durations or, for timeout
+// references, may hold a duration literal.
+var durationFields = map[string]bool{
+	"duration":       true,
+	"gracePeriod":    true,
+	"drainTimeout":   true,
+	"warnAt":         true,
+	"interval":       true,
+	"timeout":        true,
+	"overallTimeout": true,
+	"keyIdleTimeout": true,
+	"rampUp":         true,
+	"warmUp":         true,
+	"probeTimeout":   true,
+	"window":         true,
+	"cooldown":       true,
+	"maxWait":        true,
+	"ttl":            true,
+	"latencyTarget":  true,
+	"minDeadline":    true,
+}
+
+// versionDurationUnit returns the unit bare integer durations are read in
+// under version, given the unit the provider's options ask for.
+func versionDurationUnit(version string, unit time.Duration) (time.Duration, error) {
+	switch version {
+	case "":
+		return unit, nil
+	case ConfigV1:
+		return time.Microsecond, nil
+	case ConfigV2:
+		return 0, nil
+	default:
+		return 0, fmt.Errorf("unknown config version %q: must be %q or %q", version, ConfigV1, ConfigV2)
+	}
+}
+
+// checkRetryVersion rejects the retry field version does not have.
+func checkRetryVersion(version string, r Retry) error {
+	var errs configErrors
+
+	switch {
+	case version == ConfigV1 && r.MaxAttempts != 0:
+		errs.add("maxAttempts", "is not available in config version %s, use maxRetries", ConfigV1)
+	case version == ConfigV2 && r.MaxRetries != 0:
+		errs.add("maxRetries", "is replaced by maxAttempts in config version %s", ConfigV2)
+	}
+
+	return errs.err()
+}
+
+// MigrateConfig rewrites cfg for the toVersion schema. Going to v2, bare
+// integer durations get their microsecond unit and MaxRetries becomes
+// MaxAttempts; going to v1, MaxAttempts becomes MaxRetries. An unversioned
+// config is migrated as v1.
+func MigrateConfig(cfg Config, toVersion string) (Config, error) {
+	if _, err := versionDurationUnit(cfg.Version, 0); err != nil {
+		return Config{}, err
+	}
+	if toVersion == "" {
+		return Config{}, fmt.Errorf("unknown config version %q: must be %q or %q", toVersion, ConfigV1, ConfigV2)
+	}
+	if _, err := versionDurationUnit(toVersion, 0); err != nil {
+		return Config{}, err
+	}
+
+	migrated, err := mapConfigStrings(cfg, func(field, val string) (string, error) {
+		if toVersion != ConfigV2 || !durationFields[field] {
+			return val, nil
+		}
+
+		if _, named := cfg.Timeouts[val]; named {
+			return val, nil
+		}
+
+		i, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return val, nil
+		}
+
+		return (time.Duration(i) * time.Microsecond).String(), nil
+	})
+	if err != nil {
+		return Config{}, err
+	}
+
+	migrate := func(r Retry) Retry {
+		if toVersion == ConfigV2 {
+			if r.MaxAttempts == 0 {
+				r.MaxAttempts = retriesToAttempts(r.MaxRetries)
+			}
+			r.MaxRetries = 0
+		} else {
+			if r.MaxAttempts != 0 {
+				r.MaxRetries = attemptsToRetries(r.MaxAttempts)
+			}
+			r.MaxAttempts = 0
+		}
+		return r
+	}
+
+	for name, r := range migrated.Retries {
+		migrated.Retries[name] = migrate(r)
+	}
+	for name, t := range migrated.Targets {
+		if t.RetrySpec != nil {
+			*t.RetrySpec = migrate(*t.RetrySpec)
+		}
+		migrated.Targets[name] = t
+	}
+
+	migrated.Version = toVersion
+	return migrated, nil
+}
+
+func retriesToAttempts(retries int) int {
+	if retries < 0 {
+		return -1
+	}
+
+	return retries + 1
+}
+
+func attemptsToRetries(attempts int) int {
+	if attempts < 0 {
+		return -1
+	}
+
+	return attempts - 1
+}
@@ -0,0 +1,191 @@
+package goresilience_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	goresilience "github.com/rickKoch/go-resilience"
+)
+
+func versionedConfig(version string, retry goresilience.Retry) goresilience.Config {
+	return goresilience.Config{
+		Version: version,
+		Retries: map[string]goresilience.Retry{
+			"test_retry": retry,
+		},
+		Targets: map[string]goresilience.PolicyNames{
+			"test_target": {Retry: "test_retry"},
+		},
+	}
+}
+
+func TestConfigVersions(t *testing.T) {
+	tests := []struct {
+		name  string
+		cfg   goresilience.Config
+		calls int32
+	}{
+		{
+			name:  "v1",
+			cfg:   versionedConfig(goresilience.ConfigV1, goresilience.Retry{Duration: "1000", MaxRetries: 2}),
+			calls: 3,
+		},
+		{
+			name:  "v2",
+			cfg:   versionedConfig(goresilience.ConfigV2, goresilience.Retry{Duration: "1ms", MaxAttempts: 3}),
+			calls: 3,
+		},
+		{
+			name:  "unversioned attempts",
+			cfg:   versionedConfig("", goresilience.Retry{Duration: "1ms", MaxAttempts: 2}),
+			calls: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, err := goresilience.FromConfig(tt.cfg)
+			if err != nil {
+				t.Fatalf("failed to create provider: %v", err)
+			}
+
+			exec := goresilience.NewExecWithPolicy(context.Background(), provider.Policy("test_target"))
+			if got := countAttempts(exec); got != tt.calls {
+				t.Fatalf("expected %d attempts, got %d", tt.calls, got)
+			}
+		})
+	}
+}
+
+func TestConfigVersionsCircuitBreaker(t *testing.T) {
+	for _, registry := range []*goresilience.BreakerRegistry{nil, goresilience.NewBreakerRegistry()} {
+		cfg := goresilience.Config{
+			Version: goresilience.ConfigV1,
+			CircuitBreakers: map[string]goresilience.CircuitBreaker{
+				"test_cb": {Interval: "60000000", Timeout: "60000000", MaxRequests: 1, Failures: 1},
+			},
+			Targets: map[string]goresilience.PolicyNames{
+				"test_target": {CircuitBreaker: "test_cb"},
+			},
+		}
+
+		var opts []goresilience.Option
+		if registry != nil {
+			opts = append(opts, goresilience.WithBreakerRegistry(registry))
+		}
+
+		provider, err := goresilience.FromConfigWithOptions(cfg, opts...)
+		if err != nil {
+			t.Fatalf("failed to create provider: %v", err)
+		}
+
+		exec := goresilience.NewExecWithPolicy(context.Background(), provider.Policy("test_target"))
+		_, _ = exec(func(ctx context.Context) (any, error) {
+			return nil, errors.New("failed")
+		})
+
+		if state, _ := provider.BreakerState("test_cb"); state != goresilience.StateOpen {
+			t.Fatalf("expected the breaker to open for a minute, got %s", state)
+		}
+	}
+}
+
+func TestConfigVersionsInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  goresilience.Config
+		path string
+	}{
+		{
+			name: "unknown version",
+			cfg:  versionedConfig("v3", goresilience.Retry{Duration: "1ms"}),
+			path: "version",
+		},
+		{
+			name: "v1 with maxAttempts",
+			cfg:  versionedConfig(goresilience.ConfigV1, goresilience.Retry{Duration: "1ms", MaxAttempts: 3}),
+			path: `retries["test_retry"].maxAttempts`,
+		},
+		{
+			name: "v2 with maxRetries",
+			cfg:  versionedConfig(goresilience.ConfigV2, goresilience.Retry{Duration: "1ms", MaxRetries: 2}),
+			path: `retries["test_retry"].maxRetries`,
+		},
+		{
+			name: "v2 with bare integer",
+			cfg:  versionedConfig(goresilience.ConfigV2, goresilience.Retry{Duration: "1000", MaxAttempts: 3}),
+			path: `retries["test_retry"].duration`,
+		},
+		{
+			name: "both retry counts",
+			cfg:  versionedConfig("", goresilience.Retry{Duration: "1ms", MaxRetries: 2, MaxAttempts: 3}),
+			path: `retries["test_retry"].maxAttempts`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := goresilience.FromConfig(tt.cfg)
+
+			var cfgErr *goresilience.ConfigError
+			if !errors.As(err, &cfgErr) || cfgErr.Path != tt.path {
+				t.Fatalf("expected a ConfigError for %s, got: %v", tt.path, err)
+			}
+		})
+	}
+}
+
+func TestMigrateConfig(t *testing.T) {
+	v1 := versionedConfig(goresilience.ConfigV1, goresilience.Retry{Duration: "1000", MaxRetries: 2})
+	v1.Timeouts = map[string]goresilience.TimeoutSpec{
+		"test_timeout": {Duration: "500000"},
+	}
+	v1.Targets["test_target"] = goresilience.PolicyNames{Retry: "test_retry", Timeout: "test_timeout", MinDeadline: "100"}
+
+	v2, err := goresilience.MigrateConfig(v1, goresilience.ConfigV2)
+	if err != nil {
+		t.Fatalf("failed to migrate to v2: %v", err)
+	}
+
+	if v2.Version != goresilience.ConfigV2 {
+		t.Fatalf("expected version %q, got %q", goresilience.ConfigV2, v2.Version)
+	}
+	if got := v2.Retries["test_retry"]; got != (goresilience.Retry{Duration: "1ms", MaxAttempts: 3}) {
+		t.Fatalf("unexpected migrated retry %+v", got)
+	}
+	if got := v2.Timeouts["test_timeout"].Duration; got != "500ms" {
+		t.Fatalf("expected the timeout to get a unit, got %q", got)
+	}
+	if got := v2.Targets["test_target"]; got.MinDeadline != "100µs" || got.Timeout != "test_timeout" {
+		t.Fatalf("unexpected migrated target %+v", got)
+	}
+	if v1.Retries["test_retry"].MaxRetries != 2 || v1.Timeouts["test_timeout"].Duration != "500000" {
+		t.Fatal("expected the original config to be left alone")
+	}
+
+	back, err := goresilience.MigrateConfig(v2, goresilience.ConfigV1)
+	if err != nil {
+		t.Fatalf("failed to migrate back to v1: %v", err)
+	}
+	if got := back.Retries["test_retry"]; got != (goresilience.Retry{Duration: "1ms", MaxRetries: 2}) {
+		t.Fatalf("unexpected retry after migrating back %+v", got)
+	}
+
+	for _, cfg := range []goresilience.Config{v1, v2, back} {
+		provider, err := goresilience.FromConfig(cfg)
+		if err != nil {
+			t.Fatalf("failed to create provider from %s config: %v", cfg.Version, err)
+		}
+
+		exec := goresilience.NewExecWithPolicy(context.Background(), provider.Policy("test_target"))
+		if got := countAttempts(exec); got != 3 {
+			t.Fatalf("expected 3 attempts from %s config, got %d", cfg.Version, got)
+		}
+	}
+
+	if _, err := goresilience.MigrateConfig(v1, "v3"); err == nil || !strings.Contains(err.Error(), "unknown config version") {
+		t.Fatalf("expected an unknown version error, got: %v", err)
+	}
+}
@@ -0,0 +1,83 @@
+package goresilience
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"time"
+)
+
+const defaultConfigPollInterval = 2 * time.Second
+
+// WatchConfigFile loads the config file at path, applies it with
+// UpdateConfig and then keeps polling the file, applying it again whenever
+// its content changes. A change that cannot be read, parsed or applied is
+// reported through OnReloadError and the last good config stays in place.
+// Watching stops when ctx is cancelled or the provider is closed. Only the
+// initial load's error is returned.
+func (p *Provider) WatchConfigFile(ctx context.Context, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	if err := p.applyConfigFile(path, data); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return nil
+	}
+
+	p.watchers.Add(1)
+	go p.watchConfigFile(ctx, path, data)
+
+	return nil
+}
+
+func (p *Provider) watchConfigFile(ctx context.Context, path string, last []byte) {
+	defer p.watchers.Done()
+
+	ticker := time.NewTicker(p.opts.configPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-p.done:
+			return
+		case <-ticker.C:
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			p.events.reloadFailed(path, err)
+			continue
+		}
+
+		if bytes.Equal(data, last) {
+			continue
+		}
+		last = data
+
+		if err := p.applyConfigFile(path, data); err != nil {
+			p.events.reloadFailed(path, err)
+			continue
+		}
+
+		p.events.reloaded(path)
+	}
+}
+
+func (p *Provider) applyConfigFile(path string, data []byte) error {
+	cfg, err := parseConfigFile(path, data)
+	if err != nil {
+		return err
+	}
+
+	return p.UpdateConfig(cfg)
+}
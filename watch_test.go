@@ -0,0 +1,127 @@
+package goresilience_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	goresilience "github.com/rickKoch/go-resilience"
+)
+
+func writeRetryConfig(t *testing.T, path string, maxRetries int) {
+	t.Helper()
+
+	data := fmt.Sprintf("retries:\n  test_retry:\n    duration: 1ms\n    maxRetries: %d\ntargets:\n  test_target:\n    retry: test_retry\n", maxRetries)
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+}
+
+func waitForAttempts(t *testing.T, provider *goresilience.Provider, want int32) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		got := countAttempts(goresilience.NewExecWithPolicy(context.Background(), provider.Policy("test_target")))
+		if got == want {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %d attempts, got %d", want, got)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func watchedProvider(t *testing.T) *goresilience.Provider {
+	t.Helper()
+
+	provider, err := goresilience.FromConfigWithOptions(goresilience.Config{}, goresilience.WithConfigPollInterval(5*time.Millisecond))
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+	t.Cleanup(func() { _ = provider.Close() })
+
+	return provider
+}
+
+func TestWatchConfigFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "resilience.yaml")
+	writeRetryConfig(t, path, 1)
+
+	provider := watchedProvider(t)
+
+	reloads := make(chan string, 10)
+	provider.OnReload(func(path string) { reloads <- path })
+
+	errs := make(chan error, 10)
+	provider.OnReloadError(func(path string, err error) { errs <- err })
+
+	if err := provider.WatchConfigFile(context.Background(), path); err != nil {
+		t.Fatalf("failed to watch config: %v", err)
+	}
+	waitForAttempts(t, provider, 2)
+
+	writeRetryConfig(t, path, 4)
+	waitForAttempts(t, provider, 5)
+	if got := <-reloads; got != path {
+		t.Fatalf("expected a reload of %s, got %s", path, got)
+	}
+
+	// A half-written file is reported and the last good config stays.
+	if err := os.WriteFile(path, []byte("retries:\n  test_retry: [\n"), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	select {
+	case <-errs:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected the malformed config to be reported")
+	}
+	waitForAttempts(t, provider, 5)
+
+	writeRetryConfig(t, path, 2)
+	waitForAttempts(t, provider, 3)
+}
+
+func TestWatchConfigFileStops(t *testing.T) {
+	for _, stop := range []string{"cancel", "close"} {
+		t.Run(stop, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "resilience.yaml")
+			writeRetryConfig(t, path, 1)
+
+			provider := watchedProvider(t)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			if err := provider.WatchConfigFile(ctx, path); err != nil {
+				t.Fatalf("failed to watch config: %v", err)
+			}
+
+			if stop == "cancel" {
+				cancel()
+			} else if err := provider.Close(); err != nil {
+				t.Fatalf("failed to close provider: %v", err)
+			}
+
+			time.Sleep(20 * time.Millisecond)
+			writeRetryConfig(t, path, 4)
+			time.Sleep(50 * time.Millisecond)
+
+			if got := provider.Config().Retries["test_retry"].MaxRetries; got != 1 {
+				t.Fatalf("expected the watcher to have stopped, got %d retries", got)
+			}
+		})
+	}
+}
+
+func TestWatchConfigFileInitialError(t *testing.T) {
+	provider := watchedProvider(t)
+
+	if err := provider.WatchConfigFile(context.Background(), filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Fatal("expected an error for a missing file")
+	}
+}